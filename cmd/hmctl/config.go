@@ -0,0 +1,240 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// config is the hmctl configuration file
+type config struct {
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       map[string]*profile `yaml:"profiles"`
+}
+
+// profile describes how to connect to a single CCU
+type profile struct {
+	URL       string     `yaml:"url"`
+	Token     string     `yaml:"token"`
+	TokenEnv  string     `yaml:"token_env"`
+	TokenFile string     `yaml:"token_file"`
+	TLS       *tlsConfig `yaml:"tls"`
+	SSH       *sshConfig `yaml:"ssh"`
+
+	// UnixSocket and DialTarget connect to a Unix domain socket or another
+	// host:port than the one of the URL, e.g. a local proxy forwarding to the CCU
+	UnixSocket string `yaml:"unix_socket"`
+	DialTarget string `yaml:"dial_target"`
+
+	// Hosts maps host names to static IP addresses; DNSCacheTTL caches
+	// lookups, e.g. "10m", to ride out flaky DNS servers
+	Hosts       map[string]string `yaml:"hosts"`
+	DNSCacheTTL string            `yaml:"dns_cache_ttl"`
+
+	// Timezone is the IANA time zone of the CCU, used to convert its timestamps
+	Timezone string `yaml:"timezone"`
+
+	// EncryptionKeyFile holds the key for the encrypted token file and
+	// exports; HOMEMATIC_ENCRYPTION_KEY is used if it is not set
+	EncryptionKeyFile string `yaml:"encryption_key_file"`
+
+	// MetadataFile is the local store of device and channel tags, labels and
+	// notes, which exports include
+	MetadataFile string `yaml:"metadata_file"`
+}
+
+// tlsConfig holds the TLS options of a profile
+type tlsConfig struct {
+	InsecureSkipVerify *bool  `yaml:"insecure_skip_verify"`
+	CAFile             string `yaml:"ca_file"`
+	ServerName         string `yaml:"server_name"`
+}
+
+// sshConfig holds the SSH tunnel options of a profile
+type sshConfig struct {
+	Addr           string `yaml:"addr"`
+	User           string `yaml:"user"`
+	KeyFile        string `yaml:"key_file"`
+	PasswordEnv    string `yaml:"password_env"`
+	KnownHostsFile string `yaml:"known_hosts_file"`
+}
+
+// defaultConfigPath returns the default location of the configuration file
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "hmctl", "config.yaml")
+}
+
+// loadConfig reads a configuration file; a missing file yields an empty configuration
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// profile returns the named profile, or the default profile if name is empty
+func (c *config) profile(name string) (*profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		if len(c.Profiles) == 1 {
+			for _, p := range c.Profiles {
+				return p, nil
+			}
+		}
+		return nil, nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(c.Profiles))
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+// resolveToken returns the token of the profile from its configured source
+func (p *profile) resolveToken() (string, error) {
+	switch {
+	case p.Token != "":
+		return p.Token, nil
+	case p.TokenEnv != "":
+		return os.Getenv(p.TokenEnv), nil
+	case p.TokenFile != "":
+		key, err := p.encryptionKey()
+		if err != nil {
+			return "", err
+		}
+		data, err := homematic.ReadEncryptedFile(expandHome(p.TokenFile), key)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", nil
+}
+
+// encryptionKey returns the key for encrypted files, or nil if none is configured
+func (p *profile) encryptionKey() (*homematic.EncryptionKey, error) {
+	if p != nil && p.EncryptionKeyFile != "" {
+		return homematic.LoadEncryptionKeyFile(expandHome(p.EncryptionKeyFile))
+	}
+	return homematic.LoadEncryptionKey()
+}
+
+// metadataStore opens the metadata store of the profile; it is nil if none is configured
+func (p *profile) metadataStore() (*homematic.MetadataStore, error) {
+	if p == nil || p.MetadataFile == "" {
+		return nil, nil
+	}
+	key, err := p.encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return homematic.OpenMetadataStore(expandHome(p.MetadataFile), key)
+}
+
+// apply configures the TLS settings of the profile on the client's transport
+func (t *tlsConfig) apply(httpClient *http.Client) error {
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		return errors.New("TLS options require an *http.Transport")
+	}
+
+	tlsCfg := &tls.Config{ServerName: t.ServerName}
+	if transport.TLSClientConfig != nil {
+		tlsCfg = transport.TLSClientConfig.Clone()
+		tlsCfg.ServerName = t.ServerName
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(expandHome(t.CAFile))
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in CA file %s", t.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+		tlsCfg.InsecureSkipVerify = false
+	}
+	if t.InsecureSkipVerify != nil {
+		tlsCfg.InsecureSkipVerify = *t.InsecureSkipVerify
+	}
+
+	transport.TLSClientConfig = tlsCfg
+	return nil
+}
+
+// apply routes the requests of the client through the SSH tunnel of the profile
+func (s *sshConfig) apply(client *homematic.Client) error {
+	tunnel := &homematic.SSHTunnel{Addr: s.Addr, User: s.User}
+	if s.KeyFile != "" {
+		auth, err := homematic.SSHKeyAuth(expandHome(s.KeyFile), nil)
+		if err != nil {
+			return err
+		}
+		tunnel.Auth = append(tunnel.Auth, auth)
+	}
+	if s.PasswordEnv != "" {
+		tunnel.Auth = append(tunnel.Auth, ssh.Password(os.Getenv(s.PasswordEnv)))
+	}
+
+	knownHosts := s.KnownHostsFile
+	if knownHosts == "" {
+		knownHosts = "~/.ssh/known_hosts"
+	}
+	callback, err := homematic.SSHKnownHosts(expandHome(knownHosts))
+	if err != nil {
+		return err
+	}
+	tunnel.HostKeyCallback = callback
+
+	return client.SetSSHTunnel(tunnel)
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadProfile(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CABIN_TOKEN", "env-token")
+
+	path := writeConfig(t, `
+default_profile: home
+profiles:
+  home:
+    url: https://ccu.home
+    token_file: `+tokenFile+`
+  cabin:
+    url: https://ccu.cabin
+    token_env: CABIN_TOKEN
+    tls:
+      insecure_skip_verify: false
+`)
+
+	a := &app{}
+	if err := a.loadProfile(path, ""); err != nil {
+		t.Fatalf("loadProfile failed: %v", err)
+	}
+	if a.baseURL != "https://ccu.home" || a.token != "file-token" {
+		t.Errorf("unexpected default profile settings: %s %s", a.baseURL, a.token)
+	}
+
+	a = &app{baseURL: "https://override"}
+	if err := a.loadProfile(path, "cabin"); err != nil {
+		t.Fatalf("loadProfile failed: %v", err)
+	}
+	if a.baseURL != "https://override" || a.token != "env-token" {
+		t.Errorf("expected flags to take precedence, got %s %s", a.baseURL, a.token)
+	}
+
+	if err := (&app{}).loadProfile(path, "office"); err == nil || !strings.Contains(err.Error(), "available: cabin, home") {
+		t.Errorf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestLoadProfileMissingConfig(t *testing.T) {
+	a := &app{}
+	if err := a.loadProfile(filepath.Join(t.TempDir(), "missing.yaml"), ""); err != nil {
+		t.Errorf("expected missing config to be ignored, got %v", err)
+	}
+}
+
+func TestRunWithProfile(t *testing.T) {
+	server := newTestServer(t, map[string]string{"sysvarlist.cgi": testSysvarList})
+	path := writeConfig(t, "profiles:\n  test:\n    url: "+server.URL+"\n    token: secret\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--config", path, "--profile", "test", "sysvar", "get", "Presence"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "present") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestEncryptedTokenFileAndExport(t *testing.T) {
+	key, err := homematic.NewEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte(key.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tokenFile := filepath.Join(dir, "token")
+	if err := homematic.WriteEncryptedFile(tokenFile, []byte("file-token\n"), key); err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestServer(t, map[string]string{"sysvarlist.cgi": testSysvarList})
+	path := writeConfig(t, "profiles:\n  home:\n    url: "+server.URL+"\n    token_file: "+tokenFile+"\n    encryption_key_file: "+keyFile+"\n")
+
+	a := &app{}
+	if err := a.loadProfile(path, ""); err != nil {
+		t.Fatalf("loadProfile failed: %v", err)
+	}
+	if a.token != "file-token" {
+		t.Errorf("expected the decrypted token, got %q", a.token)
+	}
+
+	out := filepath.Join(dir, "sysvars.json")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--config", path, "export", "--what", "sysvars", "--file", out, "--encrypt"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	data, err := homematic.ReadEncryptedFile(out, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := os.ReadFile(out); !homematic.IsEncrypted(raw) || !strings.Contains(string(data), "Presence") {
+		t.Errorf("expected an encrypted export of the system variables, got %s", data)
+	}
+}
+
+func TestReplayEncryptedChangeLogWithProfileKey(t *testing.T) {
+	key, err := homematic.NewEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	if err := os.WriteFile(keyFile, []byte(key.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	changeLog, err := homematic.OpenChangeLog(filepath.Join(dir, "log"), homematic.ChangeLogOptions{Key: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := changeLog.Append(homematic.DataPointChange{IseID: "1251", NewValue: "21.5", ObservedAt: time.Unix(1700000000, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	changeLog.Close()
+	logs, _ := filepath.Glob(filepath.Join(dir, "log", "*"))
+
+	t.Setenv(homematic.EncryptionKeyEnv, "")
+	path := writeConfig(t, "profiles:\n  home:\n    url: https://ccu\n    token: secret\n    encryption_key_file: "+keyFile+"\n")
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"--config", path, "replay", "--speed", "0"}, logs...), nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"new_value":"21.5"`) {
+		t.Errorf("expected the decrypted change, got %s", stdout.String())
+	}
+}
+
+func TestSSHProfile(t *testing.T) {
+	path := writeConfig(t, `
+profiles:
+  home:
+    url: https://192.168.1.100
+    token: secret
+    ssh:
+      addr: gateway.example:2222
+      user: tunnel
+      password_env: TUNNEL_PASSWORD
+      known_hosts_file: `+filepath.Join(t.TempDir(), "missing")+`
+`)
+
+	a := &app{}
+	if err := a.loadProfile(path, ""); err != nil {
+		t.Fatalf("loadProfile failed: %v", err)
+	}
+	if a.profile.SSH == nil || a.profile.SSH.Addr != "gateway.example:2222" || a.profile.SSH.User != "tunnel" {
+		t.Fatalf("unexpected SSH settings: %+v", a.profile.SSH)
+	}
+	// the host key of the tunnel must be verifiable
+	if _, err := a.client(); err == nil || !strings.Contains(err.Error(), "known hosts") {
+		t.Errorf("expected an error for a missing known_hosts file, got %v", err)
+	}
+}
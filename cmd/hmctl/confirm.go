@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// errAborted is returned when the user declines a confirmation prompt
+var errAborted = errors.New("aborted")
+
+// confirm asks the user to approve a change when it affects more targets than
+// the confirmation threshold; --yes and --readonly skip the prompt
+func (a *app) confirm(action string, targets []string) error {
+	if a.assumeYes || a.readOnly || len(targets) <= a.confirmThreshold {
+		return nil
+	}
+	if !a.interactive {
+		return fmt.Errorf("%s affects %d targets, use --yes to confirm", action, len(targets))
+	}
+
+	fmt.Fprintf(a.stderr, "About to %s %d targets:\n", action, len(targets))
+	for _, target := range targets {
+		fmt.Fprintf(a.stderr, "  %s\n", target)
+	}
+	fmt.Fprint(a.stderr, "Continue? [y/N] ")
+
+	answer, err := a.readLine()
+	if err != nil {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errAborted
+}
+
+// readLine reads a line of user input; several prompts share the buffered reader
+func (a *app) readLine() (string, error) {
+	if a.input == nil {
+		a.input = bufio.NewReader(a.stdin)
+	}
+	line, err := a.input.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return line, nil
+}
+
+// report prints the outcome of a change, marking it when running in readonly mode
+func (a *app) report(format string, args ...any) {
+	if a.readOnly {
+		format = "[readonly] " + format
+	}
+	fmt.Fprintf(a.stdout, format+"\n", args...)
+}
+
+// isTerminal reports whether r is an interactive terminal
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfirm(t *testing.T) {
+	targets := []string{"1234", "1235"}
+
+	tests := []struct {
+		name    string
+		app     app
+		input   string
+		wantErr bool
+	}{
+		{"below threshold", app{confirmThreshold: 2}, "", false},
+		{"assume yes", app{confirmThreshold: 1, assumeYes: true}, "", false},
+		{"readonly", app{confirmThreshold: 1, readOnly: true}, "", false},
+		{"non-interactive", app{confirmThreshold: 1}, "y\n", true},
+		{"confirmed", app{confirmThreshold: 1, interactive: true}, "yes\n", false},
+		{"declined", app{confirmThreshold: 1, interactive: true}, "n\n", true},
+		{"no answer", app{confirmThreshold: 1, interactive: true}, "", true},
+	}
+
+	for _, tt := range tests {
+		a := tt.app
+		a.stdin = strings.NewReader(tt.input)
+		a.stderr = &bytes.Buffer{}
+		err := a.confirm("change", targets)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: confirm() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil && a.interactive && !errors.Is(err, errAborted) {
+			t.Errorf("%s: expected errAborted, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestRunStateSetReadonly(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`<result/>`))
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "--readonly", "state", "set", "1234=0.5", "1235=true"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests in readonly mode, got %d", requests)
+	}
+	if !strings.Contains(stdout.String(), "[readonly] set 1234 to 0.5") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRunMasterSetRequiresConfirmation(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", "http://127.0.0.1", "master", "set", "1234", "TEMPERATURE_OFFSET=1.0", "BOOST_TIME_PERIOD=5"}, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "use --yes to confirm") {
+		t.Errorf("unexpected stderr: %s", stderr.String())
+	}
+}
+
+func TestParseAssignments(t *testing.T) {
+	keys, values, err := parseAssignments([]string{"1234=0.5", "TEXT=a=b"})
+	if err != nil {
+		t.Fatalf("parseAssignments failed: %v", err)
+	}
+	if strings.Join(keys, ",") != "1234,TEXT" || strings.Join(values, ",") != "0.5,a=b" {
+		t.Errorf("unexpected result: %v %v", keys, values)
+	}
+
+	if _, _, err := parseAssignments([]string{"1234"}); err == nil {
+		t.Error("expected error for missing value")
+	}
+}
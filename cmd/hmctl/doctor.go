@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// runDoctor implements "hmctl doctor"
+func runDoctor(a *app, args []string) error {
+	fs := a.newFlagSet("doctor", "[--timeout duration] [--output format]")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of all checks")
+	output := fs.String("output", "table", outputUsage)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	report := client.Validate(ctx)
+	err = a.writeOutput(*output, report, func(w io.Writer) {
+		fmt.Fprintln(w, "CHECK\tSTATUS\tMESSAGE")
+		for _, check := range report.Checks {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, strings.ToUpper(string(check.Status)), check.Message)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if !report.OK() {
+		return errors.New("some checks failed")
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// runExport implements "hmctl export"
+func runExport(a *app, args []string) error {
+	fs := a.newFlagSet("export", "[--format json|csv|yaml] [--what devices|state|sysvars|topology|names|homebridge|openhab-things|openhab-items] [--tag tag,...] [--file path [--encrypt]]")
+	format := fs.String("format", "json", "output format: json, csv or yaml")
+	what := fs.String("what", "devices", "dataset to export: devices, state, sysvars, topology, names (device and channel names by address), homebridge (a Homebridge config.json), openhab-things or openhab-items; the generated configurations ignore --format")
+	file := fs.String("file", "", "write to this file instead of stdout")
+	encrypt := fs.Bool("encrypt", false, "encrypt the file with the configured encryption key")
+	tags := fs.String("tag", "", "only export devices and channels with all of these comma separated tags from the metadata_file of the profile")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || (*encrypt && *file == "") {
+		fs.Usage()
+		return errUsage
+	}
+	var key *homematic.EncryptionKey
+	if *encrypt {
+		var err error
+		if key, err = a.profile.encryptionKey(); err != nil {
+			return err
+		}
+		if key == nil {
+			return fmt.Errorf("--encrypt requires an encryption key, set %s or encryption_key_file", homematic.EncryptionKeyEnv)
+		}
+	}
+
+	exportFormat, err := homematic.ParseExportFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := a.profile.metadataStore()
+	if err != nil {
+		return err
+	}
+	if *tags != "" && metadata == nil {
+		return errors.New("--tag requires a metadata_file in the profile")
+	}
+	// withMetadata attaches the metadata to devices and applies the tag filter
+	withMetadata := func(devices []homematic.Device) []homematic.Device {
+		if metadata == nil {
+			return devices
+		}
+		if *tags != "" {
+			devices = metadata.Filter(devices, strings.Split(*tags, ","), nil)
+		}
+		metadata.Apply(devices)
+		return devices
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	var write func(w io.Writer) error
+	switch *what {
+	case "devices":
+		devices, err := client.GetDeviceList(nil, false, false)
+		if err != nil {
+			return err
+		}
+		devices = withMetadata(devices)
+		write = func(w io.Writer) error { return homematic.ExportDevices(w, exportFormat, devices) }
+	case "state":
+		devices, err := client.GetStateList("", false, false)
+		if err != nil {
+			return err
+		}
+		devices = withMetadata(devices)
+		write = func(w io.Writer) error { return homematic.ExportState(w, exportFormat, devices) }
+	case "sysvars":
+		sysVars, err := client.GetSystemVariableList(true)
+		if err != nil {
+			return err
+		}
+		write = func(w io.Writer) error { return homematic.ExportSystemVariables(w, exportFormat, sysVars) }
+	case "topology":
+		topology, err := client.GetTopology()
+		if err != nil {
+			return err
+		}
+		write = func(w io.Writer) error { return homematic.ExportTopology(w, exportFormat, topology) }
+	case "names":
+		devices, err := client.GetDeviceList(nil, false, false)
+		if err != nil {
+			return err
+		}
+		mapping := homematic.NameMappingFromDevices(withMetadata(devices))
+		write = func(w io.Writer) error { return homematic.ExportNameMapping(w, exportFormat, mapping) }
+	case "homebridge":
+		topology, err := client.GetTopology()
+		if err != nil {
+			return err
+		}
+		opts := homematic.HomebridgeOptions{}
+		if u, err := url.Parse(client.BaseURL); err == nil {
+			opts.CCU = u.Hostname()
+		}
+		write = func(w io.Writer) error { return homematic.ExportHomebridgeConfig(w, topology, opts) }
+	case "openhab-things", "openhab-items":
+		topology, err := client.GetTopology()
+		if err != nil {
+			return err
+		}
+		opts := homematic.OpenHABOptions{}
+		if u, err := url.Parse(client.BaseURL); err == nil {
+			opts.Gateway = u.Hostname()
+		}
+		write = func(w io.Writer) error { return homematic.ExportOpenHABThings(w, topology, opts) }
+		if *what == "openhab-items" {
+			write = func(w io.Writer) error { return homematic.ExportOpenHABItems(w, topology, opts) }
+		}
+	default:
+		return fmt.Errorf("unknown dataset %q, expected devices, state, sysvars, topology, names, homebridge, openhab-things or openhab-items", *what)
+	}
+
+	if *file == "" {
+		return write(a.stdout)
+	}
+
+	if key != nil {
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			return err
+		}
+		if err := homematic.WriteEncryptedFile(*file, buf.Bytes(), key); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		return nil
+	}
+
+	f, err := os.Create(*file)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is a single step of a parsed JSONPath expression
+type jsonPathStep struct {
+	field    string
+	index    int
+	wildcard bool
+	isIndex  bool
+}
+
+// parseJSONPath parses the supported JSONPath subset: field access (.name or
+// ['name']), array indexes ([0], negative from the end) and wildcards ([*], .*),
+// optionally prefixed with $ and enclosed in braces as in {.items[*].name}
+func parseJSONPath(expr string) ([]jsonPathStep, error) {
+	path := strings.TrimSpace(expr)
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+	path = strings.TrimPrefix(path, "$")
+
+	var steps []jsonPathStep
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			name := path[:end]
+			path = path[end:]
+			switch name {
+			case "":
+				return nil, fmt.Errorf("invalid jsonpath %q: empty field name", expr)
+			case "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			default:
+				steps = append(steps, jsonPathStep{field: name})
+			}
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid jsonpath %q: missing ]", expr)
+			}
+			selector := path[1:end]
+			path = path[end+1:]
+			switch {
+			case selector == "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				steps = append(steps, jsonPathStep{field: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("invalid jsonpath %q: unsupported selector [%s]", expr, selector)
+				}
+				steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("invalid jsonpath %q: unexpected %q", expr, path[0])
+		}
+	}
+
+	return steps, nil
+}
+
+// evalJSONPath evaluates a JSONPath expression against data, returning all matched values
+func evalJSONPath(expr string, data any) ([]any, error) {
+	steps, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	// normalize Go values to their generic JSON representation
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var root any
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil, err
+	}
+
+	nodes := []any{root}
+	for _, step := range steps {
+		var next []any
+		for _, node := range nodes {
+			switch v := node.(type) {
+			case []any:
+				switch {
+				case step.wildcard:
+					next = append(next, v...)
+				case step.isIndex:
+					i := step.index
+					if i < 0 {
+						i += len(v)
+					}
+					if i >= 0 && i < len(v) {
+						next = append(next, v[i])
+					}
+				}
+			case map[string]any:
+				switch {
+				case step.wildcard:
+					keys := make([]string, 0, len(v))
+					for k := range v {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, v[k])
+					}
+				case !step.isIndex:
+					if value, ok := v[step.field]; ok {
+						next = append(next, value)
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+
+	return nodes, nil
+}
+
+// formatJSONPathValue renders a matched value: scalars verbatim, objects and arrays as JSON
+func formatJSONPathValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case map[string]any, []any:
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,238 @@
+// Command hmctl is a command line client for the HomeMatic XML-API
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// errUsage signals that a command was invoked with invalid arguments
+var errUsage = errors.New("invalid usage")
+
+// command is a hmctl subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(app *app, args []string) error
+}
+
+// app holds the global state shared by all commands
+type app struct {
+	baseURL          string
+	token            string
+	profile          *profile
+	readOnly         bool
+	assumeYes        bool
+	confirmThreshold int
+	interactive      bool
+	stdin            io.Reader
+	input            *bufio.Reader
+	stdout           io.Writer
+	stderr           io.Writer
+}
+
+// commands lists all available subcommands
+var commands = []command{
+	{"export", "Export devices, state, system variables or topology", runExport},
+	{"program", "List and run programs", runProgram},
+	{"sysvar", "List, get and set system variables", runSysvar},
+	{"state", "Change data point states", runState},
+	{"master", "Change device master values", runMaster},
+	{"names", "Compare device names with a name mapping file", runNames},
+	{"replay", "Replay a recorded change log", runReplay},
+	{"doctor", "Diagnose the connection to the CCU", runDoctor},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes hmctl with the given arguments and returns the process exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, interactive: isTerminal(stdin)}
+
+	fs := flag.NewFlagSet("hmctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&a.baseURL, "url", os.Getenv("HOMEMATIC_URL"), "base URL of the CCU (env HOMEMATIC_URL)")
+	fs.StringVar(&a.token, "token", os.Getenv("HOMEMATIC_TOKEN"), "XML-API security token (env HOMEMATIC_TOKEN)")
+	configPath := fs.String("config", defaultConfigPath(), "path of the configuration file")
+	profileName := fs.String("profile", os.Getenv("HMCTL_PROFILE"), "configuration profile to use (env HMCTL_PROFILE)")
+	fs.BoolVar(&a.readOnly, "readonly", false, "do not send any changes to the CCU (dry-run mode)")
+	fs.BoolVar(&a.assumeYes, "yes", false, "do not ask for confirmation of changes")
+	fs.IntVar(&a.confirmThreshold, "confirm-threshold", 1, "ask for confirmation when a change affects more than this many targets")
+	fs.Usage = func() { printUsage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := a.loadProfile(*configPath, *profileName); err != nil {
+		fmt.Fprintf(stderr, "hmctl: %v\n", err)
+		return 1
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	name := fs.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(a, fs.Args()[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+			return 2
+		default:
+			fmt.Fprintf(stderr, "hmctl %s: %v\n", name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stderr, "hmctl: unknown command %q\n", name)
+	fs.Usage()
+	return 2
+}
+
+// printUsage prints the global usage message
+func printUsage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintln(out, "Usage: hmctl [global flags] <command> [flags] [args]")
+	fmt.Fprintln(out, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(out, "\nGlobal flags:")
+	fs.PrintDefaults()
+}
+
+// loadProfile fills the connection settings not given by flags or environment from a configuration profile
+func (a *app) loadProfile(configPath, name string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	p, err := cfg.profile(name)
+	if err != nil || p == nil {
+		return err
+	}
+
+	a.profile = p
+	if a.baseURL == "" {
+		a.baseURL = p.URL
+	}
+	if a.token == "" {
+		token, err := p.resolveToken()
+		if err != nil {
+			return err
+		}
+		a.token = token
+	}
+	return nil
+}
+
+// client creates a XML-API client from the global flags and profile
+func (a *app) client() (*homematic.Client, error) {
+	if a.baseURL == "" {
+		return nil, errors.New("no CCU URL configured, use --url, HOMEMATIC_URL or a config profile")
+	}
+
+	client := homematic.NewClient(a.baseURL, a.token)
+	client.DryRun = a.readOnly
+	if a.profile != nil && a.profile.TLS != nil {
+		if err := a.profile.TLS.apply(client.HTTPClient); err != nil {
+			return nil, err
+		}
+	}
+	if a.profile != nil && (len(a.profile.Hosts) > 0 || a.profile.DNSCacheTTL != "") {
+		resolver := &homematic.CachingResolver{Hosts: a.profile.Hosts}
+		if a.profile.DNSCacheTTL != "" {
+			ttl, err := time.ParseDuration(a.profile.DNSCacheTTL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid dns_cache_ttl: %w", err)
+			}
+			resolver.TTL = ttl
+		}
+		if err := client.SetResolver(resolver); err != nil {
+			return nil, err
+		}
+	}
+	if a.profile != nil && a.profile.UnixSocket != "" {
+		if err := client.SetUnixSocket(expandHome(a.profile.UnixSocket)); err != nil {
+			return nil, err
+		}
+	}
+	if a.profile != nil && a.profile.DialTarget != "" {
+		if err := client.SetDialTarget(a.profile.DialTarget); err != nil {
+			return nil, err
+		}
+	}
+	if a.profile != nil && a.profile.SSH != nil {
+		if err := a.profile.SSH.apply(client); err != nil {
+			return nil, err
+		}
+	}
+	if a.profile != nil && a.profile.Timezone != "" {
+		loc, err := time.LoadLocation(a.profile.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+		client.Location = loc
+	}
+	return client, nil
+}
+
+// newFlagSet creates a flag set for a subcommand writing its messages to stderr
+func (a *app) newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet("hmctl "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: hmctl %s %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runSubcommand dispatches to one of the nested subcommands of a command
+func (a *app) runSubcommand(name string, args []string, subcommands []command) error {
+	usage := func() {
+		fmt.Fprintf(a.stderr, "Usage: hmctl %s <command> [flags] [args]\n\nCommands:\n", name)
+		for _, cmd := range subcommands {
+			fmt.Fprintf(a.stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+		}
+	}
+
+	if len(args) == 0 {
+		usage()
+		return errUsage
+	}
+	for _, cmd := range subcommands {
+		if cmd.name == args[0] {
+			return cmd.run(a, args[1:])
+		}
+	}
+
+	fmt.Fprintf(a.stderr, "hmctl %s: unknown command %q\n", name, args[0])
+	usage()
+	return errUsage
+}
+
+// parseFlags parses subcommand flags, mapping parse failures to errUsage
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
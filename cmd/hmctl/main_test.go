@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestServer serves fixed XML responses per endpoint
+func newTestServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := strings.TrimPrefix(r.URL.Path, "/addons/xmlapi/")
+		body, ok := responses[endpoint]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"bogus"}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), `unknown command "bogus"`) {
+		t.Errorf("unexpected stderr: %s", stderr.String())
+	}
+}
+
+func TestRunExport(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"sysvarlist.cgi": `<systemVariables><systemVariable name="Presence" ise_id="950" value="true" valuetype="2" subtype="2" timestamp="1700000000"/></systemVariables>`,
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "export", "--format", "csv", "--what", "sysvars"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Presence,950,true,2,2,,,,1700000000") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRunExportInvalidDataset(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", "http://127.0.0.1", "export", "--what", "bogus"}, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+const testProgramList = `<programList>
+	<program id="1001" name="Morning" active="true" visible="true" timestamp="1700000000"/>
+	<program id="1002" name="Evening" active="true" visible="true" timestamp="1700000000"/>
+</programList>`
+
+func TestRunProgramRun(t *testing.T) {
+	var ran []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/addons/xmlapi/programlist.cgi":
+			w.Write([]byte(testProgramList))
+		case "/addons/xmlapi/runprogram.cgi":
+			ran = append(ran, r.URL.Query().Get("program_id"))
+			w.Write([]byte(`<result><started program_id="` + r.URL.Query().Get("program_id") + `"/></result>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "--yes", "program", "run", "morning", "1002", "Night"}, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit code 1 for unknown program, got %d", code)
+	}
+	if len(ran) != 2 || ran[0] != "1001" || ran[1] != "1002" {
+		t.Errorf("unexpected programs run: %v", ran)
+	}
+	if !strings.Contains(stderr.String(), "program not found: Night") {
+		t.Errorf("unexpected stderr: %s", stderr.String())
+	}
+}
+
+func TestRunProgramRunDryRun(t *testing.T) {
+	server := newTestServer(t, map[string]string{"programlist.cgi": testProgramList})
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "program", "run", "--dry-run", "Evening"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if strings.TrimSpace(stdout.String()) != "would run program Evening (1002)" {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+}
+
+const testSysvarList = `<systemVariables>
+	<systemVariable name="Presence" variable="true" value="true" value_list="" ise_id="950" min="" max="" unit="" type="2" subtype="2" logged="false" visible="true" timestamp="1700000000" value_name_0="away" value_name_1="present"/>
+	<systemVariable name="Heating Mode" variable="1" value="1" value_list="Off;Comfort;Eco" ise_id="951" min="" max="" unit="" type="16" subtype="29" logged="false" visible="true" timestamp="1700000000" value_name_0="" value_name_1=""/>
+</systemVariables>`
+
+func TestRunSysvarGet(t *testing.T) {
+	server := newTestServer(t, map[string]string{"sysvarlist.cgi": testSysvarList})
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "sysvar", "get", "heating mode"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "951  Heating Mode  enum  Comfort") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRunSysvarSet(t *testing.T) {
+	var newValue string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/addons/xmlapi/sysvarlist.cgi":
+			w.Write([]byte(testSysvarList))
+		case "/addons/xmlapi/statechange.cgi":
+			newValue = r.URL.Query().Get("new_value")
+			w.Write([]byte(`<result><changed id="950" new_value="false"/></result>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "sysvar", "set", "Presence", "away"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if newValue != "false" {
+		t.Errorf("expected new value false, got %q", newValue)
+	}
+
+	code = run([]string{"--url", server.URL, "sysvar", "set", "Heating Mode", "Turbo"}, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit code 1 for invalid enum label, got %d", code)
+	}
+}
+
+func TestRunSysvarImport(t *testing.T) {
+	var changes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/addons/xmlapi/sysvarlist.cgi":
+			w.Write([]byte(testSysvarList))
+		case "/addons/xmlapi/statechange.cgi":
+			changes = append(changes, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+			w.Write([]byte(`<result><changed id="950" new_value="false"/><changed id="951" new_value="2"/></result>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "flags.json")
+	if err := os.WriteFile(path, []byte(`{"Presence": "away", "Heating Mode": "Eco"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "--yes", "sysvar", "import", path}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if len(changes) != 1 || changes[0] != "951,950=2,false" {
+		t.Errorf("expected one batch, got %v", changes)
+	}
+	if stdout.String() != "set Heating Mode to 2\nset Presence to false\n" {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	if err := os.WriteFile(path, []byte(`{"Heating Mode": "Turbo"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	code = run([]string{"--url", server.URL, "sysvar", "import", path}, nil, &stdout, &stderr)
+	if code != 1 || len(changes) != 1 {
+		t.Errorf("expected exit code 1 without changes for an invalid enum label, got %d, %v", code, changes)
+	}
+}
+
+func TestRunReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.jsonl")
+	log := `{"schema_version":1,"ise_id":"1251","new_value":"21.5","observed_at":"2024-01-01T10:00:00Z"}
+{"schema_version":1,"ise_id":"1251","new_value":"21.7","observed_at":"2024-01-01T10:00:10Z"}
+`
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"replay", "--speed", "0", path}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"new_value":"21.7"`) {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	code = run([]string{"replay", "--speed", "0", "--cloudevents", "ccu", path}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"type":"io.homematic.datapoint.changed","subject":"1251"`) {
+		t.Errorf("unexpected CloudEvents output:\n%s", stdout.String())
+	}
+}
+
+func TestRunDoctor(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"version.cgi":  `<version>2.3</version>`,
+		"roomlist.cgi": `<roomList/>`,
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "--token", "secret", "doctor"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s\n%s", code, stderr.String(), stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "XML-API 2.3") || !strings.Contains(stdout.String(), "token accepted") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	server = newTestServer(t, map[string]string{})
+	stdout.Reset()
+	code = run([]string{"--url", server.URL, "doctor"}, nil, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit code 1 without the addon, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "addon not installed") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRunNamesPlan(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"devicelist.cgi": `<deviceList><device name="Fenster" address="MEQ0000006" ise_id="2472"><channel name="Fenster:1" address="MEQ0000006:1" ise_id="2482"/></device></deviceList>`,
+	})
+	path := filepath.Join(t.TempDir(), "names.yaml")
+	if err := os.WriteFile(path, []byte("MEQ0000006: Fenster Gäste-WC\nMEQ0000006:1: 'Fenster:1'\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "names", "plan", path}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	want := "@@ device MEQ0000006 (2472)\n- Fenster\n+ Fenster Gäste-WC\n1 to rename, 1 unchanged, 0 unknown\n"
+	if stdout.String() != want {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRunStateGetAndSetByName(t *testing.T) {
+	var changed string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/addons/xmlapi/") {
+		case "statelist.cgi":
+			w.Write([]byte(`<stateList><device name="Thermostat Wohnzimmer" ise_id="2401"><channel name="Thermostat Wohnzimmer:1" ise_id="2411">` +
+				`<datapoint type="ACTUAL_TEMPERATURE" ise_id="2412" value="21.5" valueunit="°C"/>` +
+				`<datapoint type="SET_POINT_TEMPERATURE" ise_id="2416" value="22.0" valueunit="°C"/></channel></device></stateList>`))
+		case "statechange.cgi":
+			changed = r.URL.Query().Get("ise_id") + "=" + r.URL.Query().Get("new_value")
+			w.Write([]byte(`<result><changed id="2416" new_value="21"/></result>`))
+		}
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--url", server.URL, "state", "get", "wohnz actual"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "2412  Thermostat Wohnzimmer:1  ACTUAL_TEMPERATURE  21.5 °C") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"--url", server.URL, "state", "get", "--all", "--output", "jsonpath={[*].ise_id}", "wohnz temp"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if strings.Fields(stdout.String())[1] != "2416" {
+		t.Errorf("expected both temperatures, got:\n%s", stdout.String())
+	}
+
+	if code := run([]string{"--url", server.URL, "state", "get", "wohnz temp"}, nil, &stdout, &stderr); code != 1 {
+		t.Errorf("expected an ambiguous query to fail without a terminal, got exit code %d", code)
+	}
+
+	if code := run([]string{"--url", server.URL, "state", "set", "wohnz set point=21"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if changed != "2416=21" {
+		t.Errorf("expected the set point to be changed, got %q", changed)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+// runMaster implements "hmctl master"
+func runMaster(a *app, args []string) error {
+	return a.runSubcommand("master", args, []command{
+		{"set", "Set master values of a device", runMasterSet},
+	})
+}
+
+// runMasterSet implements "hmctl master set"
+func runMasterSet(a *app, args []string) error {
+	fs := a.newFlagSet("master set", "<device_id> <NAME>=<value>...")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	deviceID := fs.Arg(0)
+	names, values, err := parseAssignments(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	if err := a.confirm("change master values "+deviceID, fs.Args()[1:]); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	deviceIDs := make([]string, len(names))
+	for i := range deviceIDs {
+		deviceIDs[i] = deviceID
+	}
+	if err := client.ChangeMasterValue(deviceIDs, names, values); err != nil {
+		return err
+	}
+
+	for i := range names {
+		a.report("set %s of device %s to %s", names[i], deviceID, values[i])
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// dataPointMatch is a data point found by a name query
+type dataPointMatch struct {
+	Device  string `json:"device"`
+	Channel string `json:"channel"`
+	Type    string `json:"type"`
+	IseID   string `json:"ise_id"`
+	Value   string `json:"value"`
+	Unit    string `json:"unit,omitempty"`
+}
+
+func (m dataPointMatch) String() string {
+	return fmt.Sprintf("%s %s (%s)", m.Channel, m.Type, m.IseID)
+}
+
+// matchDataPoints returns the data points with the ise_id given as query or,
+// if there is none, those whose device name, channel name and type contain
+// all words of the query, e.g. "wohnz temp"; case, umlauts and underscores are ignored
+func matchDataPoints(devices []homematic.Device, query string) []dataPointMatch {
+	var all []dataPointMatch
+	for _, d := range devices {
+		for _, ch := range d.Channels {
+			for _, dp := range ch.DataPoints {
+				all = append(all, dataPointMatch{Device: d.Name, Channel: ch.Name, Type: dp.Type,
+					IseID: dp.IseID, Value: dp.Value, Unit: dp.ValueUnit})
+			}
+		}
+	}
+
+	for _, m := range all {
+		if m.IseID == query {
+			return []dataPointMatch{m}
+		}
+	}
+
+	words := strings.Fields(normalizeQuery(query))
+	if len(words) == 0 {
+		return nil
+	}
+	var matches []dataPointMatch
+	for _, m := range all {
+		haystack := normalizeQuery(m.Device + " " + m.Channel + " " + m.Type)
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(haystack, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// normalizeQuery lowers the case, transliterates umlauts and treats underscores as spaces
+func normalizeQuery(s string) string {
+	return strings.ReplaceAll(strings.ToLower(homematic.TransliterateName(s)), "_", " ")
+}
+
+// resolveDataPoint returns the single data point matching a query, asking the
+// user to choose if several match
+func (a *app) resolveDataPoint(devices []homematic.Device, query string) (dataPointMatch, error) {
+	matches := matchDataPoints(devices, query)
+	switch {
+	case len(matches) == 0:
+		return dataPointMatch{}, fmt.Errorf("no data point matches %q", query)
+	case len(matches) == 1:
+		return matches[0], nil
+	}
+
+	options := make([]string, len(matches))
+	for i, m := range matches {
+		options[i] = m.String()
+	}
+	if !a.interactive {
+		return dataPointMatch{}, fmt.Errorf("%q matches %d data points, use a more specific query or the ise_id:\n  %s",
+			query, len(matches), strings.Join(options, "\n  "))
+	}
+	i, err := a.choose(fmt.Sprintf("%q matches several data points:", query), options)
+	if err != nil {
+		return dataPointMatch{}, err
+	}
+	return matches[i], nil
+}
+
+// choose asks the user to pick one of the options and returns its index
+func (a *app) choose(prompt string, options []string) (int, error) {
+	fmt.Fprintln(a.stderr, prompt)
+	for i, option := range options {
+		fmt.Fprintf(a.stderr, "  %d) %s\n", i+1, option)
+	}
+	fmt.Fprintf(a.stderr, "Select [1-%d]: ", len(options))
+
+	answer, err := a.readLine()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read selection: %w", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || n < 1 || n > len(options) {
+		return 0, errors.Join(errAborted, fmt.Errorf("invalid selection %q", strings.TrimSpace(answer)))
+	}
+	return n - 1, nil
+}
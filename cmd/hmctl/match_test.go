@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+var testMatchDevices = []homematic.Device{
+	{Name: "Thermostat Wohnzimmer", Channels: []homematic.Channel{{Name: "Thermostat Wohnzimmer:1", DataPoints: []homematic.DataPoint{
+		{Type: "ACTUAL_TEMPERATURE", IseID: "2412", Value: "21.5", ValueUnit: "°C"},
+		{Type: "SET_POINT_TEMPERATURE", IseID: "2416", Value: "22.0", ValueUnit: "°C"},
+	}}}},
+	{Name: "Fenster Gäste-WC", Channels: []homematic.Channel{{Name: "Fenster Gäste-WC:1", DataPoints: []homematic.DataPoint{
+		{Type: "STATE", IseID: "2484", Value: "false"},
+	}}}},
+}
+
+func TestMatchDataPoints(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"wohnz temp", []string{"2412", "2416"}},
+		{"WOHNZ set point", []string{"2416"}},
+		{"gaeste state", []string{"2484"}},
+		{"gäste", []string{"2484"}},
+		{"2412", []string{"2412"}},
+		{"keller", nil},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range matchDataPoints(testMatchDevices, tt.query) {
+			got = append(got, m.IseID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%q: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestResolveDataPoint(t *testing.T) {
+	a := &app{stdin: strings.NewReader("2\n"), stderr: &bytes.Buffer{}}
+	if _, err := a.resolveDataPoint(testMatchDevices, "wohnz temp"); err == nil || !strings.Contains(err.Error(), "matches 2 data points") {
+		t.Errorf("expected an ambiguity error without a terminal, got %v", err)
+	}
+
+	a.interactive = true
+	match, err := a.resolveDataPoint(testMatchDevices, "wohnz temp")
+	if err != nil || match.IseID != "2416" {
+		t.Errorf("expected the second match to be selected, got %+v, %v", match, err)
+	}
+	if !strings.Contains(a.stderr.(*bytes.Buffer).String(), "2) Thermostat Wohnzimmer:1 SET_POINT_TEMPERATURE (2416)") {
+		t.Errorf("unexpected prompt:\n%s", a.stderr)
+	}
+
+	a.stdin, a.input = strings.NewReader("9\n"), nil
+	if _, err := a.resolveDataPoint(testMatchDevices, "wohnz temp"); !errors.Is(err, errAborted) {
+		t.Errorf("expected an invalid selection to abort, got %v", err)
+	}
+	if _, err := a.resolveDataPoint(testMatchDevices, "keller"); err == nil {
+		t.Error("expected an error without matches")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// runNames implements "hmctl names"
+func runNames(a *app, args []string) error {
+	return a.runSubcommand("names", args, []command{
+		{"plan", "Show the renames needed to reach the names of a mapping file", runNamesPlan},
+	})
+}
+
+// runNamesPlan implements "hmctl names plan"
+func runNamesPlan(a *app, args []string) error {
+	fs := a.newFlagSet("names plan", "[--format json|csv|yaml] <file>")
+	format := fs.String("format", "", "format of the mapping file, by default derived from its extension")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	path := fs.Arg(0)
+	mappingFormat, err := fileFormat(path, *format)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open mapping file: %w", err)
+	}
+	defer f.Close()
+	mapping, err := homematic.ReadNameMapping(f, mappingFormat)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	devices, err := client.GetDeviceList(nil, false, false)
+	if err != nil {
+		return err
+	}
+	return homematic.PlanRenames(devices, mapping).WriteDiff(a.stdout)
+}
+
+// fileFormat returns the given format of a file or, if it is empty, the format of its extension
+func fileFormat(path, format string) (homematic.ExportFormat, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+		if format == "yml" {
+			format = "yaml"
+		}
+	}
+	return homematic.ParseExportFormat(format)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// outputUsage describes the values accepted by the --output flag
+const outputUsage = "output format: table, json, template=<go template> or jsonpath=<expression>"
+
+// writeOutput writes data as a table rendered by writeTable, as indented JSON,
+// through a Go template applied to each element (template=...) or as the
+// values selected by a JSONPath expression (jsonpath=...)
+func (a *app) writeOutput(format string, data any, writeTable func(w io.Writer)) error {
+	if tmpl, ok := strings.CutPrefix(format, "template="); ok {
+		return a.writeTemplate(tmpl, data)
+	}
+	if expr, ok := strings.CutPrefix(format, "jsonpath="); ok {
+		return a.writeJSONPath(expr, data)
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case "table", "":
+		tw := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+		writeTable(tw)
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unsupported output format %q, expected table, json, template=... or jsonpath=...", format)
+	}
+}
+
+// writeTemplate executes a Go template for each element of data (or once for
+// non-slice data), terminating each execution with a newline. Elements are
+// passed by pointer so that methods like DisplayValue are available.
+func (a *app) writeTemplate(text string, data any) error {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	items := []any{data}
+	if v := reflect.ValueOf(data); v.Kind() == reflect.Slice {
+		items = make([]any, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).Addr().Interface()
+		}
+	}
+
+	for _, item := range items {
+		if err := tmpl.Execute(a.stdout, item); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		fmt.Fprintln(a.stdout)
+	}
+	return nil
+}
+
+// writeJSONPath prints each value selected by a JSONPath expression on its own line
+func (a *app) writeJSONPath(expr string, data any) error {
+	values, err := evalJSONPath(expr, data)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		fmt.Fprintln(a.stdout, formatJSONPathValue(value))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+var outputSysvars = []homematic.SystemVariable{
+	{Name: "Presence", IseID: "950", Value: "true", Type: "2", ValueName0: "away", ValueName1: "present"},
+	{Name: "Mode", IseID: "951", Value: "1", Type: "16", Subtype: "29", ValueList: "Off;Comfort;Eco"},
+}
+
+func TestWriteOutput(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"template={{.Name}} {{.Value}}", "Presence true\nMode 1\n"},
+		{"template={{.Name}}={{.DisplayValue}}", "Presence=present\nMode=Comfort\n"},
+		{"jsonpath={[*].name}", "Presence\nMode\n"},
+		{"jsonpath=$[-1].value_list", "Off;Comfort;Eco\n"},
+		{"jsonpath={[0]['ise_id']}", "950\n"},
+	}
+
+	for _, tt := range tests {
+		var stdout bytes.Buffer
+		a := &app{stdout: &stdout}
+		if err := a.writeOutput(tt.format, outputSysvars, nil); err != nil {
+			t.Errorf("writeOutput(%q) failed: %v", tt.format, err)
+			continue
+		}
+		if stdout.String() != tt.want {
+			t.Errorf("writeOutput(%q) = %q, want %q", tt.format, stdout.String(), tt.want)
+		}
+	}
+}
+
+func TestWriteOutputErrors(t *testing.T) {
+	for _, format := range []string{"xml", "template={{.Name", "template={{.Bogus}}", "jsonpath={[x]}", "jsonpath=name"} {
+		a := &app{stdout: &bytes.Buffer{}}
+		if err := a.writeOutput(format, outputSysvars, nil); err == nil {
+			t.Errorf("writeOutput(%q): expected error", format)
+		}
+	}
+}
+
+func TestEvalJSONPathObject(t *testing.T) {
+	values, err := evalJSONPath("{.channels[*].datapoints[*].value}", homematic.Device{
+		Channels: []homematic.Channel{
+			{DataPoints: []homematic.DataPoint{{Value: "21.5"}, {Value: "true"}}},
+			{DataPoints: []homematic.DataPoint{{Value: "0.5"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("evalJSONPath failed: %v", err)
+	}
+
+	var got []string
+	for _, v := range values {
+		got = append(got, formatJSONPathValue(v))
+	}
+	if strings.Join(got, ",") != "21.5,true,0.5" {
+		t.Errorf("unexpected values: %v", got)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// runProgram implements "hmctl program"
+func runProgram(a *app, args []string) error {
+	return a.runSubcommand("program", args, []command{
+		{"list", "List all programs", runProgramList},
+		{"run", "Run one or more programs by name or id", runProgramRun},
+	})
+}
+
+// runProgramList implements "hmctl program list"
+func runProgramList(a *app, args []string) error {
+	fs := a.newFlagSet("program list", "[--output format]")
+	output := fs.String("output", "table", outputUsage)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	programs, err := client.GetProgramList()
+	if err != nil {
+		return err
+	}
+
+	return a.writeOutput(*output, programs, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tACTIVE\tVISIBLE")
+		for _, p := range programs {
+			fmt.Fprintf(w, "%s\t%s\t%t\t%t\n", p.ID, p.Name, p.Active, p.Visible)
+		}
+	})
+}
+
+// runProgramRun implements "hmctl program run"
+func runProgramRun(a *app, args []string) error {
+	fs := a.newFlagSet("program run", "[--cond-check] [--dry-run] <name|id>...")
+	condCheck := fs.Bool("cond-check", false, "only run the program if its conditions are met")
+	dryRun := fs.Bool("dry-run", false, "print the programs that would be run without running them")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	programs, err := client.GetProgramList()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	var selected []*homematic.Program
+	for _, nameOrID := range fs.Args() {
+		program, err := homematic.FindProgram(programs, nameOrID)
+		if err != nil {
+			fmt.Fprintf(a.stderr, "%s: %v\n", nameOrID, err)
+			failed++
+			continue
+		}
+		selected = append(selected, program)
+	}
+
+	if *dryRun {
+		for _, program := range selected {
+			fmt.Fprintf(a.stdout, "would run program %s (%s)\n", program.Name, program.ID)
+		}
+	} else {
+		targets := make([]string, len(selected))
+		for i, program := range selected {
+			targets[i] = fmt.Sprintf("%s (%s)", program.Name, program.ID)
+		}
+		if err := a.confirm("run", targets); err != nil {
+			return err
+		}
+
+		for _, program := range selected {
+			if err := client.RunProgram(program.ID, *condCheck); err != nil {
+				fmt.Fprintf(a.stderr, "%s: failed to run program: %v\n", program.Name, err)
+				failed++
+				continue
+			}
+			a.report("ran program %s (%s)", program.Name, program.ID)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d programs failed", failed, fs.NArg())
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// runReplay implements "hmctl replay"
+func runReplay(a *app, args []string) error {
+	fs := a.newFlagSet("replay", "[flags] <change log file>...")
+	speed := fs.Float64("speed", 1, "replay speed relative to the recorded timing, 0 replays without delays")
+	source := fs.String("cloudevents", "", "wrap the changes in CloudEvents envelopes with the given source")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 || *speed < 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// encrypted change logs are read with the key of the profile
+	key, err := a.profile.encryptionKey()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(a.stdout)
+	replayer := &homematic.Replayer{
+		Speed: *speed,
+		Key:   key,
+		Handler: func(change homematic.DataPointChange) error {
+			if *source != "" {
+				return enc.Encode(homematic.NewCloudEvent(*source, change))
+			}
+			return enc.Encode(change)
+		},
+	}
+	return replayer.ReplayFiles(ctx, fs.Args()...)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// runState implements "hmctl state"
+func runState(a *app, args []string) error {
+	return a.runSubcommand("state", args, []command{
+		{"get", "Show data points by ise_id or name", runStateGet},
+		{"set", "Set data point values by ise_id or name", runStateSet},
+	})
+}
+
+// runStateGet implements "hmctl state get"
+func runStateGet(a *app, args []string) error {
+	fs := a.newFlagSet("state get", "[--output format] [--all] <ise_id|query>...")
+	output := fs.String("output", "table", outputUsage)
+	all := fs.Bool("all", false, "show all data points matching a query instead of asking which one is meant")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	devices, err := client.GetStateList("", false, false)
+	if err != nil {
+		return err
+	}
+
+	var matches []dataPointMatch
+	for _, query := range fs.Args() {
+		if *all {
+			found := matchDataPoints(devices, query)
+			if len(found) == 0 {
+				return fmt.Errorf("no data point matches %q", query)
+			}
+			matches = append(matches, found...)
+			continue
+		}
+		match, err := a.resolveDataPoint(devices, query)
+		if err != nil {
+			return err
+		}
+		matches = append(matches, match)
+	}
+
+	return a.writeOutput(*output, matches, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tCHANNEL\tTYPE\tVALUE")
+		for _, m := range matches {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.IseID, m.Channel, m.Type, strings.TrimSpace(m.Value+" "+m.Unit))
+		}
+	})
+}
+
+// runStateSet implements "hmctl state set"
+func runStateSet(a *app, args []string) error {
+	fs := a.newFlagSet("state set", "<ise_id|query>=<value>...")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	ids, values, err := parseAssignments(fs.Args())
+	if err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	targets, err := a.resolveStateTargets(client, ids, values)
+	if err != nil {
+		return err
+	}
+	if err := a.confirm("change the state of", targets); err != nil {
+		return err
+	}
+
+	results, err := client.ChangeStates(ids, values)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+			continue
+		}
+		a.report("set %s to %s", result.IseID, result.RequestedValue)
+	}
+	return errors.Join(errs...)
+}
+
+// resolveStateTargets replaces the name queries among ids with the ise_ids of
+// the matching data points and returns the assignments for confirmation
+func (a *app) resolveStateTargets(client *homematic.Client, ids, values []string) ([]string, error) {
+	targets := make([]string, len(ids))
+	var devices []homematic.Device
+	for i, id := range ids {
+		targets[i] = id + "=" + values[i]
+		if isIseID(id) {
+			continue
+		}
+		if devices == nil {
+			var err error
+			if devices, err = client.GetStateList("", false, false); err != nil {
+				return nil, err
+			}
+		}
+		match, err := a.resolveDataPoint(devices, id)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = match.IseID
+		targets[i] = fmt.Sprintf("%s=%s", match, values[i])
+	}
+	return targets, nil
+}
+
+// isIseID reports whether s has the form of an ise_id rather than a name query
+func isIseID(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// parseAssignments splits key=value arguments into keys and values
+func parseAssignments(args []string) ([]string, []string, error) {
+	keys := make([]string, 0, len(args))
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, nil, fmt.Errorf("invalid assignment %q, expected key=value", arg)
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	return keys, values, nil
+}
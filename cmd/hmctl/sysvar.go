@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// runSysvar implements "hmctl sysvar"
+func runSysvar(a *app, args []string) error {
+	return a.runSubcommand("sysvar", args, []command{
+		{"list", "List all system variables", runSysvarList},
+		{"get", "Show a system variable by name or id", runSysvarGet},
+		{"set", "Set a system variable by name or id", runSysvarSet},
+		{"import", "Set the system variables of a CSV, JSON or YAML file", runSysvarImport},
+	})
+}
+
+// runSysvarList implements "hmctl sysvar list"
+func runSysvarList(a *app, args []string) error {
+	fs := a.newFlagSet("sysvar list", "[--output format]")
+	output := fs.String("output", "table", outputUsage)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	sysVars, err := client.GetSystemVariableList(true)
+	if err != nil {
+		return err
+	}
+
+	return a.writeOutput(*output, sysVars, func(w io.Writer) {
+		writeSysvarTable(w, sysVars...)
+	})
+}
+
+// runSysvarGet implements "hmctl sysvar get"
+func runSysvarGet(a *app, args []string) error {
+	fs := a.newFlagSet("sysvar get", "[--output format] <name|id>")
+	output := fs.String("output", "table", outputUsage)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	sysVar, err := a.findSysvar(client, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return a.writeOutput(*output, sysVar, func(w io.Writer) {
+		writeSysvarTable(w, *sysVar)
+	})
+}
+
+// runSysvarSet implements "hmctl sysvar set"
+func runSysvarSet(a *app, args []string) error {
+	fs := a.newFlagSet("sysvar set", "<name|id> <value>")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	sysVar, err := a.findSysvar(client, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return client.SetSystemVariable(sysVar, fs.Arg(1))
+}
+
+// runSysvarImport implements "hmctl sysvar import"
+func runSysvarImport(a *app, args []string) error {
+	fs := a.newFlagSet("sysvar import", "[--format json|csv|yaml] <file>")
+	format := fs.String("format", "", "format of the file, by default derived from its extension")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	path := fs.Arg(0)
+	importFormat, err := fileFormat(path, *format)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read system variable file: %w", err)
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	// a dry run validates the values and lists the changes for confirmation
+	dryRun := client.DryRun
+	client.DryRun = true
+	preview, err := client.ImportSystemVariables(bytes.NewReader(data), importFormat)
+	client.DryRun = dryRun
+	if err != nil {
+		return err
+	}
+	var targets []string
+	for _, result := range preview {
+		if result.Err == nil {
+			targets = append(targets, fmt.Sprintf("%s=%s", result.Name, result.Value))
+		}
+	}
+	if err := a.confirm("set", targets); err != nil {
+		return err
+	}
+
+	results, err := client.ImportSystemVariables(bytes.NewReader(data), importFormat)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Name, result.Err))
+			continue
+		}
+		a.report("set %s to %s", result.Name, result.Value)
+	}
+	return errors.Join(errs...)
+}
+
+// findSysvar resolves a system variable by name or id
+func (a *app) findSysvar(client *homematic.Client, nameOrID string) (*homematic.SystemVariable, error) {
+	sysVars, err := client.GetSystemVariableList(true)
+	if err != nil {
+		return nil, err
+	}
+	return homematic.FindSystemVariable(sysVars, nameOrID)
+}
+
+// writeSysvarTable writes system variables as table rows
+func writeSysvarTable(w io.Writer, sysVars ...homematic.SystemVariable) {
+	fmt.Fprintln(w, "ID\tNAME\tKIND\tVALUE")
+	for _, v := range sysVars {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.IseID, v.Name, v.Kind(), v.DisplayValue())
+	}
+}
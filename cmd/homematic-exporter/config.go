@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// config is the configuration of the exporter
+type config struct {
+	URL                string        `yaml:"url"`
+	Token              string        `yaml:"token"`
+	Listen             string        `yaml:"listen"`
+	Interval           time.Duration `yaml:"interval"`
+	AvailabilityWindow time.Duration `yaml:"availability_window"`
+	// ClimateWindow enables per-room temperature and humidity statistics over this window
+	ClimateWindow time.Duration `yaml:"climate_window"`
+	ShowInternal  bool          `yaml:"show_internal"`
+	// FullRefreshInterval enables incremental polls that query the values of
+	// known devices and download the full state list only at this interval
+	FullRefreshInterval time.Duration `yaml:"full_refresh_interval"`
+	MaxClockDrift       time.Duration `yaml:"max_clock_drift"`
+
+	// SlowRequestThreshold logs requests to the CCU taking longer; zero disables the log
+	SlowRequestThreshold time.Duration `yaml:"slow_request_threshold"`
+
+	// Timezone is the IANA time zone of the CCU, used to convert its timestamps
+	Timezone string `yaml:"timezone"`
+	location *time.Location
+
+	// StaleAfter is the age after which data points are reported as stale; zero disables the check
+	StaleAfter time.Duration `yaml:"stale_after"`
+	// StaleAfterByType overrides StaleAfter per device type
+	StaleAfterByType map[string]time.Duration `yaml:"stale_after_by_type"`
+
+	// DeviceLabels maps a device name, address or ise_id to additional labels
+	DeviceLabels map[string]map[string]string `yaml:"device_labels"`
+	// TypeLabels maps a device type to additional labels
+	TypeLabels map[string]map[string]string `yaml:"type_labels"`
+}
+
+// defaultConfig returns the configuration used when no file is given
+func defaultConfig() *config {
+	return &config{
+		URL:                os.Getenv("HOMEMATIC_URL"),
+		Token:              os.Getenv("HOMEMATIC_TOKEN"),
+		Listen:             ":9740",
+		Interval:           30 * time.Second,
+		AvailabilityWindow: 24 * time.Hour,
+	}
+}
+
+// loadConfig reads a YAML configuration file on top of the defaults
+func loadConfig(path string) (*config, error) {
+	cfg := defaultConfig()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %s", cfg.Interval)
+	}
+	if cfg.Timezone != "" {
+		if cfg.location, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// staleness returns the staleness policy, nil if no threshold is configured
+func (c *config) staleness() *homematic.StalenessPolicy {
+	if c.StaleAfter <= 0 && len(c.StaleAfterByType) == 0 {
+		return nil
+	}
+	return &homematic.StalenessPolicy{Default: c.StaleAfter, DeviceTypes: c.StaleAfterByType}
+}
+
+// labels returns the additional labels of a device; device specific labels
+// override type labels
+func (c *config) labels(device *homematic.Device) map[string]string {
+	labels := make(map[string]string)
+	for name, value := range c.TypeLabels[device.DeviceType] {
+		labels[name] = value
+	}
+	for _, key := range []string{device.IseID, device.Address, device.Name} {
+		if key == "" {
+			continue
+		}
+		for name, value := range c.DeviceLabels[key] {
+			labels[name] = value
+		}
+	}
+	return labels
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// exporter polls the CCU and serves the last state list as metrics
+type exporter struct {
+	client       *homematic.Client
+	cfg          *config
+	metrics      *homematic.StateMetrics
+	availability *homematic.AvailabilityTracker
+	staleness    *homematic.StalenessPolicy
+	// cache refreshes the state list incrementally if FullRefreshInterval is set
+	cache *homematic.StateCache
+	// climate tracks room climate statistics if ClimateWindow is set
+	climate     *homematic.ClimateStats
+	roomsLoaded bool
+
+	mu             sync.Mutex
+	devices        []homematic.Device
+	lastScrape     time.Time
+	lastDuration   time.Duration
+	lastScrapeOK   bool
+	scrapeFailures int
+}
+
+// newExporter creates an exporter for the given client and configuration
+func newExporter(client *homematic.Client, cfg *config) *exporter {
+	e := &exporter{
+		client:       client,
+		cfg:          cfg,
+		metrics:      &homematic.StateMetrics{Labels: cfg.labels},
+		availability: homematic.NewAvailabilityTracker(cfg.AvailabilityWindow),
+		staleness:    cfg.staleness(),
+	}
+	if cfg.FullRefreshInterval > 0 {
+		e.cache = homematic.NewStateCache(client)
+		e.cache.FullRefreshInterval = cfg.FullRefreshInterval
+		e.cache.ShowInternal = cfg.ShowInternal
+	}
+	if cfg.ClimateWindow > 0 {
+		e.climate = homematic.NewClimateStats(nil, cfg.ClimateWindow)
+	}
+	return e
+}
+
+// run polls the CCU until the context is canceled
+func (e *exporter) run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		e.poll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches the state list once and stores the result
+func (e *exporter) poll() {
+	start := time.Now()
+	var devices []homematic.Device
+	var err error
+	if e.cache != nil {
+		if _, err = e.cache.Refresh(start); err == nil {
+			devices = e.cache.Devices()
+		}
+	} else {
+		devices, err = e.client.GetStateList("", e.cfg.ShowInternal, false)
+	}
+	duration := time.Since(start)
+	if err == nil && e.climate != nil {
+		e.observeClimate(devices, start)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastScrape = start
+	e.lastDuration = duration
+	e.lastScrapeOK = err == nil
+	if err != nil {
+		e.scrapeFailures++
+		log.Printf("failed to poll state list: %v", err)
+		return
+	}
+	e.devices = devices
+	e.availability.Observe(devices, start)
+}
+
+// observeClimate records the room climate, loading the rooms with the first poll
+func (e *exporter) observeClimate(devices []homematic.Device, at time.Time) {
+	if !e.roomsLoaded {
+		rooms, err := e.client.GetRoomList()
+		if err != nil {
+			log.Printf("failed to load rooms: %v", err)
+			return
+		}
+		e.climate.SetRooms(rooms)
+		e.roomsLoaded = true
+	}
+	e.climate.Observe(devices, at)
+}
+
+// ServeHTTP writes the metrics of the last successful poll
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	devices := e.devices
+	lastScrape, lastDuration, lastScrapeOK, failures := e.lastScrape, e.lastDuration, e.lastScrapeOK, e.scrapeFailures
+	e.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := e.metrics.Write(&buf, devices); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := e.availability.WritePrometheus(&buf, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if e.staleness != nil {
+		if err := e.staleness.WritePrometheus(&buf, devices, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if e.climate != nil {
+		if err := e.climate.WritePrometheus(&buf, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := e.client.WriteTransportMetrics(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := e.client.WriteLatencyMetrics(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	success := 0
+	if lastScrapeOK {
+		success = 1
+	}
+	fmt.Fprintf(&buf, "# HELP homematic_exporter_last_poll_success Whether the last poll of the CCU succeeded.\n")
+	fmt.Fprintf(&buf, "# TYPE homematic_exporter_last_poll_success gauge\nhomematic_exporter_last_poll_success %d\n", success)
+	fmt.Fprintf(&buf, "# HELP homematic_exporter_poll_failures_total Number of failed polls of the CCU.\n")
+	fmt.Fprintf(&buf, "# TYPE homematic_exporter_poll_failures_total counter\nhomematic_exporter_poll_failures_total %d\n", failures)
+	fmt.Fprintf(&buf, "# HELP homematic_exporter_last_poll_duration_seconds Duration of the last poll of the CCU.\n")
+	fmt.Fprintf(&buf, "# TYPE homematic_exporter_last_poll_duration_seconds gauge\nhomematic_exporter_last_poll_duration_seconds %g\n", lastDuration.Seconds())
+	if drift, ok := e.client.ClockDrift(); ok {
+		fmt.Fprintf(&buf, "# HELP homematic_ccu_clock_drift_seconds Difference between the CCU clock and the local clock.\n")
+		fmt.Fprintf(&buf, "# TYPE homematic_ccu_clock_drift_seconds gauge\nhomematic_ccu_clock_drift_seconds %g\n", drift.Seconds())
+	}
+	if !lastScrape.IsZero() {
+		fmt.Fprintf(&buf, "# HELP homematic_exporter_last_poll_timestamp_seconds Unix time of the last poll of the CCU.\n")
+		fmt.Fprintf(&buf, "# TYPE homematic_exporter_last_poll_timestamp_seconds gauge\nhomematic_exporter_last_poll_timestamp_seconds %d\n", lastScrape.Unix())
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+const testStateList = `<stateList>
+	<device name="Thermostat" ise_id="1234" unreach="false" config_pending="false">
+		<channel name="Thermostat:0" ise_id="1235" index="0">
+			<datapoint name="HmIP-RF.000A1234567890:0.LOW_BAT" type="LOW_BAT" ise_id="1238" value="false" valuetype="2" timestamp="1700000000"/>
+			<datapoint name="HmIP-RF.000A1234567890:0.UNREACH" type="UNREACH" ise_id="1241" value="false" valuetype="2" timestamp="1700000000"/>
+		</channel>
+		<channel name="Thermostat:1" ise_id="1250" index="1">
+			<datapoint name="HmIP-RF.000A1234567890:1.ACTUAL_TEMPERATURE" type="ACTUAL_TEMPERATURE" ise_id="1251" value="21.5" valuetype="4" timestamp="1700000000"/>
+		</channel>
+	</device>
+</stateList>`
+
+func TestExporterServeHTTP(t *testing.T) {
+	ccu := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testStateList))
+	}))
+	defer ccu.Close()
+
+	cfg := defaultConfig()
+	cfg.DeviceLabels = map[string]map[string]string{"Thermostat": {"room": "Kitchen"}}
+
+	exp := newExporter(homematic.NewClient(ccu.URL, "token"), cfg)
+	exp.poll()
+
+	rec := httptest.NewRecorder()
+	exp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`homematic_datapoint_value{device="Thermostat",device_ise_id="1234",room="Kitchen",channel="Thermostat:1",datapoint="ACTUAL_TEMPERATURE",ise_id="1251"} 21.5`,
+		`homematic_device_low_battery{device="Thermostat",device_ise_id="1234",room="Kitchen"} 0`,
+		`homematic_device_availability_ratio{ise_id="1234",name="Thermostat"} 1`,
+		"homematic_exporter_last_poll_success 1",
+		"homematic_client_connections_opened_total 1",
+		`homematic_client_request_duration_seconds_count{endpoint="statelist.cgi"} 1`,
+		"# TYPE homematic_ccu_clock_drift_seconds gauge",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestExporterStaleDataPoints(t *testing.T) {
+	ccu := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testStateList))
+	}))
+	defer ccu.Close()
+
+	cfg := defaultConfig()
+	cfg.StaleAfter = time.Hour
+
+	exp := newExporter(homematic.NewClient(ccu.URL, "token"), cfg)
+	exp.poll()
+
+	rec := httptest.NewRecorder()
+	exp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	want := `homematic_datapoint_stale_age_seconds{device="Thermostat",device_ise_id="1234",channel="Thermostat:1",datapoint="ACTUAL_TEMPERATURE",ise_id="1251"}`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("expected metrics to contain %q, got:\n%s", want, rec.Body.String())
+	}
+}
+
+func TestExporterPollFailure(t *testing.T) {
+	ccu := httptest.NewServer(http.NotFoundHandler())
+	defer ccu.Close()
+
+	exp := newExporter(homematic.NewClient(ccu.URL, "token"), defaultConfig())
+	exp.poll()
+
+	rec := httptest.NewRecorder()
+	exp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "homematic_exporter_poll_failures_total 1") {
+		t.Errorf("expected failed poll to be counted, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exporter.yaml")
+	content := `
+url: https://ccu.local
+interval: 1m
+stale_after: 2h
+timezone: Europe/Berlin
+stale_after_by_type:
+  HmIP-SWDO: 24h
+type_labels:
+  HmIP-eTRV-2: {category: heating, room: unknown}
+device_labels:
+  "1234": {room: Kitchen}
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if cfg.Interval != time.Minute || cfg.Listen != ":9740" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	if cfg.location == nil || cfg.location.String() != "Europe/Berlin" {
+		t.Errorf("unexpected location: %v", cfg.location)
+	}
+	if policy := cfg.staleness(); policy == nil || policy.Default != 2*time.Hour || policy.DeviceTypes["HmIP-SWDO"] != 24*time.Hour {
+		t.Errorf("unexpected staleness policy: %+v", policy)
+	}
+
+	labels := cfg.labels(&homematic.Device{IseID: "1234", DeviceType: "HmIP-eTRV-2"})
+	if labels["room"] != "Kitchen" || labels["category"] != "heating" {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+}
+
+func TestExporterClimate(t *testing.T) {
+	ccu := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/roomlist.cgi") {
+			w.Write([]byte(`<roomList><room name="Kitchen" ise_id="1"><channel ise_id="1250"/></room></roomList>`))
+			return
+		}
+		w.Write([]byte(testStateList))
+	}))
+	defer ccu.Close()
+
+	cfg := defaultConfig()
+	cfg.ClimateWindow = time.Hour
+	exp := newExporter(homematic.NewClient(ccu.URL, "token"), cfg)
+	exp.poll()
+	exp.poll()
+
+	rec := httptest.NewRecorder()
+	exp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		`homematic_room_temperature_celsius{room="Kitchen",quantile="1"} 21.5`,
+		`homematic_room_temperature_celsius_count{room="Kitchen"} 2`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, rec.Body.String())
+		}
+	}
+}
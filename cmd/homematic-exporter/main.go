@@ -0,0 +1,71 @@
+// Command homematic-exporter polls a CCU through the XML-API and serves its
+// device, data point, battery, reachability and duty cycle state as Prometheus metrics
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+func main() {
+	configPath := flag.String("config", "", "path of the YAML configuration file")
+	listen := flag.String("listen", "", "listen address, overrides the configuration")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *listen != "" {
+		cfg.Listen = *listen
+	}
+	if cfg.URL == "" {
+		log.Fatal("no CCU URL configured, set url in the config or HOMEMATIC_URL")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := homematic.NewClient(cfg.URL, cfg.Token)
+	client.Location = cfg.location
+	client.ClockDriftThreshold = cfg.MaxClockDrift
+	client.OnClockDrift = func(drift time.Duration) {
+		log.Printf("CCU clock differs from local clock by %s, check the NTP configuration of the CCU", drift)
+	}
+	client.SlowRequestThreshold = cfg.SlowRequestThreshold
+	client.OnSlowRequest = func(req homematic.SlowRequest) {
+		log.Printf("slow request to %s took %s (params %v)", req.Endpoint, req.Duration.Round(time.Millisecond), req.Params)
+	}
+	exp := newExporter(client, cfg)
+	go exp.run(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exp)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><h1>HomeMatic Exporter</h1><a href="/metrics">Metrics</a></body></html>`))
+	})
+	server := &http.Server{Addr: cfg.Listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+		client.Close(shutdownCtx)
+	}()
+
+	log.Printf("serving metrics on %s, polling %s every %s", cfg.Listen, cfg.URL, cfg.Interval)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,238 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AlarmMode is the arming mode of an alarm zone
+type AlarmMode string
+
+const (
+	AlarmDisarmed  AlarmMode = "disarmed"
+	AlarmArmedHome AlarmMode = "armed_home"
+	AlarmArmedAway AlarmMode = "armed_away"
+)
+
+// alarmModes lists the modes in the order of their default enum index
+var alarmModes = []AlarmMode{AlarmDisarmed, AlarmArmedHome, AlarmArmedAway}
+
+// AlarmZone controls an alarm zone of the CCU. A zone consists of an alarm
+// system variable, which the CCU programs set when a contributing sensor
+// triggers while the zone is armed, an arming system variable holding the
+// mode, and optionally siren data points.
+type AlarmZone struct {
+	Client *Client
+	Name   string
+
+	// AlarmVariable is the name or ise_id of the alarm system variable, e.g. "Alarmzone 1"
+	AlarmVariable string
+
+	// ArmingVariable is the name or ise_id of the bool or enum system variable holding the mode
+	ArmingVariable string
+
+	// Modes maps modes to the value names (or values) of the arming variable.
+	// By default enum variables use the indexes 0 (disarmed), 1 (armed home)
+	// and 2 (armed away), and bool variables false for disarmed and true otherwise.
+	Modes map[AlarmMode]string
+
+	// Sirens are the ise_ids of the siren data points (e.g. the STATE of a
+	// HM-Sec-Sir), which are switched off when the zone is disarmed
+	Sirens []string
+
+	// Sensors are the ise_ids of the data points of the contributing sensors;
+	// the latest change of one of them is reported with the alarm
+	Sensors []string
+
+	// OnAlarm is called when the alarm variable is triggered or reset
+	OnAlarm func(event AlarmEvent)
+
+	mu        sync.Mutex
+	known     bool
+	triggered bool
+	sensor    *DataPointChange
+}
+
+// AlarmZoneStatus is the state of an alarm zone
+type AlarmZoneStatus struct {
+	Mode        AlarmMode `json:"mode"`
+	Triggered   bool      `json:"triggered"`
+	TriggeredAt time.Time `json:"triggered_at,omitempty"`
+	SirenActive bool      `json:"siren_active"`
+}
+
+// AlarmEvent reports that the alarm variable of a zone was triggered or reset
+type AlarmEvent struct {
+	Zone      string    `json:"zone"`
+	Triggered bool      `json:"triggered"`
+	At        time.Time `json:"at"`
+	// Sensor is the latest change of a contributing sensor, if any was observed
+	Sensor *DataPointChange `json:"sensor,omitempty"`
+}
+
+// NewAlarmZone creates an alarm zone from its alarm and arming system variables
+func NewAlarmZone(client *Client, name, alarmVariable, armingVariable string) *AlarmZone {
+	return &AlarmZone{Client: client, Name: name, AlarmVariable: alarmVariable, ArmingVariable: armingVariable}
+}
+
+// Arm sets the arming variable to the given mode
+func (z *AlarmZone) Arm(mode AlarmMode) error {
+	sysVars, err := z.Client.GetSystemVariableList(false)
+	if err != nil {
+		return err
+	}
+	arming, err := FindSystemVariable(sysVars, z.ArmingVariable)
+	if err != nil {
+		return err
+	}
+	return z.Client.SetSystemVariable(arming, z.modeInput(arming, mode))
+}
+
+// Disarm disarms the zone, switches off the sirens and resets the alarm variable
+func (z *AlarmZone) Disarm() error {
+	sysVars, err := z.Client.GetSystemVariableList(false)
+	if err != nil {
+		return err
+	}
+	arming, err := FindSystemVariable(sysVars, z.ArmingVariable)
+	if err != nil {
+		return err
+	}
+	alarm, err := FindSystemVariable(sysVars, z.AlarmVariable)
+	if err != nil {
+		return err
+	}
+
+	if err := z.Client.SetSystemVariable(arming, z.modeInput(arming, AlarmDisarmed)); err != nil {
+		return err
+	}
+	var errs []error
+	if len(z.Sirens) > 0 {
+		values := make([]string, len(z.Sirens))
+		for i := range values {
+			values[i] = "false"
+		}
+		errs = append(errs, z.Client.ChangeState(z.Sirens, values))
+	}
+	if parseFlag(alarm.Value) {
+		errs = append(errs, z.Client.SetSystemVariable(alarm, "false"))
+	}
+	return errors.Join(errs...)
+}
+
+// Status reads the mode, the alarm variable and the sirens of the zone
+func (z *AlarmZone) Status() (*AlarmZoneStatus, error) {
+	sysVars, err := z.Client.GetSystemVariableList(false)
+	if err != nil {
+		return nil, err
+	}
+	arming, err := FindSystemVariable(sysVars, z.ArmingVariable)
+	if err != nil {
+		return nil, err
+	}
+	alarm, err := FindSystemVariable(sysVars, z.AlarmVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := z.mode(arming)
+	if err != nil {
+		return nil, err
+	}
+	status := &AlarmZoneStatus{Mode: mode, Triggered: parseFlag(alarm.Value)}
+	if status.Triggered && alarm.Timestamp > 0 {
+		status.TriggeredAt = time.Unix(alarm.Timestamp, 0)
+	}
+
+	if len(z.Sirens) > 0 {
+		devices, err := z.Client.GetState(nil, nil, z.Sirens)
+		if err != nil {
+			return nil, err
+		}
+		for _, device := range devices {
+			for _, ch := range device.Channels {
+				for _, dp := range ch.DataPoints {
+					if parseFlag(dp.Value) {
+						status.SirenActive = true
+					}
+				}
+			}
+		}
+	}
+	return status, nil
+}
+
+// Observe records changes of the contributing sensors, which are reported
+// with the next alarm event
+func (z *AlarmZone) Observe(change DataPointChange) {
+	for _, iseID := range z.Sensors {
+		if change.IseID == iseID {
+			z.mu.Lock()
+			z.sensor = &change
+			z.mu.Unlock()
+			return
+		}
+	}
+}
+
+// ObserveSystemVariables processes a system variable snapshot and calls
+// OnAlarm when the alarm variable was triggered or reset since the previous
+// snapshot. The first snapshot only records the current state.
+func (z *AlarmZone) ObserveSystemVariables(sysVars []SystemVariable, at time.Time) {
+	alarm, err := FindSystemVariable(sysVars, z.AlarmVariable)
+	if err != nil {
+		return
+	}
+	triggered := parseFlag(alarm.Value)
+
+	z.mu.Lock()
+	changed := z.known && triggered != z.triggered
+	z.known = true
+	z.triggered = triggered
+	event := AlarmEvent{Zone: z.Name, Triggered: triggered, At: at, Sensor: z.sensor}
+	if changed && !triggered {
+		z.sensor = nil
+	}
+	z.mu.Unlock()
+
+	if changed && z.OnAlarm != nil {
+		z.OnAlarm(event)
+	}
+}
+
+// modeInput returns the input for the arming variable of a mode
+func (z *AlarmZone) modeInput(arming *SystemVariable, mode AlarmMode) string {
+	if input, ok := z.Modes[mode]; ok {
+		return input
+	}
+	if arming.Kind() == SysVarEnum {
+		for i, m := range alarmModes {
+			if m == mode {
+				return strconv.Itoa(i)
+			}
+		}
+	}
+	return strconv.FormatBool(mode != AlarmDisarmed)
+}
+
+// mode maps the value of the arming variable to a mode; a set bool variable
+// is reported as armed away unless Modes maps only armed home
+func (z *AlarmZone) mode(arming *SystemVariable) (AlarmMode, error) {
+	current, err := arming.ParseValue(arming.Value)
+	if err != nil {
+		return "", err
+	}
+	for _, mode := range []AlarmMode{AlarmDisarmed, AlarmArmedAway, AlarmArmedHome} {
+		if _, ok := z.Modes[mode]; len(z.Modes) > 0 && !ok {
+			continue
+		}
+		value, err := arming.ParseValue(z.modeInput(arming, mode))
+		if err == nil && value == current {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("value %q of %s matches no alarm mode", arming.Value, arming.Name)
+}
@@ -0,0 +1,141 @@
+package homematic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// alarmCCU serves the system variables of an alarm zone and a siren data point
+type alarmCCU struct {
+	values  map[string]string
+	changes []string
+}
+
+func (s *alarmCCU) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch r.URL.Path {
+	case "/addons/xmlapi/sysvarlist.cgi":
+		fmt.Fprintf(w, `<systemVariables>
+			<systemVariable name="Alarmzone 1" ise_id="2498" value="%s" type="2" subtype="6" timestamp="1699992800"/>
+			<systemVariable name="Alarmanlage" ise_id="2499" value="%s" type="16" subtype="29" value_list="Aus;Intern;Extern"/>
+		</systemVariables>`, s.values["2498"], s.values["2499"])
+	case "/addons/xmlapi/state.cgi":
+		fmt.Fprintf(w, `<stateList><device ise_id="300"><channel ise_id="301">
+			<datapoint type="STATE" ise_id="302" value="%s"/>
+		</channel></device></stateList>`, s.values["302"])
+	case "/addons/xmlapi/statechange.cgi":
+		ids := strings.Split(query.Get("ise_id"), ",")
+		values := strings.Split(query.Get("new_value"), ",")
+		var result strings.Builder
+		result.WriteString("<result>")
+		for i, id := range ids {
+			s.values[id] = values[i]
+			s.changes = append(s.changes, id+"="+values[i])
+			fmt.Fprintf(&result, `<changed id="%s" new_value="%s"/>`, id, values[i])
+		}
+		result.WriteString("</result>")
+		w.Write([]byte(result.String()))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestAlarmZone(t *testing.T) {
+	ccu := &alarmCCU{values: map[string]string{"2498": "false", "2499": "0", "302": "false"}}
+	server := httptest.NewServer(ccu)
+	defer server.Close()
+
+	zone := NewAlarmZone(NewClient(server.URL, "token"), "house", "Alarmzone 1", "Alarmanlage")
+	zone.Sirens = []string{"302"}
+
+	status, err := zone.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Mode != AlarmDisarmed || status.Triggered || status.SirenActive {
+		t.Errorf("unexpected initial status: %+v", status)
+	}
+
+	if err := zone.Arm(AlarmArmedAway); err != nil {
+		t.Fatalf("Arm failed: %v", err)
+	}
+	if ccu.values["2499"] != "2" {
+		t.Errorf("expected the arming variable to be set to the away index, got %q", ccu.values["2499"])
+	}
+
+	// the CCU triggers the alarm and the siren
+	ccu.values["2498"] = "true"
+	ccu.values["302"] = "true"
+	status, err = zone.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Mode != AlarmArmedAway || !status.Triggered || !status.SirenActive || status.TriggeredAt.IsZero() {
+		t.Errorf("unexpected triggered status: %+v", status)
+	}
+
+	ccu.changes = nil
+	if err := zone.Disarm(); err != nil {
+		t.Fatalf("Disarm failed: %v", err)
+	}
+	if strings.Join(ccu.changes, " ") != "2499=0 302=false 2498=false" {
+		t.Errorf("unexpected changes on disarm: %v", ccu.changes)
+	}
+
+	zone.Modes = map[AlarmMode]string{AlarmDisarmed: "Aus", AlarmArmedHome: "Intern"}
+	if err := zone.Arm(AlarmArmedHome); err != nil {
+		t.Fatalf("Arm failed: %v", err)
+	}
+	if status, err := zone.Status(); err != nil || status.Mode != AlarmArmedHome {
+		t.Errorf("expected armed home, got %+v, %v", status, err)
+	}
+}
+
+func TestAlarmZoneBoolArming(t *testing.T) {
+	arming := &SystemVariable{Name: "Scharf", ValueType: regaValueTypeBinary, Value: "true"}
+	zone := &AlarmZone{}
+
+	if input := zone.modeInput(arming, AlarmArmedHome); input != "true" {
+		t.Errorf("expected armed home to set the bool variable, got %q", input)
+	}
+	if mode, err := zone.mode(arming); err != nil || mode != AlarmArmedAway {
+		t.Errorf("expected a set bool variable to be armed away, got %q, %v", mode, err)
+	}
+	arming.Value = "false"
+	if mode, err := zone.mode(arming); err != nil || mode != AlarmDisarmed {
+		t.Errorf("expected disarmed, got %q, %v", mode, err)
+	}
+}
+
+func TestAlarmZoneEvents(t *testing.T) {
+	var events []AlarmEvent
+	zone := NewAlarmZone(nil, "house", "Alarmzone 1", "Alarmanlage")
+	zone.Sensors = []string{"1234"}
+	zone.OnAlarm = func(event AlarmEvent) { events = append(events, event) }
+
+	snapshot := func(value string) []SystemVariable {
+		return []SystemVariable{{Name: "Alarmzone 1", IseID: "2498", Value: value, ValueType: regaValueTypeBinary, Subtype: regaSubtypeAlarm}}
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	zone.ObserveSystemVariables(snapshot("false"), start)
+	zone.Observe(DataPointChange{IseID: "9999", NewValue: "true"})
+	zone.Observe(DataPointChange{IseID: "1234", Name: "Fenster Küche", NewValue: "true", ObservedAt: start.Add(time.Second)})
+	zone.ObserveSystemVariables(snapshot("true"), start.Add(2*time.Second))
+	zone.ObserveSystemVariables(snapshot("true"), start.Add(3*time.Second))
+	zone.ObserveSystemVariables(snapshot("false"), start.Add(4*time.Second))
+
+	if len(events) != 2 {
+		t.Fatalf("expected a trigger and a reset event, got %+v", events)
+	}
+	if !events[0].Triggered || events[0].Zone != "house" || events[0].Sensor == nil || events[0].Sensor.IseID != "1234" {
+		t.Errorf("unexpected trigger event: %+v", events[0])
+	}
+	if events[1].Triggered || !events[1].At.Equal(start.Add(4*time.Second)) {
+		t.Errorf("unexpected reset event: %+v", events[1])
+	}
+}
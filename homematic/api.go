@@ -0,0 +1,42 @@
+package homematic
+
+import "context"
+
+// HomematicAPI is the method set of Client for reading and controlling a
+// CCU, so that automations can depend on it and be tested with
+// homematicmock.HomematicAPIMock instead of a CCU
+type HomematicAPI interface {
+	GetVersion() (string, error)
+	Ping(ctx context.Context) (*PingResult, error)
+
+	GetDeviceList(deviceIDs []string, showInternal, showRemote bool) ([]Device, error)
+	GetDeviceTypes() ([]DeviceType, error)
+	GetStateList(deviceID string, showInternal, showRemote bool) ([]Device, error)
+	GetState(deviceIDs, channelIDs, datapointIDs []string) ([]Device, error)
+	ChangeState(deviceIDs, newValues []string) error
+	ChangeStates(deviceIDs, newValues []string) ([]ChangeResult, error)
+	ChangeStatesContext(ctx context.Context, deviceIDs, newValues []string) ([]ChangeResult, error)
+	GetMasterValue(deviceIDs, requestedNames []string) ([]Device, error)
+	ChangeMasterValue(deviceIDs, names, values []string) error
+	ChangeMasterValueContext(ctx context.Context, deviceIDs, names, values []string) error
+
+	PressShort(channelID string) error
+	PressLong(channelID string) error
+	GetVirtualKeys() ([]VirtualKey, error)
+	PressVirtualKey(nameOrID string, long bool) error
+
+	GetProgramList() ([]Program, error)
+	RunProgram(programID string, condCheck bool) error
+	RunScript(ctx context.Context, script string) (*ScriptResult, error)
+	ChangeProgramActions(programID string, active, visible *bool) error
+
+	GetRoomList() ([]Room, error)
+	GetFunctionList() ([]Function, error)
+	GetTopology() (*Topology, error)
+
+	GetSystemVariableList(showText bool) ([]SystemVariable, error)
+	GetSystemVariable(iseID string, showText bool) (*SystemVariable, error)
+	SetSystemVariable(sysVar *SystemVariable, input string) error
+}
+
+var _ HomematicAPI = (*Client)(nil)
@@ -0,0 +1,128 @@
+package homematic
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Codes of APIError
+const (
+	// ErrorCodeNotFound is reported for unknown ise_ids
+	ErrorCodeNotFound = "not_found"
+	// ErrorCodeNotAuthenticated is reported for missing or invalid session ids (sid)
+	ErrorCodeNotAuthenticated = "not_authenticated"
+)
+
+// APIError is an error reported by the XML-API in an <error> document or a
+// not_found element, e.g. for an invalid sid or an unknown ise_id. Errors
+// with ErrorCodeNotAuthenticated also match ErrNotAuthenticated.
+type APIError struct {
+	Endpoint string `json:"endpoint"`
+	// Code is the code given by the CCU or derived from the message, it is
+	// empty for unknown errors
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = e.Code
+	}
+	return fmt.Sprintf("%s: %s", e.Endpoint, message)
+}
+
+// Is reports whether the error is ErrNotAuthenticated
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotAuthenticated && e.Code == ErrorCodeNotAuthenticated
+}
+
+// apiErrorDocument is an <error> document or a <result> holding an <error> element
+type apiErrorDocument struct {
+	XMLName xml.Name
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+	Errors  []struct {
+		Code    string `xml:"code,attr"`
+		Message string `xml:",chardata"`
+	} `xml:"error"`
+}
+
+// parseAPIError returns the error of an error document, or nil for other responses
+func parseAPIError(endpoint string, body []byte) *APIError {
+	if decl := xmlDeclaration(body); decl != nil {
+		body = body[len(decl):]
+	}
+	body = bytes.TrimSpace(body)
+	// other responses are decoded by the callers, so only error documents are parsed here
+	if !bytes.HasPrefix(body, []byte("<error")) &&
+		!(bytes.HasPrefix(body, []byte("<result")) && bytes.Contains(body, []byte("<error"))) {
+		return nil
+	}
+	if !utf8.Valid(body) {
+		var converted bytes.Buffer
+		writeLatin1AsUTF8(&converted, body)
+		body = converted.Bytes()
+	}
+
+	var doc apiErrorDocument
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+	switch {
+	case doc.XMLName.Local == "error":
+	case doc.XMLName.Local == "result" && len(doc.Errors) > 0:
+		doc.Code, doc.Message = doc.Errors[0].Code, doc.Errors[0].Message
+	default:
+		return nil
+	}
+
+	apiErr := &APIError{Endpoint: endpoint, Code: strings.TrimSpace(doc.Code), Message: strings.TrimSpace(doc.Message)}
+	if apiErr.Code == "" {
+		apiErr.Code = apiErrorCode(apiErr.Message)
+	}
+	return apiErr
+}
+
+// apiErrorCode derives the code of an error without code attribute from its message
+func apiErrorCode(message string) string {
+	message = strings.ToLower(message)
+	words := strings.FieldsFunc(message, func(r rune) bool { return !unicode.IsLetter(r) && r != '_' })
+	switch {
+	case slices.Contains(words, "sid") || strings.Contains(message, "authenticat"):
+		return ErrorCodeNotAuthenticated
+	case strings.Contains(message, "not found") || strings.Contains(message, "unknown"):
+		return ErrorCodeNotFound
+	default:
+		return ""
+	}
+}
+
+// notFoundError returns an APIError if a response of a write consists of a
+// not_found element, which the addon returns for unknown ise_ids
+func notFoundError(endpoint string, params map[string]string, body []byte) error {
+	var result struct {
+		NotFound *struct{} `xml:"not_found"`
+	}
+	if err := decodeXML(body, &result); err != nil || result.NotFound == nil {
+		return nil
+	}
+
+	var ids []string
+	for key, value := range params {
+		if key == "ise_id" || strings.HasSuffix(key, "_id") {
+			ids = append(ids, value)
+		}
+	}
+	slices.Sort(ids)
+	message := "not found"
+	if len(ids) > 0 {
+		message = "ise_id not found: " + strings.Join(ids, ",")
+	}
+	return &APIError{Endpoint: endpoint, Code: ErrorCodeNotFound, Message: message}
+}
@@ -0,0 +1,86 @@
+package homematic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		code    string
+		message string
+	}{
+		{"error document", `<?xml version="1.0" encoding="ISO-8859-1" ?><error>Invalid sid</error>`, ErrorCodeNotAuthenticated, "Invalid sid"},
+		{"code attribute", `<error code="42">internal failure</error>`, "42", "internal failure"},
+		{"error in result", `<result><error>ise_id 9999 not found</error></result>`, ErrorCodeNotFound, "ise_id 9999 not found"},
+		{"latin-1 message", "<error>Ger\xe4t unbekannt</error>", "", "Gerät unbekannt"},
+		{"unknown error", `<error>something went wrong</error>`, "", "something went wrong"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(staticHandler([]byte(tt.body)))
+			defer server.Close()
+
+			_, err := NewClient(server.URL, "token").GetDeviceList(nil, false, false)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+			if apiErr.Endpoint != "devicelist.cgi" || apiErr.Code != tt.code || apiErr.Message != tt.message {
+				t.Errorf("unexpected error %+v", apiErr)
+			}
+			if errors.Is(err, ErrNotAuthenticated) != (tt.code == ErrorCodeNotAuthenticated) {
+				t.Errorf("unexpected ErrNotAuthenticated match for %v", err)
+			}
+		})
+	}
+}
+
+func TestAPIErrorConnectivity(t *testing.T) {
+	server := httptest.NewServer(staticHandler([]byte(`<result><error>unknown ise_id</error></result>`)))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	if _, err := client.GetState(nil, nil, []string{"9999"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if state := client.Connectivity(); state != StateConnected {
+		t.Errorf("expected errors of the CCU to leave the connection up, got %v", state)
+	}
+}
+
+func TestAPIErrorNotFound(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/addons/xmlapi/runprogram.cgi" {
+			w.Write([]byte(`<result><not_found/></result>`))
+			return
+		}
+		mock.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	err = client.RunProgram("9999", false)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeNotFound || err.Error() != "runprogram.cgi: ise_id not found: 9999" {
+		t.Errorf("expected a not found error for the program, got %v", err)
+	}
+
+	err = client.ChangeState([]string{"2416", "9999"}, []string{"20.5", "1"})
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeNotFound || err.Error() != "statechange.cgi: data point not found: 9999" {
+		t.Errorf("expected a not found error for the data point, got %v", err)
+	}
+	if value, _ := mock.Value("2416"); value != "20.5" {
+		t.Errorf("expected the known data point to be changed, got %s", value)
+	}
+}
@@ -0,0 +1,89 @@
+package homematic
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// AuthProvider supplies authentication for every request, e.g. bearer tokens
+// or session cookies required by reverse proxies (Authelia, Traefik, ...) in
+// front of the CCU
+type AuthProvider interface {
+	// Authenticate returns headers and cookies to add to the request
+	Authenticate(req *http.Request) (http.Header, []*http.Cookie, error)
+}
+
+// AuthProviderFunc adapts a function to the AuthProvider interface
+type AuthProviderFunc func(req *http.Request) (http.Header, []*http.Cookie, error)
+
+// Authenticate implements AuthProvider
+func (f AuthProviderFunc) Authenticate(req *http.Request) (http.Header, []*http.Cookie, error) {
+	return f(req)
+}
+
+// BearerToken returns an AuthProvider sending the token as "Authorization: Bearer" header
+func BearerToken(token string) AuthProvider {
+	return AuthProviderFunc(func(req *http.Request) (http.Header, []*http.Cookie, error) {
+		return http.Header{"Authorization": {"Bearer " + token}}, nil, nil
+	})
+}
+
+// StaticHeaders returns an AuthProvider sending fixed headers, e.g. a proxy API key
+func StaticHeaders(header http.Header) AuthProvider {
+	return AuthProviderFunc(func(req *http.Request) (http.Header, []*http.Cookie, error) {
+		return header, nil, nil
+	})
+}
+
+// SessionCookies returns an AuthProvider sending fixed cookies, e.g. a proxy session cookie
+func SessionCookies(cookies ...*http.Cookie) AuthProvider {
+	return AuthProviderFunc(func(req *http.Request) (http.Header, []*http.Cookie, error) {
+		return nil, cookies, nil
+	})
+}
+
+// applyAuthProvider adds the headers and cookies of the client's auth provider to a request
+func (c *Client) applyAuthProvider(req *http.Request) error {
+	if c.Auth == nil {
+		return nil
+	}
+
+	header, cookies, err := c.Auth.Authenticate(req)
+	if err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+	for key, values := range header {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	return nil
+}
+
+// SetClientCertificate configures a TLS client certificate for mutual TLS with
+// the CCU or a reverse proxy in front of it
+func (c *Client) SetClientCertificate(cert tls.Certificate) error {
+	if browserTransport {
+		return fmt.Errorf("client certificates: %w", ErrUnsupportedPlatform)
+	}
+	transport, ok := httpTransport(c.HTTPClient.Transport)
+	if !ok {
+		return errors.New("client certificates require an *http.Transport")
+	}
+
+	tlsConfig := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	tlsConfig.Certificates = []tls.Certificate{cert}
+	transport.TLSClientConfig = tlsConfig
+
+	return nil
+}
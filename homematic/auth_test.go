@@ -0,0 +1,55 @@
+package homematic
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("authelia_session")
+		if r.Header.Get("Authorization") != "Bearer abc" || err != nil || cookie.Value != "s3ss10n" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	client.Auth = AuthProviderFunc(func(req *http.Request) (http.Header, []*http.Cookie, error) {
+		header, _, _ := BearerToken("abc").Authenticate(req)
+		_, cookies, _ := SessionCookies(&http.Cookie{Name: "authelia_session", Value: "s3ss10n"}).Authenticate(req)
+		return header, cookies, nil
+	})
+
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+}
+
+func TestAuthProviderError(t *testing.T) {
+	client := NewClient("http://127.0.0.1", "token")
+	client.Auth = AuthProviderFunc(func(req *http.Request) (http.Header, []*http.Cookie, error) {
+		return nil, nil, errors.New("token expired")
+	})
+
+	if _, err := client.GetVersion(); err == nil {
+		t.Error("expected auth provider error to be returned")
+	}
+}
+
+func TestSetClientCertificate(t *testing.T) {
+	client := NewClient("https://127.0.0.1", "token")
+	if err := client.SetClientCertificate(tls.Certificate{Certificate: [][]byte{{1}}}); err != nil {
+		t.Fatalf("SetClientCertificate failed: %v", err)
+	}
+
+	tlsConfig := client.HTTPClient.Transport.(*http.Transport).TLSClientConfig
+	if len(tlsConfig.Certificates) != 1 || !tlsConfig.InsecureSkipVerify {
+		t.Errorf("expected certificate to be added to the existing TLS config, got %+v", tlsConfig)
+	}
+}
@@ -0,0 +1,189 @@
+package homematic
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DeviceAvailability is the availability report of a single device
+type DeviceAvailability struct {
+	IseID        string
+	Name         string
+	Reachable    bool
+	Availability float64 // fraction of the observed window the device was reachable (0..1)
+	LastSeen     time.Time
+	Observed     time.Duration
+}
+
+// AvailabilityTracker tracks per-device availability and last-seen timestamps
+// over a rolling window, based on unreach transitions
+type AvailabilityTracker struct {
+	mu      sync.Mutex
+	window  time.Duration
+	devices map[string]*availabilityHistory
+}
+
+// availabilityHistory holds the reachability transitions of a device
+type availabilityHistory struct {
+	name        string
+	firstSeen   time.Time
+	lastSeen    time.Time
+	transitions []availabilityTransition
+}
+
+// availabilityTransition marks a change of reachability at a point in time
+type availabilityTransition struct {
+	at        time.Time
+	reachable bool
+}
+
+// NewAvailabilityTracker creates a tracker reporting availability over the given rolling window
+func NewAvailabilityTracker(window time.Duration) *AvailabilityTracker {
+	return &AvailabilityTracker{
+		window:  window,
+		devices: make(map[string]*availabilityHistory),
+	}
+}
+
+// Observe records the reachability of all devices of a state list snapshot
+func (t *AvailabilityTracker) Observe(devices []Device, at time.Time) {
+	for _, device := range devices {
+		reachable := !device.Unreach
+		if device.Maintenance != nil && device.Maintenance.Unreach {
+			reachable = false
+		}
+		t.ObserveReachability(device.IseID, device.Name, reachable, at)
+	}
+}
+
+// ObserveReachability records the reachability of a single device, e.g. from an UNREACH data point change
+func (t *AvailabilityTracker) ObserveReachability(iseID, name string, reachable bool, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.devices[iseID]
+	if !ok {
+		h = &availabilityHistory{firstSeen: at}
+		t.devices[iseID] = h
+	}
+	if name != "" {
+		h.name = name
+	}
+	if reachable && at.After(h.lastSeen) {
+		h.lastSeen = at
+	}
+
+	if n := len(h.transitions); n == 0 || h.transitions[n-1].reachable != reachable {
+		h.transitions = append(h.transitions, availabilityTransition{at: at, reachable: reachable})
+	}
+	h.prune(at.Add(-t.window))
+}
+
+// prune drops transitions before the window start, keeping the one in effect at the start
+func (h *availabilityHistory) prune(windowStart time.Time) {
+	i := 0
+	for i+1 < len(h.transitions) && !h.transitions[i+1].at.After(windowStart) {
+		i++
+	}
+	h.transitions = h.transitions[i:]
+}
+
+// availability computes the reachable fraction and the observed duration within the window
+func (h *availabilityHistory) availability(windowStart, now time.Time) (float64, time.Duration) {
+	start := windowStart
+	if h.firstSeen.After(start) {
+		start = h.firstSeen
+	}
+	if !now.After(start) || len(h.transitions) == 0 {
+		return 1, 0
+	}
+
+	var up time.Duration
+	for i, tr := range h.transitions {
+		from := tr.at
+		if from.Before(start) {
+			from = start
+		}
+		to := now
+		if i+1 < len(h.transitions) {
+			to = h.transitions[i+1].at
+		}
+		if tr.reachable && to.After(from) {
+			up += to.Sub(from)
+		}
+	}
+
+	observed := now.Sub(start)
+	return float64(up) / float64(observed), observed
+}
+
+// Report returns the availability of all tracked devices, sorted by ise_id
+func (t *AvailabilityTracker) Report(now time.Time) []DeviceAvailability {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	windowStart := now.Add(-t.window)
+	report := make([]DeviceAvailability, 0, len(t.devices))
+	for iseID, h := range t.devices {
+		h.prune(windowStart)
+		availability, observed := h.availability(windowStart, now)
+		report = append(report, DeviceAvailability{
+			IseID:        iseID,
+			Name:         h.name,
+			Reachable:    len(h.transitions) > 0 && h.transitions[len(h.transitions)-1].reachable,
+			Availability: availability,
+			LastSeen:     h.lastSeen,
+			Observed:     observed,
+		})
+	}
+
+	sort.Slice(report, func(i, j int) bool { return report[i].IseID < report[j].IseID })
+	return report
+}
+
+// WritePrometheus writes the availability report in the Prometheus text exposition format
+func (t *AvailabilityTracker) WritePrometheus(w io.Writer, now time.Time) error {
+	report := t.Report(now)
+
+	metrics := []struct {
+		name  string
+		help  string
+		value func(DeviceAvailability) (float64, bool)
+	}{
+		{"homematic_device_availability_ratio", "Fraction of the rolling window the device was reachable.", func(a DeviceAvailability) (float64, bool) {
+			return a.Availability, true
+		}},
+		{"homematic_device_reachable", "Whether the device is currently reachable (1) or not (0).", func(a DeviceAvailability) (float64, bool) {
+			if a.Reachable {
+				return 1, true
+			}
+			return 0, true
+		}},
+		{"homematic_device_last_seen_timestamp_seconds", "Unix time the device was last seen reachable.", func(a DeviceAvailability) (float64, bool) {
+			if a.LastSeen.IsZero() {
+				return 0, false
+			}
+			return float64(a.LastSeen.Unix()), true
+		}},
+	}
+
+	for _, m := range metrics {
+		if err := writeMetricHeader(w, m.name, m.help, "gauge"); err != nil {
+			return err
+		}
+		for _, a := range report {
+			value, ok := m.value(a)
+			if !ok {
+				continue
+			}
+			labels := []metricLabel{{"ise_id", a.IseID}, {"name", a.Name}}
+			if err := writeMetricSample(w, m.name, labels, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
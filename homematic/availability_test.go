@@ -0,0 +1,85 @@
+package homematic
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAvailabilityTrackerReport(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tracker := NewAvailabilityTracker(time.Hour)
+
+	devices := []Device{{IseID: "1234", Name: "Thermostat"}, {IseID: "2000", Name: "Switch"}}
+	tracker.Observe(devices, start)
+
+	devices[0].Unreach = true
+	tracker.Observe(devices, start.Add(30*time.Minute))
+
+	devices[0].Unreach = false
+	tracker.Observe(devices, start.Add(45*time.Minute))
+
+	report := tracker.Report(start.Add(time.Hour))
+	if len(report) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(report))
+	}
+
+	thermostat := report[0]
+	if thermostat.IseID != "1234" || thermostat.Name != "Thermostat" {
+		t.Fatalf("unexpected first report entry: %+v", thermostat)
+	}
+	if math.Abs(thermostat.Availability-0.75) > 1e-9 {
+		t.Errorf("expected availability 0.75, got %f", thermostat.Availability)
+	}
+	if !thermostat.Reachable {
+		t.Error("expected thermostat to be reachable again")
+	}
+	if !thermostat.LastSeen.Equal(start.Add(45 * time.Minute)) {
+		t.Errorf("unexpected last seen: %v", thermostat.LastSeen)
+	}
+
+	if report[1].Availability != 1 {
+		t.Errorf("expected switch availability 1, got %f", report[1].Availability)
+	}
+}
+
+func TestAvailabilityTrackerRollingWindow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tracker := NewAvailabilityTracker(time.Hour)
+
+	tracker.ObserveReachability("1234", "Thermostat", false, start)
+	tracker.ObserveReachability("1234", "", true, start.Add(time.Hour))
+
+	// the unreachable hour has left the window completely
+	report := tracker.Report(start.Add(2 * time.Hour))
+	if report[0].Availability != 1 {
+		t.Errorf("expected availability 1, got %f", report[0].Availability)
+	}
+	if report[0].Name != "Thermostat" {
+		t.Errorf("expected name to be kept, got %q", report[0].Name)
+	}
+}
+
+func TestAvailabilityTrackerWritePrometheus(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tracker := NewAvailabilityTracker(time.Hour)
+	tracker.ObserveReachability("1234", `Living "Room"`, true, start)
+
+	var buf bytes.Buffer
+	if err := tracker.WritePrometheus(&buf, start.Add(time.Minute)); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"# TYPE homematic_device_availability_ratio gauge",
+		`homematic_device_availability_ratio{ise_id="1234",name="Living \"Room\""} 1`,
+		`homematic_device_reachable{ise_id="1234",name="Living \"Room\""} 1`,
+		`homematic_device_last_seen_timestamp_seconds{ise_id="1234",name="Living \"Room\""} 1700000000`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
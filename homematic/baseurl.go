@@ -0,0 +1,64 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// NormalizeBaseURL turns the address of a CCU into a base URL for requests.
+// It accepts bare host names and IP addresses (https is assumed), IPv6
+// literals with or without brackets and zone, and URLs with trailing slashes
+// or the addon path, e.g. "192.168.1.100", "fe80::1%eth0",
+// "http://ccu.local:8080/" or "https://ccu/addons/xmlapi/statelist.cgi".
+// Path prefixes of reverse proxies are kept.
+func NormalizeBaseURL(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", errors.New("empty base URL")
+	}
+
+	if !strings.Contains(s, "://") {
+		host, rest := s, ""
+		if i := strings.Index(s, "/"); i >= 0 {
+			host, rest = s[:i], s[i:]
+		}
+		// bare IPv6 literals, e.g. fe80::1%eth0, need brackets and an escaped zone
+		if ip, zone, _ := strings.Cut(host, "%"); strings.Count(ip, ":") > 1 && net.ParseIP(ip) != nil {
+			host = "[" + ip
+			if zone != "" {
+				host += "%25" + zone
+			}
+			host += "]"
+		}
+		s = "https://" + host + rest
+	}
+	// zones of bracketed IPv6 literals must be escaped as %25
+	if open, end := strings.Index(s, "["), strings.Index(s, "]"); open >= 0 && end > open {
+		if literal := s[open:end]; strings.Contains(literal, "%") && !strings.Contains(literal, "%25") {
+			s = s[:open] + strings.Replace(literal, "%", "%25", 1) + s[end:]
+		}
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %q: %w", raw, err)
+	}
+	switch {
+	case u.Scheme != "http" && u.Scheme != "https":
+		return "", fmt.Errorf("invalid base URL %q: unsupported scheme %q, expected http or https", raw, u.Scheme)
+	case u.Hostname() == "":
+		return "", fmt.Errorf("invalid base URL %q: no host", raw)
+	case u.RawQuery != "" || u.Fragment != "":
+		return "", fmt.Errorf("invalid base URL %q: query and fragment are not supported", raw)
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	if i := strings.Index(path, "/addons/xmlapi"); i >= 0 {
+		path = path[:i]
+	}
+	u.Path, u.RawPath = path, ""
+	return u.String(), nil
+}
@@ -0,0 +1,68 @@
+package homematic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"192.168.1.100":                           "https://192.168.1.100",
+		"  ccu.local  ":                           "https://ccu.local",
+		"ccu.local:8080/":                         "https://ccu.local:8080",
+		"http://ccu.local/":                       "http://ccu.local",
+		"HTTP://ccu.local":                        "http://ccu.local",
+		"https://proxy.example/ccu//":             "https://proxy.example/ccu",
+		"https://ccu/addons/xmlapi/":              "https://ccu",
+		"https://ccu/addons/xmlapi/statelist.cgi": "https://ccu",
+		"fe80::1":                                 "https://[fe80::1]",
+		"fe80::1%eth0":                            "https://[fe80::1%25eth0]",
+		"[2001:db8::10]:8443":                     "https://[2001:db8::10]:8443",
+		"http://[fe80::1%eth0]:80/":               "http://[fe80::1%25eth0]:80",
+	}
+	for in, want := range tests {
+		got, err := NormalizeBaseURL(in)
+		if err != nil {
+			t.Errorf("NormalizeBaseURL(%q) failed: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("NormalizeBaseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", "ftp://ccu", "https://", "https://ccu/?sid=1"} {
+		if _, err := NormalizeBaseURL(in); err == nil {
+			t.Errorf("expected an error for %q", in)
+		}
+	}
+}
+
+func TestRequestURLNormalized(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "token")
+	if client.BaseURL != server.URL {
+		t.Errorf("expected NewClient to normalize the base URL, got %q", client.BaseURL)
+	}
+	// the field may be set directly as well
+	client.BaseURL = server.URL + "/addons/xmlapi/"
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if path != "/addons/xmlapi/version.cgi" {
+		t.Errorf("unexpected request path %q", path)
+	}
+
+	client.BaseURL = "ftp://ccu"
+	if _, err := client.GetVersion(); err == nil || !strings.Contains(err.Error(), "unsupported scheme") {
+		t.Errorf("expected an invalid base URL error, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package homematic
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxPooledBufferSize is the capacity above which buffers are dropped instead
+// of pooled, so a single huge response does not stay in memory
+const maxPooledBufferSize = 32 << 20
+
+// bufferPool holds the buffers response bodies are read and converted into
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns a buffer to the pool
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// xmlDeclaration returns the XML declaration at the start of data, if any
+func xmlDeclaration(data []byte) []byte {
+	if !bytes.HasPrefix(data, []byte("<?xml")) {
+		return nil
+	}
+	end := bytes.Index(data[:min(len(data), 200)], []byte("?>"))
+	if end < 0 {
+		return nil
+	}
+	return data[:end+2]
+}
+
+// declaresLatin1 reports whether the XML declaration names ISO-8859-1 as encoding
+func declaresLatin1(data []byte) bool {
+	decl := strings.ToLower(string(xmlDeclaration(data)))
+	return strings.Contains(decl, "iso-8859-1") || strings.Contains(decl, "latin1")
+}
+
+// writeLatin1AsUTF8 writes ISO-8859-1 encoded XML to buf converted to UTF-8,
+// declaring UTF-8 as encoding in the XML declaration
+func writeLatin1AsUTF8(buf *bytes.Buffer, data []byte) {
+	buf.Grow(len(data) + len(data)/16)
+
+	decl := xmlDeclaration(data)
+	if decl != nil {
+		buf.WriteString(`<?xml version="1.0" encoding="UTF-8" ?>`)
+		data = data[len(decl):]
+	}
+
+	for len(data) > 0 {
+		// copy runs of ASCII at once; every other byte maps to the rune of the same value
+		i := 0
+		for i < len(data) && data[i] < utf8.RuneSelf {
+			i++
+		}
+		buf.Write(data[:i])
+		if i == len(data) {
+			break
+		}
+		buf.WriteRune(rune(data[i]))
+		data = data[i+1:]
+	}
+}
@@ -0,0 +1,62 @@
+package homematic
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestConvertToUTF8(t *testing.T) {
+	latin1 := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\" ?>\n<room name=\"K\xfcche ISO-8859-1\"/>")
+
+	converted, err := convertToUTF8(latin1)
+	if err != nil {
+		t.Fatalf("convertToUTF8 failed: %v", err)
+	}
+	expected := "<?xml version=\"1.0\" encoding=\"UTF-8\" ?>\n<room name=\"Küche ISO-8859-1\"/>"
+	if string(converted) != expected {
+		t.Errorf("unexpected conversion:\n%s", converted)
+	}
+
+	utf8Body := []byte(expected)
+	if converted, _ := convertToUTF8(utf8Body); !bytes.Equal(converted, utf8Body) {
+		t.Errorf("UTF-8 input should be unchanged, got %s", converted)
+	}
+
+	undeclared := []byte("<room name=\"K\xfcche\"/>")
+	if converted, _ := convertToUTF8(undeclared); !bytes.Equal(converted, undeclared) {
+		t.Errorf("input without declaration should be unchanged, got %s", converted)
+	}
+}
+
+func TestPooledBuffersAreNotShared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("ise_id")
+		fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"ISO-8859-1\" ?><systemVariables><systemVariable name=\"Z\xe4hler %s\" ise_id=\"%s\"/></systemVariables>", id, id)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				sysVar, err := client.GetSystemVariable(id, false)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if sysVar.IseID != id || sysVar.Name != "Zähler "+id {
+					t.Errorf("expected system variable %s, got %+v", id, sysVar)
+					return
+				}
+			}
+		}(fmt.Sprint(1000 + i))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,159 @@
+package homematic
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMultiPressWindow is the time between two short presses of a
+// ButtonDetector to count as a multi-press if MultiPressWindow is not set.
+// XML-API timestamps have a resolution of one second.
+const DefaultMultiPressWindow = 2 * time.Second
+
+// Key press data point types besides PRESS_SHORT and PRESS_LONG
+const (
+	DataPointPressCont        = "PRESS_CONT"
+	DataPointPressLongStart   = "PRESS_LONG_START"
+	DataPointPressLongRelease = "PRESS_LONG_RELEASE"
+)
+
+// ClickType is the kind of a ButtonEvent
+type ClickType string
+
+const (
+	// ClickShort is a sequence of short presses, see ButtonEvent.Presses
+	ClickShort ClickType = "short"
+	// ClickLong starts a long press, ClickHold repeats while the key is held
+	// and ClickRelease ends it
+	ClickLong    ClickType = "long"
+	ClickHold    ClickType = "hold"
+	ClickRelease ClickType = "release"
+)
+
+// ButtonEvent is a press of a key of a remote, wall switch or doorbell
+type ButtonEvent struct {
+	DeviceIseID  string    `json:"device_ise_id"`
+	DeviceName   string    `json:"device_name"`
+	ChannelIseID string    `json:"channel_ise_id"`
+	ChannelName  string    `json:"channel_name"`
+	Click        ClickType `json:"click"`
+	// Presses is the number of short presses, e.g. 2 for a double click
+	Presses int       `json:"presses,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// ButtonDetector turns the changes of PRESS_* data points into button
+// events: short presses of a channel within MultiPressWindow of each other
+// are combined into one multi-press event, which is emitted once the window
+// passed, and long presses become long, hold and release events.
+type ButtonDetector struct {
+	MultiPressWindow time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*ButtonEvent
+	held    map[string]time.Time
+}
+
+// Observe processes a data point change and returns the resulting events,
+// including multi-presses whose window passed by the time of the change.
+// Changes of other data points and first observations are ignored.
+func (d *ButtonDetector) Observe(change DataPointChange) []ButtonEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch change.Type {
+	case DataPointPressShort, DataPointPressLong, DataPointPressCont, DataPointPressLongStart, DataPointPressLongRelease:
+	default:
+		return nil
+	}
+	if change.FirstObserved {
+		return nil
+	}
+
+	at := change.ObservedAt
+	if change.Timestamp > 0 {
+		at = time.Unix(change.Timestamp, 0)
+	}
+	events := d.flush(at)
+	if d.pending == nil {
+		d.pending = make(map[string]*ButtonEvent)
+		d.held = make(map[string]time.Time)
+	}
+
+	ch := change.ChannelIseID
+	event := ButtonEvent{
+		DeviceIseID:  change.DeviceIseID,
+		DeviceName:   change.DeviceName,
+		ChannelIseID: ch,
+		ChannelName:  change.ChannelName,
+		At:           at,
+	}
+	switch change.Type {
+	case DataPointPressShort:
+		if pending := d.pending[ch]; pending != nil {
+			pending.Presses++
+			pending.At = at
+			return events
+		}
+		event.Click, event.Presses = ClickShort, 1
+		d.pending[ch] = &event
+	case DataPointPressLongRelease:
+		if _, ok := d.held[ch]; ok {
+			delete(d.held, ch)
+			event.Click = ClickRelease
+			events = append(events, event)
+		}
+	default:
+		// a long press ends a sequence of short presses
+		if pending := d.pending[ch]; pending != nil {
+			events = append(events, *pending)
+			delete(d.pending, ch)
+		}
+		_, held := d.held[ch]
+		d.held[ch] = at
+		switch {
+		case !held:
+			event.Click = ClickLong
+			events = append(events, event)
+		case change.Type != DataPointPressLongStart:
+			event.Click = ClickHold
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Flush returns the multi-presses whose window passed by now; it should be
+// called periodically, e.g. after every poll
+func (d *ButtonDetector) Flush(now time.Time) []ButtonEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.flush(now)
+}
+
+func (d *ButtonDetector) flush(now time.Time) []ButtonEvent {
+	window := durationOrDefault(d.MultiPressWindow, DefaultMultiPressWindow)
+	var events []ButtonEvent
+	for ch, pending := range d.pending {
+		if now.Sub(pending.At) > window {
+			events = append(events, *pending)
+			delete(d.pending, ch)
+		}
+	}
+	// keys without a release event stop being held when no long press repeats
+	for ch, last := range d.held {
+		if now.Sub(last) > window {
+			delete(d.held, ch)
+		}
+	}
+	slices.SortFunc(events, func(a, b ButtonEvent) int {
+		if c := a.At.Compare(b.At); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ChannelIseID, b.ChannelIseID)
+	})
+	return events
+}
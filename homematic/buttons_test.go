@@ -0,0 +1,55 @@
+package homematic
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestButtonDetector(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	press := func(channel, dpType string, offset int) DataPointChange {
+		return DataPointChange{ChannelIseID: channel, Type: dpType, NewValue: "true", Timestamp: start.Unix() + int64(offset)}
+	}
+	format := func(events []ButtonEvent) string {
+		var parts []string
+		for _, e := range events {
+			parts = append(parts, fmt.Sprintf("%s:%s%d@%d", e.ChannelIseID, e.Click, e.Presses, e.At.Unix()-start.Unix()))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	d := &ButtonDetector{}
+	var events []ButtonEvent
+	for _, change := range []DataPointChange{
+		{ChannelIseID: "1", Type: DataPointPressShort, FirstObserved: true},
+		press("1", DataPointPressShort, 0),
+		press("1", DataPointPressShort, 1),
+		press("2", DataPointPressShort, 1),
+		{ChannelIseID: "1", Type: "LEVEL", Timestamp: start.Unix() + 2},
+		// the first double click ends before the next press
+		press("1", DataPointPressShort, 10),
+		press("1", DataPointPressLongStart, 11),
+		press("1", DataPointPressLong, 11),
+		press("1", DataPointPressLong, 12),
+		press("1", DataPointPressLongRelease, 13),
+		press("1", DataPointPressLongRelease, 14),
+	} {
+		events = append(events, d.Observe(change)...)
+	}
+	events = append(events, d.Flush(start.Add(time.Minute))...)
+
+	want := "1:short2@1 2:short1@1 1:short1@10 1:long0@11 1:hold0@11 1:hold0@12 1:release0@13"
+	if got := format(events); got != want {
+		t.Errorf("unexpected events:\n got %s\nwant %s", got, want)
+	}
+
+	// a long press without release stops being held once it no longer repeats
+	d = &ButtonDetector{MultiPressWindow: time.Second}
+	events = d.Observe(press("3", DataPointPressLong, 0))
+	events = append(events, d.Observe(press("3", DataPointPressLong, 5))...)
+	if got := format(events); got != "3:long0@0 3:long0@5" {
+		t.Errorf("unexpected events: %s", got)
+	}
+}
@@ -0,0 +1,205 @@
+package homematic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChangeLogSchemaVersion is the schema version written with every change log record
+const ChangeLogSchemaVersion = 1
+
+// ChangeLogRecord is a single line of the change log
+type ChangeLogRecord struct {
+	SchemaVersion int `json:"schema_version"`
+	DataPointChange
+}
+
+// ChangeLogOptions configures file rotation of a change log
+type ChangeLogOptions struct {
+	// Prefix is the file name prefix, "changes" by default
+	Prefix string
+	// MaxSize rotates the active file once it reaches this many bytes; 0 disables rotation
+	MaxSize int64
+	// MaxFiles deletes the oldest rotated files beyond this count; 0 keeps all files
+	MaxFiles int
+	// Key optionally encrypts every record; encrypted records are written as base64 lines
+	Key *EncryptionKey
+}
+
+// ChangeLog is an append-only sink writing data point changes as JSON lines
+// to rotating files. The active file is <prefix>.jsonl, rotated files are
+// named <prefix>-<UTC timestamp>.jsonl and sort chronologically.
+type ChangeLog struct {
+	dir  string
+	opts ChangeLogOptions
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenChangeLog opens (or creates) a change log in the given directory
+func OpenChangeLog(dir string, opts ChangeLogOptions) (*ChangeLog, error) {
+	if opts.Prefix == "" {
+		opts.Prefix = "changes"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create change log directory: %w", err)
+	}
+
+	l := &ChangeLog{dir: dir, opts: opts}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Append writes the given changes to the log
+func (l *ChangeLog) Append(changes ...DataPointChange) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("change log is closed")
+	}
+
+	for _, change := range changes {
+		line, err := json.Marshal(ChangeLogRecord{SchemaVersion: ChangeLogSchemaVersion, DataPointChange: change})
+		if err != nil {
+			return fmt.Errorf("failed to encode change: %w", err)
+		}
+		if l.opts.Key != nil {
+			sealed, err := l.opts.Key.Seal(line)
+			if err != nil {
+				return err
+			}
+			line = []byte(base64.StdEncoding.EncodeToString(sealed))
+		}
+		line = append(line, '\n')
+
+		if l.opts.MaxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.opts.MaxSize {
+			if err := l.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := l.file.Write(line)
+		l.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("failed to write change log: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Sync flushes the active file to disk
+func (l *ChangeLog) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Sync()
+}
+
+// Close closes the active file
+func (l *ChangeLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Files returns all change log files in chronological order, the active file last
+func (l *ChangeLog) Files() ([]string, error) {
+	rotated, err := l.rotatedFiles()
+	if err != nil {
+		return nil, err
+	}
+	return append(rotated, l.activePath()), nil
+}
+
+// activePath returns the path of the file currently written to
+func (l *ChangeLog) activePath() string {
+	return filepath.Join(l.dir, l.opts.Prefix+".jsonl")
+}
+
+// open opens the active file for appending
+func (l *ChangeLog) open() error {
+	f, err := os.OpenFile(l.activePath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open change log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat change log: %w", err)
+	}
+
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// rotate renames the active file and opens a new one
+func (l *ChangeLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close change log: %w", err)
+	}
+	l.file = nil
+
+	rotated := filepath.Join(l.dir, fmt.Sprintf("%s-%s.jsonl", l.opts.Prefix, time.Now().UTC().Format("20060102T150405.000000000")))
+	if err := os.Rename(l.activePath(), rotated); err != nil {
+		return fmt.Errorf("failed to rotate change log: %w", err)
+	}
+	if err := l.open(); err != nil {
+		return err
+	}
+
+	if l.opts.MaxFiles > 0 {
+		files, err := l.rotatedFiles()
+		if err != nil {
+			return err
+		}
+		for len(files) > l.opts.MaxFiles {
+			if err := os.Remove(files[0]); err != nil {
+				return fmt.Errorf("failed to remove old change log: %w", err)
+			}
+			files = files[1:]
+		}
+	}
+
+	return nil
+}
+
+// rotatedFiles returns the rotated files sorted from oldest to newest
+func (l *ChangeLog) rotatedFiles() ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list change logs: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, l.opts.Prefix+"-") && strings.HasSuffix(name, ".jsonl") {
+			files = append(files, filepath.Join(l.dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
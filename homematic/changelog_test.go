@@ -0,0 +1,93 @@
+package homematic
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestChangeLogAppend(t *testing.T) {
+	dir := t.TempDir()
+	log, err := OpenChangeLog(dir, ChangeLogOptions{})
+	if err != nil {
+		t.Fatalf("OpenChangeLog failed: %v", err)
+	}
+
+	change := DataPointChange{IseID: "1251", Type: "ACTUAL_TEMPERATURE", OldValue: "21.5", NewValue: "21.7", ObservedAt: time.Unix(1700000000, 0).UTC()}
+	if err := log.Append(change, change); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// reopening appends to the existing file
+	log, err = OpenChangeLog(dir, ChangeLogOptions{})
+	if err != nil {
+		t.Fatalf("OpenChangeLog failed: %v", err)
+	}
+	if err := log.Append(change); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	log.Close()
+
+	if err := log.Append(change); err == nil {
+		t.Error("expected append to closed log to fail")
+	}
+
+	f, err := os.Open(log.activePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+		var record ChangeLogRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", scanner.Text(), err)
+		}
+		if record.SchemaVersion != ChangeLogSchemaVersion || record.NewValue != "21.7" || !record.ObservedAt.Equal(change.ObservedAt) {
+			t.Errorf("unexpected record: %+v", record)
+		}
+	}
+	if lines != 3 {
+		t.Errorf("expected 3 records, got %d", lines)
+	}
+}
+
+func TestChangeLogRotation(t *testing.T) {
+	dir := t.TempDir()
+	log, err := OpenChangeLog(dir, ChangeLogOptions{Prefix: "events", MaxSize: 300, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("OpenChangeLog failed: %v", err)
+	}
+	defer log.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := log.Append(DataPointChange{IseID: "1251", NewValue: "21.7"}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	files, err := log.Files()
+	if err != nil {
+		t.Fatalf("Files failed: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 2 rotated files and the active file, got %v", files)
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 300 {
+			t.Errorf("file %s exceeds the maximum size: %d bytes", file, info.Size())
+		}
+	}
+}
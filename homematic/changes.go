@@ -0,0 +1,65 @@
+package homematic
+
+import (
+	"time"
+)
+
+// DataPointChange describes an observed change of a data point value
+type DataPointChange struct {
+	DeviceIseID   string    `json:"device_ise_id"`
+	DeviceName    string    `json:"device_name"`
+	ChannelIseID  string    `json:"channel_ise_id"`
+	ChannelName   string    `json:"channel_name"`
+	IseID         string    `json:"ise_id"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	ValueType     int       `json:"valuetype"`
+	OldValue      string    `json:"old_value"`
+	NewValue      string    `json:"new_value"`
+	Timestamp     int64     `json:"timestamp"`
+	ObservedAt    time.Time `json:"observed_at"`
+	FirstObserved bool      `json:"first_observed,omitempty"`
+}
+
+// DiffStates compares two state list snapshots and returns the changes of all
+// data points whose value or timestamp differ. Data points missing in the old
+// snapshot are reported with FirstObserved set.
+func DiffStates(old, current []Device, observedAt time.Time) []DataPointChange {
+	previous := make(map[string]DataPoint)
+	for _, device := range old {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				previous[dp.IseID] = dp
+			}
+		}
+	}
+
+	var changes []DataPointChange
+	for _, device := range current {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				before, seen := previous[dp.IseID]
+				if seen && before.Value == dp.Value && before.Timestamp == dp.Timestamp {
+					continue
+				}
+				changes = append(changes, DataPointChange{
+					DeviceIseID:   device.IseID,
+					DeviceName:    device.Name,
+					ChannelIseID:  ch.IseID,
+					ChannelName:   ch.Name,
+					IseID:         dp.IseID,
+					Name:          dp.Name,
+					Type:          dataPointType(dp),
+					ValueType:     dp.ValueType,
+					OldValue:      before.Value,
+					NewValue:      dp.Value,
+					Timestamp:     dp.Timestamp,
+					ObservedAt:    observedAt,
+					FirstObserved: !seen,
+				})
+			}
+		}
+	}
+
+	return changes
+}
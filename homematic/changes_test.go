@@ -0,0 +1,31 @@
+package homematic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffStates(t *testing.T) {
+	old := []Device{{IseID: "1234", Channels: []Channel{{IseID: "1250", DataPoints: []DataPoint{
+		{IseID: "1251", Type: "ACTUAL_TEMPERATURE", Value: "21.5", Timestamp: 100},
+		{IseID: "1252", Type: "SET_POINT_TEMPERATURE", Value: "21.0", Timestamp: 100},
+	}}}}}
+	current := []Device{{IseID: "1234", Name: "Thermostat", Channels: []Channel{{IseID: "1250", DataPoints: []DataPoint{
+		{IseID: "1251", Type: "ACTUAL_TEMPERATURE", Value: "21.7", Timestamp: 200},
+		{IseID: "1252", Type: "SET_POINT_TEMPERATURE", Value: "21.0", Timestamp: 100},
+		{IseID: "1253", Type: "LEVEL", Value: "0.2", Timestamp: 200},
+	}}}}}
+
+	observedAt := time.Unix(300, 0)
+	changes := DiffStates(old, current, observedAt)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+
+	if c := changes[0]; c.IseID != "1251" || c.OldValue != "21.5" || c.NewValue != "21.7" || c.FirstObserved || c.DeviceName != "Thermostat" {
+		t.Errorf("unexpected change: %+v", c)
+	}
+	if c := changes[1]; c.IseID != "1253" || !c.FirstObserved || !c.ObservedAt.Equal(observedAt) {
+		t.Errorf("unexpected change for new data point: %+v", c)
+	}
+}
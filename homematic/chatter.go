@@ -0,0 +1,103 @@
+package homematic
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChatterFilter suppresses rapid oscillation of binary data points, e.g.
+// flapping contacts or bouncing motion sensors. A new value is only passed on
+// once it has been held for MinHold; changes back to the stable value before
+// that are dropped together with the pending transition. Other data points
+// pass unchanged.
+type ChatterFilter struct {
+	// MinHold is the time a new value must be held to be passed on
+	MinHold time.Duration
+
+	// Match optionally selects the data points to filter; binary data points by default
+	Match func(change DataPointChange) bool
+
+	mu      sync.Mutex
+	stable  map[string]string
+	pending map[string]DataPointChange
+}
+
+// NewChatterFilter creates a filter for binary data points with the given minimum hold time
+func NewChatterFilter(minHold time.Duration) *ChatterFilter {
+	return &ChatterFilter{MinHold: minHold}
+}
+
+// Observe processes a change and returns the changes to pass on: the change
+// itself if it is not filtered, and all pending transitions that have been
+// held for MinHold at the time the change was observed
+func (f *ChatterFilter) Observe(change DataPointChange) []DataPointChange {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.stable == nil {
+		f.stable = make(map[string]string)
+		f.pending = make(map[string]DataPointChange)
+	}
+
+	out := f.flush(change.ObservedAt)
+	if !f.matches(change) {
+		return append(out, change)
+	}
+
+	stable, known := f.stable[change.IseID]
+	_, pending := f.pending[change.IseID]
+	switch {
+	case !known || change.FirstObserved:
+		f.stable[change.IseID] = change.NewValue
+		delete(f.pending, change.IseID)
+		out = append(out, change)
+	case change.NewValue == stable && pending:
+		// the value returned before it was held long enough
+		delete(f.pending, change.IseID)
+	case change.NewValue == stable:
+		out = append(out, change)
+	case pending && f.pending[change.IseID].NewValue == change.NewValue:
+		// the pending value was reported again, keep the time it was first seen
+	default:
+		change.OldValue = stable
+		f.pending[change.IseID] = change
+	}
+	return out
+}
+
+// Flush returns the pending transitions that have been held for MinHold at
+// now; it should be called periodically when no further changes arrive
+func (f *ChatterFilter) Flush(now time.Time) []DataPointChange {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.flush(now)
+}
+
+func (f *ChatterFilter) flush(now time.Time) []DataPointChange {
+	var out []DataPointChange
+	for iseID, change := range f.pending {
+		if now.Sub(change.ObservedAt) < f.MinHold {
+			continue
+		}
+		out = append(out, change)
+		f.stable[iseID] = change.NewValue
+		delete(f.pending, iseID)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].ObservedAt.Equal(out[j].ObservedAt) {
+			return out[i].ObservedAt.Before(out[j].ObservedAt)
+		}
+		return out[i].IseID < out[j].IseID
+	})
+	return out
+}
+
+func (f *ChatterFilter) matches(change DataPointChange) bool {
+	if f.Match != nil {
+		return f.Match(change)
+	}
+	return change.ValueType == regaValueTypeBinary
+}
@@ -0,0 +1,72 @@
+package homematic
+
+import (
+	"testing"
+	"time"
+)
+
+func contactChange(value string, at time.Time) DataPointChange {
+	return DataPointChange{IseID: "1001", Type: "STATE", ValueType: 2, NewValue: value, ObservedAt: at}
+}
+
+func TestChatterFilter(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	filter := NewChatterFilter(10 * time.Second)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+
+	first := contactChange("false", at(0))
+	first.FirstObserved = true
+	if out := filter.Observe(first); len(out) != 1 {
+		t.Fatalf("expected first observation to pass, got %+v", out)
+	}
+
+	// the contact flaps and settles on its old value
+	for i, value := range []string{"true", "false", "true", "false"} {
+		if out := filter.Observe(contactChange(value, at(i+1))); len(out) != 0 {
+			t.Errorf("expected flapping change %d to be filtered, got %+v", i, out)
+		}
+	}
+	if out := filter.Flush(at(30)); len(out) != 0 {
+		t.Errorf("expected no transition after settling on the old value, got %+v", out)
+	}
+
+	// the contact opens and stays open
+	if out := filter.Observe(contactChange("true", at(40))); len(out) != 0 {
+		t.Errorf("expected new value to be held back, got %+v", out)
+	}
+	if out := filter.Flush(at(45)); len(out) != 0 {
+		t.Errorf("expected transition to be pending before MinHold, got %+v", out)
+	}
+	out := filter.Flush(at(50))
+	if len(out) != 1 || out[0].OldValue != "false" || out[0].NewValue != "true" || !out[0].ObservedAt.Equal(at(40)) {
+		t.Fatalf("expected stabilized transition, got %+v", out)
+	}
+	if out := filter.Flush(at(60)); len(out) != 0 {
+		t.Errorf("expected transition to be emitted once, got %+v", out)
+	}
+}
+
+func TestChatterFilterObserveEmitsMatured(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	filter := NewChatterFilter(5 * time.Second)
+
+	filter.Observe(contactChange("false", start))
+	filter.Observe(contactChange("true", start.Add(time.Second)))
+
+	temperature := DataPointChange{IseID: "2001", ValueType: 4, OldValue: "21.0", NewValue: "21.5", ObservedAt: start.Add(10 * time.Second)}
+	out := filter.Observe(temperature)
+	if len(out) != 2 || out[0].IseID != "1001" || out[1].IseID != "2001" {
+		t.Errorf("expected matured contact transition followed by the unfiltered change, got %+v", out)
+	}
+}
+
+func TestChatterFilterMatch(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	filter := NewChatterFilter(time.Minute)
+	filter.Match = func(change DataPointChange) bool { return change.Type == "MOTION" }
+
+	filter.Observe(contactChange("false", start))
+	if out := filter.Observe(contactChange("true", start.Add(time.Second))); len(out) != 1 {
+		t.Errorf("expected data point not matched to pass, got %+v", out)
+	}
+}
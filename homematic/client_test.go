@@ -0,0 +1,178 @@
+package homematic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// staticHandler serves the same response body for every request
+func staticHandler(body []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	})
+}
+
+// writeChanged answers a statechange.cgi request with a changed entry per ise_id
+func writeChanged(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.URL.Query().Get("ise_id"), ",")
+	values := strings.Split(r.URL.Query().Get("new_value"), ",")
+	fmt.Fprint(w, "<result>")
+	for i, id := range ids {
+		if i < len(values) {
+			fmt.Fprintf(w, `<changed id="%s" new_value="%s"/>`, id, values[i])
+		}
+	}
+	fmt.Fprint(w, "</result>")
+}
+
+func TestClientDryRun(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`<programList/>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	client.DryRun = true
+
+	active := true
+	calls := map[string]func() error{
+		"ChangeState":          func() error { return client.ChangeState([]string{"1234"}, []string{"1"}) },
+		"RunProgram":           func() error { return client.RunProgram("1001", false) },
+		"ChangeProgramActions": func() error { return client.ChangeProgramActions("1001", &active, nil) },
+		"RegisterToken":        func() error { return client.RegisterToken("test") },
+		"RevokeToken":          func() error { return client.RevokeToken("token") },
+		"ChangeMasterValue": func() error {
+			return client.ChangeMasterValue([]string{"1234"}, []string{"TEMPERATURE_OFFSET"}, []string{"1.0"})
+		},
+	}
+	for name, call := range calls {
+		if err := call(); err != nil {
+			t.Errorf("%s: unexpected error in dry run: %v", name, err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests in dry run, got %d", requests)
+	}
+
+	if _, err := client.GetProgramList(); err != nil {
+		t.Errorf("expected read requests to be sent in dry run: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected read request to reach the server, got %d requests", requests)
+	}
+}
+
+func TestClientBasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("sid") != "token" {
+			t.Errorf("expected sid token alongside basic auth, got %q", r.URL.Query().Get("sid"))
+		}
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	if _, err := client.GetVersion(); err == nil {
+		t.Error("expected request without credentials to fail")
+	}
+
+	client.Username = "admin"
+	client.Password = "secret"
+	version, err := client.GetVersion()
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if version != "1.22" {
+		t.Errorf("unexpected version: %s", version)
+	}
+}
+
+func TestClientWithParams(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(`<systemVariables/>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	extended := client.WithParams(map[string]string{"show_internal": "1", "text": "ignored"})
+
+	if _, err := extended.GetSystemVariableList(false); err != nil {
+		t.Fatalf("GetSystemVariableList failed: %v", err)
+	}
+	if _, err := client.GetSystemVariableList(false); err != nil {
+		t.Fatalf("GetSystemVariableList failed: %v", err)
+	}
+
+	if queries[0] != "show_internal=1&sid=token&text=false" {
+		t.Errorf("unexpected query with extra params: %s", queries[0])
+	}
+	if queries[1] != "sid=token&text=false" {
+		t.Errorf("expected original client to be unchanged, got query %s", queries[1])
+	}
+}
+
+func TestChangeStates(t *testing.T) {
+	server := httptest.NewServer(staticHandler([]byte(
+		`<result><changed id="1251" new_value="1"/><not_found/><changed id="1253" new_value="21.5"/></result>`)))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	results, err := client.ChangeStates([]string{"1251", "9999", "1253"}, []string{"true", "0", "21.5"})
+	if err != nil {
+		t.Fatalf("ChangeStates failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if r := results[0]; r.IseID != "1251" || r.RequestedValue != "true" || r.AppliedValue != "1" || r.Err != nil {
+		t.Errorf("unexpected first result: %+v", r)
+	}
+	if r := results[1]; r.IseID != "9999" || r.Err == nil {
+		t.Errorf("expected the second change to fail: %+v", r)
+	}
+	if r := results[2]; r.AppliedValue != "21.5" || r.Err != nil {
+		t.Errorf("unexpected third result: %+v", r)
+	}
+
+	if err := client.ChangeState([]string{"1251", "9999", "1253"}, []string{"true", "0", "21.5"}); err == nil {
+		t.Error("expected ChangeState to report the failed data point")
+	}
+
+	client.DryRun = true
+	results, err = client.ChangeStates([]string{"1251"}, []string{"1"})
+	if err != nil || len(results) != 1 || results[0].Err != nil || results[0].RequestedValue != "1" {
+		t.Errorf("unexpected dry run results: %+v, %v", results, err)
+	}
+}
+
+func TestChangeStatesMissingResults(t *testing.T) {
+	server := httptest.NewServer(staticHandler([]byte(`<result><changed id="1251" new_value="1"/></result>`)))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	results, err := client.ChangeStates([]string{"1251", "1252"}, []string{"1", "0"})
+	if err != nil {
+		t.Fatalf("ChangeStates failed: %v", err)
+	}
+	if results[0].Err != nil {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+	if r := results[1]; r.Err == nil || !strings.Contains(r.Err.Error(), "no result from CCU") {
+		t.Errorf("expected the unconfirmed change to fail: %+v", r)
+	}
+	if err := client.ChangeState([]string{"1251", "1252"}, []string{"1", "0"}); err == nil {
+		t.Error("expected ChangeState to report the unconfirmed data point")
+	}
+}
@@ -0,0 +1,193 @@
+package homematic
+
+import (
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Climate quantities tracked by ClimateStats
+const (
+	QuantityTemperature = "temperature"
+	QuantityHumidity    = "humidity"
+)
+
+// climateQuantities maps data point types to the quantity they measure
+var climateQuantities = map[string]string{
+	"ACTUAL_TEMPERATURE": QuantityTemperature,
+	"TEMPERATURE":        QuantityTemperature,
+	"HUMIDITY":           QuantityHumidity,
+	"ACTUAL_HUMIDITY":    QuantityHumidity,
+}
+
+// RoomClimate holds the statistics of a quantity of a room over the rolling window
+type RoomClimate struct {
+	Room     string    `json:"room"`
+	Quantity string    `json:"quantity"`
+	Min      float64   `json:"min"`
+	Max      float64   `json:"max"`
+	Avg      float64   `json:"avg"`
+	Last     float64   `json:"last"`
+	Samples  int       `json:"samples"`
+	LastAt   time.Time `json:"last_at"`
+}
+
+// ClimateStats computes rolling minimum, maximum and average temperature and
+// humidity per room, e.g. for heating tuning and mold-risk monitoring. Rooms
+// with several sensors are aggregated over all of their samples.
+type ClimateStats struct {
+	mu     sync.Mutex
+	window time.Duration
+	rooms  map[string][]string
+	series map[climateKey][]climateSample
+}
+
+// climateKey identifies the series of a quantity of a room
+type climateKey struct {
+	room, quantity string
+}
+
+// climateSample is a single reading
+type climateSample struct {
+	at    time.Time
+	value float64
+}
+
+// NewClimateStats creates statistics over the given rolling window for the
+// channels assigned to rooms
+func NewClimateStats(rooms []Room, window time.Duration) *ClimateStats {
+	s := &ClimateStats{window: window, series: make(map[climateKey][]climateSample)}
+	s.SetRooms(rooms)
+	return s
+}
+
+// SetRooms updates the room assignments of channels, e.g. after the room list changed
+func (s *ClimateStats) SetRooms(rooms []Room) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rooms = make(map[string][]string)
+	for _, room := range rooms {
+		for _, ch := range room.Channels {
+			s.rooms[ch.IseID] = append(s.rooms[ch.IseID], room.Name)
+		}
+	}
+}
+
+// Observe records the temperatures and humidities of a state list snapshot;
+// with regular polls the averages are weighted by time
+func (s *ClimateStats) Observe(devices []Device, at time.Time) {
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				s.observe(ch.IseID, dataPointType(dp), dp.Value, at)
+			}
+		}
+	}
+}
+
+// ObserveChange records a data point change, e.g. from a change log
+func (s *ClimateStats) ObserveChange(change DataPointChange) {
+	s.observe(change.ChannelIseID, change.Type, change.NewValue, change.ObservedAt)
+}
+
+func (s *ClimateStats) observe(channelID, dpType, raw string, at time.Time) {
+	quantity, ok := climateQuantities[dpType]
+	if !ok {
+		return
+	}
+	value, ok := numericValue(raw)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, room := range s.rooms[channelID] {
+		key := climateKey{room, quantity}
+		s.series[key] = append(pruneClimate(s.series[key], at.Add(-s.window)), climateSample{at, value})
+	}
+}
+
+// pruneClimate drops the samples before the window start
+func pruneClimate(samples []climateSample, windowStart time.Time) []climateSample {
+	i := sort.Search(len(samples), func(i int) bool { return !samples[i].at.Before(windowStart) })
+	return samples[i:]
+}
+
+// Report returns the statistics of all rooms, sorted by room and quantity
+func (s *ClimateStats) Report(now time.Time) []RoomClimate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := make([]RoomClimate, 0, len(s.series))
+	for key, samples := range s.series {
+		samples = pruneClimate(samples, now.Add(-s.window))
+		s.series[key] = samples
+		if len(samples) == 0 {
+			delete(s.series, key)
+			continue
+		}
+
+		stats := RoomClimate{Room: key.room, Quantity: key.quantity, Min: math.Inf(1), Max: math.Inf(-1), Samples: len(samples)}
+		var sum float64
+		for _, sample := range samples {
+			stats.Min = math.Min(stats.Min, sample.value)
+			stats.Max = math.Max(stats.Max, sample.value)
+			sum += sample.value
+		}
+		last := samples[len(samples)-1]
+		stats.Avg, stats.Last, stats.LastAt = sum/float64(len(samples)), last.value, last.at
+		report = append(report, stats)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Room != report[j].Room {
+			return report[i].Room < report[j].Room
+		}
+		return report[i].Quantity < report[j].Quantity
+	})
+	return report
+}
+
+// WritePrometheus writes the statistics as summaries in the Prometheus text
+// exposition format, with the minimum as quantile 0 and the maximum as quantile 1
+func (s *ClimateStats) WritePrometheus(w io.Writer, now time.Time) error {
+	report := s.Report(now)
+
+	for _, m := range []struct {
+		name, help, quantity string
+	}{
+		{"homematic_room_temperature_celsius", "Temperature of the room over the rolling window.", QuantityTemperature},
+		{"homematic_room_humidity_percent", "Relative humidity of the room over the rolling window.", QuantityHumidity},
+	} {
+		if err := writeMetricHeader(w, m.name, m.help, "summary"); err != nil {
+			return err
+		}
+		for _, stats := range report {
+			if stats.Quantity != m.quantity {
+				continue
+			}
+			room := metricLabel{"room", stats.Room}
+			samples := []struct {
+				name   string
+				labels []metricLabel
+				value  float64
+			}{
+				{m.name, []metricLabel{room, {"quantile", "0"}}, stats.Min},
+				{m.name, []metricLabel{room, {"quantile", "1"}}, stats.Max},
+				{m.name + "_sum", []metricLabel{room}, stats.Avg * float64(stats.Samples)},
+				{m.name + "_count", []metricLabel{room}, float64(stats.Samples)},
+			}
+			for _, sample := range samples {
+				if err := writeMetricSample(w, sample.name, sample.labels, sample.value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package homematic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClimateStats(t *testing.T) {
+	rooms := []Room{
+		{Name: "Bad", Channels: []Channel{{IseID: "11"}, {IseID: "21"}}},
+		{Name: "Keller", Channels: []Channel{{IseID: "31"}}},
+	}
+	snapshot := func(bath, bathHumidity, mirror, cellar string) []Device {
+		return []Device{
+			{IseID: "10", Channels: []Channel{{IseID: "11", DataPoints: []DataPoint{
+				{Type: "ACTUAL_TEMPERATURE", Value: bath}, {Type: "HUMIDITY", Value: bathHumidity}, {Type: "LEVEL", Value: "0.5"},
+			}}}},
+			{IseID: "20", Channels: []Channel{{IseID: "21", DataPoints: []DataPoint{{Type: "TEMPERATURE", Value: mirror}}}}},
+			{IseID: "30", Channels: []Channel{{IseID: "31", DataPoints: []DataPoint{{Type: "ACTUAL_TEMPERATURE", Value: cellar}}}}},
+			{IseID: "40", Channels: []Channel{{IseID: "41", DataPoints: []DataPoint{{Type: "ACTUAL_TEMPERATURE", Value: "30"}}}}},
+		}
+	}
+
+	start := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+	stats := NewClimateStats(rooms, time.Hour)
+	stats.Observe(snapshot("18.0", "80", "20.0", "9.0"), start)
+	stats.Observe(snapshot("22.0", "60", "20.0", "11.0"), start.Add(30*time.Minute))
+	stats.ObserveChange(DataPointChange{ChannelIseID: "31", Type: "ACTUAL_TEMPERATURE", NewValue: "13.0", ObservedAt: start.Add(50 * time.Minute)})
+
+	report := stats.Report(start.Add(50 * time.Minute))
+	if len(report) != 3 {
+		t.Fatalf("expected 3 series, got %+v", report)
+	}
+	if bath := report[0]; bath.Room != "Bad" || bath.Quantity != QuantityHumidity || bath.Min != 60 || bath.Max != 80 || bath.Avg != 70 {
+		t.Errorf("unexpected bath humidity: %+v", bath)
+	}
+	if bath := report[1]; bath.Min != 18 || bath.Max != 22 || bath.Avg != 20 || bath.Samples != 4 {
+		t.Errorf("unexpected bath temperature: %+v", bath)
+	}
+	if cellar := report[2]; cellar.Room != "Keller" || cellar.Last != 13 || cellar.Max != 13 || cellar.Samples != 3 {
+		t.Errorf("unexpected cellar temperature: %+v", cellar)
+	}
+
+	// the first snapshot leaves the window
+	report = stats.Report(start.Add(70 * time.Minute))
+	if cellar := report[2]; cellar.Min != 11 || cellar.Samples != 2 {
+		t.Errorf("expected old samples to be dropped, got %+v", cellar)
+	}
+
+	var buf bytes.Buffer
+	if err := stats.WritePrometheus(&buf, start.Add(70*time.Minute)); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+	for _, want := range []string{
+		"# TYPE homematic_room_temperature_celsius summary",
+		`homematic_room_temperature_celsius{room="Keller",quantile="0"} 11`,
+		`homematic_room_temperature_celsius{room="Keller",quantile="1"} 13`,
+		`homematic_room_temperature_celsius_count{room="Keller"} 2`,
+		`homematic_room_humidity_percent_sum{room="Bad"} 60`,
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("expected %q in metrics:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package homematic
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultClockDriftThreshold is the clock drift reported through OnClockDrift
+// if ClockDriftThreshold is not set
+const DefaultClockDriftThreshold = time.Minute
+
+// clock tracks the drift of the CCU clock measured from the Date header of
+// its responses; it is shared by all copies of a client
+type clock struct {
+	mu       sync.Mutex
+	drift    time.Duration
+	measured bool
+	exceeded bool
+}
+
+// ClockDrift returns the CCU clock minus the local clock as measured by the
+// last response carrying a Date header. The measurement has a resolution of
+// one second; ok is false if no drift was measured yet.
+func (c *Client) ClockDrift() (drift time.Duration, ok bool) {
+	if c.clock == nil {
+		return 0, false
+	}
+	c.clock.mu.Lock()
+	defer c.clock.mu.Unlock()
+
+	return c.clock.drift, c.clock.measured
+}
+
+// observeClock measures the clock drift from the Date header of a response to
+// a request sent at start, and calls OnClockDrift when the drift exceeds the threshold
+func (c *Client) observeClock(start time.Time, date string) {
+	if c.clock == nil || date == "" {
+		return
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+
+	// the Date header is truncated to seconds and taken somewhere between
+	// sending the request and receiving the response
+	now := time.Now()
+	serverTime = serverTime.Add(500 * time.Millisecond)
+	drift := serverTime.Sub(start.Add(now.Sub(start) / 2)).Round(time.Second)
+
+	exceeded := drift.Abs() > c.clockDriftThreshold()
+
+	cl := c.clock
+	cl.mu.Lock()
+	notify := exceeded && !cl.exceeded
+	cl.drift = drift
+	cl.measured = true
+	cl.exceeded = exceeded
+	cl.mu.Unlock()
+
+	if notify && c.OnClockDrift != nil {
+		c.OnClockDrift(drift)
+	}
+}
+
+// clockDriftThreshold returns ClockDriftThreshold or its default
+func (c *Client) clockDriftThreshold() time.Duration {
+	if c.ClockDriftThreshold > 0 {
+		return c.ClockDriftThreshold
+	}
+	return DefaultClockDriftThreshold
+}
+
+// PingResult is the result of Ping
+type PingResult struct {
+	Version string        `json:"version"`
+	Latency time.Duration `json:"latency"`
+
+	// ClockDrift is the CCU clock minus the local clock, see Client.ClockDrift
+	ClockDrift         time.Duration `json:"clock_drift"`
+	ClockDriftMeasured bool          `json:"clock_drift_measured"`
+}
+
+// Ping requests the XML-API version and reports the round trip time and the
+// clock drift of the CCU
+func (c *Client) Ping(ctx context.Context) (*PingResult, error) {
+	var versionResp VersionResponse
+	start := time.Now()
+	err := c.doRequestContext(ctx, "version.cgi", nil, func(body []byte) error {
+		return decodeXML(body, &versionResp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PingResult{
+		Version: strings.TrimSpace(versionResp.Value),
+		Latency: time.Since(start),
+	}
+	result.ClockDrift, result.ClockDriftMeasured = c.ClockDrift()
+	return result, nil
+}
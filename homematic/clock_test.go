@@ -0,0 +1,90 @@
+package homematic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClockDrift(t *testing.T) {
+	var offset atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(time.Duration(offset.Load())).UTC().Format(http.TimeFormat))
+		w.Write([]byte(`<version>2.3</version>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	if _, ok := client.ClockDrift(); ok {
+		t.Error("expected no drift before the first request")
+	}
+
+	var warnings []time.Duration
+	client.OnClockDrift = func(drift time.Duration) {
+		warnings = append(warnings, drift)
+	}
+
+	steps := []time.Duration{0, 5 * time.Minute, 5 * time.Minute, 0, -3 * time.Minute}
+	for i, step := range steps {
+		offset.Store(int64(step))
+		if _, err := client.GetVersion(); err != nil {
+			t.Fatal(err)
+		}
+		drift, ok := client.ClockDrift()
+		if !ok || (drift-step).Abs() > 2*time.Second {
+			t.Errorf("step %d: expected drift of about %s, got %s", i, step, drift)
+		}
+	}
+
+	// the drift is reported once each time it exceeds the threshold
+	if len(warnings) != 2 || warnings[0] < 4*time.Minute || warnings[1] > -2*time.Minute {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestClockDriftThreshold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat))
+		w.Write([]byte(`<version>2.3</version>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	client.ClockDriftThreshold = 10 * time.Second
+	warned := false
+	client.OnClockDrift = func(time.Duration) { warned = true }
+
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatal(err)
+	}
+	if !warned {
+		t.Error("expected drift above the configured threshold to be reported")
+	}
+}
+
+func TestPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-2*time.Minute).UTC().Format(http.TimeFormat))
+		w.Write([]byte(`<version>2.3</version>`))
+	}))
+	defer server.Close()
+
+	result, err := NewClient(server.URL, "token").Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if result.Version != "2.3" || result.Latency <= 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if !result.ClockDriftMeasured || (result.ClockDrift+2*time.Minute).Abs() > 2*time.Second {
+		t.Errorf("expected drift of about -2m, got %s", result.ClockDrift)
+	}
+
+	server.Close()
+	if _, err := NewClient(server.URL, "token").Ping(context.Background()); err == nil {
+		t.Error("expected error for unreachable CCU")
+	}
+}
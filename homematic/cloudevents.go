@@ -0,0 +1,68 @@
+package homematic
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// CloudEventsSpecVersion is the CloudEvents version of the envelopes
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventTypeDataPointChanged is the event type of data point changes
+	CloudEventTypeDataPointChanged = "io.homematic.datapoint.changed"
+)
+
+// CloudEvent is a CloudEvents envelope in the structured JSON format, for
+// sinks feeding consumers like Knative or EventBridge
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            DataPointChange `json:"data"`
+}
+
+// NewCloudEvent wraps a data point change in a CloudEvents envelope. Source
+// identifies the CCU, e.g. its serial number or URL; the subject is the
+// ise_id of the data point, and the id is unique per data point and observation.
+func NewCloudEvent(source string, change DataPointChange) CloudEvent {
+	return CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              change.IseID + "-" + strconv.FormatInt(change.ObservedAt.UnixNano(), 10),
+		Source:          source,
+		Type:            CloudEventTypeDataPointChanged,
+		Subject:         change.IseID,
+		Time:            change.ObservedAt,
+		DataContentType: "application/json",
+		Data:            change,
+	}
+}
+
+// CloudEvents wraps all changes in CloudEvents envelopes
+func CloudEvents(source string, changes []DataPointChange) []CloudEvent {
+	events := make([]CloudEvent, len(changes))
+	for i, change := range changes {
+		events[i] = NewCloudEvent(source, change)
+	}
+	return events
+}
+
+// BinaryHeaders returns the ce-* headers of the binary HTTP content mode,
+// where the request body is the JSON encoded data
+func (e CloudEvent) BinaryHeaders() http.Header {
+	h := http.Header{}
+	h.Set("ce-specversion", e.SpecVersion)
+	h.Set("ce-id", e.ID)
+	h.Set("ce-source", e.Source)
+	h.Set("ce-type", e.Type)
+	if e.Subject != "" {
+		h.Set("ce-subject", e.Subject)
+	}
+	h.Set("ce-time", e.Time.UTC().Format(time.RFC3339Nano))
+	h.Set("Content-Type", e.DataContentType)
+	return h
+}
@@ -0,0 +1,48 @@
+package homematic
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNewCloudEvent(t *testing.T) {
+	observed := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+	change := DataPointChange{IseID: "2430", Name: "HmIP-RF.0000:1.STATE", Type: "STATE", OldValue: "0", NewValue: "1", ObservedAt: observed}
+
+	event := NewCloudEvent("urn:homematic:ccu:PEQ0123456", change)
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{
+		"specversion":     "1.0",
+		"id":              "2430-1709294400000000500",
+		"source":          "urn:homematic:ccu:PEQ0123456",
+		"type":            "io.homematic.datapoint.changed",
+		"subject":         "2430",
+		"time":            "2024-03-01T12:00:00.0000005Z",
+		"datacontenttype": "application/json",
+	} {
+		if decoded[key] != want {
+			t.Errorf("%s = %v, want %v", key, decoded[key], want)
+		}
+	}
+	if data, _ := decoded["data"].(map[string]any); data["new_value"] != "1" {
+		t.Errorf("unexpected data: %v", decoded["data"])
+	}
+
+	h := event.BinaryHeaders()
+	if h.Get("ce-id") != event.ID || h.Get("ce-type") != CloudEventTypeDataPointChanged || h.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers: %v", h)
+	}
+
+	events := CloudEvents("ccu", []DataPointChange{change, change})
+	if len(events) != 2 || events[1].Source != "ccu" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
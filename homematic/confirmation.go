@@ -0,0 +1,268 @@
+package homematic
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrWriteNotConfirmed is returned for writes to critical targets that were not confirmed
+var ErrWriteNotConfirmed = errors.New("write not confirmed")
+
+// WriteKind is the kind of a write awaiting confirmation
+type WriteKind string
+
+const (
+	WriteState       WriteKind = "state"
+	WriteMasterValue WriteKind = "master_value"
+	WriteProgram     WriteKind = "program"
+	// WriteScript is a HomeMatic script run with RunScript, which may change any target
+	WriteScript WriteKind = "script"
+)
+
+// ConfirmationRequest describes a write to critical targets awaiting confirmation
+type ConfirmationRequest struct {
+	Kind WriteKind `json:"kind"`
+	// IseIDs and Values are the targets and values of the write; master
+	// values are given as name=value, programs have no values, scripts are
+	// given as the only value
+	IseIDs []string `json:"ise_ids"`
+	Values []string `json:"values,omitempty"`
+	// Critical lists the configured targets matched by the write
+	Critical []string `json:"critical"`
+}
+
+// String describes the write, e.g. for prompts
+func (r ConfirmationRequest) String() string {
+	if len(r.IseIDs) == 0 {
+		return fmt.Sprintf("%s %s", r.Kind, strings.Join(r.Values, ", "))
+	}
+	parts := make([]string, len(r.IseIDs))
+	for i, id := range r.IseIDs {
+		parts[i] = id
+		if i < len(r.Values) {
+			parts[i] += "=" + r.Values[i]
+		}
+	}
+	return fmt.Sprintf("%s %s", r.Kind, strings.Join(parts, ", "))
+}
+
+// ConfirmationHook approves writes to critical targets before they are sent,
+// e.g. by asking for a TOTP code or an external approval; an error rejects the write
+type ConfirmationHook interface {
+	Confirm(ctx context.Context, req ConfirmationRequest) error
+}
+
+// ConfirmationFunc adapts a function to a ConfirmationHook
+type ConfirmationFunc func(ctx context.Context, req ConfirmationRequest) error
+
+// Confirm calls f
+func (f ConfirmationFunc) Confirm(ctx context.Context, req ConfirmationRequest) error {
+	return f(ctx, req)
+}
+
+// CriticalWrites requires a second step for state changes, master value
+// changes and program runs targeting critical data points, devices, system
+// variables or programs, e.g. door locks, garage doors or alarm variables.
+// An ise_id also matches the data points of a channel or device. Scripts run
+// with RunScript always need a confirmation, even without Targets, as they may
+// change any target.
+type CriticalWrites struct {
+	Targets []string
+	Hook    ConfirmationHook
+
+	// Timeout limits the confirmation, two minutes by default
+	Timeout time.Duration
+
+	mu sync.Mutex
+	// parents maps data point and channel ise_ids to their channel and device,
+	// it is loaded on first use
+	parents map[string][]string
+}
+
+// SetTopology sets the data points (from a state list) critical targets are
+// resolved with; otherwise the client loads them with the first checked write
+func (w *CriticalWrites) SetTopology(devices []Device) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.parents = dataPointParents(devices)
+}
+
+// invalidate drops the topology so that it is loaded again with the next checked write
+func (w *CriticalWrites) invalidate() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.parents = nil
+}
+
+// Critical reports whether writing the data point, system variable, device or program needs a confirmation
+func (w *CriticalWrites) Critical(iseID string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.matches(iseID)) > 0
+}
+
+// matches returns the critical targets matching an ise_id
+func (w *CriticalWrites) matches(iseID string) []string {
+	var matched []string
+	for _, id := range append([]string{iseID}, w.parents[iseID]...) {
+		if slices.Contains(w.Targets, id) {
+			matched = append(matched, id)
+		}
+	}
+	return matched
+}
+
+// confirm asks the hook to confirm a write if it targets critical ise_ids,
+// scripts are always confirmed
+func (w *CriticalWrites) confirm(ctx context.Context, c *Client, kind WriteKind, iseIDs, values []string) error {
+	w.mu.Lock()
+	// programs are not part of the state list, scripts have no targets
+	if w.parents == nil && kind != WriteProgram && kind != WriteScript && len(w.Targets) > 0 {
+		devices, err := c.GetStateList("", true, false)
+		if err != nil {
+			w.mu.Unlock()
+			return fmt.Errorf("failed to load critical write topology: %w", err)
+		}
+		w.parents = dataPointParents(devices)
+	}
+	var critical []string
+	if kind == WriteScript {
+		critical = slices.Clone(w.Targets)
+	}
+	for _, id := range iseIDs {
+		for _, match := range w.matches(id) {
+			if !slices.Contains(critical, match) {
+				critical = append(critical, match)
+			}
+		}
+	}
+	w.mu.Unlock()
+
+	if len(critical) == 0 && kind != WriteScript {
+		return nil
+	}
+	target := strings.Join(critical, ",")
+	if kind == WriteScript {
+		target = "script"
+	}
+	if w.Hook == nil {
+		return fmt.Errorf("%w: no confirmation hook for %s", ErrWriteNotConfirmed, target)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, durationOrDefault(w.Timeout, 2*time.Minute))
+	defer cancel()
+	req := ConfirmationRequest{Kind: kind, IseIDs: iseIDs, Values: values, Critical: critical}
+	if err := w.Hook.Confirm(ctx, req); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrWriteNotConfirmed, target, err)
+	}
+	return nil
+}
+
+// confirmWrites applies the CriticalWrites of the client, if any
+func (c *Client) confirmWrites(ctx context.Context, kind WriteKind, iseIDs, values []string) error {
+	if c.CriticalWrites == nil {
+		return nil
+	}
+	return c.CriticalWrites.confirm(ctx, c, kind, iseIDs, values)
+}
+
+// PromptConfirmation asks on a terminal to confirm writes to critical targets
+type PromptConfirmation struct {
+	In  io.Reader
+	Out io.Writer
+
+	mu     sync.Mutex
+	reader *bufio.Reader
+}
+
+// Confirm prints the write and accepts y or yes as answer
+func (p *PromptConfirmation) Confirm(ctx context.Context, req ConfirmationRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.reader == nil {
+		p.reader = bufio.NewReader(p.In)
+	}
+	fmt.Fprintf(p.Out, "Confirm critical write %s [y/N]: ", req)
+	answer, err := p.reader.ReadString('\n')
+	if err != nil && answer == "" {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errors.New("declined")
+	}
+}
+
+// TOTPConfirmation confirms writes with a time-based one-time password
+// (RFC 6238, 6 digits, 30 second steps) of an authenticator app. A code is
+// accepted once, one step before and after the current one.
+type TOTPConfirmation struct {
+	// Secret is the base32 encoded shared secret of the authenticator app
+	Secret string
+	// Code asks for the current code, e.g. with a prompt or a chat bot
+	Code func(ctx context.Context, req ConfirmationRequest) (string, error)
+
+	mu sync.Mutex
+	// used is the last accepted time step, to reject replayed codes
+	used int64
+	now  func() time.Time
+}
+
+// Confirm asks for a code and verifies it
+func (t *TOTPConfirmation) Confirm(ctx context.Context, req ConfirmationRequest) error {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(
+		strings.TrimRight(strings.ToUpper(strings.ReplaceAll(t.Secret, " ", "")), "="))
+	if err != nil {
+		return fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	code, err := t.Code(ctx, req)
+	if err != nil {
+		return err
+	}
+	code = strings.TrimSpace(code)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now
+	if t.now != nil {
+		now = t.now
+	}
+	step := now().Unix() / 30
+	for _, s := range []int64{step, step - 1, step + 1} {
+		if s > t.used && hmac.Equal([]byte(totpCode(key, s)), []byte(code)) {
+			t.used = s
+			return nil
+		}
+	}
+	return errors.New("invalid TOTP code")
+}
+
+// totpCode returns the 6 digit code of a time step
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
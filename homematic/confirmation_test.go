@@ -0,0 +1,129 @@
+package homematic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestCriticalWrites(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	var writes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "change.cgi") || strings.HasSuffix(r.URL.Path, "runprogram.cgi") {
+			writes = append(writes, r.URL.Query().Get("ise_id")+r.URL.Query().Get("device_id")+r.URL.Query().Get("program_id"))
+		}
+		mock.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var requests []ConfirmationRequest
+	approve := true
+	client := NewClient(server.URL, "token")
+	client.CriticalWrites = &CriticalWrites{
+		Targets: []string{"2430", "1500"},
+		Hook: ConfirmationFunc(func(ctx context.Context, req ConfirmationRequest) error {
+			requests = append(requests, req)
+			if !approve {
+				return errors.New("denied by the user")
+			}
+			return nil
+		}),
+	}
+
+	// the dimmer level is not critical, the switch state belongs to a critical device
+	if err := client.ChangeState([]string{"2456"}, []string{"0.5"}); err != nil {
+		t.Fatalf("expected the uncritical write to succeed: %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("expected no confirmation, got %+v", requests)
+	}
+	if err := client.ChangeState([]string{"2456", "2445"}, []string{"0.5", "true"}); err != nil {
+		t.Fatalf("expected the confirmed write to succeed: %v", err)
+	}
+	if len(requests) != 1 || requests[0].String() != "state 2456=0.5, 2445=true" || requests[0].Critical[0] != "2430" {
+		t.Errorf("unexpected confirmation requests %+v", requests)
+	}
+
+	approve = false
+	if err := client.ChangeMasterValue([]string{"2430"}, []string{"POWERUP_ACTION"}, []string{"1"}); !errors.Is(err, ErrWriteNotConfirmed) {
+		t.Errorf("expected ErrWriteNotConfirmed, got %v", err)
+	}
+	if err := client.RunProgram("1500", false); !errors.Is(err, ErrWriteNotConfirmed) || !strings.Contains(err.Error(), "denied by the user") {
+		t.Errorf("expected the program run to be rejected, got %v", err)
+	}
+	if len(requests) != 3 || requests[1].String() != "master_value 2430=POWERUP_ACTION=1" || requests[2].Kind != WriteProgram {
+		t.Errorf("unexpected confirmation requests %+v", requests)
+	}
+	if strings.Join(writes, " ") != "2456 2456,2445" {
+		t.Errorf("expected only the confirmed writes to be sent, got %v", writes)
+	}
+
+	// nothing is sent in dry run mode, so there is nothing to confirm
+	client.DryRun = true
+	if err := client.ChangeState([]string{"2445"}, []string{"true"}); err != nil {
+		t.Errorf("expected the dry run to succeed, got %v", err)
+	}
+
+	client.DryRun = false
+	client.CriticalWrites.Hook = nil
+	if err := client.ChangeState([]string{"2445"}, []string{"true"}); !errors.Is(err, ErrWriteNotConfirmed) {
+		t.Errorf("expected a critical write without hook to fail, got %v", err)
+	}
+}
+
+func TestPromptConfirmation(t *testing.T) {
+	var out bytes.Buffer
+	prompt := &PromptConfirmation{In: strings.NewReader("y\nno\n"), Out: &out}
+	req := ConfirmationRequest{Kind: WriteState, IseIDs: []string{"2445"}, Values: []string{"true"}}
+
+	if err := prompt.Confirm(context.Background(), req); err != nil {
+		t.Errorf("expected the write to be confirmed, got %v", err)
+	}
+	if err := prompt.Confirm(context.Background(), req); err == nil {
+		t.Error("expected the write to be declined")
+	}
+	if err := prompt.Confirm(context.Background(), req); err == nil {
+		t.Error("expected an error at the end of the input")
+	}
+	if !strings.Contains(out.String(), "Confirm critical write state 2445=true [y/N]: ") {
+		t.Errorf("unexpected prompt %q", out.String())
+	}
+}
+
+func TestTOTPConfirmation(t *testing.T) {
+	// test vector of RFC 6238 for the secret "12345678901234567890" at 59 seconds
+	if code := totpCode([]byte("12345678901234567890"), 59/30); code != "287082" {
+		t.Fatalf("expected 287082, got %s", code)
+	}
+
+	var code string
+	totp := &TOTPConfirmation{
+		Secret: "gezd gnbv gy3t qojq gezd gnbv gy3t qojq",
+		Code: func(ctx context.Context, req ConfirmationRequest) (string, error) {
+			return code, nil
+		},
+		now: func() time.Time { return time.Unix(59, 0) },
+	}
+	code = "000000"
+	if err := totp.Confirm(context.Background(), ConfirmationRequest{}); err == nil {
+		t.Error("expected a wrong code to be rejected")
+	}
+	code = "287082"
+	if err := totp.Confirm(context.Background(), ConfirmationRequest{}); err != nil {
+		t.Errorf("expected the code to be accepted, got %v", err)
+	}
+	if err := totp.Confirm(context.Background(), ConfirmationRequest{}); err == nil {
+		t.Error("expected a replayed code to be rejected")
+	}
+}
@@ -0,0 +1,144 @@
+package homematic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotAuthenticated is returned when the CCU rejects the security token
+var ErrNotAuthenticated = errors.New("not authenticated, the security token was rejected")
+
+// ConnectivityEventType is the kind of a connectivity event
+type ConnectivityEventType int
+
+const (
+	// Connected is emitted on the first successful request
+	Connected ConnectivityEventType = iota + 1
+	// Degraded is emitted when a request fails to reach the CCU or gets an HTTP
+	// error, also if the first request fails or the token was rejected before
+	Degraded
+	// Reconnected is emitted on the first successful request after Degraded or TokenRejected
+	Reconnected
+	// TokenRejected is emitted when the CCU starts rejecting the security token
+	TokenRejected
+)
+
+// String returns the name of the event type
+func (t ConnectivityEventType) String() string {
+	switch t {
+	case Connected:
+		return "connected"
+	case Degraded:
+		return "degraded"
+	case Reconnected:
+		return "reconnected"
+	case TokenRejected:
+		return "token_rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnectivityEvent reports a change of the connection to the CCU
+type ConnectivityEvent struct {
+	Type ConnectivityEventType
+	Time time.Time
+	// Err is the error of the request that caused a Degraded or TokenRejected event
+	Err error
+}
+
+// ConnectivityState is the connection state of a client derived from its request outcomes
+type ConnectivityState int
+
+const (
+	// StateUnknown means no request has completed yet
+	StateUnknown ConnectivityState = iota
+	// StateConnected means the last request succeeded
+	StateConnected
+	// StateDegraded means the last request failed
+	StateDegraded
+	// StateTokenRejected means the last request was rejected as not authenticated
+	StateTokenRejected
+)
+
+// String returns the name of the state
+func (s ConnectivityState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateDegraded:
+		return "degraded"
+	case StateTokenRejected:
+		return "token_rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// connectivity tracks the connection state; it is shared by all copies of a client
+type connectivity struct {
+	mu    sync.Mutex
+	state ConnectivityState
+	seen  bool
+}
+
+// Connectivity returns the connection state derived from the outcome of the last request
+func (c *Client) Connectivity() ConnectivityState {
+	if c.connectivity == nil {
+		return StateUnknown
+	}
+	c.connectivity.mu.Lock()
+	defer c.connectivity.mu.Unlock()
+
+	return c.connectivity.state
+}
+
+// observeOutcome updates the connection state with the outcome of a request
+// sent to the CCU and emits an event through OnConnectivity if the state changed
+func (c *Client) observeOutcome(err error) {
+	// requests canceled by the caller say nothing about the CCU
+	if c.connectivity == nil || errors.Is(err, ErrClientClosed) || errors.Is(err, context.Canceled) {
+		return
+	}
+	// the CCU answered, so errors it reported don't degrade the connection
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && !errors.Is(err, ErrNotAuthenticated) {
+		err = nil
+	}
+
+	var eventType ConnectivityEventType
+	conn := c.connectivity
+	conn.mu.Lock()
+	switch {
+	case err == nil && conn.state != StateConnected:
+		eventType = Connected
+		if conn.seen {
+			eventType = Reconnected
+		}
+		conn.state = StateConnected
+		conn.seen = true
+	case errors.Is(err, ErrNotAuthenticated) && conn.state != StateTokenRejected:
+		eventType = TokenRejected
+		conn.state = StateTokenRejected
+	case err != nil && !errors.Is(err, ErrNotAuthenticated) && conn.state != StateDegraded:
+		eventType = Degraded
+		conn.state = StateDegraded
+	}
+	conn.mu.Unlock()
+
+	if eventType != 0 && c.OnConnectivity != nil {
+		c.OnConnectivity(ConnectivityEvent{Type: eventType, Time: time.Now(), Err: err})
+	}
+}
+
+// isNotAuthenticated reports whether a response body is the XML-API's
+// answer to a missing or invalid security token
+func isNotAuthenticated(body []byte) bool {
+	if decl := xmlDeclaration(body); decl != nil {
+		body = body[len(decl):]
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(body), []byte("<not_authenticated"))
+}
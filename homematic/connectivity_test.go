@@ -0,0 +1,128 @@
+package homematic
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConnectivityEvents(t *testing.T) {
+	var mode atomic.Value
+	mode.Store("ok")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch mode.Load() {
+		case "down":
+			w.WriteHeader(http.StatusBadGateway)
+		case "rejected":
+			w.Write([]byte(`<?xml version="1.0" encoding="ISO-8859-1" ?><not_authenticated/>`))
+		default:
+			w.Write([]byte(`<version>1.22</version>`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	var events []ConnectivityEventType
+	client.OnConnectivity = func(event ConnectivityEvent) {
+		events = append(events, event.Type)
+	}
+
+	if client.Connectivity() != StateUnknown {
+		t.Errorf("expected unknown state before the first request, got %s", client.Connectivity())
+	}
+
+	steps := []struct {
+		mode  string
+		state ConnectivityState
+	}{
+		{"ok", StateConnected},
+		{"ok", StateConnected},
+		{"down", StateDegraded},
+		{"down", StateDegraded},
+		{"ok", StateConnected},
+		{"rejected", StateTokenRejected},
+		{"rejected", StateTokenRejected},
+		{"ok", StateConnected},
+	}
+	for i, step := range steps {
+		mode.Store(step.mode)
+		_, err := client.GetVersion()
+		if (err != nil) != (step.mode != "ok") {
+			t.Errorf("step %d: unexpected error %v", i, err)
+		}
+		if client.Connectivity() != step.state {
+			t.Errorf("step %d: expected state %s, got %s", i, step.state, client.Connectivity())
+		}
+	}
+
+	expected := []ConnectivityEventType{Connected, Degraded, Reconnected, TokenRejected, Reconnected}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("event %d: expected %s, got %s", i, expected[i], events[i])
+		}
+	}
+}
+
+func TestNotAuthenticated(t *testing.T) {
+	server := httptest.NewServer(staticHandler([]byte("<not_authenticated/>")))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "invalid").GetStateList("", false, false)
+	if !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("expected ErrNotAuthenticated, got %v", err)
+	}
+}
+
+func TestConnectivityDegradedTransitions(t *testing.T) {
+	var mode atomic.Value
+	mode.Store("down")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch mode.Load() {
+		case "down":
+			w.WriteHeader(http.StatusBadGateway)
+		case "rejected":
+			w.Write([]byte(`<not_authenticated/>`))
+		default:
+			w.Write([]byte(`<version>1.22</version>`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	var events []ConnectivityEventType
+	client.OnConnectivity = func(event ConnectivityEvent) {
+		events = append(events, event.Type)
+	}
+
+	// local failures don't change the state
+	client.GetDeviceList([]string{"12O4"}, false, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.Ping(ctx)
+	if client.Connectivity() != StateUnknown || len(events) != 0 {
+		t.Errorf("expected local failures to be ignored, got %s, %v", client.Connectivity(), events)
+	}
+
+	for _, step := range []string{"down", "ok", "rejected", "down"} {
+		mode.Store(step)
+		client.GetVersion()
+	}
+	expected := []ConnectivityEventType{Degraded, Connected, TokenRejected, Degraded}
+	if fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Errorf("expected events %v, got %v", expected, events)
+	}
+
+	failing := NewClient(server.URL, "token", WithTransport(&roundTripCounter{}), WithTLSConfig(&tls.Config{}))
+	failing.GetVersion()
+	if failing.Connectivity() != StateUnknown {
+		t.Errorf("expected request build errors to be ignored, got %s", failing.Connectivity())
+	}
+}
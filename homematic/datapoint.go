@@ -0,0 +1,72 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidValue is returned when a data point value cannot be converted to the requested type
+var ErrInvalidValue = errors.New("invalid data point value")
+
+// AsBool returns the value of a bool data point; true/false and 1/0 are accepted
+func (dp DataPoint) AsBool() (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(dp.Value)) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	}
+	return false, dp.invalid("bool")
+}
+
+// AsFloat returns the value of a numeric data point
+func (dp DataPoint) AsFloat() (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(dp.Value), 64)
+	if err != nil {
+		return 0, dp.invalid("float")
+	}
+	return f, nil
+}
+
+// AsInt returns the value of an integer or enum data point; floats without
+// fraction such as "1.000000" are accepted
+func (dp DataPoint) AsInt() (int, error) {
+	trimmed := strings.TrimSpace(dp.Value)
+	if i, err := strconv.Atoi(trimmed); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil || f != math.Trunc(f) || f < math.MinInt || f > math.MaxInt {
+		return 0, dp.invalid("int")
+	}
+	return int(f), nil
+}
+
+// AsString returns the raw value
+func (dp DataPoint) AsString() string {
+	return dp.Value
+}
+
+// Typed returns the value converted according to ValueType: bool for binary,
+// float64 for float, int for integer and enum, and string for string data
+// points and unknown value types
+func (dp DataPoint) Typed() (any, error) {
+	switch dp.ValueType {
+	case regaValueTypeBinary:
+		return dp.AsBool()
+	case regaValueTypeFloat:
+		return dp.AsFloat()
+	case regaValueTypeSignedInteger, regaValueTypeInteger:
+		return dp.AsInt()
+	default:
+		return dp.AsString(), nil
+	}
+}
+
+// invalid returns an ErrInvalidValue error for a failed conversion
+func (dp DataPoint) invalid(kind string) error {
+	return fmt.Errorf("%w: cannot convert %q of data point %s to %s", ErrInvalidValue, dp.Value, dp.IseID, kind)
+}
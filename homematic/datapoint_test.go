@@ -0,0 +1,74 @@
+package homematic
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestDataPointTyped(t *testing.T) {
+	for _, tc := range []struct {
+		dp   DataPoint
+		want any
+	}{
+		{DataPoint{Value: "true", ValueType: 2}, true},
+		{DataPoint{Value: "0", ValueType: 2}, false},
+		{DataPoint{Value: "21.500000", ValueType: 4}, 21.5},
+		{DataPoint{Value: "-65", ValueType: 8}, -65},
+		{DataPoint{Value: "2.000000", ValueType: 16}, 2},
+		{DataPoint{Value: "Tür offen", ValueType: 20}, "Tür offen"},
+		{DataPoint{Value: "1", ValueType: 0}, "1"},
+	} {
+		got, err := tc.dp.Typed()
+		if err != nil || got != tc.want {
+			t.Errorf("%+v: expected %v (%T), got %v (%T), %v", tc.dp, tc.want, tc.want, got, got, err)
+		}
+	}
+
+	for _, dp := range []DataPoint{
+		{IseID: "1", Value: "", ValueType: 2},
+		{IseID: "2", Value: "warm", ValueType: 4},
+		{IseID: "3", Value: "1.5", ValueType: 16},
+	} {
+		if _, err := dp.Typed(); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("%+v: expected ErrInvalidValue, got %v", dp, err)
+		}
+	}
+}
+
+func TestDataPointAccessors(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	devices, err := NewClient(server.URL, "token").GetState(nil, nil, []string{"2416", "2429", "2484"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]DataPoint)
+	for _, d := range devices {
+		for _, ch := range d.Channels {
+			for _, dp := range ch.DataPoints {
+				values[dp.IseID] = dp
+			}
+		}
+	}
+
+	if f, err := values["2416"].AsFloat(); err != nil || f != 21 {
+		t.Errorf("expected the set point 21, got %v, %v", f, err)
+	}
+	if i, err := values["2429"].AsInt(); err != nil || i != 0 {
+		t.Errorf("expected the window state 0, got %v, %v", i, err)
+	}
+	if b, err := values["2484"].AsBool(); err != nil || b {
+		t.Errorf("expected the contact to be closed, got %v, %v", b, err)
+	}
+	if _, err := values["2484"].AsFloat(); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected a bool not to convert to a float, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ErrUnsupportedPlatform is returned for transport options the browser
+// controls in WebAssembly builds, e.g. custom dialers and client certificates
+var ErrUnsupportedPlatform = errors.New("not supported on this platform")
+
+// DialFunc opens the connection for a request; addr is the host:port of the base URL
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// SetDialer replaces the function the transport of the client opens
+// connections with, e.g. to connect through an on-host proxy. The base URL
+// still determines the Host header and the TLS server name.
+func (c *Client) SetDialer(dial DialFunc) error {
+	if browserTransport {
+		return fmt.Errorf("custom dialers: %w", ErrUnsupportedPlatform)
+	}
+	transport, ok := httpTransport(c.HTTPClient.Transport)
+	if !ok {
+		return errors.New("custom dialers require an *http.Transport")
+	}
+	transport.DialContext = dial
+	return nil
+}
+
+// SetUnixSocket sends all requests to a Unix domain socket, e.g. of a local
+// proxy forwarding to the CCU
+func (c *Client) SetUnixSocket(path string) error {
+	return c.SetDialer(func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", path)
+	})
+}
+
+// SetDialTarget sends all requests to addr (host:port) instead of the host of
+// the base URL, e.g. to reach a CCU by IP address while the base URL carries
+// the name its certificate was issued for
+func (c *Client) SetDialTarget(addr string) error {
+	return c.SetDialer(func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, addr)
+	})
+}
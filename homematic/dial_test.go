@@ -0,0 +1,79 @@
+package homematic
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetUnixSocket(t *testing.T) {
+	listener, err := net.Listen("unix", filepath.Join(t.TempDir(), "ccu.sock"))
+	if err != nil {
+		t.Skipf("Unix sockets not supported: %v", err)
+	}
+	var host string
+	server := &httptest.Server{
+		Listener: listener,
+		Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host = r.Host
+			w.Write([]byte(`<version>1.22</version>`))
+		})},
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewClient("http://ccu.example", "token")
+	if err := client.SetUnixSocket(listener.Addr().String()); err != nil {
+		t.Fatalf("SetUnixSocket failed: %v", err)
+	}
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatalf("GetVersion over the socket failed: %v", err)
+	}
+	if host != "ccu.example" {
+		t.Errorf("expected the host of the base URL, got %q", host)
+	}
+}
+
+func TestSetDialTarget(t *testing.T) {
+	var host string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	defer server.Close()
+
+	client := NewClient("http://ccu.example:8080", "token")
+	if err := client.SetDialTarget(server.Listener.Addr().String()); err != nil {
+		t.Fatalf("SetDialTarget failed: %v", err)
+	}
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if host != "ccu.example:8080" {
+		t.Errorf("expected the host of the base URL, got %q", host)
+	}
+
+	client.HTTPClient.Transport = &roundTripCounter{}
+	if err := client.SetDialTarget("127.0.0.1:1"); err == nil {
+		t.Error("expected an error for an unknown transport")
+	}
+}
+
+func TestSetDialTargetDigestAuth(t *testing.T) {
+	challenges := 0
+	server := newDigestServer(t, "admin", "secret", &challenges)
+
+	client := NewClient("http://ccu.example:8080", "token")
+	client.SetDigestAuth("admin", "secret")
+	if err := client.SetDialTarget(server.Listener.Addr().String()); err != nil {
+		t.Fatalf("SetDialTarget failed: %v", err)
+	}
+	if version, err := client.GetVersion(); err != nil || version != "1.22" {
+		t.Fatalf("expected the digest authenticated request to reach the dial target, got %q, %v", version, err)
+	}
+	if challenges != 1 {
+		t.Errorf("expected one challenge, got %d", challenges)
+	}
+}
@@ -0,0 +1,295 @@
+package homematic
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DigestTransport is a http.RoundTripper performing HTTP Digest authentication
+// (RFC 7616). The server challenge is cached, so that subsequent requests are
+// authenticated without an additional round trip until the nonce becomes stale.
+type DigestTransport struct {
+	Username string
+	Password string
+
+	// Transport performs the actual requests; http.DefaultTransport if nil
+	Transport http.RoundTripper
+
+	mu        sync.Mutex
+	challenge *digestChallenge
+	nc        uint32
+}
+
+// digestChallenge holds the parameters of a WWW-Authenticate Digest challenge
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+// SetDigestAuth enables HTTP Digest authentication for all requests of the client
+func (c *Client) SetDigestAuth(username, password string) {
+	c.HTTPClient.Transport = &DigestTransport{
+		Username:  username,
+		Password:  password,
+		Transport: c.HTTPClient.Transport,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *DigestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// no or a stale challenge was used, retry once with the new challenge
+	if !t.updateChallenge(resp) {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return t.send(req)
+}
+
+// send performs the request, authenticated with the cached challenge if there is one
+func (t *DigestTransport) send(req *http.Request) (*http.Response, error) {
+	clone, err := cloneRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if authorization, ok := t.authorize(clone); ok {
+		clone.Header.Set("Authorization", authorization)
+	}
+	return t.transport().RoundTrip(clone)
+}
+
+// transport returns the underlying round tripper
+func (t *DigestTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+// httpTransport returns the *http.Transport below DigestTransport wrappers,
+// so that setters like SetDialer also apply with digest authentication. A
+// wrapper without Transport uses http.DefaultTransport, which is not returned.
+func httpTransport(rt http.RoundTripper) (*http.Transport, bool) {
+	for {
+		switch t := rt.(type) {
+		case *http.Transport:
+			return t, true
+		case *DigestTransport:
+			if t.Transport == nil {
+				return nil, false
+			}
+			rt = t.Transport
+		default:
+			return nil, false
+		}
+	}
+}
+
+// cloneTransport copies rt and the DigestTransport wrappers around its
+// *http.Transport, returning the copy and its *http.Transport
+func cloneTransport(rt http.RoundTripper) (http.RoundTripper, *http.Transport, bool) {
+	switch t := rt.(type) {
+	case *http.Transport:
+		clone := t.Clone()
+		return clone, clone, true
+	case *DigestTransport:
+		if t.Transport == nil {
+			return nil, nil, false
+		}
+		inner, transport, ok := cloneTransport(t.Transport)
+		if !ok {
+			return nil, nil, false
+		}
+		return &DigestTransport{Username: t.Username, Password: t.Password, Transport: inner}, transport, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// updateChallenge caches the digest challenge of a 401 response, reporting whether one was found
+func (t *DigestTransport) updateChallenge(resp *http.Response) bool {
+	for _, header := range resp.Header.Values("WWW-Authenticate") {
+		challenge, ok := parseDigestChallenge(header)
+		if !ok {
+			continue
+		}
+		t.mu.Lock()
+		t.challenge = challenge
+		t.nc = 0
+		t.mu.Unlock()
+		return true
+	}
+	return false
+}
+
+// authorize computes the Authorization header for a request from the cached challenge
+func (t *DigestTransport) authorize(req *http.Request) (string, bool) {
+	t.mu.Lock()
+	challenge := t.challenge
+	if challenge == nil {
+		t.mu.Unlock()
+		return "", false
+	}
+	t.nc++
+	nc := fmt.Sprintf("%08x", t.nc)
+	t.mu.Unlock()
+
+	newHash := md5.New
+	algorithm := strings.ToUpper(challenge.algorithm)
+	if strings.HasPrefix(algorithm, "SHA-256") {
+		newHash = sha256.New
+	}
+	h := func(s string) string {
+		return hashHex(newHash, s)
+	}
+
+	cnonce := newCnonce()
+	uri := req.URL.RequestURI()
+
+	ha1 := h(t.Username + ":" + challenge.realm + ":" + t.Password)
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = h(ha1 + ":" + challenge.nonce + ":" + cnonce)
+	}
+	ha2 := h(req.Method + ":" + uri)
+
+	var response string
+	if challenge.qop != "" {
+		response = h(strings.Join([]string{ha1, challenge.nonce, nc, cnonce, challenge.qop, ha2}, ":"))
+	} else {
+		response = h(ha1 + ":" + challenge.nonce + ":" + ha2)
+	}
+
+	parts := []string{
+		fmt.Sprintf(`username="%s"`, t.Username),
+		fmt.Sprintf(`realm="%s"`, challenge.realm),
+		fmt.Sprintf(`nonce="%s"`, challenge.nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+	if challenge.algorithm != "" {
+		parts = append(parts, "algorithm="+challenge.algorithm)
+	}
+	if challenge.opaque != "" {
+		parts = append(parts, fmt.Sprintf(`opaque="%s"`, challenge.opaque))
+	}
+	if challenge.qop != "" {
+		parts = append(parts, "qop="+challenge.qop, "nc="+nc, fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+
+	return "Digest " + strings.Join(parts, ", "), true
+}
+
+// parseDigestChallenge parses a WWW-Authenticate header value with the Digest scheme
+func parseDigestChallenge(header string) (*digestChallenge, bool) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return nil, false
+	}
+
+	params := parseAuthParams(rest)
+	challenge := &digestChallenge{
+		realm:     params["realm"],
+		nonce:     params["nonce"],
+		opaque:    params["opaque"],
+		algorithm: params["algorithm"],
+	}
+	if challenge.nonce == "" {
+		return nil, false
+	}
+
+	// only the "auth" quality of protection is supported
+	for _, qop := range strings.Split(params["qop"], ",") {
+		if strings.TrimSpace(qop) == "auth" {
+			challenge.qop = "auth"
+		}
+	}
+
+	return challenge, true
+}
+
+// parseAuthParams parses comma separated key=value pairs with optionally quoted values
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " ,\t")
+		if s == "" {
+			return params
+		}
+
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			return params
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var sb strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				sb.WriteByte(rest[i])
+			}
+			value = sb.String()
+			s = rest[min(i+1, len(rest)):]
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			value = strings.TrimSpace(rest[:end])
+			s = rest[end:]
+		}
+		params[key] = value
+	}
+}
+
+// cloneRequest returns a copy of the request with a fresh body, so it can be sent again
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("digest authentication requires a replayable request body")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// hashHex returns the hex encoded hash of s
+func hashHex(newHash func() hash.Hash, s string) string {
+	h := newHash()
+	io.WriteString(h, s)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newCnonce returns a random client nonce
+func newCnonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,85 @@
+package homematic
+
+import (
+	"crypto/md5"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newDigestServer returns a server requiring digest authentication and
+// counting the challenges it sent
+func newDigestServer(t *testing.T, username, password string, challenges *int) *httptest.Server {
+	t.Helper()
+	const realm, nonce = "ccu", "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := parseAuthParams(strings.TrimPrefix(r.Header.Get("Authorization"), "Digest "))
+		ha1 := hashHex(md5.New, username+":"+realm+":"+password)
+		ha2 := hashHex(md5.New, r.Method+":"+r.URL.RequestURI())
+		expected := hashHex(md5.New, strings.Join([]string{ha1, nonce, params["nc"], params["cnonce"], "auth", ha2}, ":"))
+
+		if params["nonce"] != nonce || params["uri"] != r.URL.RequestURI() || params["response"] != expected {
+			*challenges++
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", qop="auth,auth-int", nonce="%s", opaque="5ccc069c403ebaf9f0171e9517f40e41", algorithm=MD5`, realm, nonce))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDigestAuth(t *testing.T) {
+	challenges := 0
+	server := newDigestServer(t, "admin", "secret", &challenges)
+
+	client := NewClient(server.URL, "token")
+	client.SetDigestAuth("admin", "secret")
+
+	for i := 0; i < 3; i++ {
+		version, err := client.GetVersion()
+		if err != nil {
+			t.Fatalf("GetVersion failed: %v", err)
+		}
+		if version != "1.22" {
+			t.Errorf("unexpected version: %s", version)
+		}
+	}
+
+	if challenges != 1 {
+		t.Errorf("expected the nonce to be cached after the first challenge, got %d challenges", challenges)
+	}
+}
+
+func TestDigestAuthWrongPassword(t *testing.T) {
+	challenges := 0
+	server := newDigestServer(t, "admin", "secret", &challenges)
+
+	client := NewClient(server.URL, "token")
+	client.SetDigestAuth("admin", "wrong")
+
+	if _, err := client.GetVersion(); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected HTTP 401 error, got %v", err)
+	}
+	if challenges != 2 {
+		t.Errorf("expected a single retry, got %d challenges", challenges)
+	}
+}
+
+func TestParseDigestChallenge(t *testing.T) {
+	challenge, ok := parseDigestChallenge(`Digest realm="CCU, \"main\"", nonce="abc", algorithm=SHA-256, qop="auth"`)
+	if !ok {
+		t.Fatal("expected challenge to be parsed")
+	}
+	if challenge.realm != `CCU, "main"` || challenge.nonce != "abc" || challenge.algorithm != "SHA-256" || challenge.qop != "auth" {
+		t.Errorf("unexpected challenge: %+v", challenge)
+	}
+
+	if _, ok := parseDigestChallenge(`Basic realm="CCU"`); ok {
+		t.Error("expected basic challenge to be ignored")
+	}
+}
@@ -0,0 +1,204 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrDoorNotConfirmed is returned when a door drive does not reach the
+// requested position before the confirmation timeout
+var ErrDoorNotConfirmed = errors.New("door drive did not reach the requested position")
+
+// DoorState is the position reported by the DOOR_STATE of a door drive
+type DoorState int
+
+const (
+	// DoorStateUnknown means the drive does not know the position, e.g. after it was stopped
+	DoorStateUnknown DoorState = iota
+	// DoorStateClosed means the door is closed
+	DoorStateClosed
+	// DoorStateOpen means the door is open
+	DoorStateOpen
+	// DoorStateVentilation means the door is in the ventilation position
+	DoorStateVentilation
+)
+
+// String returns the name of the door state
+func (s DoorState) String() string {
+	switch s {
+	case DoorStateClosed:
+		return "closed"
+	case DoorStateOpen:
+		return "open"
+	case DoorStateVentilation:
+		return "ventilation"
+	default:
+		return "unknown"
+	}
+}
+
+// DOOR_COMMAND values of door drives
+const (
+	doorCommandOpen        = 1
+	doorCommandStop        = 2
+	doorCommandClose       = 3
+	doorCommandPartialOpen = 4
+)
+
+// DoorDriveStatus is the reported state of a door drive
+type DoorDriveStatus struct {
+	State DoorState `json:"state"`
+	// Moving is set while the motor is running
+	Moving bool `json:"moving"`
+}
+
+// DoorDrive controls the garage or door drive of a HmIP-MOD-HO or
+// HmIP-MOD-TM module. Open, Close and Ventilate wait until the drive reports
+// the requested position, as a door that stopped at an obstacle must not be
+// reported as closed.
+type DoorDrive struct {
+	Client   *Client
+	DeviceID string
+
+	// PollInterval is the interval of state requests while waiting for the
+	// position, one second by default
+	PollInterval time.Duration
+
+	// Timeout limits the wait for the position if the context has no
+	// deadline, 90 seconds by default as doors take a while to move
+	Timeout time.Duration
+}
+
+// NewDoorDrive creates a door drive for the device with the given ise_id
+func NewDoorDrive(client *Client, deviceID string) *DoorDrive {
+	return &DoorDrive{Client: client, DeviceID: deviceID}
+}
+
+// doorDriveChannel holds the data points of the door channel of a device
+type doorDriveChannel struct {
+	command string
+	status  DoorDriveStatus
+}
+
+// Status returns the current state of the door
+func (d *DoorDrive) Status() (*DoorDriveStatus, error) {
+	ch, err := d.channel()
+	if err != nil {
+		return nil, err
+	}
+	return &ch.status, nil
+}
+
+// Open opens the door and waits until it is open
+func (d *DoorDrive) Open(ctx context.Context) (*DoorDriveStatus, error) {
+	return d.command(ctx, doorCommandOpen, DoorStateOpen)
+}
+
+// Close closes the door and waits until it is closed
+func (d *DoorDrive) Close(ctx context.Context) (*DoorDriveStatus, error) {
+	return d.command(ctx, doorCommandClose, DoorStateClosed)
+}
+
+// Ventilate moves the door to the ventilation position and waits until it is reached
+func (d *DoorDrive) Ventilate(ctx context.Context) (*DoorDriveStatus, error) {
+	return d.command(ctx, doorCommandPartialOpen, DoorStateVentilation)
+}
+
+// Stop stops the door and waits until the motor has stopped
+func (d *DoorDrive) Stop(ctx context.Context) (*DoorDriveStatus, error) {
+	return d.command(ctx, doorCommandStop, DoorStateUnknown)
+}
+
+// command writes a DOOR_COMMAND and polls until the drive is at the target
+// position; DoorStateUnknown accepts any position once the motor stopped
+func (d *DoorDrive) command(ctx context.Context, command int, target DoorState) (*DoorDriveStatus, error) {
+	ch, err := d.channel()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Client.changeStateContext(ctx, []string{ch.command}, []string{strconv.Itoa(command)}); err != nil {
+		return nil, err
+	}
+	if d.Client.DryRun {
+		return &ch.status, nil
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, durationOrDefault(d.Timeout, 90*time.Second))
+		defer cancel()
+	}
+	timer := time.NewTimer(durationOrDefault(d.PollInterval, time.Second))
+	defer timer.Stop()
+
+	status := ch.status
+	for {
+		select {
+		case <-ctx.Done():
+			return &status, fmt.Errorf("%w: door is %s", ErrDoorNotConfirmed, status.State)
+		case <-timer.C:
+		}
+
+		ch, err := d.channel()
+		if err != nil {
+			return nil, err
+		}
+		status = ch.status
+		if !status.Moving && (target == DoorStateUnknown || status.State == target) {
+			return &status, nil
+		}
+		timer.Reset(durationOrDefault(d.PollInterval, time.Second))
+	}
+}
+
+// channel reads the state of the device and returns its door channel
+func (d *DoorDrive) channel() (*doorDriveChannel, error) {
+	devices, err := d.Client.GetState([]string{d.DeviceID}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range devices {
+		if devices[i].IseID != d.DeviceID {
+			continue
+		}
+		for _, ch := range devices[i].Channels {
+			dps := make(map[string]DataPoint, len(ch.DataPoints))
+			for _, dp := range ch.DataPoints {
+				dps[dataPointType(dp)] = dp
+			}
+			command, ok := dps["DOOR_COMMAND"]
+			if !ok {
+				continue
+			}
+			return &doorDriveChannel{
+				command: command.IseID,
+				status: DoorDriveStatus{
+					State:  doorState(dps["DOOR_STATE"].Value),
+					Moving: strings.TrimSpace(dps["PROCESS"].Value) == "1",
+				},
+			}, nil
+		}
+		return nil, fmt.Errorf("device %s is not a supported door drive", d.DeviceID)
+	}
+	return nil, fmt.Errorf("door drive %s not found", d.DeviceID)
+}
+
+// doorState maps the DOOR_STATE enum, which is 0 (closed), 1 (open),
+// 2 (ventilation position) or 3 (position unknown)
+func doorState(value string) DoorState {
+	switch strings.TrimSpace(value) {
+	case "0":
+		return DoorStateClosed
+	case "1":
+		return DoorStateOpen
+	case "2":
+		return DoorStateVentilation
+	default:
+		return DoorStateUnknown
+	}
+}
@@ -0,0 +1,106 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// doorDriveHandler serves a HmIP-MOD-HO whose door reaches the commanded
+// position one state request after it started moving; with blocked set, it
+// stops at an obstacle instead
+func doorDriveHandler(blocked bool, commands *[]string) http.Handler {
+	var mu sync.Mutex
+	state, process, pending := "0", "0", ""
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path == "/addons/xmlapi/statechange.cgi" {
+			*commands = append(*commands, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+			pending = r.URL.Query().Get("new_value")
+			w.Write([]byte(`<result><changed/></result>`))
+			return
+		}
+		switch {
+		case pending != "" && process == "0":
+			process = "1"
+		case pending != "":
+			targets := map[string]string{"1": "1", "2": "3", "3": "0", "4": "2"}
+			state, process, pending = targets[pending], "0", ""
+			if blocked {
+				state = "3"
+			}
+		}
+		fmt.Fprintf(w, `<stateList><device ise_id="200"><channel ise_id="201">
+			<datapoint type="DOOR_COMMAND" ise_id="202" value="0"/>
+			<datapoint type="DOOR_STATE" ise_id="203" value="%s"/>
+			<datapoint type="PROCESS" ise_id="204" value="%s"/>
+		</channel></device></stateList>`, state, process)
+	})
+}
+
+func TestDoorDrive(t *testing.T) {
+	var commands []string
+	server := httptest.NewServer(doorDriveHandler(false, &commands))
+	defer server.Close()
+
+	door := NewDoorDrive(NewClient(server.URL, "token"), "200")
+	door.PollInterval = time.Millisecond
+
+	status, err := door.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.State != DoorStateClosed || status.Moving {
+		t.Errorf("unexpected initial status: %+v", status)
+	}
+
+	for _, step := range []struct {
+		action func(context.Context) (*DoorDriveStatus, error)
+		want   DoorState
+	}{
+		{door.Open, DoorStateOpen},
+		{door.Ventilate, DoorStateVentilation},
+		{door.Stop, DoorStateUnknown},
+		{door.Close, DoorStateClosed},
+	} {
+		status, err := step.action(context.Background())
+		if err != nil {
+			t.Fatalf("expected the door to become %s, got %v", step.want, err)
+		}
+		if status.State != step.want || status.Moving {
+			t.Errorf("expected the door to be %s, got %+v", step.want, status)
+		}
+	}
+	if want := []string{"202=1", "202=4", "202=2", "202=3"}; fmt.Sprint(commands) != fmt.Sprint(want) {
+		t.Errorf("expected commands %v, got %v", want, commands)
+	}
+
+	if _, err := NewDoorDrive(door.Client, "201").Status(); err == nil {
+		t.Error("expected an error for a channel ise_id")
+	}
+}
+
+func TestDoorDriveNotConfirmed(t *testing.T) {
+	var commands []string
+	server := httptest.NewServer(doorDriveHandler(true, &commands))
+	defer server.Close()
+
+	door := NewDoorDrive(NewClient(server.URL, "token"), "200")
+	door.PollInterval = time.Millisecond
+	door.Timeout = 20 * time.Millisecond
+
+	status, err := door.Open(context.Background())
+	if !errors.Is(err, ErrDoorNotConfirmed) {
+		t.Fatalf("expected the blocked door to time out, got %v", err)
+	}
+	if status.State != DoorStateUnknown {
+		t.Errorf("expected the last reported state, got %+v", status)
+	}
+}
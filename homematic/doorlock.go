@@ -0,0 +1,253 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLockNotConfirmed is returned when a door lock does not report the
+// requested state before the confirmation timeout
+var ErrLockNotConfirmed = errors.New("door lock did not confirm the state change")
+
+// LockState is the state of a door lock
+type LockState int
+
+const (
+	// LockStateUnknown means the lock does not know its position, e.g. after a manual operation
+	LockStateUnknown LockState = iota
+	// LockStateLocked means the bolt is locked
+	LockStateLocked
+	// LockStateUnlocked means the bolt is unlocked
+	LockStateUnlocked
+)
+
+// String returns the name of the lock state
+func (s LockState) String() string {
+	switch s {
+	case LockStateLocked:
+		return "locked"
+	case LockStateUnlocked:
+		return "unlocked"
+	default:
+		return "unknown"
+	}
+}
+
+// DoorLockStatus is the reported state of a door lock
+type DoorLockStatus struct {
+	State LockState `json:"state"`
+	// Moving is set while the motor is running
+	Moving bool `json:"moving"`
+	// Error is the error reported by the lock, e.g. CLUTCH_FAILURE,
+	// MOTOR_ABORTED or JAMMED; it is empty if there is none
+	Error string `json:"error,omitempty"`
+}
+
+// DoorLock controls a HM-Sec-Key (KeyMatic) or HmIP-DLD door lock. Every
+// action waits until the lock confirms the requested state, since a lock
+// that silently failed to close must not be reported as locked.
+type DoorLock struct {
+	Client   *Client
+	DeviceID string
+
+	// PollInterval is the interval of state requests while waiting for the
+	// confirmation, one second by default
+	PollInterval time.Duration
+
+	// Timeout limits the wait for the confirmation if the context has no
+	// deadline, 30 seconds by default
+	Timeout time.Duration
+}
+
+// NewDoorLock creates a door lock for the device with the given ise_id
+func NewDoorLock(client *Client, deviceID string) *DoorLock {
+	return &DoorLock{Client: client, DeviceID: deviceID}
+}
+
+// doorLockChannel holds the data points of the lock channel of a device
+type doorLockChannel struct {
+	// homematicIP is set for HmIP-DLD, which is controlled by LOCK_TARGET_LEVEL
+	homematicIP bool
+	target      string
+	open        string
+	status      DoorLockStatus
+}
+
+// Status returns the current state of the lock
+func (l *DoorLock) Status() (*DoorLockStatus, error) {
+	ch, err := l.channel()
+	if err != nil {
+		return nil, err
+	}
+	return &ch.status, nil
+}
+
+// Lock locks the door and waits for the confirmation
+func (l *DoorLock) Lock(ctx context.Context) (*DoorLockStatus, error) {
+	return l.set(ctx, LockStateLocked, false)
+}
+
+// Unlock unlocks the door and waits for the confirmation
+func (l *DoorLock) Unlock(ctx context.Context) (*DoorLockStatus, error) {
+	return l.set(ctx, LockStateUnlocked, false)
+}
+
+// Open unlocks the door, opens the latch and waits until the lock reports
+// the unlocked state
+func (l *DoorLock) Open(ctx context.Context) (*DoorLockStatus, error) {
+	return l.set(ctx, LockStateUnlocked, true)
+}
+
+// set writes the target state and polls until the lock confirms it
+func (l *DoorLock) set(ctx context.Context, target LockState, open bool) (*DoorLockStatus, error) {
+	ch, err := l.channel()
+	if err != nil {
+		return nil, err
+	}
+
+	iseID, value := ch.target, strconv.FormatBool(target == LockStateUnlocked)
+	switch {
+	case ch.homematicIP:
+		// LOCK_TARGET_LEVEL is 0 (locked), 1 (unlocked) or 2 (open)
+		value = strconv.Itoa(int(target) - 1)
+		if open {
+			value = "2"
+		}
+	case open:
+		iseID, value = ch.open, "true"
+	}
+	if err := l.Client.changeStateContext(ctx, []string{iseID}, []string{value}); err != nil {
+		return nil, err
+	}
+	if l.Client.DryRun {
+		return &ch.status, nil
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, durationOrDefault(l.Timeout, 30*time.Second))
+		defer cancel()
+	}
+	timer := time.NewTimer(durationOrDefault(l.PollInterval, time.Second))
+	defer timer.Stop()
+
+	status := ch.status
+	for {
+		select {
+		case <-ctx.Done():
+			return &status, fmt.Errorf("%w: lock is %s", ErrLockNotConfirmed, status.State)
+		case <-timer.C:
+		}
+
+		ch, err := l.channel()
+		if err != nil {
+			return nil, err
+		}
+		status = ch.status
+		if status.Error != "" {
+			return &status, fmt.Errorf("door lock %s reports error %s", l.DeviceID, status.Error)
+		}
+		if status.State == target && !status.Moving {
+			return &status, nil
+		}
+		timer.Reset(durationOrDefault(l.PollInterval, time.Second))
+	}
+}
+
+// channel reads the state of the device and returns its lock channel
+func (l *DoorLock) channel() (*doorLockChannel, error) {
+	devices, err := l.Client.GetState([]string{l.DeviceID}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range devices {
+		if devices[i].IseID != l.DeviceID {
+			continue
+		}
+		var result *doorLockChannel
+		var lockError string
+		for _, ch := range devices[i].Channels {
+			dps := make(map[string]DataPoint, len(ch.DataPoints))
+			for _, dp := range ch.DataPoints {
+				dps[dataPointType(dp)] = dp
+			}
+			// errors may be reported on the maintenance channel
+			if dp, ok := dps["ERROR_JAMMED"]; ok && parseFlag(dp.Value) {
+				lockError = "JAMMED"
+			}
+			if result != nil {
+				continue
+			}
+			if dp, ok := dps["LOCK_TARGET_LEVEL"]; ok {
+				result = &doorLockChannel{homematicIP: true, target: dp.IseID}
+				result.status.State = hmIPLockState(dps["LOCK_STATE"].Value)
+				result.status.Moving = strings.TrimSpace(dps["PROCESS"].Value) == "1"
+				continue
+			}
+			if dp, ok := dps["OPEN"]; ok {
+				result = &doorLockChannel{target: dps["STATE"].IseID, open: dp.IseID}
+				result.status.State = keyMaticLockState(dps["STATE"].Value, dps["STATE_UNCERTAIN"].Value)
+				direction := strings.TrimSpace(dps["DIRECTION"].Value)
+				result.status.Moving = direction == "1" || direction == "2"
+				lockError = keyMaticError(dps["ERROR"].Value)
+			}
+		}
+		if result == nil || result.target == "" {
+			return nil, fmt.Errorf("device %s is not a supported door lock", l.DeviceID)
+		}
+		if result.status.Error == "" {
+			result.status.Error = lockError
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("door lock %s not found", l.DeviceID)
+}
+
+// hmIPLockState maps the LOCK_STATE of a HmIP-DLD
+func hmIPLockState(value string) LockState {
+	switch strings.TrimSpace(value) {
+	case "1":
+		return LockStateLocked
+	case "2":
+		return LockStateUnlocked
+	default:
+		return LockStateUnknown
+	}
+}
+
+// keyMaticLockState maps the STATE of a HM-Sec-Key, which is true when unlocked
+func keyMaticLockState(value, uncertain string) LockState {
+	switch {
+	case parseFlag(uncertain) || strings.TrimSpace(value) == "":
+		return LockStateUnknown
+	case parseFlag(value):
+		return LockStateUnlocked
+	default:
+		return LockStateLocked
+	}
+}
+
+// keyMaticError maps the ERROR enum of a HM-Sec-Key
+func keyMaticError(value string) string {
+	switch strings.TrimSpace(value) {
+	case "1":
+		return "CLUTCH_FAILURE"
+	case "2":
+		return "MOTOR_ABORTED"
+	default:
+		return ""
+	}
+}
+
+// durationOrDefault returns d, or def if d is not positive
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
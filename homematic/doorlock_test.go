@@ -0,0 +1,162 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+// lockMotor moves the simulated HmIP-DLD of the RaspberryMatic fixtures to its
+// target level: the first state request reports the motor running, the next
+// one the reached state
+func lockMotor(st *fixtures.State, step int) {
+	target, _ := st.Value("3457")
+	process, _ := st.Value("3458")
+	if target == "" {
+		return
+	}
+	if process == "0" {
+		st.SetValue("3458", "1")
+		return
+	}
+	state := "1"
+	if target != "0" {
+		state = "2"
+	}
+	st.SetValue("3456", state)
+	st.SetValue("3458", "0")
+	st.SetValue("3457", "")
+}
+
+func TestDoorLockHmIP(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.RaspberryMatic, lockMotor)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	lock := NewDoorLock(NewClient(server.URL, "token"), "3445")
+	lock.PollInterval = time.Millisecond
+
+	status, err := lock.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.State != LockStateLocked || status.Moving || status.Error != "" {
+		t.Errorf("unexpected initial status: %+v", status)
+	}
+
+	status, err = lock.Open(context.Background())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if status.State != LockStateUnlocked || status.Moving {
+		t.Errorf("expected the lock to be unlocked, got %+v", status)
+	}
+
+	status, err = lock.Lock(context.Background())
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if status.State != LockStateLocked {
+		t.Errorf("expected the lock to be locked, got %+v", status)
+	}
+
+	// the ise_id of the lock channel does not identify a device
+	if _, err := NewDoorLock(lock.Client, "3455").Status(); err == nil {
+		t.Error("expected an error for a device that is not a door lock")
+	}
+}
+
+// keyMaticHandler serves a HM-Sec-Key whose state never changes
+func keyMaticHandler(state, lockError string, changes *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/addons/xmlapi/statechange.cgi" {
+			*changes = append(*changes, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+			w.Write([]byte(`<result><changed/></result>`))
+			return
+		}
+		fmt.Fprintf(w, `<stateList><device ise_id="100"><channel ise_id="101">
+			<datapoint type="DIRECTION" ise_id="102" value="0"/>
+			<datapoint type="ERROR" ise_id="103" value="%s"/>
+			<datapoint type="OPEN" ise_id="104" value=""/>
+			<datapoint type="STATE" ise_id="105" value="%s"/>
+			<datapoint type="STATE_UNCERTAIN" ise_id="106" value="false"/>
+		</channel></device></stateList>`, lockError, state)
+	})
+}
+
+func TestDoorLockKeyMatic(t *testing.T) {
+	var changes []string
+	server := httptest.NewServer(keyMaticHandler("true", "0", &changes))
+	defer server.Close()
+
+	lock := NewDoorLock(NewClient(server.URL, "token"), "100")
+	lock.PollInterval = time.Millisecond
+	lock.Timeout = 20 * time.Millisecond
+
+	if _, err := lock.Unlock(context.Background()); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if _, err := lock.Open(context.Background()); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	status, err := lock.Lock(context.Background())
+	if !errors.Is(err, ErrLockNotConfirmed) {
+		t.Fatalf("expected the unchanged lock to time out, got %v", err)
+	}
+	if status.State != LockStateUnlocked {
+		t.Errorf("expected the last reported state, got %+v", status)
+	}
+	if len(changes) != 3 || changes[0] != "105=true" || changes[1] != "104=true" || changes[2] != "105=false" {
+		t.Errorf("unexpected state changes: %v", changes)
+	}
+}
+
+func TestDoorLockKeyMaticError(t *testing.T) {
+	var changes []string
+	server := httptest.NewServer(keyMaticHandler("true", "1", &changes))
+	defer server.Close()
+
+	lock := NewDoorLock(NewClient(server.URL, "token"), "100")
+	lock.PollInterval = time.Millisecond
+
+	status, err := lock.Lock(context.Background())
+	if err == nil || status == nil || status.Error != "CLUTCH_FAILURE" {
+		t.Errorf("expected a clutch failure, got %+v, %v", status, err)
+	}
+}
+
+func TestDoorLockContextBoundsWriteLock(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.RaspberryMatic, lockMotor)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	locker := NewLocalWriteLocker()
+	client.WriteLock = locker
+	unlock, err := locker.Lock(context.Background(), []string{"datapoint:3457"})
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := NewDoorLock(client, "3445").Open(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the held write lock to time out with ctx, got %v", err)
+	}
+	if value, _ := mock.Value("3457"); value != "" {
+		t.Errorf("expected no write, got LOCK_TARGET_LEVEL %q", value)
+	}
+}
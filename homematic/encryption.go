@@ -0,0 +1,166 @@
+package homematic
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// EncryptionKeyEnv is the environment variable holding the key for files
+// encrypted at rest, e.g. tokens, exported snapshots and rule state
+const EncryptionKeyEnv = "HOMEMATIC_ENCRYPTION_KEY"
+
+// ErrEncrypted is returned when reading an encrypted file without a key
+var ErrEncrypted = errors.New("file is encrypted, but no encryption key is configured")
+
+// encryptedHeader prefixes files sealed with an EncryptionKey
+var encryptedHeader = []byte("hmenc1\n")
+
+// EncryptionKey is a 32 byte NaCl secretbox key for files encrypted at rest
+type EncryptionKey [32]byte
+
+// NewEncryptionKey generates a random key
+func NewEncryptionKey() (*EncryptionKey, error) {
+	var key EncryptionKey
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+	return &key, nil
+}
+
+// ParseEncryptionKey decodes a base64 or hex encoded 32 byte key
+func ParseEncryptionKey(s string) (*EncryptionKey, error) {
+	s = strings.TrimSpace(s)
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(data) != 32 {
+		data, err = hex.DecodeString(s)
+	}
+	if err != nil || len(data) != 32 {
+		return nil, errors.New("encryption key must be 32 bytes, base64 or hex encoded")
+	}
+
+	var key EncryptionKey
+	copy(key[:], data)
+	return &key, nil
+}
+
+// LoadEncryptionKey returns the key from the HOMEMATIC_ENCRYPTION_KEY
+// environment variable, or nil if it is not set
+func LoadEncryptionKey() (*EncryptionKey, error) {
+	value := os.Getenv(EncryptionKeyEnv)
+	if value == "" {
+		return nil, nil
+	}
+	key, err := ParseEncryptionKey(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EncryptionKeyEnv, err)
+	}
+	return key, nil
+}
+
+// LoadEncryptionKeyFile reads a key from a file, e.g. one provisioned by a
+// keyring or secret manager
+func LoadEncryptionKeyFile(path string) (*EncryptionKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key: %w", err)
+	}
+	return ParseEncryptionKey(string(data))
+}
+
+// String returns the base64 encoding of the key
+func (k *EncryptionKey) String() string {
+	return base64.StdEncoding.EncodeToString(k[:])
+}
+
+// Seal encrypts and authenticates data
+func (k *EncryptionKey) Seal(data []byte) ([]byte, error) {
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(encryptedHeader)+len(nonce)+len(data)+secretbox.Overhead)
+	out = append(out, encryptedHeader...)
+	out = append(out, nonce[:]...)
+	return secretbox.Seal(out, data, &nonce, (*[32]byte)(k)), nil
+}
+
+// Open decrypts data sealed with Seal
+func (k *EncryptionKey) Open(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, errors.New("data is not encrypted")
+	}
+	data = data[len(encryptedHeader):]
+	if len(data) < 24+secretbox.Overhead {
+		return nil, errors.New("encrypted data is truncated")
+	}
+
+	var nonce [24]byte
+	copy(nonce[:], data)
+	plain, ok := secretbox.Open(nil, data[24:], &nonce, (*[32]byte)(k))
+	if !ok {
+		return nil, errors.New("failed to decrypt data, wrong key or corrupted data")
+	}
+	return plain, nil
+}
+
+// IsEncrypted reports whether data was sealed with an EncryptionKey
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedHeader)
+}
+
+// ReadEncryptedFile reads a file, decrypting it if it is encrypted. Plain
+// files are returned as they are, so that existing files keep working after
+// a key is configured.
+func ReadEncryptedFile(path string, key *EncryptionKey) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrEncrypted)
+	}
+	plain, err := key.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plain, nil
+}
+
+// WriteEncryptedFile atomically writes data to a file readable only by the
+// owner, encrypted if key is not nil
+func WriteEncryptedFile(path string, data []byte, key *EncryptionKey) error {
+	if key != nil {
+		sealed, err := key.Seal(data)
+		if err != nil {
+			return err
+		}
+		data = sealed
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
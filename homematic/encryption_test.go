@@ -0,0 +1,94 @@
+package homematic
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptionKeySealOpen(t *testing.T) {
+	key, err := NewEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseEncryptionKey(key.String())
+	if err != nil || *parsed != *key {
+		t.Fatalf("expected the base64 key to round trip, got %v", err)
+	}
+	if _, err := ParseEncryptionKey("c2hvcnQ="); err == nil {
+		t.Error("expected an error for a short key")
+	}
+
+	sealed, err := key.Seal([]byte("secret-token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(sealed) || bytes.Contains(sealed, []byte("secret-token")) {
+		t.Fatalf("expected sealed data to be encrypted: %q", sealed)
+	}
+	plain, err := key.Open(sealed)
+	if err != nil || string(plain) != "secret-token" {
+		t.Errorf("unexpected opened data %q: %v", plain, err)
+	}
+
+	other, _ := NewEncryptionKey()
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("expected opening with another key to fail")
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := key.Open(sealed); err == nil {
+		t.Error("expected opening tampered data to fail")
+	}
+}
+
+func TestEncryptedFile(t *testing.T) {
+	key, _ := NewEncryptionKey()
+	path := filepath.Join(t.TempDir(), "token")
+
+	if err := WriteEncryptedFile(path, []byte("secret-token"), key); err != nil {
+		t.Fatalf("WriteEncryptedFile failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the file to be readable only by the owner, got %v", info.Mode())
+	}
+
+	data, err := ReadEncryptedFile(path, key)
+	if err != nil || string(data) != "secret-token" {
+		t.Errorf("unexpected file content %q: %v", data, err)
+	}
+	if _, err := ReadEncryptedFile(path, nil); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted without a key, got %v", err)
+	}
+
+	// plain files are read as they are
+	if err := WriteEncryptedFile(path, []byte("plain-token"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ReadEncryptedFile(path, key); err != nil || string(data) != "plain-token" {
+		t.Errorf("unexpected plain file content %q: %v", data, err)
+	}
+}
+
+func TestLoadEncryptionKey(t *testing.T) {
+	t.Setenv(EncryptionKeyEnv, "")
+	if key, err := LoadEncryptionKey(); key != nil || err != nil {
+		t.Errorf("expected no key, got %v, %v", key, err)
+	}
+
+	t.Setenv(EncryptionKeyEnv, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	key, err := LoadEncryptionKey()
+	if err != nil || key[31] != 0x1f {
+		t.Errorf("expected the hex key, got %v, %v", key, err)
+	}
+
+	t.Setenv(EncryptionKeyEnv, "nope")
+	if _, err := LoadEncryptionKey(); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
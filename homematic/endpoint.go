@@ -0,0 +1,145 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EndpointParam describes a query parameter of a custom endpoint
+type EndpointParam struct {
+	Name     string
+	Required bool
+}
+
+// Endpoint declares a XML-API endpoint that is not built into the client, so
+// new addon endpoints can be used without waiting for a library release
+type Endpoint struct {
+	// Name is the CGI name, e.g. "newfeature.cgi"
+	Name string
+	// Params lists the accepted query parameters; unknown parameters are rejected
+	Params []EndpointParam
+	// NewResponse returns a pointer to the value the XML response is decoded into
+	NewResponse func() any
+	// Decode optionally replaces the XML decoding of the response body
+	Decode func(body []byte) (any, error)
+}
+
+// EndpointRegistry holds custom endpoint declarations
+type EndpointRegistry struct {
+	mu        sync.RWMutex
+	endpoints map[string]Endpoint
+}
+
+// DefaultEndpoints is the registry used by clients without their own registry
+var DefaultEndpoints = NewEndpointRegistry()
+
+// NewEndpointRegistry creates an empty endpoint registry
+func NewEndpointRegistry() *EndpointRegistry {
+	return &EndpointRegistry{endpoints: make(map[string]Endpoint)}
+}
+
+// Register adds an endpoint declaration
+func (r *EndpointRegistry) Register(endpoint Endpoint) error {
+	if endpoint.Name == "" {
+		return errors.New("endpoint name must not be empty")
+	}
+	if endpoint.NewResponse == nil && endpoint.Decode == nil {
+		return fmt.Errorf("endpoint %s needs NewResponse or Decode", endpoint.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.endpoints[endpoint.Name]; ok {
+		return fmt.Errorf("endpoint %s is already registered", endpoint.Name)
+	}
+	r.endpoints[endpoint.Name] = endpoint
+	return nil
+}
+
+// Lookup returns the declaration of an endpoint
+func (r *EndpointRegistry) Lookup(name string) (Endpoint, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	endpoint, ok := r.endpoints[name]
+	return endpoint, ok
+}
+
+// RegisterEndpoint adds an endpoint declaration to the default registry
+func RegisterEndpoint(endpoint Endpoint) error {
+	return DefaultEndpoints.Register(endpoint)
+}
+
+// validate checks the parameters of a call against the declaration
+func (e *Endpoint) validate(params map[string]string) error {
+	known := make(map[string]bool, len(e.Params))
+	for _, param := range e.Params {
+		known[param.Name] = true
+		if _, ok := params[param.Name]; param.Required && !ok {
+			return fmt.Errorf("endpoint %s: missing required parameter %s", e.Name, param.Name)
+		}
+	}
+
+	var unknown []string
+	for name := range params {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("endpoint %s: unknown parameters %s", e.Name, strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// Call invokes a registered custom endpoint and returns its decoded response
+func (c *Client) Call(name string, params map[string]string) (any, error) {
+	registry := c.Endpoints
+	if registry == nil {
+		registry = DefaultEndpoints
+	}
+
+	endpoint, ok := registry.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("endpoint not registered: %s", name)
+	}
+	if err := endpoint.validate(params); err != nil {
+		return nil, err
+	}
+
+	if endpoint.Decode != nil {
+		body, err := c.makeRawRequest(endpoint.Name, params)
+		if err != nil {
+			return nil, err
+		}
+		return endpoint.Decode(body)
+	}
+
+	result := endpoint.NewResponse()
+	if err := c.decodeResponse(endpoint.Name, params, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CallEndpoint invokes a registered custom endpoint and returns its response as T
+func CallEndpoint[T any](c *Client, name string, params map[string]string) (T, error) {
+	var zero T
+
+	result, err := c.Call(name, params)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := result.(T)
+	if !ok {
+		return zero, fmt.Errorf("endpoint %s returned %T, not %T", name, result, zero)
+	}
+	return typed, nil
+}
@@ -0,0 +1,76 @@
+package homematic
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// protocolResponse is the response of a hypothetical protocol.cgi endpoint
+type protocolResponse struct {
+	XMLName xml.Name `xml:"systemProtocol"`
+	Rows    []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"row"`
+}
+
+func TestClientCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/addons/xmlapi/protocol.cgi" || r.URL.Query().Get("limit") != "2" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="ISO-8859-1" ?><systemProtocol><row name="Fenster K` + "\xfc" + `che" value="open"/><row name="Licht" value="on"/></systemProtocol>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	client.Endpoints = NewEndpointRegistry()
+	err := client.Endpoints.Register(Endpoint{
+		Name:        "protocol.cgi",
+		Params:      []EndpointParam{{Name: "limit", Required: true}, {Name: "offset"}},
+		NewResponse: func() any { return &protocolResponse{} },
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	protocol, err := CallEndpoint[*protocolResponse](client, "protocol.cgi", map[string]string{"limit": "2"})
+	if err != nil {
+		t.Fatalf("CallEndpoint failed: %v", err)
+	}
+	if len(protocol.Rows) != 2 || protocol.Rows[0].Name != "Fenster Küche" {
+		t.Errorf("unexpected response: %+v", protocol)
+	}
+
+	if _, err := client.Call("protocol.cgi", nil); err == nil || !strings.Contains(err.Error(), "missing required parameter limit") {
+		t.Errorf("expected missing parameter error, got %v", err)
+	}
+	if _, err := client.Call("protocol.cgi", map[string]string{"limit": "1", "sort": "asc"}); err == nil || !strings.Contains(err.Error(), "unknown parameters sort") {
+		t.Errorf("expected unknown parameter error, got %v", err)
+	}
+	if _, err := client.Call("unknown.cgi", nil); err == nil {
+		t.Error("expected error for unregistered endpoint")
+	}
+	if _, err := CallEndpoint[string](client, "protocol.cgi", map[string]string{"limit": "2"}); err == nil {
+		t.Error("expected type mismatch error")
+	}
+}
+
+func TestEndpointRegistryRegister(t *testing.T) {
+	registry := NewEndpointRegistry()
+	decode := func(body []byte) (any, error) { return string(body), nil }
+
+	if err := registry.Register(Endpoint{Name: "raw.cgi", Decode: decode}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Register(Endpoint{Name: "raw.cgi", Decode: decode}); err == nil {
+		t.Error("expected duplicate registration to fail")
+	}
+	if err := registry.Register(Endpoint{Name: "empty.cgi"}); err == nil {
+		t.Error("expected endpoint without decoder to be rejected")
+	}
+}
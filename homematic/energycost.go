@@ -0,0 +1,323 @@
+package homematic
+
+import (
+	"cmp"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Tariff returns the electricity price per kWh at a time; ok is false if no
+// price is known, e.g. before a dynamic price feed was fetched
+type Tariff interface {
+	Price(t time.Time) (price float64, ok bool)
+}
+
+// FixedTariff charges the same price at all times
+type FixedTariff float64
+
+// Price returns the fixed price
+func (f FixedTariff) Price(time.Time) (float64, bool) {
+	return float64(f), true
+}
+
+// TariffPeriod is a time of day range with its own price
+type TariffPeriod struct {
+	// From and To are times of day, e.g. 22*time.Hour; a period from 22h to 6h spans midnight
+	From, To time.Duration
+	// Weekdays restricts the period to the given days; empty matches every day
+	Weekdays []time.Weekday
+	Price    float64
+}
+
+// TimeOfUseTariff charges the price of the first period containing a time and Default otherwise
+type TimeOfUseTariff struct {
+	Default float64
+	Periods []TariffPeriod
+	// Location is the time zone of the periods, time.Local by default
+	Location *time.Location
+}
+
+// Price returns the price of the period containing t
+func (t *TimeOfUseTariff) Price(at time.Time) (float64, bool) {
+	local := at.In(cmp.Or(t.Location, time.Local))
+	y, m, d := local.Date()
+	sinceMidnight := local.Sub(time.Date(y, m, d, 0, 0, 0, 0, local.Location()))
+	for _, p := range t.Periods {
+		if len(p.Weekdays) > 0 && !slices.Contains(p.Weekdays, local.Weekday()) {
+			continue
+		}
+		inPeriod := sinceMidnight >= p.From && sinceMidnight < p.To
+		if p.From > p.To {
+			inPeriod = sinceMidnight >= p.From || sinceMidnight < p.To
+		}
+		if inPeriod {
+			return p.Price, true
+		}
+	}
+	return t.Default, true
+}
+
+// DynamicTariff charges the prices of a price feed plus a surcharge, e.g.
+// for grid fees and taxes not included in exchange prices
+type DynamicTariff struct {
+	Surcharge float64
+
+	mu     sync.Mutex
+	prices []PriceSlot
+}
+
+// SetPrices replaces the prices, e.g. after fetching a PriceFeed
+func (t *DynamicTariff) SetPrices(prices []PriceSlot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prices = slices.Clone(prices)
+}
+
+// Price returns the price of the slot containing at, if any
+func (t *DynamicTariff) Price(at time.Time) (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, slot := range t.prices {
+		if slot.Contains(at) {
+			return slot.Price + t.Surcharge, true
+		}
+	}
+	return 0, false
+}
+
+// DefaultEnergyRetention is the number of days EnergyCosts keeps daily costs for
+const DefaultEnergyRetention = 31
+
+// EnergyCost is the energy consumed and its cost on a day, per device or per room
+type EnergyCost struct {
+	Day    string  `json:"day"`
+	Device string  `json:"device,omitempty"`
+	Room   string  `json:"room,omitempty"`
+	Energy float64 `json:"energy_kwh"`
+	Cost   float64 `json:"cost"`
+	// Unpriced is the energy consumed while the tariff had no price
+	Unpriced float64 `json:"unpriced_kwh,omitempty"`
+}
+
+func (c *EnergyCost) add(energy float64, price float64, priced bool) {
+	c.Energy += energy
+	if priced {
+		c.Cost += energy * price
+	} else {
+		c.Unpriced += energy
+	}
+}
+
+// EnergyCosts computes the running energy cost of devices from the deltas of
+// their ENERGY_COUNTER data points (in Wh) and a tariff. Each interval between
+// two readings is charged at the price of its midpoint.
+type EnergyCosts struct {
+	Tariff Tariff
+	// Location determines the days costs are attributed to, time.Local by default
+	Location *time.Location
+	// Retention is the number of days to keep, DefaultEnergyRetention by default
+	Retention int
+
+	mu       sync.Mutex
+	rooms    map[string]string
+	names    map[string]string
+	counters map[string]*energyCounter
+	days     map[energyKey]*EnergyCost
+	totals   map[string]*EnergyCost
+}
+
+// energyCounter is the last reading of an energy counter
+type energyCounter struct {
+	counterTotal
+	at time.Time
+}
+
+// energyKey identifies the cost of a device on a day
+type energyKey struct {
+	day, device string
+}
+
+// NewEnergyCosts creates energy costs charged with the given tariff
+func NewEnergyCosts(tariff Tariff) *EnergyCosts {
+	return &EnergyCosts{
+		Tariff:   tariff,
+		rooms:    make(map[string]string),
+		names:    make(map[string]string),
+		counters: make(map[string]*energyCounter),
+		days:     make(map[energyKey]*EnergyCost),
+		totals:   make(map[string]*EnergyCost),
+	}
+}
+
+// SetTopology assigns devices to the room of their first channel in a room, for room reports
+func (e *EnergyCosts) SetTopology(topology *Topology) {
+	rooms := channelRooms(topology)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rooms = make(map[string]string)
+	for _, device := range topology.Devices {
+		e.names[device.IseID] = device.Name
+		for _, ch := range device.Channels {
+			if room, ok := rooms[ch.IseID]; ok {
+				e.rooms[device.IseID] = room
+				break
+			}
+		}
+	}
+}
+
+// Observe records the energy counters of a state list snapshot
+func (e *EnergyCosts) Observe(devices []Device, at time.Time) {
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				e.observe(device.IseID, device.Name, dp.IseID, dataPointType(dp), dp.Value, at)
+			}
+		}
+	}
+}
+
+// ObserveChange records a data point change
+func (e *EnergyCosts) ObserveChange(change DataPointChange) {
+	e.observe(change.DeviceIseID, change.DeviceName, change.IseID, change.Type, change.NewValue, change.ObservedAt)
+}
+
+func (e *EnergyCosts) observe(deviceID, deviceName, iseID, dpType, raw string, at time.Time) {
+	if dpType != "ENERGY_COUNTER" {
+		return
+	}
+	value, ok := numericValue(raw)
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if deviceName != "" {
+		e.names[deviceID] = deviceName
+	}
+	counter := e.counters[iseID]
+	if counter == nil {
+		counter = &energyCounter{}
+		e.counters[iseID] = counter
+	}
+	from := counter.at
+	first := !counter.known
+	// counters of devices that lost power restart at zero
+	increment := counter.add(value, 0)
+	counter.at = at
+	if first || increment == 0 {
+		return
+	}
+
+	energy := increment / 1000
+	price, priced := e.Tariff.Price(from.Add(at.Sub(from) / 2))
+	loc := cmp.Or(e.Location, time.Local)
+	day := at.In(loc).Format(time.DateOnly)
+
+	key := energyKey{day, deviceID}
+	if e.days[key] == nil {
+		e.days[key] = &EnergyCost{Day: day}
+	}
+	e.days[key].add(energy, price, priced)
+	if e.totals[deviceID] == nil {
+		e.totals[deviceID] = &EnergyCost{}
+	}
+	e.totals[deviceID].add(energy, price, priced)
+
+	retention := e.Retention
+	if retention <= 0 {
+		retention = DefaultEnergyRetention
+	}
+	cutoff := at.In(loc).AddDate(0, 0, -retention).Format(time.DateOnly)
+	for k := range e.days {
+		if k.day <= cutoff {
+			delete(e.days, k)
+		}
+	}
+}
+
+// Report returns the daily energy and cost per device, ordered by day and device
+func (e *EnergyCosts) Report() []EnergyCost {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	report := make([]EnergyCost, 0, len(e.days))
+	for key, cost := range e.days {
+		c := *cost
+		c.Device = e.names[key.device]
+		c.Room = e.rooms[key.device]
+		report = append(report, c)
+	}
+	sortEnergyCosts(report)
+	return report
+}
+
+// RoomReport returns the daily energy and cost per room, ordered by day and
+// room; devices without room are summed up with an empty room
+func (e *EnergyCosts) RoomReport() []EnergyCost {
+	byRoom := make(map[[2]string]*EnergyCost)
+	for _, c := range e.Report() {
+		key := [2]string{c.Day, c.Room}
+		if byRoom[key] == nil {
+			byRoom[key] = &EnergyCost{Day: c.Day, Room: c.Room}
+		}
+		byRoom[key].Energy += c.Energy
+		byRoom[key].Cost += c.Cost
+		byRoom[key].Unpriced += c.Unpriced
+	}
+
+	report := make([]EnergyCost, 0, len(byRoom))
+	for _, c := range byRoom {
+		report = append(report, *c)
+	}
+	sortEnergyCosts(report)
+	return report
+}
+
+// WritePrometheus writes the energy and cost per device since the start as counters
+func (e *EnergyCosts) WritePrometheus(w io.Writer) error {
+	e.mu.Lock()
+	totals := make([]EnergyCost, 0, len(e.totals))
+	for id, total := range e.totals {
+		c := *total
+		c.Device = e.names[id]
+		c.Room = e.rooms[id]
+		totals = append(totals, c)
+	}
+	e.mu.Unlock()
+	sortEnergyCosts(totals)
+
+	for _, m := range []struct {
+		name, help string
+		value      func(c EnergyCost) float64
+	}{
+		{"homematic_energy_consumed_kwh_total", "Energy consumed by the device.", func(c EnergyCost) float64 { return c.Energy }},
+		{"homematic_energy_cost_total", "Cost of the energy consumed by the device.", func(c EnergyCost) float64 { return c.Cost }},
+	} {
+		if err := writeMetricHeader(w, m.name, m.help, "counter"); err != nil {
+			return err
+		}
+		for _, c := range totals {
+			labels := []metricLabel{{"device", c.Device}, {"room", c.Room}}
+			if err := writeMetricSample(w, m.name, labels, m.value(c)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sortEnergyCosts orders costs by day, room and device
+func sortEnergyCosts(costs []EnergyCost) {
+	slices.SortFunc(costs, func(a, b EnergyCost) int {
+		return cmp.Or(cmp.Compare(a.Day, b.Day), cmp.Compare(a.Room, b.Room), cmp.Compare(a.Device, b.Device))
+	})
+}
@@ -0,0 +1,127 @@
+package homematic
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeOfUseTariff(t *testing.T) {
+	tariff := &TimeOfUseTariff{
+		Default: 0.30,
+		Periods: []TariffPeriod{
+			{From: 22 * time.Hour, To: 6 * time.Hour, Price: 0.20},
+			{From: 0, To: 24 * time.Hour, Weekdays: []time.Weekday{time.Saturday, time.Sunday}, Price: 0.25},
+		},
+		Location: time.UTC,
+	}
+	for _, tc := range []struct {
+		at   time.Time
+		want float64
+	}{
+		{time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), 0.30},
+		{time.Date(2024, 1, 10, 23, 0, 0, 0, time.UTC), 0.20},
+		{time.Date(2024, 1, 11, 5, 59, 0, 0, time.UTC), 0.20},
+		{time.Date(2024, 1, 11, 6, 0, 0, 0, time.UTC), 0.30},
+		{time.Date(2024, 1, 13, 12, 0, 0, 0, time.UTC), 0.25},
+	} {
+		if got, ok := tariff.Price(tc.at); !ok || got != tc.want {
+			t.Errorf("price at %s: expected %v, got %v", tc.at, tc.want, got)
+		}
+	}
+}
+
+func TestDynamicTariff(t *testing.T) {
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	tariff := &DynamicTariff{Surcharge: 0.15}
+	if _, ok := tariff.Price(start); ok {
+		t.Fatal("expected no price before prices are set")
+	}
+	tariff.SetPrices([]PriceSlot{{Start: start, End: start.Add(time.Hour), Price: 0.10}})
+	if got, ok := tariff.Price(start.Add(30 * time.Minute)); !ok || math.Abs(got-0.25) > 1e-9 {
+		t.Errorf("expected 0.25, got %v", got)
+	}
+	if _, ok := tariff.Price(start.Add(time.Hour)); ok {
+		t.Error("expected no price after the last slot")
+	}
+}
+
+func TestEnergyCosts(t *testing.T) {
+	costs := NewEnergyCosts(&TimeOfUseTariff{
+		Default:  0.30,
+		Periods:  []TariffPeriod{{From: 22 * time.Hour, To: 6 * time.Hour, Price: 0.20}},
+		Location: time.UTC,
+	})
+	costs.Location = time.UTC
+	costs.SetTopology(&Topology{
+		Devices: []Device{{IseID: "1", Name: "Waschmaschine", Channels: []Channel{{IseID: "2"}}}},
+		Rooms:   []Room{{Name: "Keller", Channels: []Channel{{IseID: "2"}}}},
+	})
+
+	at := time.Date(2024, 1, 10, 20, 0, 0, 0, time.UTC)
+	observe := func(deviceID, value string, offset time.Duration) {
+		costs.ObserveChange(DataPointChange{DeviceIseID: deviceID, IseID: deviceID + "0", Type: "ENERGY_COUNTER",
+			NewValue: value, ObservedAt: at.Add(offset)})
+	}
+	observe("1", "10000", 0)
+	observe("1", "12000", time.Hour)   // 2 kWh at 0.30
+	observe("1", "13000", 3*time.Hour) // 1 kWh, midpoint 22:00 at 0.20
+	observe("1", "500", 5*time.Hour)   // reset, 0.5 kWh on the next day at 0.20
+	costs.Observe([]Device{{IseID: "5", Name: "Kühlschrank", Channels: []Channel{{
+		DataPoints: []DataPoint{{IseID: "50", Type: "ENERGY_COUNTER", Value: "100"}}}}}}, at)
+	observe("5", "1100", time.Hour)
+	observe("5", "oops", 2*time.Hour)
+
+	report := costs.Report()
+	want := []EnergyCost{
+		{Day: "2024-01-10", Device: "Kühlschrank", Energy: 1, Cost: 0.30},
+		{Day: "2024-01-10", Device: "Waschmaschine", Room: "Keller", Energy: 3, Cost: 0.80},
+		{Day: "2024-01-11", Device: "Waschmaschine", Room: "Keller", Energy: 0.5, Cost: 0.10},
+	}
+	if len(report) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), report)
+	}
+	for i := range want {
+		if report[i].Day != want[i].Day || report[i].Device != want[i].Device || report[i].Room != want[i].Room ||
+			math.Abs(report[i].Energy-want[i].Energy) > 1e-9 || math.Abs(report[i].Cost-want[i].Cost) > 1e-9 {
+			t.Errorf("entry %d: expected %+v, got %+v", i, want[i], report[i])
+		}
+	}
+
+	rooms := costs.RoomReport()
+	if len(rooms) != 3 || rooms[1].Room != "Keller" || math.Abs(rooms[1].Cost-0.80) > 1e-9 {
+		t.Errorf("unexpected room report %+v", rooms)
+	}
+
+	var buf bytes.Buffer
+	if err := costs.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE homematic_energy_cost_total counter",
+		`homematic_energy_consumed_kwh_total{device="Waschmaschine",room="Keller"} 3.5`,
+		`homematic_energy_cost_total{device="Kühlschrank",room=""} 0.3`,
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("expected %q in metrics:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestEnergyCostsUnpricedAndRetention(t *testing.T) {
+	costs := NewEnergyCosts(&DynamicTariff{})
+	costs.Location = time.UTC
+	costs.Retention = 1
+
+	at := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	for i, value := range []string{"0", "1000", "3000"} {
+		costs.ObserveChange(DataPointChange{DeviceIseID: "1", IseID: "10", Type: "ENERGY_COUNTER",
+			NewValue: value, ObservedAt: at.Add(time.Duration(i) * 24 * time.Hour)})
+	}
+	report := costs.Report()
+	if len(report) != 1 || report[0].Day != "2024-01-12" || report[0].Unpriced != 2 || report[0].Cost != 0 {
+		t.Errorf("expected only the last day with unpriced energy, got %+v", report)
+	}
+}
@@ -0,0 +1,214 @@
+package homematic
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// etcdRequestTimeout bounds keep-alive and revoke requests, which are not bound to the caller's context
+const etcdRequestTimeout = 5 * time.Second
+
+// EtcdWriteLocker is a WriteLocker backed by etcd, for clients running in
+// several processes or hosts. It uses the JSON gateway of etcd 3.4 or later
+// rather than the gRPC client, which would add gRPC and the etcd API modules
+// to the dependencies of every user of this module.
+// The locks of a Lock call share a lease that expires after TTL, so a crashed
+// holder cannot block an actuator forever; while held, the lease is kept
+// alive every third of the TTL.
+type EtcdWriteLocker struct {
+	// Endpoint is the URL of an etcd member, e.g. http://etcd:2379
+	Endpoint string
+	Username string
+	Password string
+	// HTTPClient sends the requests, e.g. with TLS client certificates; http.DefaultClient if nil
+	HTTPClient *http.Client
+	// Prefix is prepended to all lock keys, "homematic/lock/" by default
+	Prefix string
+	// TTL is the lifetime of a lock, rounded up to seconds, 30s by default
+	TTL time.Duration
+	// Wait is the maximum time to wait for a held lock, 10s by default
+	Wait time.Duration
+	// RetryInterval is the pause between attempts to acquire a held lock, 50ms by default
+	RetryInterval time.Duration
+	// OnLost is called with an error wrapping ErrLockLost when the lease of
+	// held locks could not be kept alive, e.g. because etcd was unreachable
+	OnLost func(err error)
+}
+
+// Lock acquires the locks of all keys, retrying held locks until Wait elapses or ctx is done
+func (e *EtcdWriteLocker) Lock(ctx context.Context, keys []string) (func(), error) {
+	ctx, cancel := context.WithTimeout(ctx, durationOrDefault(e.Wait, 10*time.Second))
+	defer cancel()
+
+	token, err := e.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := durationOrDefault(e.TTL, 30*time.Second)
+	var grant struct {
+		ID string `json:"ID"`
+	}
+	if err := e.call(ctx, "/v3/lease/grant", token, map[string]any{"TTL": int64(math.Ceil(ttl.Seconds()))}, &grant); err != nil {
+		return nil, err
+	}
+	if grant.ID == "" {
+		return nil, errors.New("etcd: no lease granted")
+	}
+
+	// revoking the lease deletes all keys attached to it
+	release := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+		defer cancel()
+		e.call(ctx, "/v3/lease/revoke", token, map[string]any{"ID": grant.ID}, nil)
+	}
+
+	for _, key := range keys {
+		if err := e.acquire(ctx, token, e.prefix()+key, grant.ID); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	stop := keepAlive(ttl/3, func() error { return e.keepAlive(token, grant.ID) }, e.OnLost)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stop()
+			release()
+		})
+	}, nil
+}
+
+// acquire creates a lock key attached to the lease, retrying while it exists
+func (e *EtcdWriteLocker) acquire(ctx context.Context, token, key, lease string) error {
+	encoded := base64.StdEncoding.EncodeToString([]byte(key))
+	txn := map[string]any{
+		"compare": []map[string]any{
+			{"key": encoded, "target": "CREATE", "result": "EQUAL", "create_revision": "0"},
+		},
+		"success": []map[string]any{
+			{"request_put": map[string]any{"key": encoded, "value": base64.StdEncoding.EncodeToString([]byte(lease)), "lease": lease}},
+		},
+	}
+
+	for {
+		var result struct {
+			Succeeded bool `json:"succeeded"`
+		}
+		if err := e.call(ctx, "/v3/kv/txn", token, txn, &result); err != nil {
+			// the wait may end while a retry is in flight
+			if ctx.Err() != nil {
+				return fmt.Errorf("lock %s is held: %w", key, ctx.Err())
+			}
+			return err
+		}
+		if result.Succeeded {
+			return nil
+		}
+
+		select {
+		case <-time.After(durationOrDefault(e.RetryInterval, 50*time.Millisecond)):
+		case <-ctx.Done():
+			return fmt.Errorf("lock %s is held: %w", key, ctx.Err())
+		}
+	}
+}
+
+// keepAlive renews the lease once
+func (e *EtcdWriteLocker) keepAlive(token, lease string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+
+	var response struct {
+		Result struct {
+			TTL string `json:"TTL"`
+		} `json:"result"`
+	}
+	if err := e.call(ctx, "/v3/lease/keepalive", token, map[string]any{"ID": lease}, &response); err != nil {
+		return fmt.Errorf("%w: lease %s: %w", ErrLockLost, lease, err)
+	}
+	// an expired lease is reported without TTL
+	if response.Result.TTL == "" || response.Result.TTL == "0" {
+		return fmt.Errorf("%w: lease %s expired", ErrLockLost, lease)
+	}
+	return nil
+}
+
+// authenticate returns the auth token for Username, or an empty token without user
+func (e *EtcdWriteLocker) authenticate(ctx context.Context) (string, error) {
+	if e.Username == "" {
+		return "", nil
+	}
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := e.call(ctx, "/v3/auth/authenticate", "", map[string]string{"name": e.Username, "password": e.Password}, &result); err != nil {
+		return "", err
+	}
+	return result.Token, nil
+}
+
+// prefix returns the key prefix
+func (e *EtcdWriteLocker) prefix() string {
+	if e.Prefix == "" {
+		return "homematic/lock/"
+	}
+	return e.Prefix
+}
+
+// call posts a JSON request to the gateway and decodes the first JSON object
+// of the response into result, as keep-alive responses are streamed
+func (e *EtcdWriteLocker) call(ctx context.Context, path, token string, request, result any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(e.Endpoint, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid etcd endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	client := e.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to etcd: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(message, &failure) == nil && failure.Message != "" {
+			return fmt.Errorf("etcd: %s", failure.Message)
+		}
+		return fmt.Errorf("etcd: HTTP error %d", resp.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse etcd response: %w", err)
+	}
+	return nil
+}
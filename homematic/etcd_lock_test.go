@@ -0,0 +1,162 @@
+package homematic
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEtcd implements the gateway endpoints used by EtcdWriteLocker
+type fakeEtcd struct {
+	mu       sync.Mutex
+	keys     map[string]string // key to lease
+	leases   map[string]bool
+	next     int
+	tokens   []string
+	requests []string
+}
+
+func newFakeEtcd(t *testing.T) (*fakeEtcd, *httptest.Server) {
+	t.Helper()
+	fake := &fakeEtcd{keys: make(map[string]string), leases: make(map[string]bool)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+	json.NewDecoder(r.Body).Decode(&req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.URL.Path)
+	f.tokens = append(f.tokens, r.Header.Get("Authorization"))
+
+	var resp any
+	switch r.URL.Path {
+	case "/v3/auth/authenticate":
+		if req["name"] != "root" || req["password"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]any{"message": "etcdserver: authentication failed, invalid user ID or password"})
+			return
+		}
+		resp = map[string]any{"token": "auth-token"}
+	case "/v3/lease/grant":
+		f.next++
+		id := strconv.Itoa(f.next)
+		f.leases[id] = true
+		resp = map[string]any{"ID": id, "TTL": "1"}
+	case "/v3/kv/txn":
+		compare := req["compare"].([]any)[0].(map[string]any)
+		put := req["success"].([]any)[0].(map[string]any)["request_put"].(map[string]any)
+		key, _ := base64.StdEncoding.DecodeString(compare["key"].(string))
+		if _, held := f.keys[string(key)]; held {
+			resp = map[string]any{}
+			break
+		}
+		f.keys[string(key)] = put["lease"].(string)
+		resp = map[string]any{"succeeded": true}
+	case "/v3/lease/keepalive":
+		result := map[string]any{"ID": req["ID"]}
+		if f.leases[req["ID"].(string)] {
+			result["TTL"] = "1"
+		}
+		resp = map[string]any{"result": result}
+	case "/v3/lease/revoke":
+		f.revoke(req["ID"].(string))
+		resp = map[string]any{}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// revoke expires a lease and deletes its keys
+func (f *fakeEtcd) revoke(lease string) {
+	delete(f.leases, lease)
+	for key, l := range f.keys {
+		if l == lease {
+			delete(f.keys, key)
+		}
+	}
+}
+
+func TestEtcdWriteLocker(t *testing.T) {
+	fake, server := newFakeEtcd(t)
+	locker := &EtcdWriteLocker{Endpoint: server.URL, Username: "root", Password: "secret", Wait: 50 * time.Millisecond, RetryInterval: 5 * time.Millisecond}
+
+	unlock, err := locker.Lock(context.Background(), []string{"datapoint:1251", "datapoint:1252"})
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	fake.mu.Lock()
+	if fake.keys["homematic/lock/datapoint:1251"] != "1" || fake.keys["homematic/lock/datapoint:1252"] != "1" {
+		t.Errorf("expected both keys to be attached to the lease, got %v", fake.keys)
+	}
+	fake.mu.Unlock()
+
+	// a second holder times out and its lease is revoked without touching the held keys
+	if _, err := locker.Lock(context.Background(), []string{"datapoint:1252"}); err == nil || !strings.Contains(err.Error(), "is held") {
+		t.Errorf("expected held lock error, got %v", err)
+	}
+
+	unlock()
+	unlock()
+	fake.mu.Lock()
+	remaining, leases := len(fake.keys), len(fake.leases)
+	tokens := fake.tokens
+	fake.mu.Unlock()
+	if remaining != 0 || leases != 0 {
+		t.Errorf("expected all locks and leases to be released, got %d keys and %d leases", remaining, leases)
+	}
+	if tokens[0] != "" || tokens[len(tokens)-1] != "auth-token" {
+		t.Errorf("expected the auth token to be sent, got %v", tokens)
+	}
+}
+
+func TestEtcdWriteLockerKeepAlive(t *testing.T) {
+	fake, server := newFakeEtcd(t)
+	lost := make(chan error, 1)
+	locker := &EtcdWriteLocker{Endpoint: server.URL, TTL: 30 * time.Millisecond, OnLost: func(err error) { lost <- err }}
+
+	unlock, err := locker.Lock(context.Background(), []string{"datapoint:1251"})
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	defer unlock()
+	time.Sleep(30 * time.Millisecond)
+	fake.mu.Lock()
+	if !strings.Contains(strings.Join(fake.requests, ","), "/v3/lease/keepalive") {
+		t.Errorf("expected the lease to be kept alive, got %v", fake.requests)
+	}
+	fake.revoke("1")
+	fake.mu.Unlock()
+
+	select {
+	case err := <-lost:
+		if !errors.Is(err, ErrLockLost) {
+			t.Errorf("expected ErrLockLost, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the expired lease to be reported")
+	}
+}
+
+func TestEtcdWriteLockerAuthError(t *testing.T) {
+	_, server := newFakeEtcd(t)
+	locker := &EtcdWriteLocker{Endpoint: server.URL, Username: "root", Password: "wrong"}
+
+	if _, err := locker.Lock(context.Background(), []string{"datapoint:1251"}); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Errorf("expected authentication error, got %v", err)
+	}
+}
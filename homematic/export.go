@@ -0,0 +1,168 @@
+package homematic
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportFormat is the output format of an export
+type ExportFormat string
+
+const (
+	ExportJSON ExportFormat = "json"
+	ExportCSV  ExportFormat = "csv"
+	ExportYAML ExportFormat = "yaml"
+)
+
+// ParseExportFormat validates and returns an export format
+func ParseExportFormat(format string) (ExportFormat, error) {
+	switch f := ExportFormat(format); f {
+	case ExportJSON, ExportCSV, ExportYAML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// Topology bundles the devices of a CCU with their room and function assignments
+type Topology struct {
+	Devices   []Device   `json:"devices" yaml:"devices"`
+	Rooms     []Room     `json:"rooms" yaml:"rooms"`
+	Functions []Function `json:"functions" yaml:"functions"`
+}
+
+// GetTopology returns all devices, rooms and functions
+func (c *Client) GetTopology() (*Topology, error) {
+	devices, err := c.GetDeviceList(nil, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+	rooms, err := c.GetRoomList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rooms: %w", err)
+	}
+	functions, err := c.GetFunctionList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get functions: %w", err)
+	}
+
+	return &Topology{
+		Devices:   devices,
+		Rooms:     rooms,
+		Functions: functions,
+	}, nil
+}
+
+// ExportDevices writes devices and their channels in the given format
+func ExportDevices(w io.Writer, format ExportFormat, devices []Device) error {
+	return export(w, format, devices, func() [][]string {
+		rows := [][]string{{"device_name", "device_address", "device_ise_id", "device_type", "interface",
+			"channel_name", "channel_address", "channel_ise_id", "channel_type", "device_tags", "channel_tags"}}
+		for _, d := range devices {
+			if len(d.Channels) == 0 {
+				rows = append(rows, []string{d.Name, d.Address, d.IseID, d.DeviceType, d.InterfaceID, "", "", "", "",
+					metadataTags(d.Metadata), ""})
+			}
+			for _, ch := range d.Channels {
+				rows = append(rows, []string{d.Name, d.Address, d.IseID, d.DeviceType, d.InterfaceID,
+					ch.Name, ch.Address, ch.IseID, ch.Type, metadataTags(d.Metadata), metadataTags(ch.Metadata)})
+			}
+		}
+		return rows
+	})
+}
+
+// ExportState writes the data points of a state list in the given format
+func ExportState(w io.Writer, format ExportFormat, devices []Device) error {
+	return export(w, format, devices, func() [][]string {
+		rows := [][]string{{"device_name", "device_ise_id", "channel_name", "channel_ise_id",
+			"datapoint_name", "datapoint_type", "datapoint_ise_id", "value", "valuetype", "valueunit", "timestamp"}}
+		for _, d := range devices {
+			for _, ch := range d.Channels {
+				for _, dp := range ch.DataPoints {
+					rows = append(rows, []string{d.Name, d.IseID, ch.Name, ch.IseID,
+						dp.Name, dp.Type, dp.IseID, dp.Value, strconv.Itoa(dp.ValueType), dp.ValueUnit,
+						strconv.FormatInt(dp.Timestamp, 10)})
+				}
+			}
+		}
+		return rows
+	})
+}
+
+// ExportSystemVariables writes system variables in the given format
+func ExportSystemVariables(w io.Writer, format ExportFormat, sysVars []SystemVariable) error {
+	return export(w, format, sysVars, func() [][]string {
+		rows := [][]string{{"name", "ise_id", "value", "valuetype", "subtype", "unit", "min", "max", "timestamp"}}
+		for _, v := range sysVars {
+			rows = append(rows, []string{v.Name, v.IseID, v.Value, strconv.Itoa(v.ValueType), v.Subtype,
+				v.Unit, v.Min, v.Max, strconv.FormatInt(v.Timestamp, 10)})
+		}
+		return rows
+	})
+}
+
+// ExportTopology writes a topology in the given format; the CSV format lists
+// one row per room or function membership of a channel
+func ExportTopology(w io.Writer, format ExportFormat, topology *Topology) error {
+	return export(w, format, topology, func() [][]string {
+		channelNames := make(map[string]string)
+		for _, d := range topology.Devices {
+			for _, ch := range d.Channels {
+				channelNames[ch.IseID] = ch.Name
+			}
+		}
+
+		rows := [][]string{{"kind", "name", "ise_id", "channel_name", "channel_ise_id"}}
+		for _, r := range topology.Rooms {
+			for _, ch := range r.Channels {
+				rows = append(rows, []string{"room", r.Name, r.IseID, channelNames[ch.IseID], ch.IseID})
+			}
+		}
+		for _, f := range topology.Functions {
+			for _, ch := range f.Channels {
+				rows = append(rows, []string{"function", f.Name, f.IseID, channelNames[ch.IseID], ch.IseID})
+			}
+		}
+		return rows
+	})
+}
+
+// metadataTags joins the tags of metadata for CSV exports
+func metadataTags(m *Metadata) string {
+	if m == nil {
+		return ""
+	}
+	return strings.Join(m.Tags, ";")
+}
+
+// export encodes data as JSON or YAML, or writes the rows built by csvRows as CSV
+func export(w io.Writer, format ExportFormat, data any, csvRows func() [][]string) error {
+	switch format {
+	case ExportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case ExportYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(data); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case ExportCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(csvRows()); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
@@ -0,0 +1,142 @@
+package homematic
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var exportDevices = []Device{
+	{
+		Name:       "Thermostat",
+		IseID:      "1234",
+		DeviceType: "HmIP-eTRV-2",
+		Channels: []Channel{
+			{
+				Name:  "Thermostat:1",
+				IseID: "1250",
+				DataPoints: []DataPoint{
+					{Name: "HmIP-RF.000A1234567890:1.ACTUAL_TEMPERATURE", Type: "ACTUAL_TEMPERATURE", IseID: "1251", Value: "21.5", ValueType: 4, Timestamp: 1700000000},
+				},
+			},
+		},
+	},
+}
+
+func TestExportState(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportState(&buf, ExportCSV, exportDevices); err != nil {
+		t.Fatalf("ExportState failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and one row, got %q", buf.String())
+	}
+	want := "Thermostat,1234,Thermostat:1,1250,HmIP-RF.000A1234567890:1.ACTUAL_TEMPERATURE,ACTUAL_TEMPERATURE,1251,21.5,4,,1700000000"
+	if lines[1] != want {
+		t.Errorf("unexpected row:\n got %s\nwant %s", lines[1], want)
+	}
+}
+
+func TestExportDevicesJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportDevices(&buf, ExportJSON, exportDevices); err != nil {
+		t.Fatalf("ExportDevices failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "XMLName") {
+		t.Errorf("expected XMLName to be omitted, got %s", buf.String())
+	}
+
+	var devices []Device
+	if err := json.Unmarshal(buf.Bytes(), &devices); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	if len(devices) != 1 || devices[0].Channels[0].DataPoints[0].Value != "21.5" {
+		t.Errorf("unexpected round trip result: %+v", devices)
+	}
+}
+
+func TestExportTopologyYAML(t *testing.T) {
+	topology := &Topology{
+		Devices: exportDevices,
+		Rooms:   []Room{{Name: "Living Room", IseID: "1000", Channels: []Channel{{IseID: "1250"}}}},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportTopology(&buf, ExportYAML, topology); err != nil {
+		t.Fatalf("ExportTopology failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "rooms:") || !strings.Contains(buf.String(), "Living Room") {
+		t.Errorf("unexpected YAML output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := ExportTopology(&buf, ExportCSV, topology); err != nil {
+		t.Fatalf("ExportTopology failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "room,Living Room,1000,Thermostat:1,1250") {
+		t.Errorf("unexpected CSV output:\n%s", buf.String())
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	if _, err := ParseExportFormat("xml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+	if f, err := ParseExportFormat("yaml"); err != nil || f != ExportYAML {
+		t.Errorf("expected yaml format, got %q (%v)", f, err)
+	}
+}
+
+func TestExportYAMLKeysMatchJSON(t *testing.T) {
+	rssi := -65
+	devices := append([]Device{}, exportDevices...)
+	devices[0].Maintenance = &MaintenanceInfo{RSSIDevice: &rssi, LowBat: true}
+	sysVars := []SystemVariable{{Name: "Presence", IseID: "950", Value: "true", ValueType: 2}}
+
+	for name, export := range map[string]func(format ExportFormat, buf *bytes.Buffer) error{
+		"devices":          func(format ExportFormat, buf *bytes.Buffer) error { return ExportDevices(buf, format, devices) },
+		"system variables": func(format ExportFormat, buf *bytes.Buffer) error { return ExportSystemVariables(buf, format, sysVars) },
+	} {
+		var jsonBuf, yamlBuf bytes.Buffer
+		if err := export(ExportJSON, &jsonBuf); err != nil {
+			t.Fatalf("%s: JSON export failed: %v", name, err)
+		}
+		if err := export(ExportYAML, &yamlBuf); err != nil {
+			t.Fatalf("%s: YAML export failed: %v", name, err)
+		}
+		var fromJSON, fromYAML any
+		if err := json.Unmarshal(jsonBuf.Bytes(), &fromJSON); err != nil {
+			t.Fatal(err)
+		}
+		if err := yaml.Unmarshal(yamlBuf.Bytes(), &fromYAML); err != nil {
+			t.Fatal(err)
+		}
+		if jsonKeys, yamlKeys := keyPaths("", fromJSON), keyPaths("", fromYAML); strings.Join(jsonKeys, ",") != strings.Join(yamlKeys, ",") {
+			t.Errorf("%s: keys differ:\n JSON %v\n YAML %v", name, jsonKeys, yamlKeys)
+		}
+	}
+}
+
+// keyPaths returns the sorted paths of all object keys of a decoded document
+func keyPaths(prefix string, value any) []string {
+	var paths []string
+	switch value := value.(type) {
+	case map[string]any:
+		for key, child := range value {
+			paths = append(paths, prefix+key)
+			paths = append(paths, keyPaths(prefix+key+".", child)...)
+		}
+	case []any:
+		for _, child := range value {
+			paths = append(paths, keyPaths(prefix, child)...)
+		}
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
@@ -0,0 +1,113 @@
+// Package fixtures provides a corpus of synthetic XML-API responses modeled on
+// different CCU generations, for testing code built on the homematic package
+// against realistic payloads without access to a CCU. The responses are
+// synthetic and follow the format of the XML-API addon; they were not
+// captured from real installations.
+//
+// The corpus contains responses of a simulated CCU2 (HomeMatic BidCos devices,
+// XML-API 1.15), CCU3 (mixed HomeMatic and HomeMatic IP devices, XML-API 1.22)
+// and RaspberryMatic installation (HomeMatic IP devices, XML-API 2.3). All responses
+// are ISO-8859-1 encoded and contain German umlauts in names. A large state list
+// of several hundred devices is available through LargeStateList, state lists of
+// arbitrary size are produced by GenerateStateList.
+package fixtures
+
+import (
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed data
+var data embed.FS
+
+// Source identifies the simulated installation of a set of responses
+type Source string
+
+const (
+	CCU2           Source = "ccu2"
+	CCU3           Source = "ccu3"
+	RaspberryMatic Source = "raspberrymatic"
+)
+
+// Sources returns all sources of the corpus
+func Sources() []Source {
+	return []Source{CCU2, CCU3, RaspberryMatic}
+}
+
+// Endpoints returns the XML-API endpoints (e.g. "statelist.cgi") with responses for a source
+func Endpoints(source Source) ([]string, error) {
+	entries, err := fs.ReadDir(data, path.Join("data", string(source)))
+	if err != nil {
+		return nil, fmt.Errorf("unknown fixture source: %s", source)
+	}
+
+	endpoints := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		endpoints = append(endpoints, strings.TrimSuffix(entry.Name(), ".xml")+".cgi")
+	}
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
+
+// Load returns the raw response of an endpoint (e.g. "statelist.cgi") for a source
+func Load(source Source, endpoint string) ([]byte, error) {
+	name := path.Join("data", string(source), strings.TrimSuffix(endpoint, ".cgi")+".xml")
+	body, err := data.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("no fixture for %s in %s", endpoint, source)
+	}
+	return body, nil
+}
+
+// MustLoad is like Load but panics if the fixture does not exist
+func MustLoad(source Source, endpoint string) []byte {
+	body, err := Load(source, endpoint)
+	if err != nil {
+		panic(err)
+	}
+	return body
+}
+
+// LargeStateList returns a synthetic state list response of a large installation with several hundred devices
+func LargeStateList() []byte {
+	compressed, err := data.ReadFile("data/large/statelist.xml.gz")
+	if err != nil {
+		panic(err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		panic(err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		panic(err)
+	}
+	return body
+}
+
+// Handler returns a http.Handler serving the fixed responses of a source
+// below /addons/xmlapi/, as the XML-API addon does
+func Handler(source Source) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint, ok := strings.CutPrefix(r.URL.Path, "/addons/xmlapi/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := Load(source, endpoint)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml; charset=ISO-8859-1")
+		w.Write(body)
+	})
+}
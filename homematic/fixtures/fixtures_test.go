@@ -0,0 +1,65 @@
+package fixtures
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEndpoints(t *testing.T) {
+	for _, source := range Sources() {
+		endpoints, err := Endpoints(source)
+		if err != nil {
+			t.Fatalf("Endpoints(%s) failed: %v", source, err)
+		}
+		if len(endpoints) == 0 {
+			t.Errorf("expected endpoints for %s", source)
+		}
+		for _, endpoint := range endpoints {
+			body, err := Load(source, endpoint)
+			if err != nil {
+				t.Errorf("Load(%s, %s) failed: %v", source, endpoint, err)
+			}
+			if !bytes.HasPrefix(body, []byte(`<?xml version="1.0" encoding="ISO-8859-1" ?>`)) {
+				t.Errorf("%s/%s: unexpected XML declaration", source, endpoint)
+			}
+		}
+	}
+
+	if _, err := Endpoints("ccu1"); err == nil {
+		t.Error("expected error for unknown source")
+	}
+}
+
+func TestLargeStateList(t *testing.T) {
+	body := LargeStateList()
+	if n := bytes.Count(body, []byte("<device ")); n < 300 {
+		t.Errorf("expected several hundred devices, got %d", n)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler(CCU3))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/addons/xmlapi/version.cgi?sid=token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !bytes.Contains(body, []byte("<version>1.22</version>")) {
+		t.Errorf("unexpected version response: %s", body)
+	}
+
+	resp, err = http.Get(server.URL + "/addons/xmlapi/unknown.cgi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown endpoint, got %d", resp.StatusCode)
+	}
+}
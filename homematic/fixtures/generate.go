@@ -0,0 +1,232 @@
+package fixtures
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strconv"
+)
+
+// GeneratorOptions configures a synthetic installation
+type GeneratorOptions struct {
+	// Devices is the number of devices to generate
+	Devices int
+	// Seed makes the output reproducible: equal options produce equal output
+	Seed uint64
+	// UnreachRatio is the share of devices that are unreachable
+	UnreachRatio float64
+	// Timestamp is the newest data point timestamp in Unix seconds, 1700000000 by default
+	Timestamp int64
+}
+
+// GenerateStateList returns the state list response of a synthetic installation
+func GenerateStateList(opts GeneratorOptions) []byte {
+	var buf bytes.Buffer
+	WriteStateList(&buf, opts)
+	return buf.Bytes()
+}
+
+// WriteStateList writes the state list response of a synthetic installation.
+// Device types are drawn from a distribution typical for homes (mostly
+// thermostats, window contacts and switches) and spread across rooms;
+// values and timestamps vary per data point.
+func WriteStateList(w io.Writer, opts GeneratorOptions) error {
+	if opts.Timestamp == 0 {
+		opts.Timestamp = 1700000000
+	}
+	r := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+
+	totalWeight := 0
+	for _, t := range deviceTemplates {
+		totalWeight += t.weight
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\" ?>\n<stateList>\n")
+
+	iseID := 100000
+	nextID := func() int {
+		iseID++
+		return iseID
+	}
+
+	for i := 0; i < opts.Devices; i++ {
+		t := pickTemplate(r.IntN(totalWeight))
+		name := fmt.Sprintf("%s %s %d", t.name, generatorRooms[i%len(generatorRooms)], i/len(generatorRooms)+1)
+		address := fmt.Sprintf("%s%05X", t.addressPrefix, i+1)
+		unreach := r.Float64() < opts.UnreachRatio
+
+		fmt.Fprintf(bw, "\t<device name=\"%s\" ise_id=\"%d\" unreach=\"%t\" config_pending=\"false\">\n", escapeAttr(name), nextID(), unreach)
+		for _, ch := range t.channels {
+			fmt.Fprintf(bw, "\t\t<channel name=\"%s:%d\" ise_id=\"%d\" index=\"%d\" visible=\"true\" operate=\"true\">\n", escapeAttr(name), ch.index, nextID(), ch.index)
+			for _, dp := range ch.dataPoints {
+				value := dp.value(r)
+				if dp.dpType == "UNREACH" {
+					value = strconv.FormatBool(unreach)
+				}
+				timestamp := int64(0)
+				if value != "" {
+					timestamp = opts.Timestamp - r.Int64N(86400)
+				}
+				fmt.Fprintf(bw, "\t\t\t<datapoint name=\"%s.%s:%d.%s\" type=\"%s\" ise_id=\"%d\" value=\"%s\" valuetype=\"%d\" valueunit=\"%s\" timestamp=\"%d\" operations=\"5\"/>\n",
+					t.iface, address, ch.index, dp.dpType, dp.dpType, nextID(), escapeAttr(value), dp.valueType, escapeAttr(dp.unit), timestamp)
+			}
+			bw.WriteString("\t\t</channel>\n")
+		}
+		bw.WriteString("\t</device>\n")
+	}
+
+	bw.WriteString("</stateList>\n")
+	return bw.Flush()
+}
+
+// pickTemplate returns the device template for a weighted random number
+func pickTemplate(n int) deviceTemplate {
+	for _, t := range deviceTemplates {
+		if n < t.weight {
+			return t
+		}
+		n -= t.weight
+	}
+	return deviceTemplates[len(deviceTemplates)-1]
+}
+
+// deviceTemplate describes a device type of a synthetic installation
+type deviceTemplate struct {
+	deviceType    string
+	name          string
+	iface         string
+	addressPrefix string
+	weight        int
+	channels      []channelTemplate
+}
+
+// channelTemplate describes a channel of a device template
+type channelTemplate struct {
+	index      int
+	dataPoints []dataPointTemplate
+}
+
+// dataPointTemplate describes a data point of a channel template
+type dataPointTemplate struct {
+	dpType    string
+	valueType int
+	unit      string
+	value     func(r *rand.Rand) string
+}
+
+// value generators for data point templates
+func fixed(value string) func(*rand.Rand) string {
+	return func(*rand.Rand) string { return value }
+}
+
+func randomBool(probability float64) func(*rand.Rand) string {
+	return func(r *rand.Rand) string { return strconv.FormatBool(r.Float64() < probability) }
+}
+
+func randomFloat(min, max float64, decimals int) func(*rand.Rand) string {
+	return func(r *rand.Rand) string {
+		return strconv.FormatFloat(min+r.Float64()*(max-min), 'f', decimals, 64)
+	}
+}
+
+func randomInt(min, max int) func(*rand.Rand) string {
+	return func(r *rand.Rand) string { return strconv.Itoa(min + r.IntN(max-min+1)) }
+}
+
+// maintenance channels of battery powered and mains powered devices
+var (
+	hmMaintenance = channelTemplate{0, []dataPointTemplate{
+		{"CONFIG_PENDING", 2, "", fixed("false")},
+		{"DUTYCYCLE", 2, "", fixed("false")},
+		{"LOWBAT", 2, "", randomBool(0.05)},
+		{"RSSI_DEVICE", 8, "", randomInt(-95, -45)},
+		{"RSSI_PEER", 8, "", randomInt(-95, -45)},
+		{"UNREACH", 2, "", fixed("false")},
+	}}
+	hmMainsMaintenance = channelTemplate{0, []dataPointTemplate{
+		{"CONFIG_PENDING", 2, "", fixed("false")},
+		{"DUTYCYCLE", 2, "", fixed("false")},
+		{"RSSI_DEVICE", 8, "", randomInt(-85, -40)},
+		{"RSSI_PEER", 8, "", randomInt(-85, -40)},
+		{"UNREACH", 2, "", fixed("false")},
+	}}
+	hmipMaintenance = channelTemplate{0, []dataPointTemplate{
+		{"CONFIG_PENDING", 2, "", fixed("false")},
+		{"DUTY_CYCLE", 2, "", fixed("false")},
+		{"LOW_BAT", 2, "", randomBool(0.05)},
+		{"OPERATING_VOLTAGE", 4, "V", randomFloat(2.2, 3.1, 1)},
+		{"RSSI_DEVICE", 8, "", randomInt(-95, -45)},
+		{"RSSI_PEER", 8, "", randomInt(-95, -45)},
+		{"UNREACH", 2, "", fixed("false")},
+		{"UPDATE_PENDING", 2, "", randomBool(0.02)},
+	}}
+	hmipMainsMaintenance = channelTemplate{0, []dataPointTemplate{
+		{"CONFIG_PENDING", 2, "", fixed("false")},
+		{"DUTY_CYCLE", 2, "", fixed("false")},
+		{"RSSI_DEVICE", 8, "", randomInt(-85, -40)},
+		{"RSSI_PEER", 8, "", randomInt(-85, -40)},
+		{"UNREACH", 2, "", fixed("false")},
+		{"UPDATE_PENDING", 2, "", randomBool(0.02)},
+	}}
+)
+
+// deviceTemplates are the device types of synthetic installations with their relative frequency
+var deviceTemplates = []deviceTemplate{
+	{"HmIP-eTRV-2", "Thermostat", "HmIP-RF", "000A1D89A", 20, []channelTemplate{hmipMaintenance, {1, []dataPointTemplate{
+		{"ACTUAL_TEMPERATURE", 4, "°C", randomFloat(17, 24, 1)},
+		{"BOOST_MODE", 2, "", randomBool(0.05)},
+		{"LEVEL", 4, "100%", randomFloat(0, 1, 6)},
+		{"SET_POINT_MODE", 16, "", randomInt(0, 1)},
+		{"SET_POINT_TEMPERATURE", 4, "°C", randomFloat(17, 23, 1)},
+		{"WINDOW_STATE", 16, "", randomInt(0, 1)},
+	}}}},
+	{"HmIP-SWDO", "Fensterkontakt", "HmIP-RF", "000A1D89B", 20, []channelTemplate{hmipMaintenance, {1, []dataPointTemplate{
+		{"STATE", 16, "", randomInt(0, 1)},
+	}}}},
+	{"HmIP-BSM", "Schalter", "HmIP-RF", "000A1D89C", 10, []channelTemplate{hmipMainsMaintenance, {4, []dataPointTemplate{
+		{"STATE", 2, "", randomBool(0.3)},
+	}}}},
+	{"HmIP-PSM", "Steckdose", "HmIP-RF", "000A1D89D", 8, []channelTemplate{hmipMainsMaintenance, {3, []dataPointTemplate{
+		{"STATE", 2, "", randomBool(0.5)},
+	}}, {6, []dataPointTemplate{
+		{"CURRENT", 4, "mA", randomFloat(0, 5000, 6)},
+		{"ENERGY_COUNTER", 4, "Wh", randomFloat(0, 500000, 6)},
+		{"FREQUENCY", 4, "Hz", randomFloat(49.9, 50.1, 6)},
+		{"POWER", 4, "W", randomFloat(0, 2000, 6)},
+		{"VOLTAGE", 4, "V", randomFloat(225, 235, 6)},
+	}}}},
+	{"HmIP-WTH-2", "Wandthermostat", "HmIP-RF", "000A1D89E", 8, []channelTemplate{hmipMaintenance, {1, []dataPointTemplate{
+		{"ACTUAL_TEMPERATURE", 4, "°C", randomFloat(17, 24, 1)},
+		{"HUMIDITY", 16, "%", randomInt(35, 65)},
+		{"SET_POINT_TEMPERATURE", 4, "°C", randomFloat(17, 23, 1)},
+	}}}},
+	{"HmIP-SWSD", "Rauchmelder", "HmIP-RF", "000A1D89F", 8, []channelTemplate{hmipMaintenance, {1, []dataPointTemplate{
+		{"SMOKE_DETECTOR_ALARM_STATUS", 16, "", fixed("0")},
+		{"SMOKE_DETECTOR_TEST_RESULT", 16, "", fixed("0")},
+	}}}},
+	{"HM-LC-Dim1T-FM", "Dimmer", "BidCos-RF", "MEQ00", 8, []channelTemplate{hmMainsMaintenance, {1, []dataPointTemplate{
+		{"LEVEL", 4, "100%", randomFloat(0, 1, 6)},
+		{"WORKING", 2, "", fixed("false")},
+	}}}},
+	{"HM-CC-RT-DN", "Heizung", "BidCos-RF", "MEQ01", 6, []channelTemplate{hmMaintenance, {4, []dataPointTemplate{
+		{"ACTUAL_TEMPERATURE", 4, "°C", randomFloat(17, 24, 1)},
+		{"BATTERY_STATE", 4, "V", randomFloat(2.2, 3.1, 1)},
+		{"CONTROL_MODE", 16, "", randomInt(0, 3)},
+		{"SET_TEMPERATURE", 4, "°C", randomFloat(17, 23, 1)},
+		{"VALVE_STATE", 16, "%", randomInt(0, 100)},
+	}}}},
+	{"HM-Sec-SCo", "Fenster", "BidCos-RF", "MEQ02", 6, []channelTemplate{hmMaintenance, {1, []dataPointTemplate{
+		{"ERROR", 16, "", fixed("0")},
+		{"STATE", 2, "", randomBool(0.1)},
+	}}}},
+	{"HM-WDS40-TH-I-2", "Klima", "BidCos-RF", "MEQ03", 6, []channelTemplate{hmMaintenance, {1, []dataPointTemplate{
+		{"HUMIDITY", 16, "%", randomInt(35, 65)},
+		{"TEMPERATURE", 4, "°C", randomFloat(15, 25, 1)},
+	}}}},
+}
+
+// generatorRooms are the rooms devices of synthetic installations are named after
+var generatorRooms = []string{"Küche", "Wohnzimmer", "Schlafzimmer", "Bad", "Büro", "Flur", "Keller", "Garage", "Dachboden", "Gästezimmer", "Kinderzimmer", "Hauswirtschaftsraum"}
@@ -0,0 +1,53 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+func TestGenerateStateList(t *testing.T) {
+	opts := GeneratorOptions{Devices: 2000, Seed: 7, UnreachRatio: 0.05}
+	body := GenerateStateList(opts)
+
+	if !bytes.Equal(body, GenerateStateList(opts)) {
+		t.Error("expected equal output for equal options")
+	}
+	if bytes.Equal(body, GenerateStateList(GeneratorOptions{Devices: 2000, Seed: 8, UnreachRatio: 0.05})) {
+		t.Error("expected different output for different seeds")
+	}
+
+	var result homematic.StateListResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		t.Fatalf("generated state list is not valid: %v", err)
+	}
+	if len(result.Devices) != 2000 {
+		t.Fatalf("expected 2000 devices, got %d", len(result.Devices))
+	}
+
+	types := make(map[string]int)
+	unreach := 0
+	ids := make(map[string]bool)
+	for _, device := range result.Devices {
+		if device.Unreach {
+			unreach++
+		}
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				if ids[dp.IseID] {
+					t.Fatalf("duplicate ise_id %s", dp.IseID)
+				}
+				ids[dp.IseID] = true
+				types[dp.Type]++
+			}
+		}
+	}
+	if unreach < 50 || unreach > 150 {
+		t.Errorf("expected about 100 unreachable devices, got %d", unreach)
+	}
+	if types["ACTUAL_TEMPERATURE"] < 400 || types["UNREACH"] != 2000 {
+		t.Errorf("unexpected data point distribution: %v", types)
+	}
+}
@@ -0,0 +1,432 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// Behavior simulates device behavior in a Server. It is called with the
+// number of the current step before every state response, i.e. every
+// statelist.cgi or state.cgi request advances the simulation by one step.
+type Behavior func(state *State, step int)
+
+// Server is a mock CCU serving the responses of a source. Unlike
+// Handler, its state list is live: statechange.cgi updates data point values
+// in subsequent statelist.cgi and state.cgi responses and system variable
+// values in sysvarlist.cgi and sysvar.cgi responses, and behaviors can
+// script changes such as thermostats drifting toward their set point or
+// devices becoming unreachable.
+type Server struct {
+	source    Source
+	behaviors []Behavior
+
+	mu    sync.Mutex
+	state *State
+	step  int
+}
+
+// State is the simulated state list of a Server
+type State struct {
+	stateList  node
+	devices    map[string]*node
+	dataPoints map[string]*node
+	sysVarList node
+	sysVars    map[string]*node
+	now        func() time.Time
+}
+
+// node is a generic XML element preserving all attributes of the fixture responses
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []node     `xml:",any"`
+}
+
+// NewServer creates a mock CCU for the given source with optional behaviors
+func NewServer(source Source, behaviors ...Behavior) (*Server, error) {
+	body, err := Load(source, "statelist.cgi")
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{
+		devices:    make(map[string]*node),
+		dataPoints: make(map[string]*node),
+		sysVars:    make(map[string]*node),
+		now:        time.Now,
+	}
+	if err := decodeNode(body, &state.stateList); err != nil {
+		return nil, fmt.Errorf("failed to parse state list of %s: %w", source, err)
+	}
+
+	for i := range state.stateList.Nodes {
+		device := &state.stateList.Nodes[i]
+		state.devices[device.attr("ise_id")] = device
+		for j := range device.Nodes {
+			channel := &device.Nodes[j]
+			for k := range channel.Nodes {
+				dp := &channel.Nodes[k]
+				state.dataPoints[dp.attr("ise_id")] = dp
+			}
+		}
+	}
+
+	// sources without system variables serve an empty list
+	state.sysVarList = node{XMLName: xml.Name{Local: "systemVariables"}}
+	if body, err := Load(source, "sysvarlist.cgi"); err == nil {
+		if err := decodeNode(body, &state.sysVarList); err != nil {
+			return nil, fmt.Errorf("failed to parse system variables of %s: %w", source, err)
+		}
+	}
+	for i := range state.sysVarList.Nodes {
+		sysVar := &state.sysVarList.Nodes[i]
+		state.sysVars[sysVar.attr("ise_id")] = sysVar
+	}
+
+	return &Server{source: source, behaviors: behaviors, state: state}, nil
+}
+
+// decodeNode parses an ISO-8859-1 encoded response
+func decodeNode(body []byte, n *node) error {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return transform.NewReader(input, charmap.ISO8859_1.NewDecoder()), nil
+	}
+	return decoder.Decode(n)
+}
+
+// Value returns the current value of a data point or system variable
+func (s *Server) Value(iseID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.Value(iseID)
+}
+
+// SetValue changes the value of a data point or system variable
+func (s *Server) SetValue(iseID, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.SetValue(iseID, value)
+}
+
+// SetUnreach marks a device as (un)reachable
+func (s *Server) SetUnreach(deviceID string, unreach bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.SetUnreach(deviceID, unreach)
+}
+
+// Steps returns the number of simulation steps taken so far
+func (s *Server) Steps() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.step
+}
+
+// ServeHTTP serves the XML-API below /addons/xmlapi/
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := strings.CutPrefix(r.URL.Path, "/addons/xmlapi/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch endpoint {
+	case "statelist.cgi":
+		s.writeState(w, nil)
+	case "state.cgi":
+		s.writeState(w, newStateFilter(r))
+	case "statechange.cgi":
+		s.changeState(w, r)
+	case "sysvarlist.cgi":
+		s.writeSysVars(w, "")
+	case "sysvar.cgi":
+		s.writeSysVars(w, r.URL.Query().Get("ise_id"))
+	default:
+		Handler(s.source).ServeHTTP(w, r)
+	}
+}
+
+// writeState advances the simulation and writes the (filtered) state list
+func (s *Server) writeState(w http.ResponseWriter, filter *stateFilter) {
+	s.mu.Lock()
+	s.step++
+	for _, behavior := range s.behaviors {
+		behavior(s.state, s.step)
+	}
+	stateList := s.state.stateList
+	if filter != nil {
+		stateList.Nodes = filter.apply(stateList.Nodes)
+	}
+	body, err := xml.Marshal(stateList)
+	s.mu.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeXML(w, body)
+}
+
+// writeSysVars writes all system variables, or the one with the given ise_id
+func (s *Server) writeSysVars(w http.ResponseWriter, iseID string) {
+	s.mu.Lock()
+	sysVarList := s.state.sysVarList
+	if iseID != "" {
+		sysVarList.Nodes = nil
+		if sysVar, ok := s.state.sysVars[iseID]; ok {
+			sysVarList.Nodes = []node{*sysVar}
+		}
+	}
+	body, err := xml.Marshal(sysVarList)
+	s.mu.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeXML(w, body)
+}
+
+// changeState implements statechange.cgi
+func (s *Server) changeState(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.URL.Query().Get("ise_id"), ",")
+	// the client sends values in ISO-8859-1
+	newValue, err := charmap.ISO8859_1.NewDecoder().String(r.URL.Query().Get("new_value"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	values := strings.Split(newValue, ",")
+	if len(ids) != len(values) {
+		writeXML(w, []byte("<result><not_found/></result>"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result bytes.Buffer
+	result.WriteString("<result>")
+	for i, id := range ids {
+		if err := s.state.SetValue(id, values[i]); err != nil {
+			result.WriteString("<not_found/>")
+			continue
+		}
+		fmt.Fprintf(&result, `<changed id="%s" new_value="%s"/>`, escapeAttr(id), escapeAttr(values[i]))
+	}
+	result.WriteString("</result>")
+	writeXML(w, result.Bytes())
+}
+
+// Value returns the current value of a data point or system variable
+func (st *State) Value(iseID string) (string, bool) {
+	if dp, ok := st.dataPoints[iseID]; ok {
+		return dp.attr("value"), true
+	}
+	if sysVar, ok := st.sysVars[iseID]; ok {
+		return sysVar.attr("value"), true
+	}
+	return "", false
+}
+
+// SetValue changes the value of a data point or system variable and updates its timestamp
+func (st *State) SetValue(iseID, value string) error {
+	timestamp := strconv.FormatInt(st.now().Unix(), 10)
+	if dp, ok := st.dataPoints[iseID]; ok {
+		dp.setAttr("value", value)
+		dp.setAttr("timestamp", timestamp)
+		return nil
+	}
+	if sysVar, ok := st.sysVars[iseID]; ok {
+		sysVar.setAttr("value", value)
+		sysVar.setAttr("variable", value)
+		sysVar.setAttr("timestamp", timestamp)
+		return nil
+	}
+	return fmt.Errorf("unknown data point %s", iseID)
+}
+
+// SetUnreach marks a device as (un)reachable, updating the device attribute
+// and the UNREACH data point of its maintenance channel
+func (st *State) SetUnreach(deviceID string, unreach bool) error {
+	device, ok := st.devices[deviceID]
+	if !ok {
+		return fmt.Errorf("unknown device %s", deviceID)
+	}
+	value := strconv.FormatBool(unreach)
+	device.setAttr("unreach", value)
+	for i := range device.Nodes {
+		if dp := device.Nodes[i].dataPoint("UNREACH"); dp != nil {
+			return st.SetValue(dp.attr("ise_id"), value)
+		}
+	}
+	return nil
+}
+
+// ThermostatDrift moves the ACTUAL_TEMPERATURE of every channel with a
+// SET_POINT_TEMPERATURE toward the set point by at most delta degrees per step
+func ThermostatDrift(delta float64) Behavior {
+	return func(st *State, step int) {
+		for i := range st.stateList.Nodes {
+			device := &st.stateList.Nodes[i]
+			for j := range device.Nodes {
+				actual := device.Nodes[j].dataPoint("ACTUAL_TEMPERATURE")
+				setPoint := device.Nodes[j].dataPoint("SET_POINT_TEMPERATURE")
+				if actual == nil || setPoint == nil {
+					continue
+				}
+				current, err := strconv.ParseFloat(actual.attr("value"), 64)
+				if err != nil {
+					continue
+				}
+				target, err := strconv.ParseFloat(setPoint.attr("value"), 64)
+				if err != nil || current == target {
+					continue
+				}
+				next := target
+				if math.Abs(target-current) > delta {
+					next = math.Round((current+math.Copysign(delta, target-current))*1000) / 1000
+				}
+				st.SetValue(actual.attr("ise_id"), strconv.FormatFloat(next, 'f', -1, 64))
+			}
+		}
+	}
+}
+
+// UnreachAt makes a device unreachable at the given step; a positive
+// duration makes it reachable again after that many steps
+func UnreachAt(deviceID string, step, duration int) Behavior {
+	return func(st *State, current int) {
+		switch {
+		case current == step:
+			st.SetUnreach(deviceID, true)
+		case duration > 0 && current == step+duration:
+			st.SetUnreach(deviceID, false)
+		}
+	}
+}
+
+// SetAt sets a data point to a value at the given step
+func SetAt(step int, iseID, value string) Behavior {
+	return func(st *State, current int) {
+		if current == step {
+			st.SetValue(iseID, value)
+		}
+	}
+}
+
+// stateFilter selects devices, channels and data points for state.cgi
+type stateFilter struct {
+	devices, channels, dataPoints map[string]bool
+}
+
+// newStateFilter creates a filter from the device_id, channel_id and datapoint_id parameters
+func newStateFilter(r *http.Request) *stateFilter {
+	ids := func(name string) map[string]bool {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			return nil
+		}
+		set := make(map[string]bool)
+		for _, id := range strings.Split(value, ",") {
+			set[id] = true
+		}
+		return set
+	}
+	return &stateFilter{devices: ids("device_id"), channels: ids("channel_id"), dataPoints: ids("datapoint_id")}
+}
+
+// apply returns copies of the devices narrowed to the selected elements
+func (f *stateFilter) apply(devices []node) []node {
+	var result []node
+	for _, device := range devices {
+		if f.devices[device.attr("ise_id")] {
+			result = append(result, device)
+			continue
+		}
+
+		var channels []node
+		for _, channel := range device.Nodes {
+			if f.channels[channel.attr("ise_id")] {
+				channels = append(channels, channel)
+				continue
+			}
+			var dataPoints []node
+			for _, dp := range channel.Nodes {
+				if f.dataPoints[dp.attr("ise_id")] {
+					dataPoints = append(dataPoints, dp)
+				}
+			}
+			if len(dataPoints) > 0 {
+				channel.Nodes = dataPoints
+				channels = append(channels, channel)
+			}
+		}
+		if len(channels) > 0 {
+			device.Nodes = channels
+			result = append(result, device)
+		}
+	}
+	return result
+}
+
+// attr returns the value of an attribute
+func (n *node) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// setAttr sets the value of an attribute, adding it if missing
+func (n *node) setAttr(name, value string) {
+	for i := range n.Attrs {
+		if n.Attrs[i].Name.Local == name {
+			n.Attrs[i].Value = value
+			return
+		}
+	}
+	n.Attrs = append(n.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// dataPoint returns the data point of a channel with the given type
+func (n *node) dataPoint(dpType string) *node {
+	for i := range n.Nodes {
+		if n.Nodes[i].attr("type") == dpType {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// writeXML writes an UTF-8 XML response
+func writeXML(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "text/xml; charset=UTF-8")
+	io.WriteString(w, `<?xml version="1.0" encoding="UTF-8" ?>`)
+	w.Write(body)
+}
+
+// escapeAttr escapes a value for use in an XML attribute
+func escapeAttr(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package fixtures
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// findDataPoint returns the data point with the given ise_id
+func findDataPoint(t *testing.T, devices []homematic.Device, iseID string) homematic.DataPoint {
+	t.Helper()
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				if dp.IseID == iseID {
+					return dp
+				}
+			}
+		}
+	}
+	t.Fatalf("data point %s not found", iseID)
+	return homematic.DataPoint{}
+}
+
+func TestServerStateChange(t *testing.T) {
+	mock, err := NewServer(CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	client := homematic.NewClient(server.URL, "token")
+
+	if err := client.ChangeState([]string{"2456"}, []string{"0.75"}); err != nil {
+		t.Fatalf("ChangeState failed: %v", err)
+	}
+
+	devices, err := client.GetStateList("", false, false)
+	if err != nil {
+		t.Fatalf("GetStateList failed: %v", err)
+	}
+	if len(devices) != 6 || devices[1].Name != "Fensterkontakt Küche" {
+		t.Errorf("unexpected devices: %d", len(devices))
+	}
+	if dp := findDataPoint(t, devices, "2456"); dp.Value != "0.75" || dp.Timestamp == 1699976098 {
+		t.Errorf("expected changed dimmer level, got %+v", dp)
+	}
+
+	devices, err = client.GetState(nil, nil, []string{"2456"})
+	if err != nil {
+		t.Fatalf("GetState failed: %v", err)
+	}
+	if len(devices) != 1 || len(devices[0].Channels) != 1 || len(devices[0].Channels[0].DataPoints) != 1 {
+		t.Fatalf("expected a single data point, got %+v", devices)
+	}
+
+	if _, err := client.GetProgramList(); err != nil {
+		t.Errorf("fixed endpoints should still be served: %v", err)
+	}
+}
+
+func TestServerBehaviors(t *testing.T) {
+	mock, err := NewServer(CCU3,
+		ThermostatDrift(0.2),
+		UnreachAt("2430", 2, 2),
+		SetAt(3, "2456", "1.0"),
+	)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	client := homematic.NewClient(server.URL, "token")
+
+	// actual 21.5, set point 21.0
+	expected := []struct {
+		temperature string
+		unreach     bool
+		level       string
+	}{
+		{"21.3", false, "0.200000"},
+		{"21.1", true, "0.200000"},
+		{"21", true, "1.0"},
+		{"21", false, "1.0"},
+	}
+	for i, want := range expected {
+		devices, err := client.GetStateList("", false, false)
+		if err != nil {
+			t.Fatalf("GetStateList failed: %v", err)
+		}
+		if dp := findDataPoint(t, devices, "2412"); dp.Value != want.temperature {
+			t.Errorf("step %d: expected temperature %s, got %s", i+1, want.temperature, dp.Value)
+		}
+		if dp := findDataPoint(t, devices, "2456"); dp.Value != want.level {
+			t.Errorf("step %d: expected level %s, got %s", i+1, want.level, dp.Value)
+		}
+		for _, device := range devices {
+			if device.IseID == "2430" && (device.Unreach != want.unreach || device.Maintenance.Unreach != want.unreach) {
+				t.Errorf("step %d: expected unreach %v, got %+v", i+1, want.unreach, device.Maintenance)
+			}
+		}
+	}
+
+	if mock.Steps() != 4 {
+		t.Errorf("expected 4 steps, got %d", mock.Steps())
+	}
+	if err := mock.SetValue("2412", "18.5"); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := mock.Value("2412"); value != "18.5" {
+		t.Errorf("unexpected value: %s", value)
+	}
+	if err := mock.SetUnreach("9999", true); err == nil {
+		t.Error("expected error for unknown device")
+	}
+}
+
+func TestServerSystemVariables(t *testing.T) {
+	mock, err := NewServer(CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	client := homematic.NewClient(server.URL, "token")
+
+	if err := client.ChangeState([]string{"2499"}, []string{"42.5"}); err != nil {
+		t.Fatalf("ChangeState failed: %v", err)
+	}
+	if value, _ := mock.Value("2499"); value != "42.5" {
+		t.Errorf("expected changed value, got %q", value)
+	}
+
+	sysVar, err := client.GetSystemVariable("2499", false)
+	if err != nil {
+		t.Fatalf("GetSystemVariable failed: %v", err)
+	}
+	if sysVar.Name != "DutyCycle" || sysVar.Value != "42.5" || sysVar.Timestamp == 1699992800 {
+		t.Errorf("expected changed system variable, got %+v", sysVar)
+	}
+
+	sysVars, err := client.GetSystemVariableList(true)
+	if err != nil {
+		t.Fatalf("GetSystemVariableList failed: %v", err)
+	}
+	if len(sysVars) != 5 || sysVars[2].Value != "42.5" {
+		t.Errorf("expected the changed value in the list, got %+v", sysVars)
+	}
+}
@@ -0,0 +1,131 @@
+package homematic
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestClientWithFixtures(t *testing.T) {
+	for _, source := range fixtures.Sources() {
+		t.Run(string(source), func(t *testing.T) {
+			server := httptest.NewServer(fixtures.Handler(source))
+			defer server.Close()
+			client := NewClient(server.URL, "token")
+
+			if _, err := client.GetVersion(); err != nil {
+				t.Errorf("GetVersion failed: %v", err)
+			}
+
+			devices, err := client.GetDeviceList(nil, false, false)
+			if err != nil || len(devices) == 0 {
+				t.Fatalf("GetDeviceList failed: %v (%d devices)", err, len(devices))
+			}
+
+			states, err := client.GetStateList("", false, false)
+			if err != nil || len(states) != len(devices) {
+				t.Fatalf("GetStateList failed: %v (%d devices)", err, len(states))
+			}
+			for _, device := range states {
+				if !strings.Contains(device.Name, "RCV") && device.Maintenance == nil {
+					t.Errorf("expected maintenance info for %s", device.Name)
+				}
+			}
+
+			rooms, err := client.GetRoomList()
+			if err != nil || len(rooms) == 0 {
+				t.Errorf("GetRoomList failed: %v", err)
+			}
+			if _, err := client.GetFunctionList(); err != nil {
+				t.Errorf("GetFunctionList failed: %v", err)
+			}
+			if _, err := client.GetProgramList(); err != nil {
+				t.Errorf("GetProgramList failed: %v", err)
+			}
+
+			sysVars, err := client.GetSystemVariableList(true)
+			if err != nil {
+				t.Fatalf("GetSystemVariableList failed: %v", err)
+			}
+			mode, err := FindSystemVariable(sysVars, "Heizungsmodus")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode.DisplayValue() != "Komfort" {
+				t.Errorf("expected enum label Komfort, got %q", mode.DisplayValue())
+			}
+			if mode.EnumValues()[2] != "Öko" {
+				t.Errorf("expected ISO-8859-1 label to be decoded, got %q", mode.EnumValues()[2])
+			}
+		})
+	}
+}
+
+func TestGetStateListLarge(t *testing.T) {
+	body := fixtures.LargeStateList()
+	server := httptest.NewServer(staticHandler(body))
+	defer server.Close()
+
+	devices, err := NewClient(server.URL, "token").GetStateList("", false, false)
+	if err != nil {
+		t.Fatalf("GetStateList failed: %v", err)
+	}
+	if len(devices) != 400 {
+		t.Errorf("expected 400 devices, got %d", len(devices))
+	}
+}
+
+func BenchmarkGetStateList(b *testing.B) {
+	for _, n := range []int{1000, 5000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			body := fixtures.GenerateStateList(fixtures.GeneratorOptions{Devices: n, Seed: 1})
+			server := httptest.NewServer(staticHandler(body))
+			defer server.Close()
+			client := NewClient(server.URL, "token")
+
+			b.SetBytes(int64(len(body)))
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := client.GetStateList("", false, false); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStateMetrics(b *testing.B) {
+	body := fixtures.GenerateStateList(fixtures.GeneratorOptions{Devices: 2000, Seed: 1})
+	var result StateListResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		b.Fatal(err)
+	}
+	attachMaintenanceInfo(result.Devices)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := (&StateMetrics{}).Write(io.Discard, result.Devices); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetStateListLatin1(b *testing.B) {
+	body := fixtures.LargeStateList()
+	server := httptest.NewServer(staticHandler(body))
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := client.GetStateList("", false, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
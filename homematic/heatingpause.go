@@ -0,0 +1,234 @@
+package homematic
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultFrostSetPoint is the set point in °C thermostats are lowered to while a window is open
+const DefaultFrostSetPoint = 5.0
+
+// HeatingPauseAction is the action taken by a HeatingPause check
+type HeatingPauseAction string
+
+const (
+	HeatingPauseNone     HeatingPauseAction = ""
+	HeatingPauseStarted  HeatingPauseAction = "paused"
+	HeatingPauseRestored HeatingPauseAction = "restored"
+	// HeatingPauseExpired restores the set points of a window that stayed
+	// open longer than the maximum pause
+	HeatingPauseExpired HeatingPauseAction = "expired"
+)
+
+// HeatingPauseStatus is the result of a HeatingPause check
+type HeatingPauseStatus struct {
+	Open   bool               `json:"open"`
+	Paused bool               `json:"paused"`
+	Action HeatingPauseAction `json:"action,omitempty"`
+	// SetPoints holds the set points that were saved when the pause started
+	SetPoints map[string]string `json:"set_points,omitempty"`
+	At        time.Time         `json:"at"`
+}
+
+// HeatingPause lowers the thermostats of a room to a frost protection set
+// point while any of its window contacts is open and restores the previous
+// set points when all windows are closed again
+type HeatingPause struct {
+	Client *Client
+	// Contacts are the ise_ids of the STATE data points of the window
+	// contacts; rotary handles count as open when tilted
+	Contacts []string
+	// SetPoints are the ise_ids of the set point data points of the thermostats
+	SetPoints []string
+
+	// FrostSetPoint defaults to DefaultFrostSetPoint
+	FrostSetPoint float64
+	// MaxPause restores the set points of a window left open for longer, e.g.
+	// to keep a forgotten window from cooling down the room; the room is not
+	// paused again until the windows were closed. Zero disables the limit.
+	MaxPause time.Duration
+
+	mu      sync.Mutex
+	paused  bool
+	expired bool
+	since   time.Time
+	saved   map[string]string
+}
+
+// NewHeatingPause creates a heating pause for the given window contact and set point data points
+func NewHeatingPause(client *Client, contacts, setPoints []string) *HeatingPause {
+	return &HeatingPause{Client: client, Contacts: contacts, SetPoints: setPoints}
+}
+
+// NewRoomHeatingPause creates a heating pause for the window contacts and
+// thermostats assigned to a room
+func NewRoomHeatingPause(client *Client, room string) (*HeatingPause, error) {
+	topology, err := client.GetTopology()
+	if err != nil {
+		return nil, err
+	}
+	rooms := channelRooms(topology)
+
+	roles := make(map[string]ChannelRole)
+	var deviceIDs []string
+	for i := range topology.Devices {
+		device := &topology.Devices[i]
+		found := false
+		forEachChannelRole(device, rooms, []string{room}, []ChannelRole{RoleContact, RoleThermostat}, func(ch *Channel, role ChannelRole, _ string) {
+			roles[ch.IseID] = role
+			found = true
+		})
+		if found {
+			deviceIDs = append(deviceIDs, device.IseID)
+		}
+	}
+	if len(deviceIDs) == 0 {
+		return nil, fmt.Errorf("no window contacts or thermostats in room %q", room)
+	}
+
+	devices, err := client.GetState(deviceIDs, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	pause := NewHeatingPause(client, nil, nil)
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				switch typ := dataPointType(dp); {
+				case roles[ch.IseID] == RoleContact && typ == "STATE":
+					pause.Contacts = append(pause.Contacts, dp.IseID)
+				case roles[ch.IseID] == RoleThermostat && (typ == "SET_POINT_TEMPERATURE" || typ == "SET_TEMPERATURE"):
+					pause.SetPoints = append(pause.SetPoints, dp.IseID)
+				}
+			}
+		}
+	}
+	if len(pause.Contacts) == 0 || len(pause.SetPoints) == 0 {
+		return nil, fmt.Errorf("room %q needs a window contact and a thermostat", room)
+	}
+	return pause, nil
+}
+
+// Paused returns whether the set points are currently lowered
+func (p *HeatingPause) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.paused
+}
+
+// Check reads the window contacts and set points and lowers or restores the
+// set points as needed. A failed restore is retried with the next check.
+func (p *HeatingPause) Check(now time.Time) (*HeatingPauseStatus, error) {
+	ids := append(append([]string{}, p.Contacts...), p.SetPoints...)
+	devices, err := p.Client.GetState(nil, nil, ids)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(ids))
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				values[dp.IseID] = dp.Value
+			}
+		}
+	}
+
+	status := &HeatingPauseStatus{At: now}
+	for _, id := range p.Contacts {
+		value, ok := values[id]
+		if !ok {
+			return nil, fmt.Errorf("no value for window contact %s", id)
+		}
+		if windowOpen(value) {
+			status.Open = true
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !status.Open {
+		p.expired = false
+	}
+
+	switch {
+	case p.paused && !status.Open:
+		status.Action = HeatingPauseRestored
+		err = p.restore()
+	case p.paused && p.MaxPause > 0 && now.Sub(p.since) >= p.MaxPause:
+		status.Action = HeatingPauseExpired
+		p.expired = true
+		err = p.restore()
+	case !p.paused && status.Open && !p.expired:
+		status.Action = HeatingPauseStarted
+		err = p.pause(values, now)
+	}
+	status.Paused = p.paused
+	if p.paused {
+		status.SetPoints = p.saved
+	}
+	return status, err
+}
+
+// pause saves the current set points and lowers them to the frost protection set point
+func (p *HeatingPause) pause(values map[string]string, now time.Time) error {
+	saved := make(map[string]string, len(p.SetPoints))
+	for _, id := range p.SetPoints {
+		value, ok := values[id]
+		if !ok {
+			return fmt.Errorf("no value for set point %s", id)
+		}
+		saved[id] = value
+	}
+
+	frost := p.FrostSetPoint
+	if frost == 0 {
+		frost = DefaultFrostSetPoint
+	}
+	newValues := make([]string, len(p.SetPoints))
+	for i := range newValues {
+		newValues[i] = strconv.FormatFloat(frost, 'f', -1, 64)
+	}
+
+	// mark the pause before writing, so that a partially failed write is
+	// restored once the window is closed
+	p.paused = true
+	p.since = now
+	p.saved = saved
+	if err := p.Client.ChangeState(p.SetPoints, newValues); err != nil {
+		return fmt.Errorf("failed to lower set points: %w", err)
+	}
+	return nil
+}
+
+// restore writes the saved set points back
+func (p *HeatingPause) restore() error {
+	ids := make([]string, 0, len(p.saved))
+	values := make([]string, 0, len(p.saved))
+	for _, id := range p.SetPoints {
+		if value, ok := p.saved[id]; ok {
+			ids = append(ids, id)
+			values = append(values, value)
+		}
+	}
+	if len(ids) == 0 {
+		p.paused = false
+		return nil
+	}
+	if err := p.Client.ChangeState(ids, values); err != nil {
+		return fmt.Errorf("failed to restore set points: %w", err)
+	}
+	p.paused = false
+	p.saved = nil
+	return nil
+}
+
+// windowOpen interprets the STATE of a window contact; rotary handles report
+// 0 for closed, 1 for tilted and 2 for open
+func windowOpen(value string) bool {
+	v, ok := numericValue(value)
+	return ok && v > 0
+}
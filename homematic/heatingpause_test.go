@@ -0,0 +1,77 @@
+package homematic
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestHeatingPause(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	pause := NewHeatingPause(NewClient(server.URL, "token"), []string{"2429", "2484"}, []string{"2416"})
+	pause.MaxPause = 30 * time.Minute
+	at := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	check := func(at time.Time, action HeatingPauseAction, paused bool, setPoint string) {
+		t.Helper()
+		status, err := pause.Check(at)
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		if status.Action != action || status.Paused != paused {
+			t.Errorf("expected action %q and paused %v, got %+v", action, paused, status)
+		}
+		if value, _ := mock.Value("2416"); value != setPoint {
+			t.Errorf("expected set point %s, got %s", setPoint, value)
+		}
+	}
+
+	check(at, HeatingPauseNone, false, "21.000000")
+
+	// a tilted rotary handle or an open contact pauses the heating
+	mock.SetValue("2429", "1")
+	check(at.Add(time.Minute), HeatingPauseStarted, true, "5")
+	mock.SetValue("2484", "true")
+	check(at.Add(2*time.Minute), HeatingPauseNone, true, "5")
+
+	// closing one of two windows keeps the pause
+	mock.SetValue("2429", "0")
+	check(at.Add(3*time.Minute), HeatingPauseNone, true, "5")
+	mock.SetValue("2484", "false")
+	check(at.Add(4*time.Minute), HeatingPauseRestored, false, "21.000000")
+
+	// a window left open is restored after the maximum pause and not paused again until closed
+	mock.SetValue("2484", "true")
+	check(at.Add(5*time.Minute), HeatingPauseStarted, true, "5")
+	check(at.Add(35*time.Minute), HeatingPauseExpired, false, "21.000000")
+	check(at.Add(40*time.Minute), HeatingPauseNone, false, "21.000000")
+	mock.SetValue("2484", "false")
+	check(at.Add(41*time.Minute), HeatingPauseNone, false, "21.000000")
+	mock.SetValue("2484", "true")
+	check(at.Add(42*time.Minute), HeatingPauseStarted, true, "5")
+}
+
+func TestNewRoomHeatingPause(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	// the kitchen has a window contact, but no thermostat
+	if _, err := NewRoomHeatingPause(NewClient(server.URL, "token"), "Küche"); err == nil {
+		t.Error("expected an error for a room without thermostat")
+	}
+	if _, err := NewRoomHeatingPause(NewClient(server.URL, "token"), "Keller"); err == nil {
+		t.Error("expected an error for an unknown room")
+	}
+}
@@ -0,0 +1,112 @@
+package homematic
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// homebridgeServices maps channel roles to HomeKit service types
+var homebridgeServices = map[ChannelRole]string{
+	RoleSwitch:     "Switch",
+	RoleDimmer:     "Lightbulb",
+	RoleBlind:      "WindowCovering",
+	RoleThermostat: "Thermostat",
+	RoleClimate:    "TemperatureSensor",
+	RoleContact:    "ContactSensor",
+	RoleMotion:     "MotionSensor",
+	RoleSmoke:      "SmokeSensor",
+	RoleWater:      "LeakSensor",
+	RoleLock:       "LockMechanism",
+	RoleKey:        "StatelessProgrammableSwitch",
+}
+
+// HomebridgeOptions configures the generated Homebridge configuration
+type HomebridgeOptions struct {
+	// Name is the name of the platform, "HomeMatic CCU" by default
+	Name string
+	// CCU is the host name or IP address of the CCU
+	CCU string
+	// Rooms restricts the accessories to channels in the given rooms
+	Rooms []string
+	// Roles restricts the accessories to channels with the given roles
+	Roles []ChannelRole
+}
+
+// HomebridgeConfig is a Homebridge config.json with a HomeMatic platform
+type HomebridgeConfig struct {
+	Platforms []HomebridgePlatform `json:"platforms"`
+}
+
+// HomebridgePlatform is the HomeMatic platform entry of a Homebridge configuration
+type HomebridgePlatform struct {
+	Platform    string                `json:"platform"`
+	Name        string                `json:"name"`
+	CCUIP       string                `json:"ccu_ip"`
+	Accessories []HomebridgeAccessory `json:"accessories"`
+}
+
+// HomebridgeAccessory maps a channel to a HomeKit service
+type HomebridgeAccessory struct {
+	Name       string      `json:"name"`
+	Type       string      `json:"type"`
+	Address    string      `json:"address"`
+	IseID      string      `json:"ise_id"`
+	DeviceType string      `json:"device_type"`
+	Role       ChannelRole `json:"role"`
+	Room       string      `json:"room,omitempty"`
+}
+
+// NewHomebridgeConfig creates a Homebridge configuration with one accessory
+// per classified channel of the topology; channels without a HomeKit
+// counterpart are skipped
+func NewHomebridgeConfig(topology *Topology, opts HomebridgeOptions) *HomebridgeConfig {
+	name := opts.Name
+	if name == "" {
+		name = "HomeMatic CCU"
+	}
+
+	rooms := channelRooms(topology)
+	platform := HomebridgePlatform{Platform: "HomeMatic", Name: name, CCUIP: opts.CCU, Accessories: []HomebridgeAccessory{}}
+	for i := range topology.Devices {
+		device := &topology.Devices[i]
+		var accessories []HomebridgeAccessory
+		var indexes []int
+		forEachChannelRole(device, rooms, opts.Rooms, opts.Roles, func(ch *Channel, role ChannelRole, room string) {
+			service, ok := homebridgeServices[role]
+			if !ok {
+				return
+			}
+			accessories = append(accessories, HomebridgeAccessory{
+				Name:       ch.Name,
+				Type:       service,
+				Address:    ch.Address,
+				IseID:      ch.IseID,
+				DeviceType: device.DeviceType,
+				Role:       role,
+				Room:       room,
+			})
+			indexes = append(indexes, ch.Index)
+		})
+
+		// replace default channel names like "Device:1" by the device name
+		for j := range accessories {
+			a := &accessories[j]
+			if a.Name != "" && !strings.HasPrefix(a.Name, device.Name+":") {
+				continue
+			}
+			a.Name = device.Name
+			if len(accessories) > 1 {
+				a.Name = fmt.Sprintf("%s %d", device.Name, indexes[j])
+			}
+		}
+		platform.Accessories = append(platform.Accessories, accessories...)
+	}
+
+	return &HomebridgeConfig{Platforms: []HomebridgePlatform{platform}}
+}
+
+// ExportHomebridgeConfig writes the Homebridge configuration of a topology as indented JSON
+func ExportHomebridgeConfig(w io.Writer, topology *Topology, opts HomebridgeOptions) error {
+	return export(w, ExportJSON, NewHomebridgeConfig(topology, opts), nil)
+}
@@ -0,0 +1,68 @@
+package homematic
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestHomebridgeConfig(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	topology, err := NewClient(server.URL, "token").GetTopology()
+	if err != nil {
+		t.Fatalf("GetTopology failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportHomebridgeConfig(&buf, topology, HomebridgeOptions{CCU: "192.168.1.100"}); err != nil {
+		t.Fatalf("ExportHomebridgeConfig failed: %v", err)
+	}
+	var config HomebridgeConfig
+	if err := json.Unmarshal(buf.Bytes(), &config); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	if len(config.Platforms) != 1 {
+		t.Fatalf("expected one platform, got %d", len(config.Platforms))
+	}
+	platform := config.Platforms[0]
+	if platform.Platform != "HomeMatic" || platform.Name != "HomeMatic CCU" || platform.CCUIP != "192.168.1.100" {
+		t.Errorf("unexpected platform: %+v", platform)
+	}
+
+	types := make(map[string]HomebridgeAccessory)
+	for _, a := range platform.Accessories {
+		types[a.IseID] = a
+	}
+	for iseID, want := range map[string]string{
+		"2411": "Thermostat",
+		"2428": "ContactSensor",
+		"2438": "StatelessProgrammableSwitch",
+		"2444": "Switch",
+		"2455": "Lightbulb",
+		"2469": "SmokeSensor",
+		"2482": "ContactSensor",
+	} {
+		if types[iseID].Type != want {
+			t.Errorf("channel %s: expected %s, got %+v", iseID, want, types[iseID])
+		}
+	}
+	if a := types["2411"]; a.Name != "Thermostat Schlafzimmer" || a.Room != "Schlafzimmer" {
+		t.Errorf("expected the device name and room for a single accessory, got %+v", a)
+	}
+	if a := types["2444"]; a.Name != "Schalter Esszimmer 4" {
+		t.Errorf("expected the channel index for devices with several accessories, got %+v", a)
+	}
+
+	filtered := NewHomebridgeConfig(topology, HomebridgeOptions{Rooms: []string{"esszimmer"}, Roles: []ChannelRole{RoleSwitch}})
+	if accessories := filtered.Platforms[0].Accessories; len(accessories) != 1 || accessories[0].IseID != "2444" {
+		t.Errorf("expected only the switch in Esszimmer, got %+v", accessories)
+	}
+}
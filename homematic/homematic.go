@@ -2,12 +2,15 @@ package homematic
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -22,123 +25,245 @@ type Client struct {
 	BaseURL    string
 	Token      string
 	HTTPClient *http.Client
+
+	// Secrets optionally supplies the token instead of Token, e.g. from Vault;
+	// it is fetched again when the CCU rejects the cached token
+	Secrets SecretsProvider
+
+	// Username and Password are sent as HTTP Basic authentication on every
+	// request when set, e.g. for CCUs behind an authenticating reverse proxy
+	Username string
+	Password string
+
+	// Auth optionally adds headers and cookies to every request
+	Auth AuthProvider
+
+	// UserAgent is sent as User-Agent header if set
+	UserAgent string
+
+	// ExtraParams are added to the query of every request, e.g. addon flags
+	// not modeled by this library; parameters of the call itself take precedence
+	ExtraParams map[string]string
+
+	// Endpoints holds custom endpoint declarations used by Call; DefaultEndpoints if nil
+	Endpoints *EndpointRegistry
+
+	// DryRun makes all mutating calls (state, program, master value and token
+	// changes) succeed without sending them to the CCU
+	DryRun bool
+
+	// WriteLock optionally coordinates state and master value changes with
+	// other clients, locking the affected data points for the duration of the request
+	WriteLock WriteLocker
+
+	// WritePolicy optionally restricts the data points, system variables and
+	// devices that state and master value changes may target
+	WritePolicy *WritePolicy
+
+	// CriticalWrites optionally requires a confirmation, e.g. a TOTP code,
+	// before state and master value changes and program runs of critical targets are sent
+	CriticalWrites *CriticalWrites
+
+	// IDTracker optionally detects ise_ids that changed because a device was
+	// paired again, when state reads and changes miss them
+	IDTracker *IDTracker
+
+	// OnConnectivity is called when the connection state derived from the
+	// request outcomes changes, see Connectivity
+	OnConnectivity func(event ConnectivityEvent)
+
+	// Location is the time zone of the CCU. The XML-API reports timestamps as
+	// the local wall clock time of the CCU; if Location is set, they are
+	// converted to Unix time when decoding, see CCUTime
+	Location *time.Location
+
+	// ClockDriftThreshold is the clock drift between the CCU and the local
+	// clock above which OnClockDrift is called; DefaultClockDriftThreshold if zero
+	ClockDriftThreshold time.Duration
+
+	// OnClockDrift is called when the clock drift measured from the responses
+	// exceeds ClockDriftThreshold, see ClockDrift
+	OnClockDrift func(drift time.Duration)
+
+	// SlowRequestThreshold is the response time above which OnSlowRequest is
+	// called, e.g. to log slow state list requests; zero disables the check
+	SlowRequestThreshold time.Duration
+
+	// OnSlowRequest is called for requests slower than SlowRequestThreshold,
+	// see LatencyStats for the response times of all requests
+	OnSlowRequest func(req SlowRequest)
+
+	lifecycle      *lifecycle
+	connectivity   *connectivity
+	clock          *clock
+	transportStats *transportStats
+	latency        *latency
+	secrets        *secretCache
+	optionErr      error
 }
 
-// NewClient creates a new HomeMatic XML-API client
-func NewClient(baseURL, token string) *Client {
+// NewClient creates a new HomeMatic XML-API client. By default, requests
+// time out after 30 seconds and TLS certificates are not verified; options
+// change these defaults.
+func NewClient(baseURL, token string, opts ...Option) *Client {
 	// a http client that uses insecure TLS settings
 	client := &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			// a custom TLS config disables HTTP/2 unless requested explicitly
+			ForceAttemptHTTP2: true,
 		},
 		Timeout: 30 * time.Second,
 	}
 
-	return &Client{
-		BaseURL:    baseURL,
-		Token:      token,
-		HTTPClient: client,
+	// invalid URLs are kept and reported by the first request
+	if normalized, err := NormalizeBaseURL(baseURL); err == nil {
+		baseURL = normalized
+	}
+
+	c := &Client{
+		BaseURL:        baseURL,
+		Token:          token,
+		HTTPClient:     client,
+		lifecycle:      &lifecycle{},
+		connectivity:   &connectivity{},
+		clock:          &clock{},
+		transportStats: &transportStats{},
+		latency:        &latency{},
+		secrets:        &secretCache{},
 	}
+	for _, opt := range opts {
+		// like invalid URLs, failed options are reported by the first request
+		if err := opt(c); err != nil && c.optionErr == nil {
+			c.optionErr = fmt.Errorf("invalid option: %w", err)
+		}
+	}
+	return c
+}
+
+// WithParams returns a copy of the client that adds the given query parameters
+// to its requests, e.g. client.WithParams(map[string]string{"show_internal": "1"}).GetRoomList()
+func (c *Client) WithParams(params map[string]string) *Client {
+	clone := *c
+	clone.ExtraParams = make(map[string]string, len(c.ExtraParams)+len(params))
+	for key, value := range c.ExtraParams {
+		clone.ExtraParams[key] = value
+	}
+	for key, value := range params {
+		clone.ExtraParams[key] = value
+	}
+	return &clone
 }
 
 // Device represents a HomeMatic device
 type Device struct {
-	XMLName     xml.Name  `xml:"device"`
-	Name        string    `xml:"name,attr"`
-	Address     string    `xml:"address,attr"`
-	IseID       string    `xml:"ise_id,attr"`
-	Unreach     bool      `xml:"unreach,attr"`
-	Config      bool      `xml:"config,attr"`
-	DeviceType  string    `xml:"device_type,attr"`
-	InterfaceID string    `xml:"interface_id,attr"`
-	Channels    []Channel `xml:"channel"`
+	XMLName     xml.Name  `xml:"device" json:"-" yaml:"-"`
+	Name        string    `xml:"name,attr" json:"name" yaml:"name"`
+	Address     string    `xml:"address,attr" json:"address,omitempty" yaml:"address,omitempty"`
+	IseID       string    `xml:"ise_id,attr" json:"ise_id" yaml:"ise_id"`
+	Unreach     bool      `xml:"unreach,attr" json:"unreach" yaml:"unreach"`
+	Config      bool      `xml:"config,attr" json:"config" yaml:"config"`
+	DeviceType  string    `xml:"device_type,attr" json:"device_type,omitempty" yaml:"device_type,omitempty"`
+	InterfaceID string    `xml:"interface_id,attr" json:"interface_id,omitempty" yaml:"interface_id,omitempty"`
+	Channels    []Channel `xml:"channel" json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	// Maintenance holds the health data of channel 0; it is only populated by
+	// state requests, which include data point values
+	Maintenance *MaintenanceInfo `xml:"-" json:"maintenance,omitempty" yaml:"maintenance,omitempty"`
+
+	// Metadata holds local tags, labels and notes set by MetadataStore.Apply
+	Metadata *Metadata `xml:"-" json:"metadata,omitempty" yaml:"metadata,omitempty"`
 }
 
 // Channel represents a device channel
 type Channel struct {
-	XMLName      xml.Name    `xml:"channel"`
-	Name         string      `xml:"name,attr"`
-	Type         string      `xml:"type,attr"`
-	Address      string      `xml:"address,attr"`
-	IseID        string      `xml:"ise_id,attr"`
-	Direction    string      `xml:"direction,attr"`
-	ParentType   string      `xml:"parent_type,attr"`
-	Index        int         `xml:"index,attr"`
-	GroupPartner string      `xml:"group_partner,attr"`
-	AESAvailable bool        `xml:"aes_available,attr"`
-	Transmission string      `xml:"transmission_mode,attr"`
-	Visible      bool        `xml:"visible,attr"`
-	Ready        bool        `xml:"ready_config,attr"`
-	Operate      bool        `xml:"operate,attr"`
-	DataPoints   []DataPoint `xml:"datapoint"`
+	XMLName      xml.Name    `xml:"channel" json:"-" yaml:"-"`
+	Name         string      `xml:"name,attr" json:"name" yaml:"name"`
+	Type         string      `xml:"type,attr" json:"type,omitempty" yaml:"type,omitempty"`
+	Address      string      `xml:"address,attr" json:"address,omitempty" yaml:"address,omitempty"`
+	IseID        string      `xml:"ise_id,attr" json:"ise_id" yaml:"ise_id"`
+	Direction    string      `xml:"direction,attr" json:"direction,omitempty" yaml:"direction,omitempty"`
+	ParentType   string      `xml:"parent_type,attr" json:"parent_type,omitempty" yaml:"parent_type,omitempty"`
+	Index        int         `xml:"index,attr" json:"index" yaml:"index"`
+	GroupPartner string      `xml:"group_partner,attr" json:"group_partner,omitempty" yaml:"group_partner,omitempty"`
+	AESAvailable bool        `xml:"aes_available,attr" json:"aes_available" yaml:"aes_available"`
+	Transmission string      `xml:"transmission_mode,attr" json:"transmission_mode,omitempty" yaml:"transmission_mode,omitempty"`
+	Visible      bool        `xml:"visible,attr" json:"visible" yaml:"visible"`
+	Ready        bool        `xml:"ready_config,attr" json:"ready_config" yaml:"ready_config"`
+	Operate      bool        `xml:"operate,attr" json:"operate" yaml:"operate"`
+	DataPoints   []DataPoint `xml:"datapoint" json:"datapoints,omitempty" yaml:"datapoints,omitempty"`
+
+	// Metadata holds local tags, labels and notes set by MetadataStore.Apply
+	Metadata *Metadata `xml:"-" json:"metadata,omitempty" yaml:"metadata,omitempty"`
 }
 
 // DataPoint represents a channel data point
 type DataPoint struct {
-	XMLName   xml.Name `xml:"datapoint"`
-	Name      string   `xml:"name,attr"`
-	Type      string   `xml:"type,attr"`
-	IseID     string   `xml:"ise_id,attr"`
-	Value     string   `xml:"value,attr"`
-	ValueType int      `xml:"valuetype,attr"`
-	ValueUnit string   `xml:"valueunit,attr"`
-	Timestamp int64    `xml:"timestamp,attr"`
+	XMLName   xml.Name `xml:"datapoint" json:"-" yaml:"-"`
+	Name      string   `xml:"name,attr" json:"name" yaml:"name"`
+	Type      string   `xml:"type,attr" json:"type" yaml:"type"`
+	IseID     string   `xml:"ise_id,attr" json:"ise_id" yaml:"ise_id"`
+	Value     string   `xml:"value,attr" json:"value" yaml:"value"`
+	ValueType int      `xml:"valuetype,attr" json:"valuetype" yaml:"valuetype"`
+	ValueUnit string   `xml:"valueunit,attr" json:"valueunit,omitempty" yaml:"valueunit,omitempty"`
+	Timestamp int64    `xml:"timestamp,attr" json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
 }
 
 // Program represents a HomeMatic program
 type Program struct {
-	XMLName     xml.Name `xml:"program"`
-	ID          string   `xml:"id,attr"`
-	Name        string   `xml:"name,attr"`
-	Description string   `xml:"description,attr"`
-	Info        string   `xml:"info,attr"`
-	Visible     bool     `xml:"visible,attr"`
-	Active      bool     `xml:"active,attr"`
-	Timestamp   int64    `xml:"timestamp,attr"`
+	XMLName     xml.Name `xml:"program" json:"-" yaml:"-"`
+	ID          string   `xml:"id,attr" json:"id" yaml:"id"`
+	Name        string   `xml:"name,attr" json:"name" yaml:"name"`
+	Description string   `xml:"description,attr" json:"description,omitempty" yaml:"description,omitempty"`
+	Info        string   `xml:"info,attr" json:"info,omitempty" yaml:"info,omitempty"`
+	Visible     bool     `xml:"visible,attr" json:"visible" yaml:"visible"`
+	Active      bool     `xml:"active,attr" json:"active" yaml:"active"`
+	Timestamp   int64    `xml:"timestamp,attr" json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
 }
 
 // Room represents a HomeMatic room
 type Room struct {
-	XMLName  xml.Name  `xml:"room"`
-	Name     string    `xml:"name,attr"`
-	IseID    string    `xml:"ise_id,attr"`
-	Channels []Channel `xml:"channel"`
+	XMLName  xml.Name  `xml:"room" json:"-" yaml:"-"`
+	Name     string    `xml:"name,attr" json:"name" yaml:"name"`
+	IseID    string    `xml:"ise_id,attr" json:"ise_id" yaml:"ise_id"`
+	Channels []Channel `xml:"channel" json:"channels,omitempty" yaml:"channels,omitempty"`
 }
 
 // Function represents a HomeMatic function
 type Function struct {
-	XMLName  xml.Name  `xml:"function"`
-	Name     string    `xml:"name,attr"`
-	IseID    string    `xml:"ise_id,attr"`
-	Channels []Channel `xml:"channel"`
+	XMLName  xml.Name  `xml:"function" json:"-" yaml:"-"`
+	Name     string    `xml:"name,attr" json:"name" yaml:"name"`
+	IseID    string    `xml:"ise_id,attr" json:"ise_id" yaml:"ise_id"`
+	Channels []Channel `xml:"channel" json:"channels,omitempty" yaml:"channels,omitempty"`
 }
 
 // SystemVariable represents a HomeMatic system variable
 type SystemVariable struct {
-	XMLName    xml.Name `xml:"systemVariable"`
-	Name       string   `xml:"name,attr"`
-	Variable   string   `xml:"variable,attr"`
-	Value      string   `xml:"value,attr"`
-	ValueType  int      `xml:"valuetype,attr"`
-	IseID      string   `xml:"ise_id,attr"`
-	Min        string   `xml:"min,attr"`
-	Max        string   `xml:"max,attr"`
-	Unit       string   `xml:"unit,attr"`
-	Type       string   `xml:"type,attr"`
-	Subtype    string   `xml:"subtype,attr"`
-	Logged     bool     `xml:"logged,attr"`
-	Visible    bool     `xml:"visible,attr"`
-	Timestamp  int64    `xml:"timestamp,attr"`
-	ValueName0 string   `xml:"value_name_0,attr"`
-	ValueName1 string   `xml:"value_name_1,attr"`
-	ValueText  string   `xml:"value_text,attr"`
+	XMLName    xml.Name `xml:"systemVariable" json:"-" yaml:"-"`
+	Name       string   `xml:"name,attr" json:"name" yaml:"name"`
+	Variable   string   `xml:"variable,attr" json:"variable,omitempty" yaml:"variable,omitempty"`
+	Value      string   `xml:"value,attr" json:"value" yaml:"value"`
+	ValueType  int      `xml:"valuetype,attr" json:"valuetype" yaml:"valuetype"`
+	IseID      string   `xml:"ise_id,attr" json:"ise_id" yaml:"ise_id"`
+	Min        string   `xml:"min,attr" json:"min,omitempty" yaml:"min,omitempty"`
+	Max        string   `xml:"max,attr" json:"max,omitempty" yaml:"max,omitempty"`
+	Unit       string   `xml:"unit,attr" json:"unit,omitempty" yaml:"unit,omitempty"`
+	Type       string   `xml:"type,attr" json:"type,omitempty" yaml:"type,omitempty"`
+	Subtype    string   `xml:"subtype,attr" json:"subtype,omitempty" yaml:"subtype,omitempty"`
+	Logged     bool     `xml:"logged,attr" json:"logged" yaml:"logged"`
+	Visible    bool     `xml:"visible,attr" json:"visible" yaml:"visible"`
+	Timestamp  int64    `xml:"timestamp,attr" json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+	ValueName0 string   `xml:"value_name_0,attr" json:"value_name_0,omitempty" yaml:"value_name_0,omitempty"`
+	ValueName1 string   `xml:"value_name_1,attr" json:"value_name_1,omitempty" yaml:"value_name_1,omitempty"`
+	ValueList  string   `xml:"value_list,attr" json:"value_list,omitempty" yaml:"value_list,omitempty"`
+	ValueText  string   `xml:"value_text,attr" json:"value_text,omitempty" yaml:"value_text,omitempty"`
 }
 
 // DeviceType represents a HomeMatic device type
 type DeviceType struct {
-	XMLName xml.Name `xml:"deviceType"`
-	Name    string   `xml:"name,attr"`
-	ID      string   `xml:"id,attr"`
+	XMLName xml.Name `xml:"deviceType" json:"-" yaml:"-"`
+	Name    string   `xml:"name,attr" json:"name" yaml:"name"`
+	ID      string   `xml:"id,attr" json:"id" yaml:"id"`
 }
 
 // APIResponse represents the common XML response structure
@@ -216,27 +341,35 @@ func charsetReader(charset string, input io.Reader) (io.Reader, error) {
 // convertToUTF8 converts XML content to UTF-8 if needed
 func convertToUTF8(data []byte) ([]byte, error) {
 	// Check if already UTF-8
-	if utf8.Valid(data) {
+	if utf8.Valid(data) || !declaresLatin1(data) {
 		return data, nil
 	}
 
-	// Try to detect encoding from XML declaration
-	xmlDecl := string(data[:min(len(data), 200)])
-	if strings.Contains(strings.ToLower(xmlDecl), "iso-8859-1") ||
-		strings.Contains(strings.ToLower(xmlDecl), "latin1") {
-		decoder := charmap.ISO8859_1.NewDecoder()
-		utf8Data, err := decoder.Bytes(data)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert from ISO-8859-1: %w", err)
-		}
-		// Replace encoding declaration with UTF-8
-		utf8String := string(utf8Data)
-		utf8String = strings.ReplaceAll(utf8String, "ISO-8859-1", "UTF-8")
-		utf8String = strings.ReplaceAll(utf8String, "iso-8859-1", "utf-8")
-		return []byte(utf8String), nil
+	var buf bytes.Buffer
+	writeLatin1AsUTF8(&buf, data)
+	return buf.Bytes(), nil
+}
+
+// encodeLatin1 transcodes a value to ISO-8859-1, the encoding the addon stores values in
+func encodeLatin1(value string) (string, error) {
+	if isASCII(value) {
+		return value, nil
+	}
+	encoded, err := charmap.ISO8859_1.NewEncoder().String(value)
+	if err != nil {
+		return "", fmt.Errorf("value %q cannot be encoded as ISO-8859-1: %w", value, err)
 	}
+	return encoded, nil
+}
 
-	return data, nil
+// isASCII reports whether s contains only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
 }
 
 // min returns the minimum of two integers
@@ -249,107 +382,224 @@ func min(a, b int) int {
 
 // makeRequest performs an HTTP request to the XML-API
 func (c *Client) makeRequest(endpoint string, params map[string]string) (*APIResponse, error) {
-	u, err := url.Parse(fmt.Sprintf("%s/addons/xmlapi/%s", c.BaseURL, endpoint))
+	return c.makeRequestContext(context.Background(), endpoint, params)
+}
+
+// makeRequestContext is makeRequest bound to ctx
+func (c *Client) makeRequestContext(ctx context.Context, endpoint string, params map[string]string) (*APIResponse, error) {
+	var result APIResponse
+	err := c.doRequestContext(ctx, endpoint, params, func(body []byte) error {
+		if err := decodeXML(body, &result); err != nil {
+			return err
+		}
+		return notFoundError(endpoint, params, body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// newRequest builds the HTTP request for an XML-API endpoint, including
+// the sid token, the given query parameters and authentication headers; a
+// non-nil payload is sent as body of a POST request
+func (c *Client) newRequest(endpoint, token string, params map[string]string, payload []byte) (*http.Request, error) {
+	if c.optionErr != nil {
+		return nil, c.optionErr
+	}
+	baseURL, err := NormalizeBaseURL(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(fmt.Sprintf("%s/addons/xmlapi/%s", baseURL, endpoint))
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
 	q := u.Query()
-	q.Set("sid", c.Token)
+	q.Set("sid", token)
 
+	for key, value := range c.ExtraParams {
+		q.Set(key, value)
+	}
 	for key, value := range params {
 		q.Set(key, value)
 	}
 
-	u.RawQuery = q.Encode()
+	// the addon's CGI parser does not decode '+' as space; Encode escapes a literal '+' as %2B
+	u.RawQuery = strings.ReplaceAll(q.Encode(), "+", "%20")
 
-	resp, err := c.HTTPClient.Get(u.String())
+	method, body := http.MethodGet, io.Reader(nil)
+	if payload != nil {
+		method, body = http.MethodPost, bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, u.String(), body)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "text/plain; charset=ISO-8859-1")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	if err := c.applyAuthProvider(req); err != nil {
+		return nil, err
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	return req, nil
+}
+
+// makeRawRequest performs an HTTP request and returns raw XML bytes
+func (c *Client) makeRawRequest(endpoint string, params map[string]string) ([]byte, error) {
+	var body []byte
+	err := c.doRequest(endpoint, params, func(b []byte) error {
+		body = bytes.Clone(b)
+		return nil
+	})
+	return body, err
+}
+
+// decodeResponse performs an HTTP request and decodes the XML response into v
+func (c *Client) decodeResponse(endpoint string, params map[string]string, v any) error {
+	return c.doRequest(endpoint, params, func(body []byte) error {
+		return decodeXML(body, v)
+	})
+}
+
+// decodeXML decodes an XML response body into v
+func decodeXML(body []byte, v any) error {
+	// xml.Decoder cannot be reset, so only the body buffers are reused;
+	// the decoder copies all values out of the body
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charsetReader
+
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to parse XML: %w", err)
 	}
+	return nil
+}
 
-	// Convert to UTF-8 if needed
-	utf8Body, err := convertToUTF8(body)
+// doRequest performs an HTTP request and passes the response body, converted
+// to UTF-8, to fn. The body is backed by pooled buffers and must not be
+// retained after fn returns.
+func (c *Client) doRequest(endpoint string, params map[string]string, fn func(body []byte) error) error {
+	return c.doRequestContext(context.Background(), endpoint, params, fn)
+}
+
+// doRequestContext is doRequest bound to ctx
+func (c *Client) doRequestContext(ctx context.Context, endpoint string, params map[string]string, fn func(body []byte) error) error {
+	return c.doPostContext(ctx, endpoint, params, nil, fn)
+}
+
+// doPostContext is doRequestContext sending payload as POST body; a nil payload sends a GET request
+func (c *Client) doPostContext(ctx context.Context, endpoint string, params map[string]string, payload []byte, fn func(body []byte) error) error {
+	if c.lifecycle != nil {
+		if err := c.lifecycle.begin(); err != nil {
+			return err
+		}
+		defer c.lifecycle.end()
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	cached, sent, err := c.send(ctx, endpoint, params, payload, buf, false)
+	if errors.Is(err, ErrNotAuthenticated) && cached {
+		// the secret may have been rotated since it was fetched
+		buf.Reset()
+		_, sent, err = c.send(ctx, endpoint, params, payload, buf, true)
+	}
+	// local failures, e.g. of the secrets provider or building the request, say nothing about the CCU
+	if sent {
+		c.observeOutcome(err)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert encoding: %w", err)
+		return err
 	}
 
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(utf8Body))
-	decoder.CharsetReader = charsetReader
+	body := buf.Bytes()
+	if !utf8.Valid(body) && declaresLatin1(body) {
+		converted := getBuffer()
+		defer putBuffer(converted)
 
-	var result APIResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+		writeLatin1AsUTF8(converted, body)
+		body = converted.Bytes()
 	}
 
-	return &result, nil
+	return fn(body)
 }
 
-// makeRawRequest performs an HTTP request and returns raw XML bytes
-func (c *Client) makeRawRequest(endpoint string, params map[string]string) ([]byte, error) {
-	u, err := url.Parse(fmt.Sprintf("%s/addons/xmlapi/%s", c.BaseURL, endpoint))
+// send performs a request with the session token and reads the response
+// into buf; cached reports whether the token came from the secrets cache,
+// sent whether the request was passed to the transport
+func (c *Client) send(ctx context.Context, endpoint string, params map[string]string, payload []byte, buf *bytes.Buffer, refresh bool) (cached, sent bool, err error) {
+	token, cached, err := c.sessionToken(ctx, refresh)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
+		return false, false, err
+	}
+	req, err := c.newRequest(endpoint, token, params, payload)
+	if err != nil {
+		return false, false, err
 	}
+	req = req.WithContext(c.traceTransport(ctx))
 
-	q := u.Query()
-	q.Set("sid", c.Token)
+	start := time.Now()
+	err = c.readResponse(req, buf)
+	c.observeLatency(endpoint, params, time.Since(start), err)
+	return cached, true, err
+}
 
-	for key, value := range params {
-		q.Set(key, value)
-	}
+// HTTPError is returned for responses with a status other than 200 OK
+type HTTPError struct {
+	StatusCode int
+}
 
-	u.RawQuery = q.Encode()
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP error: %d", e.StatusCode)
+}
 
-	resp, err := c.HTTPClient.Get(u.String())
+// readResponse sends the request and reads the response body into buf
+func (c *Client) readResponse(req *http.Request, buf *bytes.Buffer) error {
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	c.observeClock(start, resp.Header.Get("Date"))
+	c.observeProtocol(resp)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+		return &HTTPError{StatusCode: resp.StatusCode}
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	if resp.ContentLength > 0 {
+		buf.Grow(int(resp.ContentLength))
 	}
-
-	// Convert to UTF-8 if needed
-	utf8Body, err := convertToUTF8(body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert encoding: %w", err)
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return utf8Body, nil
+	if isNotAuthenticated(buf.Bytes()) {
+		return ErrNotAuthenticated
+	}
+	if apiErr := parseAPIError(path.Base(req.URL.Path), buf.Bytes()); apiErr != nil {
+		return apiErr
+	}
+	return nil
 }
 
 // GetVersion returns the XML-API version
 func (c *Client) GetVersion() (string, error) {
-	body, err := c.makeRawRequest("version.cgi", nil)
-	if err != nil {
-		return "", err
-	}
-
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
-
 	var versionResp VersionResponse
-	if err := decoder.Decode(&versionResp); err != nil {
-		return "", fmt.Errorf("failed to parse version XML: %w", err)
+	if err := c.decodeResponse("version.cgi", nil, &versionResp); err != nil {
+		return "", err
 	}
 
 	return strings.TrimSpace(versionResp.Value), nil
@@ -357,6 +607,10 @@ func (c *Client) GetVersion() (string, error) {
 
 // GetDeviceList returns all devices with their channels
 func (c *Client) GetDeviceList(deviceIDs []string, showInternal, showRemote bool) ([]Device, error) {
+	if err := validateIseIDs("device_id", deviceIDs); err != nil {
+		return nil, err
+	}
+
 	params := make(map[string]string)
 
 	if len(deviceIDs) > 0 {
@@ -369,18 +623,9 @@ func (c *Client) GetDeviceList(deviceIDs []string, showInternal, showRemote bool
 		params["show_remote"] = "1"
 	}
 
-	body, err := c.makeRawRequest("devicelist.cgi", params)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
-
 	var result DeviceListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	if err := c.decodeResponse("devicelist.cgi", params, &result); err != nil {
+		return nil, err
 	}
 
 	return result.Devices, nil
@@ -388,18 +633,9 @@ func (c *Client) GetDeviceList(deviceIDs []string, showInternal, showRemote bool
 
 // GetDeviceTypes returns all possible device types
 func (c *Client) GetDeviceTypes() ([]DeviceType, error) {
-	body, err := c.makeRawRequest("devicetypelist.cgi", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
-
 	var result DeviceTypeListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	if err := c.decodeResponse("devicetypelist.cgi", nil, &result); err != nil {
+		return nil, err
 	}
 
 	return result.DeviceTypes, nil
@@ -407,6 +643,12 @@ func (c *Client) GetDeviceTypes() ([]DeviceType, error) {
 
 // GetStateList returns all devices with their current values
 func (c *Client) GetStateList(deviceID string, showInternal, showRemote bool) ([]Device, error) {
+	if deviceID != "" {
+		if err := validateIseIDs("device_id", []string{deviceID}); err != nil {
+			return nil, err
+		}
+	}
+
 	params := make(map[string]string)
 
 	if showInternal {
@@ -416,19 +658,13 @@ func (c *Client) GetStateList(deviceID string, showInternal, showRemote bool) ([
 		params["show_remote"] = "1"
 	}
 
-	body, err := c.makeRawRequest("statelist.cgi", params)
-	if err != nil {
+	var result StateListResponse
+	if err := c.decodeResponse("statelist.cgi", params, &result); err != nil {
 		return nil, err
 	}
 
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
-
-	var result StateListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
-	}
+	attachMaintenanceInfo(result.Devices)
+	c.convertTimestamps(result.Devices)
 
 	if deviceID != "" {
 		// Filter devices by deviceID if provided
@@ -446,6 +682,14 @@ func (c *Client) GetStateList(deviceID string, showInternal, showRemote bool) ([
 
 // GetState returns specific devices/channels with their current values
 func (c *Client) GetState(deviceIDs, channelIDs, datapointIDs []string) ([]Device, error) {
+	if err := errors.Join(
+		validateIseIDs("device_id", deviceIDs),
+		validateIseIDs("channel_id", channelIDs),
+		validateIseIDs("datapoint_id", datapointIDs),
+	); err != nil {
+		return nil, err
+	}
+
 	params := make(map[string]string)
 
 	if len(deviceIDs) > 0 {
@@ -458,59 +702,219 @@ func (c *Client) GetState(deviceIDs, channelIDs, datapointIDs []string) ([]Devic
 		params["datapoint_id"] = strings.Join(datapointIDs, ",")
 	}
 
-	body, err := c.makeRawRequest("state.cgi", params)
-	if err != nil {
+	if err := c.trackIDs(); err != nil {
 		return nil, err
 	}
 
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
-
 	var result StateListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	if err := c.decodeResponse("state.cgi", params, &result); err != nil {
+		return nil, err
 	}
 
+	attachMaintenanceInfo(result.Devices)
+	c.convertTimestamps(result.Devices)
+
+	if c.IDTracker != nil {
+		c.missedIDs(missingIseIDs(result.Devices, deviceIDs, channelIDs, datapointIDs))
+	}
 	return result.Devices, nil
 }
 
-// ChangeState changes the state of one or more devices
+// missingIseIDs returns the requested ise_ids not contained in devices
+func missingIseIDs(devices []Device, requested ...[]string) []string {
+	found := make(map[string]bool)
+	for _, device := range devices {
+		found[device.IseID] = true
+		for _, ch := range device.Channels {
+			found[ch.IseID] = true
+			for _, dp := range ch.DataPoints {
+				found[dp.IseID] = true
+			}
+		}
+	}
+	var missing []string
+	for _, ids := range requested {
+		for _, id := range ids {
+			if !found[id] {
+				missing = append(missing, id)
+			}
+		}
+	}
+	return missing
+}
+
+// ChangeResult is the outcome of changing a single data point
+type ChangeResult struct {
+	IseID          string `json:"ise_id" yaml:"ise_id"`
+	RequestedValue string `json:"requested_value" yaml:"requested_value"`
+	// AppliedValue is the value reported by the CCU; it is empty and Err is
+	// set if the response did not contain an entry for the data point
+	AppliedValue string `json:"applied_value,omitempty" yaml:"applied_value,omitempty"`
+	Err          error  `json:"-" yaml:"-"`
+}
+
+// stateChangeResponse is the statechange.cgi response; it holds one changed
+// or not_found element per requested data point, in request order
+type stateChangeResponse struct {
+	XMLName xml.Name `xml:"result"`
+	Entries []struct {
+		XMLName  xml.Name
+		IseID    string `xml:"id,attr"`
+		NewValue string `xml:"new_value,attr"`
+	} `xml:",any"`
+}
+
+// ChangeState changes the state of one or more devices. It fails if the
+// request fails or any of the data points could not be changed. Values must
+// not contain commas, which statechange.cgi uses as separator.
 func (c *Client) ChangeState(deviceIDs, newValues []string) error {
+	return c.changeStateContext(context.Background(), deviceIDs, newValues)
+}
+
+// changeStateContext is ChangeState bound to ctx
+func (c *Client) changeStateContext(ctx context.Context, deviceIDs, newValues []string) error {
+	results, err := c.ChangeStatesContext(ctx, deviceIDs, newValues)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, result := range results {
+		errs = append(errs, result.Err)
+	}
+	return errors.Join(errs...)
+}
+
+// ChangeStates changes the state of one or more devices and returns the
+// result for each of them, so that only the failed changes need to be
+// retried. The error is only set if the request as a whole failed.
+func (c *Client) ChangeStates(deviceIDs, newValues []string) ([]ChangeResult, error) {
+	return c.ChangeStatesContext(context.Background(), deviceIDs, newValues)
+}
+
+// ChangeStatesContext is ChangeStates bound to ctx, which also limits the
+// wait for write locks
+func (c *Client) ChangeStatesContext(ctx context.Context, deviceIDs, newValues []string) ([]ChangeResult, error) {
 	if len(deviceIDs) != len(newValues) {
-		return fmt.Errorf("device IDs and new values must have the same length")
+		return nil, fmt.Errorf("device IDs and new values must have the same length")
+	}
+	if err := validateIseIDs("ise_id", deviceIDs); err != nil {
+		return nil, err
+	}
+
+	// the addon splits both lists at commas, even for a single value, and
+	// expects ISO-8859-1 values
+	encoded := make([]string, len(newValues))
+	for i, value := range newValues {
+		if err := checkStateValue(value); err != nil {
+			return nil, err
+		}
+		latin1, err := encodeLatin1(value)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = latin1
 	}
 
 	params := map[string]string{
 		"ise_id":    strings.Join(deviceIDs, ","),
-		"new_value": strings.Join(newValues, ","),
+		"new_value": strings.Join(encoded, ","),
 	}
 
-	_, err := c.makeRequest("statechange.cgi", params)
-	return err
-}
+	if err := c.checkWrites(deviceIDs); err != nil {
+		return nil, err
+	}
 
-// GetProgramList returns all programs
-func (c *Client) GetProgramList() ([]Program, error) {
-	body, err := c.makeRawRequest("programlist.cgi", nil)
+	results := make([]ChangeResult, len(deviceIDs))
+	for i, id := range deviceIDs {
+		results[i] = ChangeResult{IseID: id, RequestedValue: newValues[i]}
+	}
+
+	if c.DryRun {
+		return results, nil
+	}
+
+	if err := c.trackIDs(); err != nil {
+		return nil, err
+	}
+	if err := c.confirmWrites(ctx, WriteState, deviceIDs, newValues); err != nil {
+		return nil, err
+	}
+
+	unlock, err := c.lockWrites(ctx, dataPointLockKeys(deviceIDs))
 	if err != nil {
 		return nil, err
 	}
+	defer unlock()
 
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
+	var response stateChangeResponse
+	err = c.doRequestContext(ctx, "statechange.cgi", params, func(body []byte) error {
+		return decodeXML(body, &response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		if i >= len(response.Entries) {
+			// the addon answers each change in order, a missing entry was not confirmed
+			results[i].Err = fmt.Errorf("no result from CCU for %s", results[i].IseID)
+			continue
+		}
+		switch entry := response.Entries[i]; entry.XMLName.Local {
+		case "changed":
+			results[i].AppliedValue = entry.NewValue
+		case "not_found":
+			results[i].Err = &APIError{Endpoint: "statechange.cgi", Code: ErrorCodeNotFound, Message: "data point not found: " + results[i].IseID}
+		default:
+			results[i].Err = fmt.Errorf("unexpected result %s from CCU for %s", entry.XMLName.Local, results[i].IseID)
+		}
+	}
+
+	if c.IDTracker != nil {
+		var missed []string
+		for _, result := range results {
+			if result.Err != nil {
+				missed = append(missed, result.IseID)
+			}
+		}
+		changes := c.missedIDs(missed)
+		for i, result := range results {
+			if change, ok := changes[result.IseID]; ok {
+				results[i].Err = fmt.Errorf("%w: %w", result.Err, &IDChangedError{Change: change})
+			}
+		}
+	}
+	return results, nil
+}
+
+// checkStateValue rejects values statechange.cgi would split at a comma
+func checkStateValue(value string) error {
+	if strings.Contains(value, ",") {
+		return fmt.Errorf("value %q contains a comma, which statechange.cgi cannot write", value)
+	}
+	return nil
+}
 
+// GetProgramList returns all programs
+func (c *Client) GetProgramList() ([]Program, error) {
 	var result ProgramListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	if err := c.decodeResponse("programlist.cgi", nil, &result); err != nil {
+		return nil, err
 	}
+	c.convertProgramTimestamps(result.Programs)
 
 	return result.Programs, nil
 }
 
-// RunProgram starts a program with the specified ID
+// RunProgram starts a program with the specified ID. The program_id is
+// checked against the WritePolicy like a data point, and the run holds the
+// program's write lock.
 func (c *Client) RunProgram(programID string, condCheck bool) error {
+	if err := validateIseIDs("program_id", []string{programID}); err != nil {
+		return err
+	}
+
 	params := map[string]string{
 		"program_id": programID,
 	}
@@ -518,12 +922,33 @@ func (c *Client) RunProgram(programID string, condCheck bool) error {
 		params["cond_check"] = "1"
 	}
 
-	_, err := c.makeRequest("runprogram.cgi", params)
+	if err := c.checkWrites([]string{programID}); err != nil {
+		return err
+	}
+	if c.DryRun {
+		return nil
+	}
+	if err := c.confirmWrites(context.Background(), WriteProgram, []string{programID}, nil); err != nil {
+		return err
+	}
+
+	unlock, err := c.lockWrites(context.Background(), programLockKeys(programID))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	_, err = c.makeRequest("runprogram.cgi", params)
 	return err
 }
 
-// ChangeProgramActions modifies program active/visible status
+// ChangeProgramActions modifies program active/visible status, subject to the
+// WritePolicy and write lock like RunProgram
 func (c *Client) ChangeProgramActions(programID string, active, visible *bool) error {
+	if err := validateIseIDs("program_id", []string{programID}); err != nil {
+		return err
+	}
+
 	params := map[string]string{
 		"program_id": programID,
 	}
@@ -535,24 +960,28 @@ func (c *Client) ChangeProgramActions(programID string, active, visible *bool) e
 		params["visible"] = strconv.FormatBool(*visible)
 	}
 
-	_, err := c.makeRequest("programactions.cgi", params)
-	return err
-}
+	if err := c.checkWrites([]string{programID}); err != nil {
+		return err
+	}
+	if c.DryRun {
+		return nil
+	}
 
-// GetRoomList returns all configured rooms including channels
-func (c *Client) GetRoomList() ([]Room, error) {
-	body, err := c.makeRawRequest("roomlist.cgi", nil)
+	unlock, err := c.lockWrites(context.Background(), programLockKeys(programID))
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer unlock()
 
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
+	_, err = c.makeRequest("programactions.cgi", params)
+	return err
+}
 
+// GetRoomList returns all configured rooms including channels
+func (c *Client) GetRoomList() ([]Room, error) {
 	var result RoomListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	if err := c.decodeResponse("roomlist.cgi", nil, &result); err != nil {
+		return nil, err
 	}
 
 	return result.Rooms, nil
@@ -560,18 +989,9 @@ func (c *Client) GetRoomList() ([]Room, error) {
 
 // GetFunctionList returns all functions including channels
 func (c *Client) GetFunctionList() ([]Function, error) {
-	body, err := c.makeRawRequest("functionlist.cgi", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
-
 	var result FunctionListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	if err := c.decodeResponse("functionlist.cgi", nil, &result); err != nil {
+		return nil, err
 	}
 
 	return result.Functions, nil
@@ -586,25 +1006,21 @@ func (c *Client) GetSystemVariableList(showText bool) ([]SystemVariable, error)
 		params["text"] = "false"
 	}
 
-	body, err := c.makeRawRequest("sysvarlist.cgi", params)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
-
 	var result SystemVariableListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	if err := c.decodeResponse("sysvarlist.cgi", params, &result); err != nil {
+		return nil, err
 	}
+	c.convertSystemVariableTimestamps(result.SystemVariables)
 
 	return result.SystemVariables, nil
 }
 
 // GetSystemVariable returns a single system variable
 func (c *Client) GetSystemVariable(iseID string, showText bool) (*SystemVariable, error) {
+	if err := validateIseIDs("ise_id", []string{iseID}); err != nil {
+		return nil, err
+	}
+
 	params := map[string]string{
 		"ise_id": iseID,
 	}
@@ -614,19 +1030,11 @@ func (c *Client) GetSystemVariable(iseID string, showText bool) (*SystemVariable
 		params["text"] = "false"
 	}
 
-	body, err := c.makeRawRequest("sysvar.cgi", params)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
-
 	var result SystemVariableListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	if err := c.decodeResponse("sysvar.cgi", params, &result); err != nil {
+		return nil, err
 	}
+	c.convertSystemVariableTimestamps(result.SystemVariables)
 
 	if len(result.SystemVariables) == 0 {
 		return nil, fmt.Errorf("system variable not found")
@@ -641,22 +1049,57 @@ func (c *Client) RegisterToken(description string) error {
 		"desc": description,
 	}
 
+	if c.DryRun {
+		return nil
+	}
+
 	_, err := c.makeRequest("tokenregister.cgi", params)
 	return err
 }
 
+// RegisterNewToken registers a new security access token and returns it
+func (c *Client) RegisterNewToken(description string) (string, error) {
+	params := map[string]string{
+		"desc": description,
+	}
+
+	if c.DryRun {
+		return "", nil
+	}
+
+	var result struct {
+		Token string `xml:"token"`
+	}
+	if err := c.decodeResponse("tokenregister.cgi", params, &result); err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(result.Token)
+	if token == "" {
+		return "", fmt.Errorf("no token in tokenregister.cgi response")
+	}
+	return token, nil
+}
+
 // RevokeToken revokes an existing security access token
 func (c *Client) RevokeToken(tokenID string) error {
 	params := map[string]string{
 		"sid": tokenID,
 	}
 
+	if c.DryRun {
+		return nil
+	}
+
 	_, err := c.makeRequest("tokenrevoke.cgi", params)
 	return err
 }
 
 // GetMasterValue outputs devices with their master values
 func (c *Client) GetMasterValue(deviceIDs, requestedNames []string) ([]Device, error) {
+	if err := validateIseIDs("device_id", deviceIDs); err != nil {
+		return nil, err
+	}
+
 	params := make(map[string]string)
 
 	if len(deviceIDs) > 0 {
@@ -666,18 +1109,9 @@ func (c *Client) GetMasterValue(deviceIDs, requestedNames []string) ([]Device, e
 		params["requested_names"] = strings.Join(requestedNames, ",")
 	}
 
-	body, err := c.makeRawRequest("mastervalue.cgi", params)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create XML decoder with charset reader support
-	decoder := xml.NewDecoder(bytes.NewReader(body))
-	decoder.CharsetReader = charsetReader
-
 	var result DeviceListResponse
-	if err := decoder.Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	if err := c.decodeResponse("mastervalue.cgi", params, &result); err != nil {
+		return nil, err
 	}
 
 	return result.Devices, nil
@@ -685,9 +1119,18 @@ func (c *Client) GetMasterValue(deviceIDs, requestedNames []string) ([]Device, e
 
 // ChangeMasterValue sets master values for devices
 func (c *Client) ChangeMasterValue(deviceIDs, names, values []string) error {
+	return c.ChangeMasterValueContext(context.Background(), deviceIDs, names, values)
+}
+
+// ChangeMasterValueContext is ChangeMasterValue bound to ctx, which also
+// limits the wait for write locks
+func (c *Client) ChangeMasterValueContext(ctx context.Context, deviceIDs, names, values []string) error {
 	if len(deviceIDs) != len(names) || len(names) != len(values) {
 		return fmt.Errorf("device IDs, names, and values must have the same length")
 	}
+	if err := validateIseIDs("device_id", deviceIDs); err != nil {
+		return err
+	}
 
 	params := map[string]string{
 		"device_id": strings.Join(deviceIDs, ","),
@@ -695,7 +1138,28 @@ func (c *Client) ChangeMasterValue(deviceIDs, names, values []string) error {
 		"value":     strings.Join(values, ","),
 	}
 
-	_, err := c.makeRequest("mastervaluechange.cgi", params)
+	if err := c.checkWrites(deviceIDs); err != nil {
+		return err
+	}
+	if c.DryRun {
+		return nil
+	}
+
+	assignments := make([]string, len(names))
+	for i := range names {
+		assignments[i] = names[i] + "=" + values[i]
+	}
+	if err := c.confirmWrites(ctx, WriteMasterValue, deviceIDs, assignments); err != nil {
+		return err
+	}
+
+	unlock, err := c.lockWrites(ctx, masterValueLockKeys(deviceIDs, names))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	_, err = c.makeRequestContext(ctx, "mastervaluechange.cgi", params)
 	return err
 }
 
@@ -0,0 +1,101 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Hysteresis turns a numeric signal into on/off transitions with separate
+// thresholds and a minimum dwell time, e.g. switching a fan on above 65 %
+// humidity and off again below 55 %. If On is below Off the logic is
+// inverted: on at or below On and off at or above Off, e.g. for heating.
+type Hysteresis struct {
+	On  float64
+	Off float64
+
+	// MinDwell is the minimum time between two transitions; a crossing within
+	// the dwell time takes effect with the first observation after it
+	MinDwell time.Duration
+
+	mu    sync.Mutex
+	state bool
+	known bool
+	since time.Time
+}
+
+// HysteresisTransition is a change of the hysteresis state
+type HysteresisTransition struct {
+	On    bool      `json:"on"`
+	Value float64   `json:"value"`
+	At    time.Time `json:"at"`
+}
+
+// NewHysteresis creates a hysteresis with the given thresholds and minimum dwell time
+func NewHysteresis(on, off float64, minDwell time.Duration) (*Hysteresis, error) {
+	if on == off {
+		return nil, errors.New("on and off thresholds must differ")
+	}
+	return &Hysteresis{On: on, Off: off, MinDwell: minDwell}, nil
+}
+
+// Observe processes a value observed at the given time and returns the
+// transition it caused, if any. The first observation always yields a
+// transition reporting the initial state; values between the thresholds
+// start in the off state.
+func (h *Hysteresis) Observe(value float64, at time.Time) (HysteresisTransition, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	next := h.state
+	switch {
+	case h.crossesOn(value):
+		next = true
+	case h.crossesOff(value):
+		next = false
+	}
+
+	if h.known {
+		if next == h.state || at.Sub(h.since) < h.MinDwell {
+			return HysteresisTransition{}, false
+		}
+	}
+
+	h.state = next
+	h.known = true
+	h.since = at
+	return HysteresisTransition{On: next, Value: value, At: at}, true
+}
+
+// ObserveChange processes a data point change, using its new value and observation time
+func (h *Hysteresis) ObserveChange(change DataPointChange) (HysteresisTransition, bool, error) {
+	value, ok := numericValue(change.NewValue)
+	if !ok {
+		return HysteresisTransition{}, false, fmt.Errorf("non-numeric value %q of data point %s", change.NewValue, change.IseID)
+	}
+	transition, changed := h.Observe(value, change.ObservedAt)
+	return transition, changed, nil
+}
+
+// State returns the current state; known is false before the first observation
+func (h *Hysteresis) State() (on, known bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.state, h.known
+}
+
+func (h *Hysteresis) crossesOn(value float64) bool {
+	if h.On > h.Off {
+		return value >= h.On
+	}
+	return value <= h.On
+}
+
+func (h *Hysteresis) crossesOff(value float64) bool {
+	if h.On > h.Off {
+		return value <= h.Off
+	}
+	return value >= h.Off
+}
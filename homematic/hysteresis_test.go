@@ -0,0 +1,84 @@
+package homematic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHysteresis(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	h, err := NewHysteresis(65, 55, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		value   float64
+		changed bool
+		on      bool
+	}{
+		{60, true, false}, // initial state between the thresholds
+		{64.9, false, false},
+		{65, true, true},
+		{58, false, true},
+		{70, false, true},
+		{55.1, false, true},
+		{55, true, false},
+		{62, false, false},
+	}
+	for i, step := range steps {
+		transition, changed := h.Observe(step.value, start.Add(time.Duration(i)*time.Minute))
+		if changed != step.changed {
+			t.Errorf("step %d (%v): changed = %t, want %t", i, step.value, changed, step.changed)
+		}
+		if on, _ := h.State(); on != step.on {
+			t.Errorf("step %d (%v): on = %t, want %t", i, step.value, on, step.on)
+		}
+		if changed && (transition.On != step.on || transition.Value != step.value) {
+			t.Errorf("step %d: unexpected transition %+v", i, transition)
+		}
+	}
+}
+
+func TestHysteresisInverted(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	h, _ := NewHysteresis(19, 21, 0)
+
+	if transition, _ := h.Observe(18.5, start); !transition.On {
+		t.Error("expected heating to start on below the on threshold")
+	}
+	if _, changed := h.Observe(20, start.Add(time.Minute)); changed {
+		t.Error("expected no transition between the thresholds")
+	}
+	if transition, changed := h.Observe(21.2, start.Add(2*time.Minute)); !changed || transition.On {
+		t.Error("expected heating to switch off above the off threshold")
+	}
+}
+
+func TestHysteresisMinDwell(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	h, _ := NewHysteresis(65, 55, 10*time.Minute)
+
+	h.Observe(70, start)
+	if _, changed := h.Observe(50, start.Add(5*time.Minute)); changed {
+		t.Error("expected transition within the dwell time to be held back")
+	}
+	transition, changed := h.Observe(52, start.Add(11*time.Minute))
+	if !changed || transition.On || !transition.At.Equal(start.Add(11*time.Minute)) {
+		t.Errorf("expected off transition after the dwell time, got %+v", transition)
+	}
+}
+
+func TestHysteresisObserveChange(t *testing.T) {
+	h, _ := NewHysteresis(65, 55, 0)
+	transition, changed, err := h.ObserveChange(DataPointChange{IseID: "1251", NewValue: "67.5", ObservedAt: time.Unix(1700000000, 0)})
+	if err != nil || !changed || !transition.On {
+		t.Errorf("unexpected result: %+v %t %v", transition, changed, err)
+	}
+	if _, _, err := h.ObserveChange(DataPointChange{IseID: "1251", NewValue: "n/a"}); err == nil {
+		t.Error("expected error for non-numeric value")
+	}
+	if _, err := NewHysteresis(60, 60, 0); err == nil {
+		t.Error("expected error for equal thresholds")
+	}
+}
@@ -0,0 +1,242 @@
+package homematic
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultIDRefreshInterval is the minimum time between topology refreshes of
+// an IDTracker if MinRefreshInterval is not set
+const DefaultIDRefreshInterval = time.Minute
+
+// IDChange maps an ise_id that vanished from the CCU to the ise_id now
+// assigned to the same device, channel or data point, e.g. after the device
+// was removed and paired again
+type IDChange struct {
+	OldID string `json:"old_id"`
+	// NewID is empty if the address is no longer known to the CCU
+	NewID string `json:"new_id,omitempty"`
+	// Address is the device or channel address the ids were matched by
+	Address string `json:"address"`
+	// Type is the data point type for data points, empty for devices and channels
+	Type string `json:"type,omitempty"`
+	Name string `json:"name"`
+}
+
+// IDChangedError is returned for writes to an ise_id that changed
+type IDChangedError struct {
+	Change IDChange
+}
+
+func (e *IDChangedError) Error() string {
+	if e.Change.NewID == "" {
+		return fmt.Sprintf("ise_id %s of %s was removed", e.Change.OldID, e.Change.Address)
+	}
+	return fmt.Sprintf("ise_id %s of %s changed to %s", e.Change.OldID, e.Change.Address, e.Change.NewID)
+}
+
+// idIdentity identifies a device or channel by address and a data point by
+// its channel address and type, which survive re-pairing
+type idIdentity struct {
+	address, dataPointType string
+}
+
+// IDTracker detects ise_ids that changed because a device was paired again.
+// It keeps the topology of the CCU and, when a read or write of the client
+// misses a known ise_id, refreshes it and maps the old ids to the new ones by
+// address.
+type IDTracker struct {
+	// OnIDChanged is called for every ise_id found to be changed or removed
+	OnIDChanged func(change IDChange)
+
+	// MinRefreshInterval limits how often misses refresh the topology;
+	// DefaultIDRefreshInterval if zero
+	MinRefreshInterval time.Duration
+
+	mu         sync.Mutex
+	identities map[string]idIdentity
+	names      map[string]string
+	ids        map[idIdentity]string
+	changes    map[string]IDChange
+	// refreshed is the time of the last refresh caused by a miss
+	refreshed time.Time
+}
+
+// SetTopology sets the state list the tracker maps ise_ids with; otherwise
+// the client loads it with the first tracked request. Ids that changed since
+// the previous topology are reported through OnIDChanged.
+func (t *IDTracker) SetTopology(devices []Device) {
+	t.mu.Lock()
+	changes := t.setTopology(devices)
+	t.mu.Unlock()
+
+	t.notify(changes)
+}
+
+func (t *IDTracker) setTopology(devices []Device) []IDChange {
+	identities := make(map[string]idIdentity)
+	names := make(map[string]string)
+	for _, device := range devices {
+		identities[device.IseID] = idIdentity{address: device.Address}
+		names[device.IseID] = device.Name
+		for _, ch := range device.Channels {
+			identities[ch.IseID] = idIdentity{address: ch.Address}
+			names[ch.IseID] = ch.Name
+			for _, dp := range ch.DataPoints {
+				identities[dp.IseID] = idIdentity{address: ch.Address, dataPointType: dataPointType(dp)}
+				names[dp.IseID] = dp.Name
+			}
+		}
+	}
+	ids := make(map[idIdentity]string, len(identities))
+	for id, identity := range identities {
+		ids[identity] = id
+	}
+
+	var changes []IDChange
+	if t.changes == nil {
+		t.changes = make(map[string]IDChange)
+	}
+	for oldID, identity := range t.identities {
+		if _, ok := identities[oldID]; ok {
+			continue
+		}
+		change := IDChange{OldID: oldID, NewID: ids[identity], Address: identity.address, Type: identity.dataPointType, Name: t.names[oldID]}
+		if change.NewID != "" {
+			change.Name = names[change.NewID]
+		}
+		t.changes[oldID] = change
+		changes = append(changes, change)
+	}
+	// ids mapped earlier follow their successor
+	for oldID, change := range t.changes {
+		if _, ok := identities[change.NewID]; change.NewID != "" && !ok {
+			change.NewID = ids[t.identities[change.NewID]]
+			t.changes[oldID] = change
+		}
+	}
+
+	t.identities, t.names, t.ids = identities, names, ids
+	return changes
+}
+
+// Resolve returns the current ise_id for id, following recorded changes;
+// ok is false if the id was removed
+func (t *IDTracker) Resolve(id string) (current string, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	change, changed := t.changes[id]
+	if !changed {
+		return id, true
+	}
+	return change.NewID, change.NewID != ""
+}
+
+// Changes returns all ise_id changes found so far
+func (t *IDTracker) Changes() []IDChange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	changes := make([]IDChange, 0, len(t.changes))
+	for _, change := range t.changes {
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// notify calls OnIDChanged for changes
+func (t *IDTracker) notify(changes []IDChange) {
+	if t.OnIDChanged == nil {
+		return
+	}
+	for _, change := range changes {
+		t.OnIDChanged(change)
+	}
+}
+
+// load loads the topology if the tracker has none yet
+func (t *IDTracker) load(c *Client) error {
+	t.mu.Lock()
+	loaded := t.identities != nil
+	t.mu.Unlock()
+	if loaded {
+		return nil
+	}
+	return t.refresh(c)
+}
+
+// refresh reloads the topology from the CCU
+func (t *IDTracker) refresh(c *Client) error {
+	devices, err := c.GetStateList("", true, false)
+	if err != nil {
+		return fmt.Errorf("failed to load ise_id topology: %w", err)
+	}
+	t.mu.Lock()
+	changes := t.setTopology(devices)
+	t.mu.Unlock()
+
+	t.notify(changes)
+	return nil
+}
+
+// missed returns the changes of ids the CCU did not know, refreshing the
+// topology if one of them was known before and MinRefreshInterval passed
+func (t *IDTracker) missed(c *Client, ids []string) map[string]IDChange {
+	t.mu.Lock()
+	stale := false
+	for _, id := range ids {
+		if _, ok := t.identities[id]; ok {
+			stale = true
+		}
+	}
+	if stale && time.Since(t.refreshed) < durationOrDefault(t.MinRefreshInterval, DefaultIDRefreshInterval) {
+		stale = false
+	}
+	if stale {
+		t.refreshed = time.Now()
+	}
+	t.mu.Unlock()
+
+	if stale {
+		if err := t.refresh(c); err != nil {
+			return nil
+		}
+		// the data points of re-paired devices get new ise_ids as well
+		if c.WritePolicy != nil {
+			c.WritePolicy.invalidate()
+		}
+		if c.CriticalWrites != nil {
+			c.CriticalWrites.invalidate()
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	changes := make(map[string]IDChange)
+	for _, id := range ids {
+		if change, ok := t.changes[id]; ok {
+			changes[id] = change
+		}
+	}
+	return changes
+}
+
+// trackIDs loads the topology of the IDTracker of the client, if any, before
+// a request with ise_ids
+func (c *Client) trackIDs() error {
+	if c.IDTracker == nil {
+		return nil
+	}
+	return c.IDTracker.load(c)
+}
+
+// missedIDs reports ise_ids the CCU did not know to the IDTracker of the client, if any
+func (c *Client) missedIDs(ids []string) map[string]IDChange {
+	if c.IDTracker == nil || len(ids) == 0 {
+		return nil
+	}
+	return c.IDTracker.missed(c, ids)
+}
@@ -0,0 +1,120 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// repairCCU serves a single switch whose ise_ids start at base, so that
+// changing base simulates removing and pairing the device again
+type repairCCU struct {
+	mu         sync.Mutex
+	base       int
+	stateLists int
+}
+
+func (s *repairCCU) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dp := strconv.Itoa(s.base + 2)
+	query := r.URL.Query()
+	switch r.URL.Path {
+	case "/addons/xmlapi/statelist.cgi":
+		s.stateLists++
+		fmt.Fprintf(w, `<stateList><device name="Licht" ise_id="%d" address="000A1" type="HmIP-PS">
+			<channel name="Licht:1" ise_id="%d" address="000A1:1"><datapoint name="HmIP-RF.000A1:1.STATE" type="STATE" ise_id="%s" value="false"/></channel>
+		</device></stateList>`, s.base, s.base+1, dp)
+	case "/addons/xmlapi/state.cgi":
+		if query.Get("datapoint_id") != dp {
+			w.Write([]byte(`<stateList></stateList>`))
+			return
+		}
+		fmt.Fprintf(w, `<stateList><device ise_id="%d"><channel ise_id="%d"><datapoint type="STATE" ise_id="%s" value="false"/></channel></device></stateList>`, s.base, s.base+1, dp)
+	case "/addons/xmlapi/statechange.cgi":
+		if query.Get("ise_id") != dp {
+			w.Write([]byte(`<result><not_found/></result>`))
+			return
+		}
+		fmt.Fprintf(w, `<result><changed id="%s" new_value="%s"/></result>`, dp, query.Get("new_value"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestIDTrackerDetectsRepairing(t *testing.T) {
+	ccu := &repairCCU{base: 100}
+	server := httptest.NewServer(ccu)
+	defer server.Close()
+
+	var changes []string
+	client := NewClient(server.URL, "token")
+	client.IDTracker = &IDTracker{OnIDChanged: func(change IDChange) {
+		changes = append(changes, change.OldID+"->"+change.NewID)
+	}}
+
+	results, err := client.ChangeStates([]string{"102"}, []string{"true"})
+	if err != nil || results[0].Err != nil {
+		t.Fatalf("ChangeStates failed: %v %v", err, results)
+	}
+	if ccu.stateLists != 1 {
+		t.Errorf("expected the topology to be loaded once, got %d", ccu.stateLists)
+	}
+
+	ccu.base = 200
+	results, err = client.ChangeStates([]string{"102"}, []string{"true"})
+	if err != nil {
+		t.Fatalf("ChangeStates failed: %v", err)
+	}
+	var changed *IDChangedError
+	if !errors.As(results[0].Err, &changed) {
+		t.Fatalf("expected an IDChangedError, got %v", results[0].Err)
+	}
+	if changed.Change.NewID != "202" || changed.Change.Address != "000A1:1" || changed.Change.Type != "STATE" {
+		t.Errorf("unexpected change: %+v", changed.Change)
+	}
+	if got := strings.Join(slices.Sorted(slices.Values(changes)), " "); got != "100->200 101->201 102->202" {
+		t.Errorf("unexpected change events: %s", got)
+	}
+	if id, ok := client.IDTracker.Resolve("102"); !ok || id != "202" {
+		t.Errorf("expected 102 to resolve to 202, got %s %v", id, ok)
+	}
+
+	// misses within MinRefreshInterval use the recorded changes
+	if _, err := client.GetState(nil, nil, []string{"102"}); err != nil {
+		t.Fatalf("GetState failed: %v", err)
+	}
+	if ccu.stateLists != 2 {
+		t.Errorf("expected the topology to be refreshed once, got %d loads", ccu.stateLists-1)
+	}
+}
+
+func TestIDTrackerSetTopology(t *testing.T) {
+	device := func(base int, address string) Device {
+		return Device{IseID: strconv.Itoa(base), Address: address, Channels: []Channel{{
+			IseID: strconv.Itoa(base + 1), Address: address + ":1",
+			DataPoints: []DataPoint{{IseID: strconv.Itoa(base + 2), Type: "STATE"}},
+		}}}
+	}
+
+	tracker := &IDTracker{}
+	tracker.SetTopology([]Device{device(100, "A"), device(110, "B")})
+	tracker.SetTopology([]Device{device(200, "A")})
+	tracker.SetTopology([]Device{device(300, "A")})
+
+	for id, want := range map[string]string{"102": "302", "202": "302", "302": "302", "999": "999"} {
+		if got, ok := tracker.Resolve(id); !ok || got != want {
+			t.Errorf("Resolve(%s) = %s %v, want %s", id, got, ok, want)
+		}
+	}
+	if got, ok := tracker.Resolve("112"); ok {
+		t.Errorf("expected removed data point 112 not to resolve, got %s", got)
+	}
+}
@@ -0,0 +1,161 @@
+//go:build integration
+
+package homematic
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Integration tests run against a real XML-API. By default a RaspberryMatic
+// container is started with testcontainers-go and the XML-API addon archive
+// given by HOMEMATIC_TEST_ADDON is installed into it. Setting HOMEMATIC_TEST_URL
+// runs the tests against an existing CCU instead.
+//
+//	HOMEMATIC_TEST_URL    base URL of an existing CCU (skips the container)
+//	HOMEMATIC_TEST_TOKEN  XML-API security token
+//	HOMEMATIC_TEST_IMAGE  CCU image (default ghcr.io/jens-maus/raspberrymatic:latest)
+//	HOMEMATIC_TEST_ADDON  path of the XML-API addon archive (xml-api-addon.tgz)
+//	HOMEMATIC_TEST_WRITES set to 1 to run the write tests against an existing CCU
+//
+// The write tests change a bool system variable and restore it afterwards;
+// against an existing CCU they only run if HOMEMATIC_TEST_WRITES is set.
+//
+// Run with: go test -tags integration ./homematic/...
+
+const defaultIntegrationImage = "ghcr.io/jens-maus/raspberrymatic:latest"
+
+var (
+	integrationOnce   sync.Once
+	integrationURL    string
+	integrationErr    error
+	integrationCloser func()
+)
+
+// TestMain tears down the shared CCU container after all tests ran
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if integrationCloser != nil {
+		integrationCloser()
+	}
+	os.Exit(code)
+}
+
+// integrationClient returns a client connected to the shared integration CCU
+func integrationClient(t *testing.T) *Client {
+	t.Helper()
+
+	integrationOnce.Do(func() {
+		if url := os.Getenv("HOMEMATIC_TEST_URL"); url != "" {
+			integrationURL = url
+			return
+		}
+		integrationURL, integrationCloser, integrationErr = startCCUContainer(context.Background())
+	})
+	if integrationErr != nil {
+		t.Fatalf("failed to start CCU: %v", integrationErr)
+	}
+
+	return NewClient(integrationURL, os.Getenv("HOMEMATIC_TEST_TOKEN"))
+}
+
+// integrationWriteClient is integrationClient for tests that change the CCU
+func integrationWriteClient(t *testing.T) *Client {
+	t.Helper()
+
+	if os.Getenv("HOMEMATIC_TEST_URL") != "" && os.Getenv("HOMEMATIC_TEST_WRITES") != "1" {
+		t.Skip("write tests against an existing CCU require HOMEMATIC_TEST_WRITES=1")
+	}
+	return integrationClient(t)
+}
+
+// startCCUContainer starts a CCU container with the XML-API addon installed
+// and returns its base URL and a function terminating it
+func startCCUContainer(ctx context.Context) (string, func(), error) {
+	addon := os.Getenv("HOMEMATIC_TEST_ADDON")
+	if addon == "" {
+		return "", nil, fmt.Errorf("HOMEMATIC_TEST_ADDON must point to the XML-API addon archive")
+	}
+	image := os.Getenv("HOMEMATIC_TEST_IMAGE")
+	if image == "" {
+		image = defaultIntegrationImage
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        image,
+		ExposedPorts: []string{"80/tcp"},
+		Privileged:   true,
+		Files: []testcontainers.ContainerFile{{
+			HostFilePath:      addon,
+			ContainerFilePath: "/tmp/xml-api-addon.tgz",
+			FileMode:          0o644,
+		}},
+		WaitingFor: wait.ForHTTP("/").WithPort("80/tcp").WithStartupTimeout(5 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start container: %w", err)
+	}
+	terminate := func() { testcontainers.TerminateContainer(container) }
+
+	// install the addon the same way the WebUI does: unpack and run its update script
+	install := []string{"sh", "-c", "mkdir -p /tmp/addon && tar xzf /tmp/xml-api-addon.tgz -C /tmp/addon && cd /tmp/addon && ./update_script"}
+	code, output, err := container.Exec(ctx, install)
+	if err == nil && code != 0 {
+		out, _ := io.ReadAll(output)
+		err = fmt.Errorf("exit code %d: %s", code, strings.TrimSpace(string(out)))
+	}
+	if err != nil {
+		terminate()
+		return "", nil, fmt.Errorf("failed to install XML-API addon: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "80/tcp", "http")
+	if err != nil {
+		terminate()
+		return "", nil, fmt.Errorf("failed to get container endpoint: %w", err)
+	}
+
+	if err := waitForXMLAPI(ctx, endpoint, 2*time.Minute); err != nil {
+		terminate()
+		return "", nil, err
+	}
+
+	return endpoint, terminate, nil
+}
+
+// waitForXMLAPI waits until the addon answers version requests
+func waitForXMLAPI(ctx context.Context, baseURL string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := http.Get(baseURL + "/addons/xmlapi/version.cgi")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("XML-API did not become ready within %s", timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,146 @@
+//go:build integration
+
+package homematic
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestIntegrationVersion(t *testing.T) {
+	client := integrationClient(t)
+
+	version, err := client.GetVersion()
+	if err != nil {
+		t.Fatalf("Error getting version: %v", err)
+	}
+	if version == "" {
+		t.Error("expected a non-empty version")
+	}
+	t.Logf("XML-API Version: %s", version)
+}
+
+func TestIntegrationDeviceList(t *testing.T) {
+	client := integrationClient(t)
+
+	devices, err := client.GetDeviceList(nil, false, false)
+	if err != nil {
+		t.Fatalf("Error getting devices: %v", err)
+	}
+
+	for _, device := range devices {
+		t.Logf("Device: %s (ID: %s, Type: %s)",
+			device.Name, device.IseID, device.DeviceType)
+	}
+}
+
+func TestIntegrationStateList(t *testing.T) {
+	client := integrationClient(t)
+
+	if _, err := client.GetStateList("", false, false); err != nil {
+		t.Fatalf("Error getting state list: %v", err)
+	}
+}
+
+func TestIntegrationProgramsAndRooms(t *testing.T) {
+	client := integrationClient(t)
+
+	if _, err := client.GetProgramList(); err != nil {
+		t.Errorf("Error getting programs: %v", err)
+	}
+	if _, err := client.GetRoomList(); err != nil {
+		t.Errorf("Error getting rooms: %v", err)
+	}
+	if _, err := client.GetFunctionList(); err != nil {
+		t.Errorf("Error getting functions: %v", err)
+	}
+}
+
+func TestIntegrationSystemVariables(t *testing.T) {
+	client := integrationClient(t)
+
+	sysVars, err := client.GetSystemVariableList(true)
+	if err != nil {
+		t.Fatalf("Error getting system variables: %v", err)
+	}
+
+	for _, sysVar := range sysVars {
+		t.Logf("System Variable: %s = %s", sysVar.Name, sysVar.Value)
+	}
+}
+
+// unknownIseID is an ise_id no CCU object has
+const unknownIseID = "999999"
+
+func TestIntegrationChangeStates(t *testing.T) {
+	client := integrationWriteClient(t)
+
+	sysVars, err := client.GetSystemVariableList(false)
+	if err != nil {
+		t.Fatalf("Error getting system variables: %v", err)
+	}
+	var sysVar *SystemVariable
+	for i := range sysVars {
+		if sysVars[i].Kind() == SysVarBool {
+			sysVar = &sysVars[i]
+			break
+		}
+	}
+	if sysVar == nil {
+		t.Skip("no bool system variable to change")
+	}
+	original, err := strconv.ParseBool(sysVar.Value)
+	if err != nil {
+		t.Fatalf("unexpected value of %s: %q", sysVar.Name, sysVar.Value)
+	}
+	t.Cleanup(func() {
+		if err := client.SetSystemVariable(sysVar, strconv.FormatBool(original)); err != nil {
+			t.Errorf("Error restoring %s: %v", sysVar.Name, err)
+		}
+	})
+
+	// one request changes the variable and reports the unknown data point
+	toggled := strconv.FormatBool(!original)
+	results, err := client.ChangeStates([]string{sysVar.IseID, unknownIseID}, []string{toggled, "true"})
+	if err != nil {
+		t.Fatalf("Error changing states: %v", err)
+	}
+	if results[0].Err != nil {
+		t.Errorf("Error changing %s: %v", sysVar.Name, results[0].Err)
+	}
+	var apiErr *APIError
+	if !errors.As(results[1].Err, &apiErr) || apiErr.Code != ErrorCodeNotFound {
+		t.Errorf("expected a not found error for the unknown ise_id, got %v", results[1].Err)
+	}
+
+	changed, err := client.GetSystemVariable(sysVar.IseID, false)
+	if err != nil {
+		t.Fatalf("Error getting %s: %v", sysVar.Name, err)
+	}
+	if changed.Value != toggled {
+		t.Errorf("expected %s to be %s, got %s", sysVar.Name, toggled, changed.Value)
+	}
+}
+
+func TestIntegrationPrograms(t *testing.T) {
+	client := integrationWriteClient(t)
+
+	var apiErr *APIError
+	if err := client.RunProgram(unknownIseID, false); !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeNotFound {
+		t.Errorf("expected a not found error for an unknown program, got %v", err)
+	}
+
+	programs, err := client.GetProgramList()
+	if err != nil {
+		t.Fatalf("Error getting programs: %v", err)
+	}
+	if len(programs) == 0 {
+		t.Skip("no program to change")
+	}
+	// rewriting the current visibility leaves the program unchanged
+	program := programs[0]
+	if err := client.ChangeProgramActions(program.ID, nil, &program.Visible); err != nil {
+		t.Errorf("Error changing program %s: %v", program.Name, err)
+	}
+}
@@ -0,0 +1,33 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidIseID is returned for ise_ids that are empty or not numeric. The
+// CCU answers requests with such ids with an empty result instead of an error.
+var ErrInvalidIseID = errors.New("invalid ise_id")
+
+// ValidateIseID checks that id is a non-empty decimal number
+func ValidateIseID(id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidIseID)
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return fmt.Errorf("%w %q: must be numeric", ErrInvalidIseID, id)
+		}
+	}
+	return nil
+}
+
+// validateIseIDs checks all ids of a request parameter
+func validateIseIDs(param string, ids []string) error {
+	for _, id := range ids {
+		if err := ValidateIseID(id); err != nil {
+			return fmt.Errorf("%s: %w", param, err)
+		}
+	}
+	return nil
+}
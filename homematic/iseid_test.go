@@ -0,0 +1,70 @@
+package homematic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateIseID(t *testing.T) {
+	for _, id := range []string{"0", "1234", "950"} {
+		if err := ValidateIseID(id); err != nil {
+			t.Errorf("ValidateIseID(%q): unexpected error: %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "12a4", " 1234", "-1", "1,2", "１２"} {
+		if err := ValidateIseID(id); !errors.Is(err, ErrInvalidIseID) {
+			t.Errorf("ValidateIseID(%q): expected ErrInvalidIseID, got %v", id, err)
+		}
+	}
+}
+
+func TestInvalidIseIDsAreRejectedBeforeRequests(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`<result/>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	calls := map[string]func() error{
+		"ChangeState": func() error { return client.ChangeState([]string{"1234", "12O4"}, []string{"1", "0"}) },
+		"GetState": func() error {
+			_, err := client.GetState(nil, []string{"1234"}, []string{""})
+			return err
+		},
+		"GetStateList": func() error {
+			_, err := client.GetStateList("lamp", false, false)
+			return err
+		},
+		"GetDeviceList": func() error {
+			_, err := client.GetDeviceList([]string{"abc"}, false, false)
+			return err
+		},
+		"GetSystemVariable": func() error {
+			_, err := client.GetSystemVariable("Presence", false)
+			return err
+		},
+		"RunProgram": func() error { return client.RunProgram("", false) },
+		"ChangeMasterValue": func() error {
+			return client.ChangeMasterValue([]string{"x"}, []string{"TEMPERATURE_OFFSET"}, []string{"1.0"})
+		},
+	}
+	for name, call := range calls {
+		err := call()
+		if !errors.Is(err, ErrInvalidIseID) {
+			t.Errorf("%s: expected ErrInvalidIseID, got %v", name, err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests for invalid ise_ids, got %d", requests)
+	}
+
+	err := client.ChangeState([]string{"1234", "12O4"}, []string{"1", "0"})
+	if err == nil || !strings.Contains(err.Error(), `"12O4"`) || !strings.Contains(err.Error(), "ise_id") {
+		t.Errorf("expected the error to name the parameter and value, got %v", err)
+	}
+}
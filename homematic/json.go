@@ -0,0 +1,108 @@
+package homematic
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// unixTimestamp is a Unix timestamp in seconds that is serialized as RFC3339 in JSON
+type unixTimestamp int64
+
+// MarshalJSON encodes the timestamp as RFC3339 string in UTC
+func (t unixTimestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Unix(int64(t), 0).UTC().Format(time.RFC3339))
+}
+
+// UnmarshalJSON decodes a RFC3339 string or a number of Unix seconds
+func (t *unixTimestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var seconds int64
+		if err := json.Unmarshal(data, &seconds); err != nil {
+			return fmt.Errorf("invalid timestamp %s", data)
+		}
+		*t = unixTimestamp(seconds)
+		return nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp: %w", err)
+	}
+	*t = unixTimestamp(parsed.Unix())
+	return nil
+}
+
+// MarshalJSON encodes the data point with its timestamp as RFC3339
+func (dp DataPoint) MarshalJSON() ([]byte, error) {
+	type dataPoint DataPoint
+	return json.Marshal(struct {
+		dataPoint
+		Timestamp unixTimestamp `json:"timestamp,omitempty"`
+	}{dataPoint(dp), unixTimestamp(dp.Timestamp)})
+}
+
+// UnmarshalJSON decodes a data point encoded by MarshalJSON
+func (dp *DataPoint) UnmarshalJSON(data []byte) error {
+	type dataPoint DataPoint
+	aux := struct {
+		*dataPoint
+		Timestamp unixTimestamp `json:"timestamp"`
+	}{dataPoint: (*dataPoint)(dp)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	dp.Timestamp = int64(aux.Timestamp)
+	return nil
+}
+
+// MarshalJSON encodes the program with its timestamp as RFC3339
+func (p Program) MarshalJSON() ([]byte, error) {
+	type program Program
+	return json.Marshal(struct {
+		program
+		Timestamp unixTimestamp `json:"timestamp,omitempty"`
+	}{program(p), unixTimestamp(p.Timestamp)})
+}
+
+// UnmarshalJSON decodes a program encoded by MarshalJSON
+func (p *Program) UnmarshalJSON(data []byte) error {
+	type program Program
+	aux := struct {
+		*program
+		Timestamp unixTimestamp `json:"timestamp"`
+	}{program: (*program)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Timestamp = int64(aux.Timestamp)
+	return nil
+}
+
+// MarshalJSON encodes the system variable with its timestamp as RFC3339
+func (s SystemVariable) MarshalJSON() ([]byte, error) {
+	type systemVariable SystemVariable
+	return json.Marshal(struct {
+		systemVariable
+		Timestamp unixTimestamp `json:"timestamp,omitempty"`
+	}{systemVariable(s), unixTimestamp(s.Timestamp)})
+}
+
+// UnmarshalJSON decodes a system variable encoded by MarshalJSON
+func (s *SystemVariable) UnmarshalJSON(data []byte) error {
+	type systemVariable SystemVariable
+	aux := struct {
+		*systemVariable
+		Timestamp unixTimestamp `json:"timestamp"`
+	}{systemVariable: (*systemVariable)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.Timestamp = int64(aux.Timestamp)
+	return nil
+}
@@ -0,0 +1,75 @@
+package homematic
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDeviceJSON(t *testing.T) {
+	rssi := -65
+	device := Device{
+		Name:  "Thermostat",
+		IseID: "1234",
+		Channels: []Channel{{Name: "Thermostat:1", IseID: "1250", Index: 1, DataPoints: []DataPoint{
+			{Name: "ACTUAL_TEMPERATURE", Type: "ACTUAL_TEMPERATURE", IseID: "1251", Value: "21.5", ValueType: 4, ValueUnit: "°C", Timestamp: 1700000000},
+			{Name: "PRESS_SHORT", Type: "PRESS_SHORT", IseID: "1252", ValueType: 2},
+		}}},
+		Maintenance: &MaintenanceInfo{RSSIDevice: &rssi},
+	}
+
+	data, err := json.Marshal(device)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, expected := range []string{
+		`"ise_id":"1234"`,
+		`"channels":[{"name":"Thermostat:1","ise_id":"1250","index":1`,
+		`"timestamp":"2023-11-14T22:13:20Z"`,
+		`"valueunit":"°C"`,
+		`"maintenance":{"rssi_device":-65,`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected %s in %s", expected, data)
+		}
+	}
+	if strings.Contains(string(data), "XMLName") || strings.Contains(string(data), `"address"`) {
+		t.Errorf("unexpected fields in %s", data)
+	}
+	if strings.Count(string(data), `"timestamp"`) != 1 {
+		t.Errorf("zero timestamps should be omitted: %s", data)
+	}
+
+	var decoded Device
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	dp := decoded.Channels[0].DataPoints[0]
+	if dp.Timestamp != 1700000000 || dp.Value != "21.5" || dp.IseID != "1251" {
+		t.Errorf("unexpected round trip: %+v", dp)
+	}
+}
+
+func TestSystemVariableAndProgramJSON(t *testing.T) {
+	data, err := json.Marshal([]any{
+		SystemVariable{Name: "Presence", IseID: "950", Value: "true", ValueType: 2, Timestamp: 1700000000},
+		Program{ID: "1400", Name: "Morning", Active: true},
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := `[{"name":"Presence","value":"true","valuetype":2,"ise_id":"950","logged":false,"visible":false,"timestamp":"2023-11-14T22:13:20Z"},` +
+		`{"id":"1400","name":"Morning","visible":false,"active":true}]`
+	if string(data) != expected {
+		t.Errorf("unexpected JSON:\n%s\nwant\n%s", data, expected)
+	}
+
+	var sysVar SystemVariable
+	if err := json.Unmarshal([]byte(`{"name":"Presence","timestamp":1700000000}`), &sysVar); err != nil || sysVar.Timestamp != 1700000000 {
+		t.Errorf("expected numeric timestamps to be accepted, got %+v (%v)", sysVar, err)
+	}
+	var program Program
+	if err := json.Unmarshal([]byte(`{"timestamp":"yesterday"}`), &program); err == nil {
+		t.Error("expected error for invalid timestamp")
+	}
+}
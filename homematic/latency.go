@@ -0,0 +1,162 @@
+package homematic
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// latencySamples is the number of recent response times kept per endpoint
+// to compute percentiles
+const latencySamples = 512
+
+// LatencyStats summarizes the response times of an endpoint. Percentiles are
+// computed from the most recent requests, counts and the mean from all requests.
+type LatencyStats struct {
+	Endpoint string        `json:"endpoint"`
+	Count    uint64        `json:"count"`
+	Errors   uint64        `json:"errors"`
+	Total    time.Duration `json:"total"`
+	Mean     time.Duration `json:"mean"`
+	P50      time.Duration `json:"p50"`
+	P90      time.Duration `json:"p90"`
+	P99      time.Duration `json:"p99"`
+	Max      time.Duration `json:"max"`
+}
+
+// SlowRequest describes a request that took longer than SlowRequestThreshold
+type SlowRequest struct {
+	Endpoint string `json:"endpoint"`
+	// Params are the parameters of the call, without the session token
+	Params   map[string]string `json:"params,omitempty"`
+	Duration time.Duration     `json:"duration"`
+	Err      error             `json:"-"`
+}
+
+// latency tracks the response times per endpoint; it is shared by all copies of a client
+type latency struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpointLatency
+}
+
+// endpointLatency holds the counters and a ring of recent response times of an endpoint
+type endpointLatency struct {
+	count, errors uint64
+	total, max    time.Duration
+	samples       []time.Duration
+	next          int
+}
+
+// LatencyStats returns the response time statistics of all endpoints
+// requested so far, ordered by endpoint
+func (c *Client) LatencyStats() []LatencyStats {
+	if c.latency == nil {
+		return nil
+	}
+	c.latency.mu.Lock()
+	defer c.latency.mu.Unlock()
+
+	stats := make([]LatencyStats, 0, len(c.latency.endpoints))
+	for _, endpoint := range slices.Sorted(maps.Keys(c.latency.endpoints)) {
+		e := c.latency.endpoints[endpoint]
+		samples := slices.Sorted(slices.Values(e.samples))
+		stats = append(stats, LatencyStats{
+			Endpoint: endpoint,
+			Count:    e.count,
+			Errors:   e.errors,
+			Total:    e.total,
+			Mean:     e.total / time.Duration(e.count),
+			P50:      percentile(samples, 0.5),
+			P90:      percentile(samples, 0.9),
+			P99:      percentile(samples, 0.99),
+			Max:      e.max,
+		})
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.999999) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+// observeLatency records the response time of a request and calls
+// OnSlowRequest when it exceeds SlowRequestThreshold
+func (c *Client) observeLatency(endpoint string, params map[string]string, duration time.Duration, err error) {
+	if c.latency == nil {
+		return
+	}
+	l := c.latency
+	l.mu.Lock()
+	if l.endpoints == nil {
+		l.endpoints = make(map[string]*endpointLatency)
+	}
+	e := l.endpoints[endpoint]
+	if e == nil {
+		e = &endpointLatency{}
+		l.endpoints[endpoint] = e
+	}
+	e.count++
+	if err != nil {
+		e.errors++
+	}
+	e.total += duration
+	e.max = max(e.max, duration)
+	if len(e.samples) < latencySamples {
+		e.samples = append(e.samples, duration)
+	} else {
+		e.samples[e.next] = duration
+		e.next = (e.next + 1) % latencySamples
+	}
+	l.mu.Unlock()
+
+	if c.SlowRequestThreshold > 0 && duration > c.SlowRequestThreshold && c.OnSlowRequest != nil {
+		c.OnSlowRequest(SlowRequest{Endpoint: endpoint, Params: maps.Clone(params), Duration: duration, Err: err})
+	}
+}
+
+// WriteLatencyMetrics writes the response time statistics of the client in the Prometheus text format
+func (c *Client) WriteLatencyMetrics(w io.Writer) error {
+	stats := c.LatencyStats()
+
+	const duration = "homematic_client_request_duration_seconds"
+	if err := writeMetricHeader(w, duration, "Response times of requests to the CCU by endpoint.", "summary"); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		endpoint := metricLabel{Name: "endpoint", Value: s.Endpoint}
+		for _, q := range []struct {
+			quantile float64
+			value    time.Duration
+		}{{0.5, s.P50}, {0.9, s.P90}, {0.99, s.P99}} {
+			labels := []metricLabel{endpoint, {Name: "quantile", Value: strconv.FormatFloat(q.quantile, 'g', -1, 64)}}
+			if err := writeMetricSample(w, duration, labels, q.value.Seconds()); err != nil {
+				return err
+			}
+		}
+		if err := writeMetricSample(w, duration+"_sum", []metricLabel{endpoint}, s.Total.Seconds()); err != nil {
+			return err
+		}
+		if err := writeMetricSample(w, duration+"_count", []metricLabel{endpoint}, float64(s.Count)); err != nil {
+			return err
+		}
+	}
+
+	const errors = "homematic_client_request_errors_total"
+	if err := writeMetricHeader(w, errors, "Number of failed requests to the CCU by endpoint.", "counter"); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		if err := writeMetricSample(w, errors, []metricLabel{{Name: "endpoint", Value: s.Endpoint}}, float64(s.Errors)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package homematic
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/state.cgi"):
+			time.Sleep(20 * time.Millisecond)
+			w.Write([]byte(`<stateList></stateList>`))
+		case strings.HasSuffix(r.URL.Path, "/version.cgi"):
+			w.Write([]byte(`<version>1.22</version>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	client.SlowRequestThreshold = 10 * time.Millisecond
+	var slow []SlowRequest
+	client.OnSlowRequest = func(req SlowRequest) { slow = append(slow, req) }
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetVersion(); err != nil {
+			t.Fatalf("GetVersion failed: %v", err)
+		}
+	}
+	if _, err := client.GetState(nil, nil, []string{"1234"}); err != nil {
+		t.Fatalf("GetState failed: %v", err)
+	}
+	if _, err := client.GetProgramList(); err == nil {
+		t.Fatal("expected GetProgramList to fail")
+	}
+
+	stats := client.LatencyStats()
+	if len(stats) != 3 {
+		t.Fatalf("expected stats for 3 endpoints, got %+v", stats)
+	}
+	byEndpoint := make(map[string]LatencyStats)
+	for _, s := range stats {
+		byEndpoint[s.Endpoint] = s
+	}
+	if s := byEndpoint["version.cgi"]; s.Count != 3 || s.Errors != 0 || s.P99 > s.Max {
+		t.Errorf("unexpected version.cgi stats: %+v", s)
+	}
+	if s := byEndpoint["programlist.cgi"]; s.Count != 1 || s.Errors != 1 {
+		t.Errorf("unexpected programlist.cgi stats: %+v", s)
+	}
+	if s := byEndpoint["state.cgi"]; s.P50 < 20*time.Millisecond {
+		t.Errorf("expected state.cgi median of at least 20ms, got %+v", s)
+	}
+
+	if len(slow) != 1 {
+		t.Fatalf("expected 1 slow request, got %+v", slow)
+	}
+	if slow[0].Endpoint != "state.cgi" || slow[0].Params["datapoint_id"] != "1234" {
+		t.Errorf("unexpected slow request: %+v", slow[0])
+	}
+	if _, ok := slow[0].Params["sid"]; ok {
+		t.Error("slow request must not include the session token")
+	}
+
+	var buf bytes.Buffer
+	if err := client.WriteLatencyMetrics(&buf); err != nil {
+		t.Fatalf("WriteLatencyMetrics failed: %v", err)
+	}
+	for _, want := range []string{
+		"# TYPE homematic_client_request_duration_seconds summary",
+		`homematic_client_request_duration_seconds{endpoint="state.cgi",quantile="0.99"}`,
+		`homematic_client_request_duration_seconds_count{endpoint="version.cgi"} 3`,
+		`homematic_client_request_errors_total{endpoint="programlist.cgi"} 1`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in metrics:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var samples []time.Duration
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	for _, tc := range []struct {
+		p    float64
+		want time.Duration
+	}{{0.5, 50 * time.Millisecond}, {0.9, 90 * time.Millisecond}, {0.99, 99 * time.Millisecond}, {1, 100 * time.Millisecond}} {
+		if got := percentile(samples, tc.p); got != tc.want {
+			t.Errorf("percentile(%g) = %s, want %s", tc.p, got, tc.want)
+		}
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("expected 0 for no samples, got %s", got)
+	}
+}
@@ -0,0 +1,100 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClientClosed is returned by requests of a closed client
+var ErrClientClosed = errors.New("client is closed")
+
+// lifecycle tracks in-flight requests and shutdown hooks; it is shared by all
+// copies of a client created with WithParams
+type lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	closers  []func(ctx context.Context) error
+	inFlight sync.WaitGroup
+}
+
+// OnClose registers a function that is called by Close, e.g. to stop a
+// background goroutine or flush a sink. Functions run in reverse order of
+// registration; on a closed client fn is not registered and ErrClientClosed is returned.
+func (c *Client) OnClose(fn func(ctx context.Context) error) error {
+	l := c.lifecycle
+	if l == nil {
+		return errors.New("client was not created by NewClient")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClientClosed
+	}
+	l.closers = append(l.closers, fn)
+	return nil
+}
+
+// Close shuts the client down: new requests fail with ErrClientClosed,
+// in-flight requests are waited for until ctx is done, the functions
+// registered with OnClose are called and idle connections are closed.
+// Closing a closed client is a no-op.
+func (c *Client) Close(ctx context.Context) error {
+	l := c.lifecycle
+	if l == nil {
+		l = &lifecycle{}
+		c.lifecycle = l
+	}
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	closers := l.closers
+	l.closers = nil
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		l.inFlight.Wait()
+		close(done)
+	}()
+
+	var errs []error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+	}
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.HTTPClient != nil {
+		c.HTTPClient.CloseIdleConnections()
+	}
+
+	return errors.Join(errs...)
+}
+
+// begin registers an in-flight request, failing if the client is closed
+func (l *lifecycle) begin() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClientClosed
+	}
+	l.inFlight.Add(1)
+	return nil
+}
+
+// end marks an in-flight request as finished
+func (l *lifecycle) end() {
+	l.inFlight.Done()
+}
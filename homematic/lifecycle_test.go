@@ -0,0 +1,85 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCloseRejectsNewRequests(t *testing.T) {
+	server := httptest.NewServer(staticHandler([]byte(`<version>1.22</version>`)))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	clone := client.WithParams(map[string]string{"show_internal": "1"})
+
+	var order []string
+	client.OnClose(func(context.Context) error { order = append(order, "first"); return nil })
+	client.OnClose(func(context.Context) error { order = append(order, "second"); return nil })
+
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(order) != 2 || order[0] != "second" || order[1] != "first" {
+		t.Errorf("expected close hooks in reverse order, got %v", order)
+	}
+
+	if _, err := client.GetVersion(); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+	if _, err := clone.GetVersion(); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed for copies of the client, got %v", err)
+	}
+	if err := client.OnClose(func(context.Context) error { return nil }); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed when registering on a closed client, got %v", err)
+	}
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("second Close should be a no-op, got %v", err)
+	}
+}
+
+func TestCloseWaitsForInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	result := make(chan error)
+	go func() {
+		_, err := client.GetVersion()
+		result <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Close to time out waiting for the request, got %v", err)
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("in-flight request should complete, got %v", err)
+	}
+}
+
+func TestCloseReportsHookErrors(t *testing.T) {
+	client := NewClient("http://127.0.0.1", "token")
+	errFlush := errors.New("flush failed")
+	client.OnClose(func(context.Context) error { return errFlush })
+
+	if err := client.Close(context.Background()); !errors.Is(err, errFlush) {
+		t.Errorf("expected hook error, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package homematic
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MaintenanceInfo summarizes the health data of a device's maintenance channel (channel 0)
+type MaintenanceInfo struct {
+	RSSIDevice    *int `json:"rssi_device,omitempty" yaml:"rssi_device,omitempty"`
+	RSSIPeer      *int `json:"rssi_peer,omitempty" yaml:"rssi_peer,omitempty"`
+	Unreach       bool `json:"unreach" yaml:"unreach"`
+	LowBat        bool `json:"low_bat" yaml:"low_bat"`
+	ConfigPending bool `json:"config_pending" yaml:"config_pending"`
+	UpdatePending bool `json:"update_pending" yaml:"update_pending"`
+	DutyCycle     bool `json:"duty_cycle" yaml:"duty_cycle"`
+}
+
+// maintenanceChannel returns the maintenance channel (channel 0) of a device
+func (d *Device) maintenanceChannel() *Channel {
+	for i := range d.Channels {
+		ch := &d.Channels[i]
+		if ch.Address != "" {
+			if strings.HasSuffix(ch.Address, ":0") {
+				return ch
+			}
+			continue
+		}
+		if ch.Index == 0 {
+			return ch
+		}
+	}
+	return nil
+}
+
+// newMaintenanceInfo extracts the maintenance data points of a device, or returns nil
+// if the device has no maintenance channel with data points
+func newMaintenanceInfo(d *Device) *MaintenanceInfo {
+	ch := d.maintenanceChannel()
+	if ch == nil || len(ch.DataPoints) == 0 {
+		return nil
+	}
+
+	info := &MaintenanceInfo{}
+	for _, dp := range ch.DataPoints {
+		switch dataPointType(dp) {
+		case "RSSI_DEVICE":
+			info.RSSIDevice = parseRSSI(dp.Value)
+		case "RSSI_PEER":
+			info.RSSIPeer = parseRSSI(dp.Value)
+		case "UNREACH":
+			info.Unreach = parseFlag(dp.Value)
+		case "LOWBAT", "LOW_BAT":
+			info.LowBat = parseFlag(dp.Value)
+		case "CONFIG_PENDING":
+			info.ConfigPending = parseFlag(dp.Value)
+		case "UPDATE_PENDING":
+			info.UpdatePending = parseFlag(dp.Value)
+		case "DUTY_CYCLE", "DUTYCYCLE":
+			info.DutyCycle = parseFlag(dp.Value)
+		}
+	}
+
+	return info
+}
+
+// attachMaintenanceInfo populates the Maintenance field of all given devices
+func attachMaintenanceInfo(devices []Device) {
+	for i := range devices {
+		devices[i].Maintenance = newMaintenanceInfo(&devices[i])
+	}
+}
+
+// dataPointType returns the type of a data point, falling back to the last
+// segment of its fully qualified name (e.g. "BidCos-RF.ABC0000001:0.UNREACH")
+func dataPointType(dp DataPoint) string {
+	if dp.Type != "" {
+		return dp.Type
+	}
+	if i := strings.LastIndex(dp.Name, "."); i >= 0 {
+		return dp.Name[i+1:]
+	}
+	return dp.Name
+}
+
+// parseFlag interprets a data point value as a boolean flag
+func parseFlag(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1":
+		return true
+	}
+	return false
+}
+
+// parseRSSI parses a RSSI value; empty or invalid values yield nil
+func parseRSSI(value string) *int {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+	rssi := int(f)
+	return &rssi
+}
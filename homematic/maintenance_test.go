@@ -0,0 +1,64 @@
+package homematic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const maintenanceStateList = `<?xml version="1.0" encoding="ISO-8859-1" ?>
+<stateList>
+	<device name="Thermostat" ise_id="1234" unreach="false" config_pending="false">
+		<channel name="Thermostat:0" ise_id="1235" index="0" visible="true" operate="true">
+			<datapoint name="HmIP-RF.000A1234567890:0.CONFIG_PENDING" type="CONFIG_PENDING" ise_id="1236" value="false" valuetype="2" valueunit="" timestamp="1700000000" />
+			<datapoint name="HmIP-RF.000A1234567890:0.DUTY_CYCLE" type="DUTY_CYCLE" ise_id="1237" value="false" valuetype="2" valueunit="" timestamp="1700000000" />
+			<datapoint name="HmIP-RF.000A1234567890:0.LOW_BAT" type="LOW_BAT" ise_id="1238" value="true" valuetype="2" valueunit="" timestamp="1700000000" />
+			<datapoint name="HmIP-RF.000A1234567890:0.RSSI_DEVICE" type="RSSI_DEVICE" ise_id="1239" value="-65" valuetype="8" valueunit="" timestamp="1700000000" />
+			<datapoint name="HmIP-RF.000A1234567890:0.RSSI_PEER" type="RSSI_PEER" ise_id="1240" value="" valuetype="8" valueunit="" timestamp="1700000000" />
+			<datapoint name="HmIP-RF.000A1234567890:0.UNREACH" type="UNREACH" ise_id="1241" value="true" valuetype="2" valueunit="" timestamp="1700000000" />
+		</channel>
+		<channel name="Thermostat:1" ise_id="1250" index="1" visible="true" operate="true">
+			<datapoint name="HmIP-RF.000A1234567890:1.ACTUAL_TEMPERATURE" type="ACTUAL_TEMPERATURE" ise_id="1251" value="21.5" valuetype="4" valueunit="" timestamp="1700000000" />
+		</channel>
+	</device>
+	<device name="Virtual" ise_id="2000" unreach="false" config_pending="false">
+		<channel name="Virtual:1" ise_id="2001" index="1" visible="true" operate="true" />
+	</device>
+</stateList>`
+
+func TestGetStateListMaintenanceInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(maintenanceStateList))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	devices, err := client.GetStateList("", false, false)
+	if err != nil {
+		t.Fatalf("GetStateList failed: %v", err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(devices))
+	}
+
+	info := devices[0].Maintenance
+	if info == nil {
+		t.Fatal("expected maintenance info for thermostat")
+	}
+	if !info.Unreach || !info.LowBat {
+		t.Errorf("expected unreach and low battery, got %+v", info)
+	}
+	if info.ConfigPending || info.UpdatePending || info.DutyCycle {
+		t.Errorf("unexpected pending/duty cycle flags: %+v", info)
+	}
+	if info.RSSIDevice == nil || *info.RSSIDevice != -65 {
+		t.Errorf("expected RSSI_DEVICE -65, got %v", info.RSSIDevice)
+	}
+	if info.RSSIPeer != nil {
+		t.Errorf("expected empty RSSI_PEER to be nil, got %d", *info.RSSIPeer)
+	}
+
+	if devices[1].Maintenance != nil {
+		t.Errorf("expected no maintenance info for device without channel 0, got %+v", devices[1].Maintenance)
+	}
+}
@@ -0,0 +1,253 @@
+package homematic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Metadata holds user defined tags, labels and notes of a device or channel,
+// which the CCU has no concept of
+type Metadata struct {
+	Tags   []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Notes  string            `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// HasTag reports whether the metadata contains a tag, ignoring case
+func (m *Metadata) HasTag(tag string) bool {
+	if m == nil {
+		return false
+	}
+	return slices.ContainsFunc(m.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+func (m *Metadata) empty() bool {
+	return len(m.Tags) == 0 && len(m.Labels) == 0 && m.Notes == ""
+}
+
+func (m Metadata) clone() Metadata {
+	m.Tags = slices.Clone(m.Tags)
+	m.Labels = maps.Clone(m.Labels)
+	return m
+}
+
+// MetadataStore keeps the metadata of devices and channels in a local JSON
+// file, keyed by their address, so that it survives re-pairing and CCU restores
+type MetadataStore struct {
+	// Path is the file the store is saved to; an empty path keeps it in memory
+	Path string
+	// Key encrypts the file if set
+	Key *EncryptionKey
+
+	mu      sync.Mutex
+	entries map[string]Metadata
+}
+
+// OpenMetadataStore loads a metadata store from a file; a missing file yields an empty store
+func OpenMetadataStore(path string, key *EncryptionKey) (*MetadataStore, error) {
+	s := &MetadataStore{Path: path, Key: key, entries: make(map[string]Metadata)}
+	data, err := ReadEncryptedFile(path, key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Get returns the metadata of a device or channel address
+func (s *MetadataStore) Get(address string) (Metadata, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.entries[address]
+	return m.clone(), ok
+}
+
+// Set replaces the metadata of an address and saves the store; empty metadata removes the address
+func (s *MetadataStore) Set(address string, m Metadata) error {
+	return s.update(address, func(old *Metadata) { *old = m.clone() })
+}
+
+// AddTags adds tags to an address and saves the store
+func (s *MetadataStore) AddTags(address string, tags ...string) error {
+	return s.update(address, func(m *Metadata) {
+		for _, tag := range tags {
+			if !m.HasTag(tag) {
+				m.Tags = append(m.Tags, tag)
+			}
+		}
+	})
+}
+
+// RemoveTags removes tags from an address and saves the store
+func (s *MetadataStore) RemoveTags(address string, tags ...string) error {
+	return s.update(address, func(m *Metadata) {
+		m.Tags = slices.DeleteFunc(m.Tags, func(t string) bool {
+			return slices.ContainsFunc(tags, func(tag string) bool { return strings.EqualFold(t, tag) })
+		})
+	})
+}
+
+// SetLabel sets a label of an address and saves the store; an empty value removes the label
+func (s *MetadataStore) SetLabel(address, key, value string) error {
+	return s.update(address, func(m *Metadata) {
+		if value == "" {
+			delete(m.Labels, key)
+			return
+		}
+		if m.Labels == nil {
+			m.Labels = make(map[string]string)
+		}
+		m.Labels[key] = value
+	})
+}
+
+// SetNotes sets the notes of an address and saves the store
+func (s *MetadataStore) SetNotes(address, notes string) error {
+	return s.update(address, func(m *Metadata) { m.Notes = notes })
+}
+
+// Addresses returns the sorted addresses with metadata
+func (s *MetadataStore) Addresses() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Sorted(maps.Keys(s.entries))
+}
+
+// Find returns the sorted addresses having all of the given tags and labels
+func (s *MetadataStore) Find(tags []string, labels map[string]string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var addresses []string
+	for address, m := range s.entries {
+		if matchesMetadata(&m, tags, labels) {
+			addresses = append(addresses, address)
+		}
+	}
+	slices.Sort(addresses)
+	return addresses
+}
+
+// Filter returns the devices having all of the given tags and labels, either
+// on the device itself or on one of its channels; devices matching through a
+// channel only keep the matching channels
+func (s *MetadataStore) Filter(devices []Device, tags []string, labels map[string]string) []Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var filtered []Device
+	for _, device := range devices {
+		if m, ok := s.entries[device.Address]; ok && matchesMetadata(&m, tags, labels) {
+			filtered = append(filtered, device)
+			continue
+		}
+		var channels []Channel
+		for _, ch := range device.Channels {
+			if m, ok := s.entries[ch.Address]; ok && matchesMetadata(&m, tags, labels) {
+				channels = append(channels, ch)
+			}
+		}
+		if len(channels) > 0 {
+			device.Channels = channels
+			filtered = append(filtered, device)
+		}
+	}
+	return filtered
+}
+
+// Apply sets the Metadata of the given devices and their channels, e.g. to include it in exports
+func (s *MetadataStore) Apply(devices []Device) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range devices {
+		device := &devices[i]
+		device.Metadata = s.lookup(device.Address)
+		for j := range device.Channels {
+			device.Channels[j].Metadata = s.lookup(device.Channels[j].Address)
+		}
+	}
+}
+
+func (s *MetadataStore) lookup(address string) *Metadata {
+	m, ok := s.entries[address]
+	if !ok || address == "" {
+		return nil
+	}
+	m = m.clone()
+	return &m
+}
+
+// update modifies the metadata of an address and saves the store, reverting the change if saving fails
+func (s *MetadataStore) update(address string, fn func(m *Metadata)) error {
+	if address == "" {
+		return errors.New("address must not be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries == nil {
+		s.entries = make(map[string]Metadata)
+	}
+	old, existed := s.entries[address]
+	m := old.clone()
+	fn(&m)
+	if m.empty() {
+		delete(s.entries, address)
+	} else {
+		s.entries[address] = m
+	}
+
+	if err := s.save(); err != nil {
+		if existed {
+			s.entries[address] = old
+		} else {
+			delete(s.entries, address)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *MetadataStore) save() error {
+	if s.Path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := WriteEncryptedFile(s.Path, data, s.Key); err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
+
+// matchesMetadata reports whether m has all tags and labels; label keys are case sensitive
+func matchesMetadata(m *Metadata, tags []string, labels map[string]string) bool {
+	for _, tag := range tags {
+		if !m.HasTag(tag) {
+			return false
+		}
+	}
+	for key, value := range labels {
+		if m.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
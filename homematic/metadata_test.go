@@ -0,0 +1,103 @@
+package homematic
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMetadataStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	store, err := OpenMetadataStore(path, nil)
+	if err != nil {
+		t.Fatalf("OpenMetadataStore failed: %v", err)
+	}
+
+	if err := store.AddTags("000A1D89A00001", "heating", "upstairs", "Heating"); err != nil {
+		t.Fatalf("AddTags failed: %v", err)
+	}
+	if err := store.SetLabel("000A1D89A00001", "floor", "1"); err != nil {
+		t.Fatalf("SetLabel failed: %v", err)
+	}
+	if err := store.SetNotes("000A1D89A00001", "battery replaced 2024-01"); err != nil {
+		t.Fatalf("SetNotes failed: %v", err)
+	}
+	if err := store.AddTags("000A1D89A00002:1", "window", "upstairs"); err != nil {
+		t.Fatalf("AddTags failed: %v", err)
+	}
+	if err := store.AddTags("MEQ0000006", "window"); err != nil {
+		t.Fatalf("AddTags failed: %v", err)
+	}
+	if err := store.RemoveTags("MEQ0000006", "WINDOW"); err != nil {
+		t.Fatalf("RemoveTags failed: %v", err)
+	}
+	if err := store.AddTags("", "invalid"); err == nil {
+		t.Error("expected an error for an empty address")
+	}
+
+	// the store is reloaded from the file
+	store, err = OpenMetadataStore(path, nil)
+	if err != nil {
+		t.Fatalf("OpenMetadataStore failed: %v", err)
+	}
+	if got := store.Addresses(); !slices.Equal(got, []string{"000A1D89A00001", "000A1D89A00002:1"}) {
+		t.Errorf("expected the metadata of two addresses, got %v", got)
+	}
+	m, ok := store.Get("000A1D89A00001")
+	if !ok || !slices.Equal(m.Tags, []string{"heating", "upstairs"}) || m.Labels["floor"] != "1" || m.Notes != "battery replaced 2024-01" {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+
+	if got := store.Find([]string{"UPSTAIRS"}, nil); len(got) != 2 {
+		t.Errorf("expected two addresses tagged upstairs, got %v", got)
+	}
+	if got := store.Find([]string{"upstairs"}, map[string]string{"floor": "1"}); !slices.Equal(got, []string{"000A1D89A00001"}) {
+		t.Errorf("expected the thermostat, got %v", got)
+	}
+}
+
+func TestMetadataStoreFilterAndExport(t *testing.T) {
+	store := &MetadataStore{}
+	store.AddTags("000A1D89A00001", "heating")
+	store.AddTags("000A1D89A00002:1", "window")
+
+	devices := []Device{
+		{Name: "Thermostat", Address: "000A1D89A00001", Channels: []Channel{
+			{Name: "Thermostat:0", Address: "000A1D89A00001:0"}, {Name: "Thermostat:1", Address: "000A1D89A00001:1"},
+		}},
+		{Name: "Fensterkontakt", Address: "000A1D89A00002", Channels: []Channel{
+			{Name: "Fensterkontakt:0", Address: "000A1D89A00002:0"}, {Name: "Fensterkontakt:1", Address: "000A1D89A00002:1"},
+		}},
+		{Name: "Schalter", Address: "000A1D89A00003"},
+	}
+
+	if got := store.Filter(devices, []string{"heating"}, nil); len(got) != 1 || len(got[0].Channels) != 2 {
+		t.Errorf("expected the thermostat with all channels, got %+v", got)
+	}
+	if got := store.Filter(devices, []string{"window"}, nil); len(got) != 1 || len(got[0].Channels) != 1 || got[0].Channels[0].Name != "Fensterkontakt:1" {
+		t.Errorf("expected the matching channel of the window contact, got %+v", got)
+	}
+
+	store.Apply(devices)
+	if !devices[0].Metadata.HasTag("heating") || devices[1].Metadata != nil || !devices[1].Channels[1].Metadata.HasTag("window") {
+		t.Errorf("metadata was not applied: %+v", devices)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportDevices(&buf, ExportCSV, devices); err != nil {
+		t.Fatalf("ExportDevices failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Fensterkontakt:1,000A1D89A00002:1,,,,window") {
+		t.Errorf("expected the channel tags in the CSV export:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := ExportDevices(&buf, ExportJSON, devices[:1]); err != nil {
+		t.Fatalf("ExportDevices failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"metadata": {`) {
+		t.Errorf("expected the metadata in the JSON export:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,53 @@
+package homematic
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// metricLabel is a single Prometheus label pair
+type metricLabel struct {
+	Name  string
+	Value string
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric in the Prometheus text format
+func writeMetricHeader(w io.Writer, name, help, metricType string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+	return err
+}
+
+// writeMetricSample writes a single sample line in the Prometheus text format
+func writeMetricSample(w io.Writer, name string, labels []metricLabel, value float64) error {
+	var sb strings.Builder
+	sb.WriteString(name)
+	if len(labels) > 0 {
+		sb.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(label.Name)
+			sb.WriteString(`="`)
+			sb.WriteString(escapeLabelValue(label.Value))
+			sb.WriteByte('"')
+		}
+		sb.WriteByte('}')
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	sb.WriteByte('\n')
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// labelValueEscaper escapes label values according to the Prometheus text format
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// escapeLabelValue escapes a label value according to the Prometheus text format
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
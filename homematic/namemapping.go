@@ -0,0 +1,97 @@
+package homematic
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NameMapping maps device and channel addresses to their names, so that a
+// naming scheme can be kept under version control and re-applied, e.g. after
+// restoring a CCU
+type NameMapping map[string]string
+
+// NameMappingFromDevices returns the current names of devices and their channels
+func NameMappingFromDevices(devices []Device) NameMapping {
+	mapping := make(NameMapping)
+	for _, device := range devices {
+		if device.Address != "" {
+			mapping[device.Address] = device.Name
+		}
+		for _, ch := range device.Channels {
+			if ch.Address != "" {
+				mapping[ch.Address] = ch.Name
+			}
+		}
+	}
+	return mapping
+}
+
+// Addresses returns the sorted addresses of the mapping
+func (m NameMapping) Addresses() []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// ExportNameMapping writes a name mapping in the given format: JSON and YAML
+// map addresses to names, CSV has an address and a name column, sorted by address
+func ExportNameMapping(w io.Writer, format ExportFormat, mapping NameMapping) error {
+	return export(w, format, mapping, func() [][]string {
+		rows := [][]string{{"address", "name"}}
+		for _, address := range mapping.Addresses() {
+			rows = append(rows, []string{address, mapping[address]})
+		}
+		return rows
+	})
+}
+
+// ReadNameMapping reads a name mapping written by ExportNameMapping or by
+// hand; the header row of CSV files is optional
+func ReadNameMapping(r io.Reader, format ExportFormat) (NameMapping, error) {
+	mapping := make(NameMapping)
+	switch format {
+	case ExportJSON:
+		if err := json.NewDecoder(r).Decode(&mapping); err != nil {
+			return nil, fmt.Errorf("failed to parse name mapping: %w", err)
+		}
+	case ExportYAML:
+		if err := yaml.NewDecoder(r).Decode(&mapping); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse name mapping: %w", err)
+		}
+	case ExportCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = 2
+		reader.Comment = '#'
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse name mapping: %w", err)
+		}
+		if len(records) > 0 && records[0][0] == "address" && records[0][1] == "name" {
+			records = records[1:]
+		}
+		for _, record := range records {
+			if _, ok := mapping[record[0]]; ok {
+				return nil, fmt.Errorf("duplicate address %s in name mapping", record[0])
+			}
+			mapping[record[0]] = record[1]
+		}
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	for address, name := range mapping {
+		if strings.TrimSpace(address) == "" {
+			return nil, errors.New("name mapping contains an empty address")
+		}
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("empty name for address %s in name mapping", address)
+		}
+	}
+	return mapping, nil
+}
@@ -0,0 +1,61 @@
+package homematic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNameMappingRoundTrip(t *testing.T) {
+	devices := []Device{
+		{Name: "Thermostat Schlafzimmer", Address: "000A1D89A00001", Channels: []Channel{
+			{Name: "Thermostat Schlafzimmer:0", Address: "000A1D89A00001:0"},
+			{Name: "Heizung, Schlafzimmer", Address: "000A1D89A00001:1"},
+		}},
+		{Name: "Fenster Gäste-WC", Address: "MEQ0000006"},
+	}
+	mapping := NameMappingFromDevices(devices)
+	if len(mapping) != 4 || mapping["000A1D89A00001:1"] != "Heizung, Schlafzimmer" {
+		t.Fatalf("unexpected mapping %v", mapping)
+	}
+
+	for _, format := range []ExportFormat{ExportJSON, ExportCSV, ExportYAML} {
+		var buf bytes.Buffer
+		if err := ExportNameMapping(&buf, format, mapping); err != nil {
+			t.Fatalf("ExportNameMapping(%s) failed: %v", format, err)
+		}
+		read, err := ReadNameMapping(&buf, format)
+		if err != nil {
+			t.Fatalf("ReadNameMapping(%s) failed: %v", format, err)
+		}
+		if len(read) != len(mapping) {
+			t.Errorf("%s: expected %d names, got %v", format, len(mapping), read)
+		}
+		for address, name := range mapping {
+			if read[address] != name {
+				t.Errorf("%s: expected %q for %s, got %q", format, name, address, read[address])
+			}
+		}
+	}
+}
+
+func TestReadNameMapping(t *testing.T) {
+	mapping, err := ReadNameMapping(strings.NewReader("# naming scheme\nMEQ0000006,Fenster Gäste-WC\n"), ExportCSV)
+	if err != nil || mapping["MEQ0000006"] != "Fenster Gäste-WC" {
+		t.Errorf("expected a CSV file without header to be read, got %v, %v", mapping, err)
+	}
+	mapping, err = ReadNameMapping(strings.NewReader("MEQ0000006: Fenster Gäste-WC\nMEQ0000006:1: Fenster Gäste-WC:1\n"), ExportYAML)
+	if err != nil || len(mapping) != 2 {
+		t.Errorf("expected a YAML mapping to be read, got %v, %v", mapping, err)
+	}
+
+	for name, input := range map[string]string{
+		"duplicate address": "MEQ0000006,A\nMEQ0000006,B\n",
+		"empty name":        "MEQ0000006,\n",
+		"missing column":    "MEQ0000006\n",
+	} {
+		if _, err := ReadNameMapping(strings.NewReader(input), ExportCSV); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
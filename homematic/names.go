@@ -0,0 +1,118 @@
+package homematic
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// umlautReplacer transliterates the German characters common in CCU names
+var umlautReplacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ß", "ss")
+
+// TransliterateName replaces umlauts and ß in a CCU name with their ASCII spelling
+func TransliterateName(name string) string {
+	return umlautReplacer.Replace(name)
+}
+
+// TopicSegment converts a CCU name into a single MQTT topic level: lower
+// case ASCII letters, digits, '-' and '_', with umlauts transliterated, '%'
+// spelled out and other characters, including the separator '/' and the
+// wildcards '+' and '#', collapsed into '_', e.g. "Bad/WC 1" becomes "bad_wc_1"
+func TopicSegment(name string) string {
+	name = strings.ReplaceAll(TransliterateName(name), "%", " percent ")
+	return slug(strings.ToLower(name), func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+	}, '_')
+}
+
+// PrometheusLabelValue cleans a CCU name for use as a label value: invalid
+// UTF-8 and control characters are dropped and whitespace is collapsed;
+// quoting is left to the exposition format
+func PrometheusLabelValue(name string) string {
+	name = strings.ToValidUTF8(name, "")
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// windowsReserved lists file names Windows refuses regardless of extension
+var windowsReserved = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// SafeFileName converts a CCU name into a file name that is valid on Linux,
+// macOS and Windows: umlauts are transliterated, characters other than ASCII
+// letters, digits, '.', '-' and '_' are collapsed into '_', leading dots are
+// removed and reserved Windows names get a trailing '_'
+func SafeFileName(name string) string {
+	name = slug(TransliterateName(name), func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_'
+	}, '_')
+	name = strings.TrimLeft(name, ".")
+	if len(name) > 200 {
+		name = name[:200]
+	}
+	base, _, _ := strings.Cut(name, ".")
+	if slices.Contains(windowsReserved, strings.ToUpper(base)) {
+		name += "_"
+	}
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// slug keeps the runes accepted by keep and replaces runs of other runes with sep
+func slug(s string, keep func(r rune) bool, sep rune) string {
+	var b strings.Builder
+	pending, last := false, rune(0)
+	for _, r := range s {
+		if r == utf8.RuneError || !keep(r) {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending && r != sep && last != sep {
+			b.WriteRune(sep)
+		}
+		pending, last = false, r
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NameCollision lists distinct names that normalize to the same identifier
+type NameCollision struct {
+	Normalized string   `json:"normalized"`
+	Names      []string `json:"names"`
+}
+
+// NameCollisions returns the identifiers that several distinct names map to
+// with normalize, e.g. TopicSegment for "Küche" and "Kueche", ordered by identifier
+func NameCollisions(names []string, normalize func(string) string) []NameCollision {
+	byNormalized := make(map[string][]string)
+	for _, name := range names {
+		normalized := normalize(name)
+		if !slices.Contains(byNormalized[normalized], name) {
+			byNormalized[normalized] = append(byNormalized[normalized], name)
+		}
+	}
+
+	var collisions []NameCollision
+	for normalized, originals := range byNormalized {
+		if len(originals) > 1 {
+			collisions = append(collisions, NameCollision{Normalized: normalized, Names: originals})
+		}
+	}
+	slices.SortFunc(collisions, func(a, b NameCollision) int { return strings.Compare(a.Normalized, b.Normalized) })
+	return collisions
+}
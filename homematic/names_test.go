@@ -0,0 +1,69 @@
+package homematic
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTopicSegment(t *testing.T) {
+	for name, want := range map[string]string{
+		"Bad/WC 1":              "bad_wc_1",
+		"Küche":                 "kueche",
+		"Luftfeuchte %":         "luftfeuchte_percent",
+		"Fenster #2 + Tür":      "fenster_2_tuer",
+		"HmIP-RF.000A1:1.STATE": "hmip-rf_000a1_1_state",
+		"  __Straße__  ":        "__strasse__",
+		"Wohnzimmer _ Decke":    "wohnzimmer_decke",
+		"Gäste-WC\x00":          "gaeste-wc",
+		"":                      "",
+	} {
+		if got := TopicSegment(name); got != want {
+			t.Errorf("TopicSegment(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPrometheusLabelValue(t *testing.T) {
+	for name, want := range map[string]string{
+		"Küche  Decke":      "Küche Decke",
+		" Bad\tOG\n":        "Bad OG",
+		"Licht\x07 \xffAus": "Licht Aus",
+	} {
+		if got := PrometheusLabelValue(name); got != want {
+			t.Errorf("PrometheusLabelValue(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSafeFileName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Bad/WC: Licht?":         "Bad_WC_Licht",
+		"..hidden":               "hidden",
+		"Gäste WC.json":          "Gaeste_WC.json",
+		"CON":                    "CON_",
+		"nul.txt":                "nul.txt_",
+		"50% Dimmer":             "50_Dimmer",
+		"***":                    "_",
+		strings.Repeat("a", 300): strings.Repeat("a", 200),
+	} {
+		if got := SafeFileName(name); got != want {
+			t.Errorf("SafeFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNameCollisions(t *testing.T) {
+	names := []string{"Küche", "Kueche", "Bad", "Küche", "bad", "Flur"}
+	got := NameCollisions(names, TopicSegment)
+	want := []NameCollision{
+		{Normalized: "bad", Names: []string{"Bad", "bad"}},
+		{Normalized: "kueche", Names: []string{"Küche", "Kueche"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected collisions: %+v", got)
+	}
+	if got := NameCollisions(names, PrometheusLabelValue); len(got) != 0 {
+		t.Errorf("expected no collisions of label values, got %+v", got)
+	}
+}
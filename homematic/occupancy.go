@@ -0,0 +1,199 @@
+package homematic
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultOccupancyHold is the time a room stays occupied after the last detected motion
+const DefaultOccupancyHold = 5 * time.Minute
+
+// occupancyTypes are the data point types reporting motion or presence
+var occupancyTypes = map[string]bool{
+	"MOTION":                   true,
+	"PRESENCE_DETECTION_STATE": true,
+	"PRESENCE":                 true,
+}
+
+// OccupancyTransition is a room becoming occupied or vacant
+type OccupancyTransition struct {
+	Room     string    `json:"room"`
+	Occupied bool      `json:"occupied"`
+	At       time.Time `json:"at"`
+	// IseID is the data point that reported the motion making the room occupied
+	IseID string `json:"ise_id,omitempty"`
+}
+
+// OccupancyTracker fuses the motion and presence data points of the channels
+// assigned to rooms into an occupied state per room. A room becomes occupied
+// with the first detection and vacant once none of its sensors has detected
+// anything for the hold time.
+type OccupancyTracker struct {
+	// Hold defaults to DefaultOccupancyHold; RoomHold overrides it per room
+	Hold     time.Duration
+	RoomHold map[string]time.Duration
+
+	mu      sync.Mutex
+	rooms   map[string][]string
+	sensors map[string]bool
+	state   map[string]*roomOccupancy
+}
+
+// roomOccupancy is the state of a room
+type roomOccupancy struct {
+	occupied bool
+	// active holds the sensors currently detecting motion or presence
+	active   map[string]bool
+	lastSeen time.Time
+}
+
+// NewOccupancyTracker creates a tracker for the channels assigned to rooms
+func NewOccupancyTracker(rooms []Room, hold time.Duration) *OccupancyTracker {
+	t := &OccupancyTracker{Hold: hold, sensors: make(map[string]bool), state: make(map[string]*roomOccupancy)}
+	t.SetRooms(rooms)
+	return t
+}
+
+// SetRooms updates the room assignments of channels, e.g. after the room list changed
+func (t *OccupancyTracker) SetRooms(rooms []Room) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rooms = make(map[string][]string)
+	for _, room := range rooms {
+		for _, ch := range room.Channels {
+			t.rooms[ch.IseID] = append(t.rooms[ch.IseID], room.Name)
+		}
+	}
+}
+
+// Observe processes the motion and presence data points of a state list
+// snapshot and returns the resulting transitions
+func (t *OccupancyTracker) Observe(devices []Device, at time.Time) []OccupancyTransition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transitions := t.expire(at)
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				transitions = append(transitions, t.observe(ch.IseID, dp.IseID, dataPointType(dp), dp.Value, at)...)
+			}
+		}
+	}
+	sortOccupancy(transitions)
+	return transitions
+}
+
+// ObserveChange processes a data point change and returns the resulting transitions
+func (t *OccupancyTracker) ObserveChange(change DataPointChange) []OccupancyTransition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transitions := t.expire(change.ObservedAt)
+	transitions = append(transitions, t.observe(change.ChannelIseID, change.IseID, change.Type, change.NewValue, change.ObservedAt)...)
+	sortOccupancy(transitions)
+	return transitions
+}
+
+// Flush returns the vacant transitions of rooms whose hold time has passed;
+// call it periodically, as vacancy is not signaled by a change
+func (t *OccupancyTracker) Flush(now time.Time) []OccupancyTransition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transitions := t.expire(now)
+	sortOccupancy(transitions)
+	return transitions
+}
+
+// Occupied reports whether a room is occupied
+func (t *OccupancyTracker) Occupied(room string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.state[room]
+	return ok && state.occupied
+}
+
+// OccupiedRooms returns the sorted names of the occupied rooms
+func (t *OccupancyTracker) OccupiedRooms() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var rooms []string
+	for room, state := range t.state {
+		if state.occupied {
+			rooms = append(rooms, room)
+		}
+	}
+	slices.Sort(rooms)
+	return rooms
+}
+
+func (t *OccupancyTracker) observe(channelID, iseID, dpType, value string, at time.Time) []OccupancyTransition {
+	if !occupancyTypes[dpType] {
+		return nil
+	}
+	detected := parseFlag(value)
+
+	var transitions []OccupancyTransition
+	for _, room := range t.rooms[channelID] {
+		state := t.state[room]
+		if state == nil {
+			state = &roomOccupancy{active: make(map[string]bool)}
+			t.state[room] = state
+		}
+
+		switch {
+		case detected:
+			state.active[iseID] = true
+			state.lastSeen = at
+			if !state.occupied {
+				state.occupied = true
+				transitions = append(transitions, OccupancyTransition{Room: room, Occupied: true, At: at, IseID: iseID})
+			}
+		case state.active[iseID]:
+			// the hold time starts when the last sensor stops detecting
+			delete(state.active, iseID)
+			state.lastSeen = at
+		}
+	}
+	return transitions
+}
+
+// expire marks rooms vacant whose sensors have all been idle for the hold time
+func (t *OccupancyTracker) expire(now time.Time) []OccupancyTransition {
+	var transitions []OccupancyTransition
+	for room, state := range t.state {
+		if !state.occupied || len(state.active) > 0 {
+			continue
+		}
+		vacantAt := state.lastSeen.Add(t.hold(room))
+		if now.Before(vacantAt) {
+			continue
+		}
+		state.occupied = false
+		transitions = append(transitions, OccupancyTransition{Room: room, Occupied: false, At: vacantAt})
+	}
+	return transitions
+}
+
+func (t *OccupancyTracker) hold(room string) time.Duration {
+	if hold, ok := t.RoomHold[room]; ok {
+		return hold
+	}
+	return durationOrDefault(t.Hold, DefaultOccupancyHold)
+}
+
+// sortOccupancy orders transitions by time and room
+func sortOccupancy(transitions []OccupancyTransition) {
+	slices.SortFunc(transitions, func(a, b OccupancyTransition) int {
+		if c := a.At.Compare(b.At); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Room, b.Room)
+	})
+}
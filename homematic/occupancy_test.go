@@ -0,0 +1,64 @@
+package homematic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccupancyTracker(t *testing.T) {
+	rooms := []Room{
+		{Name: "Flur", Channels: []Channel{{IseID: "10"}, {IseID: "20"}}},
+		{Name: "Bad", Channels: []Channel{{IseID: "30"}}},
+	}
+	tracker := NewOccupancyTracker(rooms, time.Minute)
+	tracker.RoomHold = map[string]time.Duration{"Bad": 10 * time.Minute}
+	at := time.Date(2024, 1, 10, 20, 0, 0, 0, time.UTC)
+
+	change := func(channel, iseID, dpType, value string, offset time.Duration) DataPointChange {
+		return DataPointChange{ChannelIseID: channel, IseID: iseID, Type: dpType, NewValue: value, ObservedAt: at.Add(offset)}
+	}
+
+	got := tracker.ObserveChange(change("10", "11", "MOTION", "true", 0))
+	if len(got) != 1 || got[0].Room != "Flur" || !got[0].Occupied || got[0].IseID != "11" {
+		t.Fatalf("expected the hallway to become occupied, got %+v", got)
+	}
+	// a second sensor of the same room does not emit another transition
+	if got := tracker.ObserveChange(change("20", "21", "PRESENCE_DETECTION_STATE", "true", 10*time.Second)); len(got) != 0 {
+		t.Errorf("expected no transition, got %+v", got)
+	}
+	// other data points and rooms are ignored
+	if got := tracker.ObserveChange(change("10", "12", "ILLUMINATION", "120", 15*time.Second)); len(got) != 0 {
+		t.Errorf("expected no transition, got %+v", got)
+	}
+
+	tracker.ObserveChange(change("10", "11", "MOTION", "false", 20*time.Second))
+	// the presence sensor still detects someone
+	if got := tracker.Flush(at.Add(5 * time.Minute)); len(got) != 0 || !tracker.Occupied("Flur") {
+		t.Errorf("expected the hallway to stay occupied, got %+v", got)
+	}
+	tracker.ObserveChange(change("20", "21", "PRESENCE_DETECTION_STATE", "false", 6*time.Minute))
+	if got := tracker.Flush(at.Add(6*time.Minute + 59*time.Second)); len(got) != 0 {
+		t.Errorf("expected the hold time to keep the hallway occupied, got %+v", got)
+	}
+	got = tracker.Flush(at.Add(8 * time.Minute))
+	if len(got) != 1 || got[0].Occupied || !got[0].At.Equal(at.Add(7*time.Minute)) {
+		t.Errorf("expected the hallway to become vacant after the hold time, got %+v", got)
+	}
+
+	// snapshots refresh the hold time while motion is reported
+	snapshot := func(value string) []Device {
+		return []Device{{Channels: []Channel{{IseID: "30", DataPoints: []DataPoint{{IseID: "31", Type: "MOTION", Value: value}}}}}}
+	}
+	if got := tracker.Observe(snapshot("true"), at.Add(10*time.Minute)); len(got) != 1 || got[0].Room != "Bad" {
+		t.Errorf("expected the bathroom to become occupied, got %+v", got)
+	}
+	tracker.Observe(snapshot("true"), at.Add(15*time.Minute))
+	tracker.Observe(snapshot("false"), at.Add(20*time.Minute))
+	if got := tracker.OccupiedRooms(); len(got) != 1 || got[0] != "Bad" {
+		t.Errorf("expected the bathroom to be occupied, got %v", got)
+	}
+	got = tracker.Observe(snapshot("false"), at.Add(31*time.Minute))
+	if len(got) != 1 || got[0].Occupied || !got[0].At.Equal(at.Add(30*time.Minute)) {
+		t.Errorf("expected the bathroom to become vacant with its own hold time, got %+v", got)
+	}
+}
@@ -0,0 +1,173 @@
+package homematic
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// openHABItem is the item created for a channel role; the data point differs
+// between HomeMatic IP and BidCos devices
+type openHABItem struct {
+	itemType  string
+	icon      string
+	dataPoint string
+	bidCos    string
+}
+
+// openHABItems maps channel roles to the items created for them
+var openHABItems = map[ChannelRole][]openHABItem{
+	RoleSwitch:     {{"Switch", "switch", "STATE", "STATE"}},
+	RoleDimmer:     {{"Dimmer", "light", "LEVEL", "LEVEL"}},
+	RoleBlind:      {{"Rollershutter", "rollershutter", "LEVEL", "LEVEL"}},
+	RoleThermostat: {{"Number:Temperature", "heating", "SET_POINT_TEMPERATURE", "SET_TEMPERATURE"}, {"Number:Temperature", "temperature", "ACTUAL_TEMPERATURE", "ACTUAL_TEMPERATURE"}},
+	RoleClimate:    {{"Number:Temperature", "temperature", "ACTUAL_TEMPERATURE", "TEMPERATURE"}, {"Number:Dimensionless", "humidity", "HUMIDITY", "HUMIDITY"}},
+	RoleContact:    {{"Contact", "contact", "STATE", "STATE"}},
+	RoleMotion:     {{"Switch", "motion", "MOTION", "MOTION"}},
+	RoleSmoke:      {{"String", "smoke", "SMOKE_DETECTOR_ALARM_STATUS", "STATE"}},
+	RoleWater:      {{"Switch", "water", "MOISTURE_DETECTED", "STATE"}},
+	RoleLock:       {{"Number", "lock", "LOCK_STATE", "STATE"}},
+}
+
+// OpenHABOptions configures the generated openHAB things and items
+type OpenHABOptions struct {
+	// Bridge is the id of the homematic bridge thing, "ccu" by default
+	Bridge string
+	// Gateway is the host name or IP address of the CCU
+	Gateway string
+	// Rooms restricts the things and items to channels in the given rooms
+	Rooms []string
+	// Roles restricts the things and items to channels with the given roles
+	Roles []ChannelRole
+}
+
+func (o OpenHABOptions) bridge() string {
+	if o.Bridge == "" {
+		return "ccu"
+	}
+	return o.Bridge
+}
+
+// ExportOpenHABThings writes an openHAB .things file with a homematic bridge
+// and a thing for every device with classified channels
+func ExportOpenHABThings(w io.Writer, topology *Topology, opts OpenHABOptions) error {
+	bw := bufio.NewWriter(w)
+	rooms := channelRooms(topology)
+
+	fmt.Fprintf(bw, "Bridge homematic:bridge:%s [ gatewayAddress=%s ] {\n", opts.bridge(), strconv.Quote(opts.Gateway))
+	for i := range topology.Devices {
+		device := &topology.Devices[i]
+		if device.Address == "" || device.DeviceType == "" {
+			continue
+		}
+		found, location := false, ""
+		forEachChannelRole(device, rooms, opts.Rooms, opts.Roles, func(_ *Channel, _ ChannelRole, room string) {
+			found = true
+			if location == "" {
+				location = room
+			}
+		})
+		if !found {
+			continue
+		}
+		fmt.Fprintf(bw, "  Thing %s %s %s", openHABThingType(device), openHABID(device.Address), strconv.Quote(device.Name))
+		if location != "" {
+			fmt.Fprintf(bw, " @ %s", strconv.Quote(location))
+		}
+		fmt.Fprintln(bw)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// ExportOpenHABItems writes an openHAB .items file linking the data points of
+// classified channels to items; rooms become groups
+func ExportOpenHABItems(w io.Writer, topology *Topology, opts OpenHABOptions) error {
+	bw := bufio.NewWriter(w)
+	rooms := channelRooms(topology)
+
+	groups := make(map[string]string)
+	for _, room := range topology.Rooms {
+		if len(opts.Rooms) > 0 && !containsAnyFold(opts.Rooms, []string{room.Name}) {
+			continue
+		}
+		if _, ok := groups[room.Name]; ok {
+			continue
+		}
+		groups[room.Name] = "g" + openHABItemName(room.Name)
+		fmt.Fprintf(bw, "Group %s %s\n", groups[room.Name], strconv.Quote(room.Name))
+	}
+	if len(groups) > 0 {
+		fmt.Fprintln(bw)
+	}
+
+	names := make(map[string]int)
+	for i := range topology.Devices {
+		device := &topology.Devices[i]
+		if device.Address == "" || device.DeviceType == "" {
+			continue
+		}
+		hmIP := strings.HasPrefix(strings.ToUpper(device.DeviceType), "HMIP")
+		forEachChannelRole(device, rooms, opts.Rooms, opts.Roles, func(ch *Channel, role ChannelRole, room string) {
+			for _, item := range openHABItems[role] {
+				dataPoint := item.dataPoint
+				if !hmIP {
+					dataPoint = item.bidCos
+				}
+
+				name := openHABItemName(ch.Name + "_" + dataPoint)
+				names[name]++
+				if n := names[name]; n > 1 {
+					name = fmt.Sprintf("%s_%d", name, n)
+				}
+
+				fmt.Fprintf(bw, "%s %s %s <%s>", item.itemType, name, strconv.Quote(ch.Name+" "+dataPoint), item.icon)
+				if group, ok := groups[room]; ok {
+					fmt.Fprintf(bw, " (%s)", group)
+				}
+				fmt.Fprintf(bw, " { channel=\"homematic:%s:%s:%s:%d#%s\" }\n",
+					openHABThingType(device), opts.bridge(), openHABID(device.Address), ch.Index, dataPoint)
+			}
+		})
+	}
+	return bw.Flush()
+}
+
+// openHABThingType returns the thing type id of a device as used by the homematic binding
+func openHABThingType(device *Device) string {
+	return openHABID(device.DeviceType)
+}
+
+// openHABID replaces the characters not allowed in openHAB UID segments
+func openHABID(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// openHABItemName converts a name into a valid item name, transliterating umlauts
+func openHABItemName(s string) string {
+	s = TransliterateName(s)
+	var b strings.Builder
+	underscore := false
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	name := strings.TrimRight(b.String(), "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
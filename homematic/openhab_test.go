@@ -0,0 +1,83 @@
+package homematic
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func openHABTopology(t *testing.T) *Topology {
+	t.Helper()
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	topology, err := NewClient(server.URL, "token").GetTopology()
+	if err != nil {
+		t.Fatalf("GetTopology failed: %v", err)
+	}
+	return topology
+}
+
+func TestExportOpenHABThings(t *testing.T) {
+	topology := openHABTopology(t)
+
+	var buf bytes.Buffer
+	if err := ExportOpenHABThings(&buf, topology, OpenHABOptions{Gateway: "192.168.1.100"}); err != nil {
+		t.Fatalf("ExportOpenHABThings failed: %v", err)
+	}
+	things := buf.String()
+	for _, want := range []string{
+		`Bridge homematic:bridge:ccu [ gatewayAddress="192.168.1.100" ] {`,
+		`  Thing HmIP-eTRV-2 000A1D89A00001 "Thermostat Schlafzimmer" @ "Schlafzimmer"`,
+		`  Thing HM-LC-Dim1T-FM MEQ0000004 "Dimmer Wohnzimmer"`,
+	} {
+		if !strings.Contains(things, want) {
+			t.Errorf("expected %q in things:\n%s", want, things)
+		}
+	}
+}
+
+func TestExportOpenHABItems(t *testing.T) {
+	topology := openHABTopology(t)
+
+	var buf bytes.Buffer
+	if err := ExportOpenHABItems(&buf, topology, OpenHABOptions{Bridge: "home"}); err != nil {
+		t.Fatalf("ExportOpenHABItems failed: %v", err)
+	}
+	items := buf.String()
+	for _, want := range []string{
+		`Group gSchlafzimmer "Schlafzimmer"`,
+		`Number:Temperature Thermostat_Schlafzimmer_1_SET_POINT_TEMPERATURE "Thermostat Schlafzimmer:1 SET_POINT_TEMPERATURE" <heating> (gSchlafzimmer) { channel="homematic:HmIP-eTRV-2:home:000A1D89A00001:1#SET_POINT_TEMPERATURE" }`,
+		`Dimmer Dimmer_Wohnzimmer_1_LEVEL "Dimmer Wohnzimmer:1 LEVEL" <light>`,
+		`Switch Schalter_Esszimmer_4_STATE "Schalter Esszimmer:4 STATE" <switch> (gEsszimmer)`,
+		`Contact Fenster_Gaeste_WC_1_STATE "Fenster Gäste-WC:1 STATE" <contact> (gGaeste_WC)`,
+	} {
+		if !strings.Contains(items, want) {
+			t.Errorf("expected %q in items:\n%s", want, items)
+		}
+	}
+	// key channels trigger events and have no items
+	if strings.Contains(items, "PRESS_SHORT") {
+		t.Errorf("expected no items for key channels:\n%s", items)
+	}
+}
+
+func TestOpenHABItemName(t *testing.T) {
+	tests := map[string]string{
+		"Küche Fenster:1": "Kueche_Fenster_1",
+		"Straße":          "Strasse",
+		"1. OG":           "_1_OG",
+		"  Flur  ":        "Flur",
+	}
+	for in, want := range tests {
+		if got := openHABItemName(in); got != want {
+			t.Errorf("openHABItemName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
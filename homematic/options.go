@@ -0,0 +1,74 @@
+package homematic
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Option configures a client created by NewClient. Options never modify the
+// *http.Client or *http.Transport they are given, e.g. http.DefaultClient,
+// but change copies of them. An option that fails is reported by every
+// request of the client.
+type Option func(c *Client) error
+
+// WithTimeout sets the timeout of each request, 30 seconds by default
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		client := *c.HTTPClient
+		client.Timeout = timeout
+		c.HTTPClient = &client
+		return nil
+	}
+}
+
+// WithTLSConfig replaces the TLS configuration of the transport, which skips
+// certificate verification by default as CCUs use self-signed certificates.
+// Pass e.g. &tls.Config{} to verify the certificate against the system roots.
+// It fails for transports other than *http.Transport, optionally wrapped by a DigestTransport.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) error {
+		wrapped, transport, ok := cloneTransport(c.HTTPClient.Transport)
+		if !ok {
+			return fmt.Errorf("TLS configuration requires an *http.Transport, got %T", c.HTTPClient.Transport)
+		}
+		transport.TLSClientConfig = config.Clone()
+
+		client := *c.HTTPClient
+		client.Transport = wrapped
+		c.HTTPClient = &client
+		return nil
+	}
+}
+
+// WithTransport replaces the transport requests are sent with, e.g. to record
+// or instrument them
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) error {
+		client := *c.HTTPClient
+		client.Transport = transport
+		c.HTTPClient = &client
+		return nil
+	}
+}
+
+// WithHTTPClient replaces the HTTP client; options applied after it change a copy of it
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) error {
+		if client == nil {
+			return errors.New("HTTP client must not be nil")
+		}
+		c.HTTPClient = client
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of all requests
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		c.UserAgent = userAgent
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package homematic
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripCounter counts the requests sent through it
+type roundTripCounter struct {
+	requests int
+}
+
+func (r *roundTripCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewClientOptions(t *testing.T) {
+	var userAgent string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		w.Write([]byte(`<version>2.0</version>`))
+	}))
+	defer server.Close()
+
+	// the default client accepts the self-signed certificate
+	if _, err := NewClient(server.URL, "token").GetVersion(); err != nil {
+		t.Fatalf("expected the default client to skip verification: %v", err)
+	}
+	if _, err := NewClient(server.URL, "token", WithTLSConfig(&tls.Config{})).GetVersion(); err == nil {
+		t.Error("expected the certificate to be verified")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	client := NewClient(server.URL, "token",
+		WithTLSConfig(&tls.Config{RootCAs: roots}),
+		WithTimeout(5*time.Second),
+		WithUserAgent("automation/1.0"),
+	)
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatalf("expected the certificate to be trusted: %v", err)
+	}
+	if userAgent != "automation/1.0" {
+		t.Errorf("expected the user agent to be sent, got %q", userAgent)
+	}
+	if client.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("expected a timeout of 5s, got %s", client.HTTPClient.Timeout)
+	}
+
+	if _, err := NewClient(server.URL, "token", WithHTTPClient(server.Client())).GetVersion(); err != nil {
+		t.Errorf("expected the given HTTP client to be used: %v", err)
+	}
+
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<version>2.0</version>`))
+	}))
+	defer plain.Close()
+	counter := &roundTripCounter{}
+	if _, err := NewClient(plain.URL, "token", WithTransport(counter)).GetVersion(); err != nil {
+		t.Fatal(err)
+	}
+	if counter.requests != 1 {
+		t.Errorf("expected the request to use the transport, got %d requests", counter.requests)
+	}
+}
+
+func TestNewClientOptionsCopy(t *testing.T) {
+	base := &http.Client{Transport: &http.Transport{}}
+	transport := base.Transport.(*http.Transport)
+	client := NewClient("https://ccu", "token",
+		WithHTTPClient(base),
+		WithTimeout(time.Second),
+		WithTLSConfig(&tls.Config{ServerName: "ccu"}),
+	)
+	// Clone may set up HTTP/2 in the TLS configuration of the original transport
+	if base.Timeout != 0 || base.Transport != transport || (transport.TLSClientConfig != nil && transport.TLSClientConfig.ServerName != "") {
+		t.Error("expected the given client and transport to be left alone")
+	}
+	if client.HTTPClient == base || client.HTTPClient.Timeout != time.Second ||
+		client.HTTPClient.Transport.(*http.Transport).TLSClientConfig.ServerName != "ccu" {
+		t.Errorf("expected the options to apply to a copy, got %+v", client.HTTPClient)
+	}
+
+	counter := &roundTripCounter{}
+	client = NewClient("https://ccu", "token", WithTransport(counter), WithTLSConfig(&tls.Config{}))
+	if _, err := client.GetVersion(); err == nil || !strings.Contains(err.Error(), "TLS configuration requires an *http.Transport") {
+		t.Errorf("expected the failed option to be reported, got %v", err)
+	}
+	if counter.requests != 0 {
+		t.Errorf("expected no request to be sent, got %d", counter.requests)
+	}
+}
+
+func TestWithTLSConfigDigestAuth(t *testing.T) {
+	client := NewClient("https://ccu", "token")
+	client.SetDigestAuth("admin", "secret")
+	digest := client.HTTPClient.Transport.(*DigestTransport)
+
+	if err := WithTLSConfig(&tls.Config{ServerName: "ccu"})(client); err != nil {
+		t.Fatalf("WithTLSConfig failed: %v", err)
+	}
+	wrapped, ok := client.HTTPClient.Transport.(*DigestTransport)
+	if !ok || wrapped == digest || wrapped.Username != "admin" {
+		t.Fatalf("expected a copy of the digest transport, got %T", client.HTTPClient.Transport)
+	}
+	if wrapped.Transport.(*http.Transport).TLSClientConfig.ServerName != "ccu" {
+		t.Error("expected the TLS configuration to apply to the wrapped transport")
+	}
+	if digest.Transport.(*http.Transport).TLSClientConfig.ServerName != "" {
+		t.Error("expected the original transport to be left alone")
+	}
+}
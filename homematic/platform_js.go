@@ -0,0 +1,8 @@
+//go:build js && wasm
+
+package homematic
+
+// browserTransport is set in WebAssembly builds, where requests go through
+// the Fetch API of the browser, which opens connections and verifies
+// certificates itself
+const browserTransport = true
@@ -0,0 +1,20 @@
+//go:build !(js && wasm)
+
+package homematic
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestBuildWebAssembly(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the package for js/wasm")
+	}
+	cmd := exec.Command("go", "build", "-o", os.DevNull, ".")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("js/wasm build failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,53 @@
+package homematic
+
+import "fmt"
+
+// Key press data point types of KEY channels (remotes, wall switches and the
+// virtual remote keys of the CCU)
+const (
+	DataPointPressShort = "PRESS_SHORT"
+	DataPointPressLong  = "PRESS_LONG"
+)
+
+// PressShort simulates a short key press on a key channel, which triggers the
+// direct links and programs of the key like a physical press
+func (c *Client) PressShort(channelID string) error {
+	return c.press(channelID, DataPointPressShort)
+}
+
+// PressLong simulates a long key press on a key channel
+func (c *Client) PressLong(channelID string) error {
+	return c.press(channelID, DataPointPressLong)
+}
+
+// press writes true to the action data point of the given type of a channel
+func (c *Client) press(channelID, dpType string) error {
+	devices, err := c.GetState(nil, []string{channelID}, nil)
+	if err != nil {
+		return err
+	}
+
+	dp := findChannelDataPoint(devices, channelID, dpType)
+	if dp == nil {
+		return fmt.Errorf("channel %s has no %s data point", channelID, dpType)
+	}
+	return c.ChangeState([]string{dp.IseID}, []string{"true"})
+}
+
+// findChannelDataPoint returns the data point of the given type of a channel, or nil
+func findChannelDataPoint(devices []Device, channelID, dpType string) *DataPoint {
+	for i := range devices {
+		for j := range devices[i].Channels {
+			ch := &devices[i].Channels[j]
+			if ch.IseID != channelID {
+				continue
+			}
+			for k := range ch.DataPoints {
+				if dataPointType(ch.DataPoints[k]) == dpType {
+					return &ch.DataPoints[k]
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package homematic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestPress(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	var changes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/addons/xmlapi/statechange.cgi" {
+			changes = append(changes, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+		}
+		mock.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	if err := client.PressShort("2438"); err != nil {
+		t.Fatalf("PressShort failed: %v", err)
+	}
+	if err := client.PressLong("2441"); err != nil {
+		t.Fatalf("PressLong failed: %v", err)
+	}
+	if len(changes) != 2 || changes[0] != "2440=true" || changes[1] != "2442=true" {
+		t.Errorf("unexpected state changes: %v", changes)
+	}
+
+	// the switch actuator channel has no key press data points
+	if err := client.PressShort("2444"); err == nil {
+		t.Error("expected an error for a channel without PRESS_SHORT")
+	}
+	if len(changes) != 2 {
+		t.Errorf("expected no state change for a non-key channel, got %v", changes)
+	}
+}
@@ -0,0 +1,362 @@
+package homematic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PriceFormat is the response format of an electricity price API
+type PriceFormat string
+
+const (
+	// PriceAWATTar is the aWATTar market data API, prices in EUR/MWh
+	PriceAWATTar PriceFormat = "awattar"
+	// PriceEnergyCharts is the EPEX spot price API of energy-charts.info, prices in EUR/MWh
+	PriceEnergyCharts PriceFormat = "energy-charts"
+	// PriceTibber is the Tibber GraphQL API, total prices including taxes in the currency of the home
+	PriceTibber PriceFormat = "tibber"
+)
+
+// default endpoints of the price APIs
+var priceURLs = map[PriceFormat]string{
+	PriceAWATTar:      "https://api.awattar.de/v1/marketdata",
+	PriceEnergyCharts: "https://api.energy-charts.info/price?bzn=DE-LU",
+	PriceTibber:       "https://api.tibber.com/v1-beta/gql",
+}
+
+const tibberPriceQuery = `{ viewer { homes { currentSubscription { priceInfo {
+  today { total startsAt } tomorrow { total startsAt } } } } } }`
+
+// PriceSlot is the electricity price of a time slot, usually an hour
+type PriceSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Price float64   `json:"price"`
+}
+
+// Contains reports whether t lies within the slot
+func (s PriceSlot) Contains(t time.Time) bool {
+	return !t.Before(s.Start) && t.Before(s.End)
+}
+
+// PriceFeed fetches hourly electricity prices from an HTTP API
+type PriceFeed struct {
+	Format PriceFormat
+
+	// URL overrides the default endpoint of the format, e.g. to select
+	// another bidding zone or the Austrian aWATTar API
+	URL string
+
+	// Token is the API token sent as bearer token, required by Tibber
+	Token string
+
+	HTTPClient *http.Client
+}
+
+// Fetch requests the current prices, sorted by start time
+func (f *PriceFeed) Fetch(ctx context.Context) ([]PriceSlot, error) {
+	url := f.URL
+	if url == "" {
+		url = priceURLs[f.Format]
+	}
+	if url == "" {
+		return nil, fmt.Errorf("unsupported price format: %s", f.Format)
+	}
+
+	method, body := http.MethodGet, io.Reader(nil)
+	if f.Format == PriceTibber {
+		query, err := json.Marshal(map[string]string{"query": tibberPriceQuery})
+		if err != nil {
+			return nil, err
+		}
+		method, body = http.MethodPost, bytes.NewReader(query)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if f.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+f.Token)
+	}
+
+	httpClient := f.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch prices: unexpected status code %d", resp.StatusCode)
+	}
+	return ParsePrices(resp.Body, f.Format)
+}
+
+// ParsePrices decodes a response of a price API, sorted by start time
+func ParsePrices(r io.Reader, format PriceFormat) ([]PriceSlot, error) {
+	var prices []PriceSlot
+	switch format {
+	case PriceAWATTar:
+		var resp struct {
+			Data []struct {
+				Start       int64   `json:"start_timestamp"`
+				End         int64   `json:"end_timestamp"`
+				MarketPrice float64 `json:"marketprice"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(r).Decode(&resp); err != nil {
+			return nil, fmt.Errorf("failed to decode prices: %w", err)
+		}
+		for _, d := range resp.Data {
+			prices = append(prices, PriceSlot{Start: time.UnixMilli(d.Start), End: time.UnixMilli(d.End), Price: d.MarketPrice})
+		}
+	case PriceEnergyCharts:
+		var resp struct {
+			UnixSeconds []int64   `json:"unix_seconds"`
+			Price       []float64 `json:"price"`
+		}
+		if err := json.NewDecoder(r).Decode(&resp); err != nil {
+			return nil, fmt.Errorf("failed to decode prices: %w", err)
+		}
+		if len(resp.UnixSeconds) != len(resp.Price) {
+			return nil, errors.New("failed to decode prices: timestamps and prices differ in length")
+		}
+		for i, ts := range resp.UnixSeconds {
+			prices = append(prices, PriceSlot{Start: time.Unix(ts, 0), Price: resp.Price[i]})
+		}
+	case PriceTibber:
+		type tibberPrice struct {
+			Total    float64   `json:"total"`
+			StartsAt time.Time `json:"startsAt"`
+		}
+		var resp struct {
+			Data struct {
+				Viewer struct {
+					Homes []struct {
+						CurrentSubscription struct {
+							PriceInfo struct {
+								Today    []tibberPrice `json:"today"`
+								Tomorrow []tibberPrice `json:"tomorrow"`
+							} `json:"priceInfo"`
+						} `json:"currentSubscription"`
+					} `json:"homes"`
+				} `json:"viewer"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.NewDecoder(r).Decode(&resp); err != nil {
+			return nil, fmt.Errorf("failed to decode prices: %w", err)
+		}
+		if len(resp.Errors) > 0 {
+			var messages []string
+			for _, e := range resp.Errors {
+				messages = append(messages, e.Message)
+			}
+			return nil, fmt.Errorf("failed to fetch prices: %s", strings.Join(messages, "; "))
+		}
+		if len(resp.Data.Viewer.Homes) == 0 {
+			return nil, errors.New("failed to fetch prices: no home with a subscription")
+		}
+		info := resp.Data.Viewer.Homes[0].CurrentSubscription.PriceInfo
+		for _, p := range append(info.Today, info.Tomorrow...) {
+			prices = append(prices, PriceSlot{Start: p.StartsAt, Price: p.Total})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported price format: %s", format)
+	}
+
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Start.Before(prices[j].Start) })
+	// slots without an end last until the next slot, or an hour for the last one
+	for i := range prices {
+		if !prices[i].End.IsZero() {
+			continue
+		}
+		if i+1 < len(prices) {
+			prices[i].End = prices[i+1].Start
+		} else {
+			prices[i].End = prices[i].Start.Add(time.Hour)
+		}
+	}
+	return prices, nil
+}
+
+// CheapestSlots returns the n cheapest slots lying within [from, to), in time order
+func CheapestSlots(prices []PriceSlot, n int, from, to time.Time) []PriceSlot {
+	candidates := slotsBetween(prices, from, to)
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Price < candidates[j].Price })
+	if n < len(candidates) {
+		candidates = candidates[:n]
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Start.Before(candidates[j].Start) })
+	return candidates
+}
+
+// CheapestWindow returns the n consecutive slots within [from, to) with the
+// lowest total price, e.g. for appliances that must not be interrupted; it
+// returns nil if there are fewer than n slots
+func CheapestWindow(prices []PriceSlot, n int, from, to time.Time) []PriceSlot {
+	candidates := slotsBetween(prices, from, to)
+	if n <= 0 || n > len(candidates) {
+		return nil
+	}
+
+	best, bestSum := -1, 0.0
+	for i := 0; i+n <= len(candidates); i++ {
+		sum, gap := 0.0, false
+		for j := i; j < i+n; j++ {
+			sum += candidates[j].Price
+			if j > i && !candidates[j].Start.Equal(candidates[j-1].End) {
+				gap = true
+			}
+		}
+		if !gap && (best < 0 || sum < bestSum) {
+			best, bestSum = i, sum
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return candidates[best : best+n]
+}
+
+// slotsBetween returns a copy of the slots lying within [from, to)
+func slotsBetween(prices []PriceSlot, from, to time.Time) []PriceSlot {
+	var slots []PriceSlot
+	for _, p := range prices {
+		if !p.Start.Before(from) && !p.End.After(to) {
+			slots = append(slots, p)
+		}
+	}
+	return slots
+}
+
+// PriceLoad is a switchable load, e.g. a boiler or an EV charger plug, that
+// runs for a number of slots per day during the cheapest prices
+type PriceLoad struct {
+	Name string
+
+	// IseIDs are the STATE data points switched on during the selected slots
+	IseIDs []string
+
+	// Slots is the number of slots the load runs per window
+	Slots int
+
+	// WindowStart and WindowEnd are the offsets from midnight bounding the
+	// window the slots are chosen from, e.g. 18h and 31h for charging an EV
+	// overnight until 7:00; the default is the whole day
+	WindowStart time.Duration
+	WindowEnd   time.Duration
+
+	// Contiguous selects consecutive slots instead of the cheapest ones
+	Contiguous bool
+}
+
+// window returns the window containing now
+func (l *PriceLoad) window(now time.Time) (from, to time.Time) {
+	length := l.WindowEnd - l.WindowStart
+	if length <= 0 {
+		length += 24 * time.Hour
+	}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from = midnight.Add(l.WindowStart)
+	if from.After(now) {
+		from = from.AddDate(0, 0, -1)
+	}
+	return from, from.Add(length)
+}
+
+// PriceScheduler switches loads on during the cheapest slots of their window
+// and off otherwise. Prices are set with SetPrices, e.g. from a PriceFeed
+// fetched daily once the prices of the next day are published; Apply is
+// called periodically, e.g. every minute.
+type PriceScheduler struct {
+	Client *Client
+	Loads  []PriceLoad
+
+	mu     sync.Mutex
+	prices []PriceSlot
+	state  map[string]bool
+}
+
+// NewPriceScheduler creates a scheduler for the given loads
+func NewPriceScheduler(client *Client, loads ...PriceLoad) *PriceScheduler {
+	return &PriceScheduler{Client: client, Loads: loads}
+}
+
+// SetPrices replaces the prices the slots are chosen from
+func (s *PriceScheduler) SetPrices(prices []PriceSlot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prices = append([]PriceSlot(nil), prices...)
+}
+
+// Plan returns the slots the load runs in during the window containing now
+func (s *PriceScheduler) Plan(load PriceLoad, now time.Time) []PriceSlot {
+	s.mu.Lock()
+	prices := s.prices
+	s.mu.Unlock()
+
+	from, to := load.window(now)
+	if load.Contiguous {
+		return CheapestWindow(prices, load.Slots, from, to)
+	}
+	return CheapestSlots(prices, load.Slots, from, to)
+}
+
+// Apply switches every load on or off depending on whether now lies within
+// one of its planned slots. State changes are only sent when the desired
+// state of a load changed since the previous call.
+func (s *PriceScheduler) Apply(now time.Time) error {
+	var errs []error
+	for _, load := range s.Loads {
+		on := false
+		for _, slot := range s.Plan(load, now) {
+			if slot.Contains(now) {
+				on = true
+				break
+			}
+		}
+
+		s.mu.Lock()
+		current, known := s.state[load.Name]
+		s.mu.Unlock()
+		if known && current == on {
+			continue
+		}
+
+		values := make([]string, len(load.IseIDs))
+		for i := range values {
+			values[i] = strconv.FormatBool(on)
+		}
+		if err := s.Client.ChangeState(load.IseIDs, values); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", load.Name, err))
+			continue
+		}
+
+		s.mu.Lock()
+		if s.state == nil {
+			s.state = make(map[string]bool)
+		}
+		s.state[load.Name] = on
+		s.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
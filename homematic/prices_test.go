@@ -0,0 +1,154 @@
+package homematic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hourlyPrices returns hourly slots starting at start with the given prices
+func hourlyPrices(start time.Time, prices ...float64) []PriceSlot {
+	slots := make([]PriceSlot, len(prices))
+	for i, price := range prices {
+		slots[i] = PriceSlot{Start: start.Add(time.Duration(i) * time.Hour), End: start.Add(time.Duration(i+1) * time.Hour), Price: price}
+	}
+	return slots
+}
+
+func TestParsePrices(t *testing.T) {
+	tests := []struct {
+		format PriceFormat
+		body   string
+	}{
+		{PriceAWATTar, `{"object":"list","data":[
+			{"start_timestamp":1700002800000,"end_timestamp":1700006400000,"marketprice":80.5,"unit":"Eur/MWh"},
+			{"start_timestamp":1699999200000,"end_timestamp":1700002800000,"marketprice":95.1,"unit":"Eur/MWh"}]}`},
+		{PriceEnergyCharts, `{"license_info":"CC BY 4.0","unix_seconds":[1699999200,1700002800],"price":[95.1,80.5],"unit":"EUR / MWh"}`},
+		{PriceTibber, `{"data":{"viewer":{"homes":[{"currentSubscription":{"priceInfo":{
+			"today":[{"total":95.1,"startsAt":"2023-11-14T23:00:00+01:00"},{"total":80.5,"startsAt":"2023-11-15T00:00:00+01:00"}],
+			"tomorrow":[]}}}]}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			prices, err := ParsePrices(strings.NewReader(tt.body), tt.format)
+			if err != nil {
+				t.Fatalf("ParsePrices failed: %v", err)
+			}
+			if len(prices) != 2 {
+				t.Fatalf("expected 2 slots, got %+v", prices)
+			}
+			if prices[0].Start.Unix() != 1699999200 || prices[0].Price != 95.1 || prices[1].Price != 80.5 {
+				t.Errorf("unexpected slots: %+v", prices)
+			}
+			if prices[1].End.Sub(prices[1].Start) != time.Hour || !prices[0].End.Equal(prices[1].Start) {
+				t.Errorf("expected consecutive hourly slots, got %+v", prices)
+			}
+		})
+	}
+
+	if _, err := ParsePrices(strings.NewReader(`{"errors":[{"message":"invalid token"}]}`), PriceTibber); err == nil || !strings.Contains(err.Error(), "invalid token") {
+		t.Errorf("expected the Tibber error, got %v", err)
+	}
+}
+
+func TestPriceFeedFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "priceInfo") {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, `{"data":{"viewer":{"homes":[{"currentSubscription":{"priceInfo":{
+			"today":[{"total":0.31,"startsAt":"2023-11-15T00:00:00+01:00"}],"tomorrow":[]}}}]}}}`)
+	}))
+	defer server.Close()
+
+	feed := &PriceFeed{Format: PriceTibber, URL: server.URL, Token: "secret"}
+	prices, err := feed.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(prices) != 1 || prices[0].Price != 0.31 {
+		t.Errorf("unexpected prices: %+v", prices)
+	}
+
+	feed.Token = "wrong"
+	if _, err := feed.Fetch(context.Background()); err == nil {
+		t.Error("expected an error for a rejected token")
+	}
+}
+
+func TestCheapestSlots(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	prices := hourlyPrices(start, 30, 10, 50, 5, 20, 15)
+
+	slots := CheapestSlots(prices, 3, start, start.Add(6*time.Hour))
+	if len(slots) != 3 || slots[0].Price != 10 || slots[1].Price != 5 || slots[2].Price != 15 {
+		t.Errorf("expected the three cheapest slots in time order, got %+v", slots)
+	}
+
+	window := CheapestWindow(prices, 2, start, start.Add(6*time.Hour))
+	if len(window) != 2 || window[0].Price != 5 || window[1].Price != 20 {
+		t.Errorf("expected the cheapest consecutive slots, got %+v", window)
+	}
+	if CheapestWindow(prices, 7, start, start.Add(6*time.Hour)) != nil {
+		t.Error("expected no window for more slots than available")
+	}
+}
+
+func TestPriceScheduler(t *testing.T) {
+	var changes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		changes = append(changes, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+		w.Write([]byte(`<result><changed id="1001"/></result>`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	prices := hourlyPrices(start, 30, 10, 50, 5, 20, 15)
+	scheduler := NewPriceScheduler(client, PriceLoad{Name: "boiler", IseIDs: []string{"1001"}, Slots: 2})
+	scheduler.SetPrices(prices)
+
+	steps := []struct {
+		at   time.Time
+		want []string
+	}{
+		{start.Add(30 * time.Minute), []string{"1001=false"}},
+		{start.Add(90 * time.Minute), []string{"1001=true"}},
+		{start.Add(100 * time.Minute), nil},
+		{start.Add(150 * time.Minute), []string{"1001=false"}},
+		{start.Add(210 * time.Minute), []string{"1001=true"}},
+	}
+	for _, step := range steps {
+		changes = nil
+		if err := scheduler.Apply(step.at); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if strings.Join(changes, ",") != strings.Join(step.want, ",") {
+			t.Errorf("at %s: expected changes %v, got %v", step.at.Format("15:04"), step.want, changes)
+		}
+	}
+}
+
+func TestPriceLoadWindow(t *testing.T) {
+	load := PriceLoad{WindowStart: 18 * time.Hour, WindowEnd: 31 * time.Hour}
+
+	from, to := load.window(time.Date(2024, 3, 2, 3, 0, 0, 0, time.UTC))
+	if !from.Equal(time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2024, 3, 2, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the overnight window of the previous evening, got %s - %s", from, to)
+	}
+	from, _ = load.window(time.Date(2024, 3, 2, 19, 0, 0, 0, time.UTC))
+	if !from.Equal(time.Date(2024, 3, 2, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the window of the current evening, got %s", from)
+	}
+}
@@ -0,0 +1,31 @@
+package homematic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FindProgram resolves a program by ise_id or by (case-insensitive) name
+func FindProgram(programs []Program, nameOrID string) (*Program, error) {
+	for i := range programs {
+		if programs[i].ID == nameOrID {
+			return &programs[i], nil
+		}
+	}
+
+	var found *Program
+	for i := range programs {
+		if !strings.EqualFold(programs[i].Name, nameOrID) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("program name %q is ambiguous (ids %s and %s)", nameOrID, found.ID, programs[i].ID)
+		}
+		found = &programs[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("program not found: %s", nameOrID)
+	}
+
+	return found, nil
+}
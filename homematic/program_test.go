@@ -0,0 +1,41 @@
+package homematic
+
+import "testing"
+
+func TestFindProgram(t *testing.T) {
+	programs := []Program{
+		{ID: "1001", Name: "Morning"},
+		{ID: "1002", Name: "Evening"},
+		{ID: "1003", Name: "evening"},
+		{ID: "1004", Name: "1001"},
+	}
+
+	tests := []struct {
+		nameOrID string
+		wantID   string
+		wantErr  bool
+	}{
+		{"1002", "1002", false},
+		{"morning", "1001", false},
+		{"1001", "1001", false}, // ids take precedence over names
+		{"Evening", "", true},
+		{"Night", "", true},
+	}
+
+	for _, tt := range tests {
+		program, err := FindProgram(programs, tt.nameOrID)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("FindProgram(%q): expected error, got %+v", tt.nameOrID, program)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FindProgram(%q): unexpected error: %v", tt.nameOrID, err)
+			continue
+		}
+		if program.ID != tt.wantID {
+			t.Errorf("FindProgram(%q) = %s, want %s", tt.nameOrID, program.ID, tt.wantID)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package homematic
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redisCommandTimeout bounds the round trip of a single Redis command
+const redisCommandTimeout = 5 * time.Second
+
+// redisUnlockScript deletes a lock only if it is still held by the given token
+const redisUnlockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+// redisExtendScript resets the TTL of a lock only if it is still held by the given token
+const redisExtendScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+
+// RedisWriteLocker is a WriteLocker backed by Redis, for clients running in
+// several processes or hosts. Locks expire after TTL, so a crashed holder
+// cannot block an actuator forever; while held, they are extended every third
+// of the TTL. It speaks the few RESP commands it needs (AUTH, SELECT, SET and
+// EVAL) itself, so the module does not depend on a Redis client library for
+// an optional feature.
+type RedisWriteLocker struct {
+	// Addr is the host:port of the Redis server
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	// TLS enables TLS with the given configuration
+	TLS *tls.Config
+	// Prefix is prepended to all lock keys, "homematic:lock:" by default
+	Prefix string
+	// TTL is the lifetime of a lock, 30s by default
+	TTL time.Duration
+	// Wait is the maximum time to wait for a held lock, 10s by default
+	Wait time.Duration
+	// RetryInterval is the pause between attempts to acquire a held lock, 50ms by default
+	RetryInterval time.Duration
+	// OnLost is called with an error wrapping ErrLockLost when a held lock
+	// could not be extended, e.g. because Redis was unreachable
+	OnLost func(err error)
+}
+
+// Lock acquires the locks of all keys, retrying held locks until Wait elapses or ctx is done
+func (r *RedisWriteLocker) Lock(ctx context.Context, keys []string) (func(), error) {
+	wait := r.Wait
+	if wait <= 0 {
+		wait = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	var acquired []string
+	release := func() {
+		for _, key := range acquired {
+			conn.do("EVAL", redisUnlockScript, "1", key, token)
+		}
+		conn.Close()
+	}
+
+	for _, key := range keys {
+		key = r.prefix() + key
+		if err := r.acquire(ctx, conn, key, token); err != nil {
+			release()
+			return nil, err
+		}
+		acquired = append(acquired, key)
+	}
+
+	ttl := durationOrDefault(r.TTL, 30*time.Second)
+	stop := keepAlive(ttl/3, func() error { return r.extend(conn, acquired, token, ttl) }, r.OnLost)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stop()
+			release()
+		})
+	}, nil
+}
+
+// extend resets the TTL of the acquired locks
+func (r *RedisWriteLocker) extend(conn *redisConn, keys []string, token string, ttl time.Duration) error {
+	for _, key := range keys {
+		reply, err := conn.do("EVAL", redisExtendScript, "1", key, token, strconv.FormatInt(ttl.Milliseconds(), 10))
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrLockLost, key, err)
+		}
+		if reply != int64(1) {
+			return fmt.Errorf("%w: %s expired", ErrLockLost, key)
+		}
+	}
+	return nil
+}
+
+// acquire sets a lock key, retrying while it is held by someone else
+func (r *RedisWriteLocker) acquire(ctx context.Context, conn *redisConn, key, token string) error {
+	ttl := durationOrDefault(r.TTL, 30*time.Second)
+	retry := r.RetryInterval
+	if retry <= 0 {
+		retry = 50 * time.Millisecond
+	}
+
+	for {
+		reply, err := conn.do("SET", key, token, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+		if err != nil {
+			return err
+		}
+		if reply == "OK" {
+			return nil
+		}
+
+		select {
+		case <-time.After(retry):
+		case <-ctx.Done():
+			return fmt.Errorf("lock %s is held: %w", key, ctx.Err())
+		}
+	}
+}
+
+// prefix returns the key prefix
+func (r *RedisWriteLocker) prefix() string {
+	if r.Prefix == "" {
+		return "homematic:lock:"
+	}
+	return r.Prefix
+}
+
+// dial connects and authenticates to the Redis server
+func (r *RedisWriteLocker) dial(ctx context.Context) (*redisConn, error) {
+	var dialer interface {
+		DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+	} = &net.Dialer{}
+	if r.TLS != nil {
+		dialer = &tls.Dialer{Config: r.TLS}
+	}
+
+	nc, err := dialer.DialContext(ctx, "tcp", r.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	conn := &redisConn{conn: nc, reader: bufio.NewReader(nc)}
+
+	if r.Password != "" {
+		args := []string{"AUTH", r.Password}
+		if r.Username != "" {
+			args = []string{"AUTH", r.Username, r.Password}
+		}
+		if _, err := conn.do(args...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.DB != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(r.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// newLockToken returns a random token identifying the holder of a lock
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// redisConn is a minimal RESP client connection
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// do sends a command and returns its reply: a string for simple and bulk
+// strings, an int64 for integers and nil for null replies
+func (c *redisConn) do(args ...string) (any, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	c.conn.SetDeadline(time.Now().Add(redisCommandTimeout))
+	if _, err := io.WriteString(c.conn, sb.String()); err != nil {
+		return nil, fmt.Errorf("failed to send redis command: %w", err)
+	}
+	return c.readReply()
+}
+
+// readReply reads a single RESP reply
+func (c *redisConn) readReply() (any, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return nil, fmt.Errorf("failed to read redis reply: %w", err)
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
+
+// Close closes the connection
+func (c *redisConn) Close() error {
+	return c.conn.Close()
+}
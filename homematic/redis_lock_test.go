@@ -0,0 +1,189 @@
+package homematic
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis implements the Redis commands used by RedisWriteLocker
+type fakeRedis struct {
+	mu       sync.Mutex
+	values   map[string]string
+	password string
+	commands []string
+}
+
+// startFakeRedis serves the fake on a local port and returns its address
+func startFakeRedis(t *testing.T, password string) (*fakeRedis, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	fake := &fakeRedis{values: make(map[string]string), password: password}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go fake.serve(conn)
+		}
+	}()
+	return fake, listener.Addr().String()
+}
+
+// serve handles the commands of a single connection
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := f.password == ""
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			header, _ := reader.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+			buf := make([]byte, size+2)
+			io.ReadFull(reader, buf)
+			args[i] = string(buf[:size])
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		var reply string
+		switch {
+		case args[0] == "AUTH":
+			authenticated = args[len(args)-1] == f.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "SELECT":
+			reply = "+OK\r\n"
+		case args[0] == "SET":
+			if _, held := f.values[args[1]]; held {
+				reply = "$-1\r\n"
+			} else {
+				f.values[args[1]] = args[2]
+				reply = "+OK\r\n"
+			}
+		case args[0] == "EVAL" && args[1] == redisExtendScript:
+			extended := 0
+			if f.values[args[3]] == args[4] {
+				extended = 1
+			}
+			reply = fmt.Sprintf(":%d\r\n", extended)
+		case args[0] == "EVAL":
+			deleted := 0
+			if f.values[args[3]] == args[4] {
+				delete(f.values, args[3])
+				deleted = 1
+			}
+			reply = fmt.Sprintf(":%d\r\n", deleted)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		io.WriteString(conn, reply)
+	}
+}
+
+func TestRedisWriteLocker(t *testing.T) {
+	fake, addr := startFakeRedis(t, "secret")
+	locker := &RedisWriteLocker{Addr: addr, Password: "secret", DB: 2, Wait: 50 * time.Millisecond, RetryInterval: 5 * time.Millisecond}
+
+	unlock, err := locker.Lock(context.Background(), []string{"datapoint:1251", "datapoint:1252"})
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	// a second holder times out on the first key and releases nothing of the first holder
+	if _, err := locker.Lock(context.Background(), []string{"datapoint:1252"}); err == nil || !strings.Contains(err.Error(), "is held") {
+		t.Errorf("expected held lock error, got %v", err)
+	}
+
+	unlock()
+	unlock()
+	fake.mu.Lock()
+	remaining := len(fake.values)
+	commands := strings.Join(fake.commands, "\n")
+	fake.mu.Unlock()
+	if remaining != 0 {
+		t.Errorf("expected all locks to be released, %d remaining", remaining)
+	}
+	for _, expected := range []string{"AUTH secret", "SELECT 2", "SET homematic:lock:datapoint:1251 ", " NX PX 30000"} {
+		if !strings.Contains(commands, expected) {
+			t.Errorf("expected command %q in:\n%s", expected, commands)
+		}
+	}
+
+	unlock, err = locker.Lock(context.Background(), []string{"datapoint:1252"})
+	if err != nil {
+		t.Fatalf("Lock after release failed: %v", err)
+	}
+	unlock()
+}
+
+func TestRedisWriteLockerAuthError(t *testing.T) {
+	_, addr := startFakeRedis(t, "secret")
+	locker := &RedisWriteLocker{Addr: addr, Password: "wrong"}
+
+	if _, err := locker.Lock(context.Background(), []string{"datapoint:1251"}); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("expected authentication error, got %v", err)
+	}
+}
+
+func TestRedisWriteLockerExtend(t *testing.T) {
+	fake, addr := startFakeRedis(t, "")
+	lost := make(chan error, 1)
+	locker := &RedisWriteLocker{Addr: addr, TTL: 30 * time.Millisecond, OnLost: func(err error) { lost <- err }}
+
+	unlock, err := locker.Lock(context.Background(), []string{"datapoint:1251"})
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	fake.mu.Lock()
+	commands := strings.Join(fake.commands, "\n")
+	// another client took over the lock
+	fake.values["homematic:lock:datapoint:1251"] = "other"
+	fake.mu.Unlock()
+	if !strings.Contains(commands, redisExtendScript+" 1 homematic:lock:datapoint:1251 ") {
+		t.Errorf("expected the lock to be extended while held, got:\n%s", commands)
+	}
+
+	select {
+	case err := <-lost:
+		if !errors.Is(err, ErrLockLost) {
+			t.Errorf("expected ErrLockLost, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the lost lock to be reported")
+	}
+	unlock()
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.values["homematic:lock:datapoint:1251"] != "other" {
+		t.Error("expected the lock of the other client to be kept")
+	}
+}
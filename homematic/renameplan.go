@@ -0,0 +1,102 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Rename is a device or channel name change of a RenamePlan
+type Rename struct {
+	Address string `json:"address"`
+	IseID   string `json:"ise_id"`
+	Channel bool   `json:"channel"`
+	OldName string `json:"old_name"`
+	NewName string `json:"new_name"`
+}
+
+// RenamePlan lists the renames needed to reach the names of a NameMapping
+type RenamePlan struct {
+	Renames []Rename `json:"renames"`
+	// Unchanged counts the addresses that already have their mapped name
+	Unchanged int `json:"unchanged"`
+	// Unknown lists the addresses of the mapping that are not paired with the CCU
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+// RenameFunc renames a device or channel. The XML-API cannot rename objects,
+// so it must be backed by another interface, e.g. the JSON-RPC API of the CCU.
+type RenameFunc func(r Rename) error
+
+// PlanRenames compares the names of devices and their channels with a
+// mapping; devices and channels missing from the mapping keep their names
+func PlanRenames(devices []Device, mapping NameMapping) *RenamePlan {
+	plan := &RenamePlan{}
+	seen := make(map[string]bool)
+	check := func(address, iseID, name string, channel bool) {
+		if address == "" {
+			return
+		}
+		seen[address] = true
+		newName, ok := mapping[address]
+		switch {
+		case !ok:
+		case newName == name:
+			plan.Unchanged++
+		default:
+			plan.Renames = append(plan.Renames, Rename{Address: address, IseID: iseID, Channel: channel, OldName: name, NewName: newName})
+		}
+	}
+	for _, device := range devices {
+		check(device.Address, device.IseID, device.Name, false)
+		for _, ch := range device.Channels {
+			check(ch.Address, ch.IseID, ch.Name, true)
+		}
+	}
+	for _, address := range mapping.Addresses() {
+		if !seen[address] {
+			plan.Unknown = append(plan.Unknown, address)
+		}
+	}
+	return plan
+}
+
+// Empty reports whether the plan has no renames
+func (p *RenamePlan) Empty() bool {
+	return len(p.Renames) == 0
+}
+
+// WriteDiff writes the plan as a diff of the old and new names, followed by
+// the unknown addresses and a summary
+func (p *RenamePlan) WriteDiff(w io.Writer) error {
+	var b strings.Builder
+	for _, r := range p.Renames {
+		kind := "device"
+		if r.Channel {
+			kind = "channel"
+		}
+		fmt.Fprintf(&b, "@@ %s %s (%s)\n- %s\n+ %s\n", kind, r.Address, r.IseID, r.OldName, r.NewName)
+	}
+	for _, address := range p.Unknown {
+		fmt.Fprintf(&b, "? %s is not paired with the CCU\n", address)
+	}
+	fmt.Fprintf(&b, "%d to rename, %d unchanged, %d unknown\n", len(p.Renames), p.Unchanged, len(p.Unknown))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Apply renames the devices and channels of the plan in order and returns the
+// renames that failed along with their errors. Show the diff and ask for
+// confirmation before, as the CCU keeps no history of names.
+func (p *RenamePlan) Apply(rename RenameFunc) ([]Rename, error) {
+	var failed []Rename
+	var errs []error
+	for _, r := range p.Renames {
+		if err := rename(r); err != nil {
+			failed = append(failed, r)
+			errs = append(errs, fmt.Errorf("failed to rename %s to %q: %w", r.Address, r.NewName, err))
+		}
+	}
+	return failed, errors.Join(errs...)
+}
@@ -0,0 +1,58 @@
+package homematic
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRenamePlan(t *testing.T) {
+	devices := []Device{
+		{Name: "Thermostat Schlafzimmer", Address: "000A1D89A00001", IseID: "2401", Channels: []Channel{
+			{Name: "Thermostat Schlafzimmer:1", Address: "000A1D89A00001:1", IseID: "2411"},
+		}},
+		{Name: "Fenster Gäste-WC", Address: "MEQ0000006", IseID: "2472"},
+	}
+	mapping := NameMapping{
+		"000A1D89A00001":   "Heizung Schlafzimmer",
+		"000A1D89A00001:1": "Heizung Schlafzimmer:1",
+		"MEQ0000006":       "Fenster Gäste-WC",
+		"MEQ0000099":       "Fenster Keller",
+	}
+
+	plan := PlanRenames(devices, mapping)
+	if len(plan.Renames) != 2 || plan.Unchanged != 1 || len(plan.Unknown) != 1 || plan.Unknown[0] != "MEQ0000099" {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	if r := plan.Renames[1]; !r.Channel || r.IseID != "2411" || r.OldName != "Thermostat Schlafzimmer:1" {
+		t.Errorf("unexpected channel rename %+v", r)
+	}
+
+	var buf bytes.Buffer
+	if err := plan.WriteDiff(&buf); err != nil {
+		t.Fatalf("WriteDiff failed: %v", err)
+	}
+	want := "@@ device 000A1D89A00001 (2401)\n- Thermostat Schlafzimmer\n+ Heizung Schlafzimmer\n" +
+		"@@ channel 000A1D89A00001:1 (2411)\n- Thermostat Schlafzimmer:1\n+ Heizung Schlafzimmer:1\n" +
+		"? MEQ0000099 is not paired with the CCU\n" +
+		"2 to rename, 1 unchanged, 1 unknown\n"
+	if buf.String() != want {
+		t.Errorf("unexpected diff:\n%s", buf.String())
+	}
+
+	var renamed []string
+	failed, err := plan.Apply(func(r Rename) error {
+		if r.Channel {
+			return errors.New("channel is locked")
+		}
+		renamed = append(renamed, r.IseID)
+		return nil
+	})
+	if err == nil || len(failed) != 1 || failed[0].IseID != "2411" || len(renamed) != 1 || renamed[0] != "2401" {
+		t.Errorf("expected the channel rename to fail, got %v, %v, %v", failed, renamed, err)
+	}
+
+	if !PlanRenames(devices, NameMappingFromDevices(devices)).Empty() {
+		t.Error("expected no renames for the current names")
+	}
+}
@@ -0,0 +1,153 @@
+package homematic
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ReadChangeLog decodes the records of a change log and calls fn for each of
+// them. Encrypted records are decrypted with the key from HOMEMATIC_ENCRYPTION_KEY.
+func ReadChangeLog(r io.Reader, fn func(ChangeLogRecord) error) error {
+	return readChangeLog(r, nil, fn)
+}
+
+// readChangeLog decodes a change log, decrypting encrypted records with key
+// or, if it is nil, with the key from the environment
+func readChangeLog(r io.Reader, key *EncryptionKey, fn func(ChangeLogRecord) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		data := scanner.Bytes()
+		if data[0] != '{' {
+			if key == nil {
+				var err error
+				if key, err = LoadEncryptionKey(); err != nil {
+					return err
+				}
+				if key == nil {
+					return fmt.Errorf("change log record on line %d: %w", line, ErrEncrypted)
+				}
+			}
+			var err error
+			if data, err = decryptChangeLogRecord(data, key); err != nil {
+				return fmt.Errorf("invalid change log record on line %d: %w", line, err)
+			}
+		}
+
+		var record ChangeLogRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("invalid change log record on line %d: %w", line, err)
+		}
+		if record.SchemaVersion < 1 || record.SchemaVersion > ChangeLogSchemaVersion {
+			return fmt.Errorf("unsupported change log schema version %d on line %d", record.SchemaVersion, line)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read change log: %w", err)
+	}
+	return nil
+}
+
+// Replayer re-emits the changes of a recorded change log, keeping the gaps
+// between their observation times scaled by Speed
+type Replayer struct {
+	// Speed is the replay speed relative to the original timing; 0 replays without delays
+	Speed float64
+	// Handler receives every replayed change; returning an error stops the replay
+	Handler func(DataPointChange) error
+
+	// Key decrypts encrypted change logs; the key from HOMEMATIC_ENCRYPTION_KEY if nil
+	Key *EncryptionKey
+
+	// wait blocks for the given duration; replaced in tests
+	wait func(ctx context.Context, d time.Duration) error
+}
+
+// ReplayFiles replays the given change log files in order
+func (r *Replayer) ReplayFiles(ctx context.Context, paths ...string) error {
+	readers := make([]io.Reader, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open change log: %w", err)
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+	return r.Replay(ctx, readers...)
+}
+
+// Replay replays the change logs read from the given readers in order
+func (r *Replayer) Replay(ctx context.Context, readers ...io.Reader) error {
+	if r.Handler == nil {
+		return fmt.Errorf("replayer has no handler")
+	}
+	wait := r.wait
+	if wait == nil {
+		wait = sleepContext
+	}
+
+	var last time.Time
+	for _, reader := range readers {
+		err := readChangeLog(reader, r.Key, func(record ChangeLogRecord) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			at := record.ObservedAt
+			if r.Speed > 0 && !last.IsZero() && at.After(last) {
+				if err := wait(ctx, time.Duration(float64(at.Sub(last))/r.Speed)); err != nil {
+					return err
+				}
+			}
+			if !at.IsZero() {
+				last = at
+			}
+
+			return r.Handler(record.DataPointChange)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decryptChangeLogRecord decodes and decrypts an encrypted change log line
+func decryptChangeLogRecord(line []byte, key *EncryptionKey) ([]byte, error) {
+	sealed := make([]byte, base64.StdEncoding.DecodedLen(len(line)))
+	n, err := base64.StdEncoding.Decode(sealed, line)
+	if err != nil {
+		return nil, err
+	}
+	return key.Open(sealed[:n])
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
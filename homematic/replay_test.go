@@ -0,0 +1,142 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const replayLog = `{"schema_version":1,"ise_id":"1251","new_value":"21.5","observed_at":"2024-01-01T10:00:00Z"}
+{"schema_version":1,"ise_id":"1251","new_value":"21.7","observed_at":"2024-01-01T10:00:10Z"}
+
+{"schema_version":1,"ise_id":"1252","new_value":"true","observed_at":"2024-01-01T10:01:10Z"}
+`
+
+func TestReplayerSpeed(t *testing.T) {
+	var waits []time.Duration
+	var values []string
+	r := &Replayer{
+		Speed:   10,
+		Handler: func(c DataPointChange) error { values = append(values, c.NewValue); return nil },
+		wait: func(ctx context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		},
+	}
+
+	if err := r.Replay(context.Background(), strings.NewReader(replayLog)); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if strings.Join(values, ",") != "21.5,21.7,true" {
+		t.Errorf("unexpected replayed values: %v", values)
+	}
+	if len(waits) != 2 || waits[0] != time.Second || waits[1] != 6*time.Second {
+		t.Errorf("unexpected waits: %v", waits)
+	}
+}
+
+func TestReplayerWithoutDelay(t *testing.T) {
+	count := 0
+	r := &Replayer{
+		Handler: func(DataPointChange) error { count++; return nil },
+		wait: func(context.Context, time.Duration) error {
+			t.Fatal("unexpected wait")
+			return nil
+		},
+	}
+
+	if err := r.Replay(context.Background(), strings.NewReader(replayLog), strings.NewReader(replayLog)); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("expected 6 replayed changes, got %d", count)
+	}
+}
+
+func TestReplayerStopsOnHandlerError(t *testing.T) {
+	errStop := errors.New("stop")
+	r := &Replayer{Handler: func(DataPointChange) error { return errStop }}
+
+	if err := r.Replay(context.Background(), strings.NewReader(replayLog)); !errors.Is(err, errStop) {
+		t.Errorf("expected handler error, got %v", err)
+	}
+}
+
+func TestReplayerRejectsUnknownSchema(t *testing.T) {
+	r := &Replayer{Handler: func(DataPointChange) error { return nil }}
+
+	err := r.Replay(context.Background(), strings.NewReader(`{"schema_version":99}`))
+	if err == nil || !strings.Contains(err.Error(), "schema version 99") {
+		t.Errorf("expected schema version error, got %v", err)
+	}
+}
+
+func TestReplayFilesFromChangeLog(t *testing.T) {
+	log, err := OpenChangeLog(t.TempDir(), ChangeLogOptions{MaxSize: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{"1", "2", "3", "4"} {
+		if err := log.Append(DataPointChange{IseID: "1251", NewValue: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	log.Close()
+
+	files, err := log.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values []string
+	r := &Replayer{Handler: func(c DataPointChange) error { values = append(values, c.NewValue); return nil }}
+	if err := r.ReplayFiles(context.Background(), files...); err != nil {
+		t.Fatalf("ReplayFiles failed: %v", err)
+	}
+	if strings.Join(values, ",") != "1,2,3,4" {
+		t.Errorf("unexpected replayed values: %v", values)
+	}
+}
+
+func TestReplayEncryptedChangeLog(t *testing.T) {
+	key, err := NewEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, err := OpenChangeLog(t.TempDir(), ChangeLogOptions{Key: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Append(DataPointChange{IseID: "1251", NewValue: "21.5"}); err != nil {
+		t.Fatal(err)
+	}
+	log.Close()
+
+	files, _ := log.Files()
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "1251") {
+		t.Errorf("expected the record to be encrypted: %s", data)
+	}
+
+	var values []string
+	r := &Replayer{Key: key, Handler: func(c DataPointChange) error { values = append(values, c.NewValue); return nil }}
+	if err := r.ReplayFiles(context.Background(), files...); err != nil {
+		t.Fatalf("ReplayFiles failed: %v", err)
+	}
+	if strings.Join(values, ",") != "21.5" {
+		t.Errorf("unexpected replayed values: %v", values)
+	}
+
+	t.Setenv(EncryptionKeyEnv, "")
+	r.Key = nil
+	if err := r.ReplayFiles(context.Background(), files...); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted without a key, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultResolverTTL is the time lookups are cached for if CachingResolver.TTL is not set
+const DefaultResolverTTL = 5 * time.Minute
+
+// CachingResolver resolves the host names of dialed addresses with a cache,
+// to ride out flaky DNS servers in home networks. Static entries in Hosts
+// take precedence over lookups, and cached addresses continue to be used
+// after they expired while lookups fail.
+type CachingResolver struct {
+	// TTL is the time lookups are cached for, DefaultResolverTTL by default
+	TTL time.Duration
+
+	// Hosts maps host names to static IP addresses, like /etc/hosts
+	Hosts map[string]string
+
+	// Lookup resolves a host name; net.DefaultResolver.LookupHost if nil
+	Lookup func(ctx context.Context, host string) ([]string, error)
+
+	mu    sync.Mutex
+	cache map[string]cachedHost
+	now   func() time.Time
+}
+
+// cachedHost holds the addresses of a lookup and when they expire
+type cachedHost struct {
+	addrs   []string
+	expires time.Time
+}
+
+// SetResolver makes the client resolve host names with the caching resolver
+func (c *Client) SetResolver(resolver *CachingResolver) error {
+	return c.SetDialer(resolver.DialContext)
+}
+
+// LookupHost returns the addresses of a host from Hosts, the cache or a lookup
+func (r *CachingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for name, addr := range r.Hosts {
+		if strings.EqualFold(name, host) {
+			return []string{addr}, nil
+		}
+	}
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	now := time.Now
+	if r.now != nil {
+		now = r.now
+	}
+	r.mu.Lock()
+	cached, ok := r.cache[host]
+	r.mu.Unlock()
+	if ok && now().Before(cached.expires) {
+		return cached.addrs, nil
+	}
+
+	lookup := r.Lookup
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupHost
+	}
+	addrs, err := lookup(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = fmt.Errorf("no addresses for %s", host)
+	}
+	if err != nil {
+		if ok {
+			// serve the stale entry rather than failing the request
+			return cached.addrs, nil
+		}
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.cache == nil {
+		r.cache = make(map[string]cachedHost)
+	}
+	r.cache[host] = cachedHost{addrs: addrs, expires: now().Add(durationOrDefault(r.TTL, DefaultResolverTTL))}
+	r.mu.Unlock()
+	return addrs, nil
+}
+
+// DialContext resolves the host of addr and connects to the first reachable address
+func (r *CachingResolver) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := r.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	var errs []error
+	for _, ip := range addrs {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Flush drops all cached lookups
+func (r *CachingResolver) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cache = nil
+}
@@ -0,0 +1,74 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCachingResolver(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	lookups := 0
+	var lookupErr error
+	resolver := &CachingResolver{
+		TTL: time.Minute,
+		Hosts: map[string]string{
+			"static.local": "10.0.0.2",
+		},
+		Lookup: func(ctx context.Context, host string) ([]string, error) {
+			lookups++
+			return []string{"10.0.0.1"}, lookupErr
+		},
+		now: func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	if addrs, err := resolver.LookupHost(ctx, "Static.Local."); err != nil || addrs[0] != "10.0.0.2" {
+		t.Errorf("expected the static entry, got %v %v", addrs, err)
+	}
+	if _, err := resolver.LookupHost(ctx, "ccu.local"); err != nil {
+		t.Fatalf("LookupHost failed: %v", err)
+	}
+	if _, err := resolver.LookupHost(ctx, "ccu.local"); err != nil || lookups != 1 {
+		t.Errorf("expected a cached result, got %d lookups, %v", lookups, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := resolver.LookupHost(ctx, "ccu.local"); err != nil || lookups != 2 {
+		t.Errorf("expected a new lookup after the TTL, got %d lookups, %v", lookups, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	lookupErr = errors.New("server misbehaving")
+	if addrs, err := resolver.LookupHost(ctx, "ccu.local"); err != nil || addrs[0] != "10.0.0.1" {
+		t.Errorf("expected the stale entry while lookups fail, got %v %v", addrs, err)
+	}
+	if _, err := resolver.LookupHost(ctx, "other.local"); err == nil {
+		t.Error("expected an error for an uncached host")
+	}
+
+	resolver.Flush()
+	if _, err := resolver.LookupHost(ctx, "ccu.local"); err == nil {
+		t.Error("expected an error after flushing the cache")
+	}
+}
+
+func TestClientResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	client := NewClient("http://ccu.invalid:"+u.Port(), "token")
+	if err := client.SetResolver(&CachingResolver{Hosts: map[string]string{"ccu.invalid": u.Hostname()}}); err != nil {
+		t.Fatalf("SetResolver failed: %v", err)
+	}
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package homematic
+
+import "strings"
+
+// ChannelRole classifies what a channel does, e.g. for generating the
+// configuration of home automation systems from the topology
+type ChannelRole string
+
+const (
+	RoleUnknown     ChannelRole = ""
+	RoleMaintenance ChannelRole = "maintenance"
+	RoleSwitch      ChannelRole = "switch"
+	RoleDimmer      ChannelRole = "dimmer"
+	RoleBlind       ChannelRole = "blind"
+	RoleThermostat  ChannelRole = "thermostat"
+	RoleClimate     ChannelRole = "climate"
+	RoleContact     ChannelRole = "contact"
+	RoleMotion      ChannelRole = "motion"
+	RoleSmoke       ChannelRole = "smoke"
+	RoleWater       ChannelRole = "water"
+	RoleLock        ChannelRole = "lock"
+	RoleKey         ChannelRole = "key"
+)
+
+// channelRoleRule assigns a role to channels of devices whose type starts
+// with prefix; an empty channel list matches all channels but the maintenance channel
+type channelRoleRule struct {
+	prefix   string
+	role     ChannelRole
+	channels []int
+}
+
+// channelRoleRules are checked in order, so longer prefixes come first
+var channelRoleRules = []channelRoleRule{
+	{"HmIP-eTRV", RoleThermostat, []int{1}},
+	{"HmIP-BWTH", RoleThermostat, []int{1}},
+	{"HmIP-WTH", RoleThermostat, []int{1}},
+	{"HmIP-STHO", RoleClimate, []int{1}},
+	{"HmIP-STH", RoleThermostat, []int{1}},
+	{"HM-CC-RT-DN", RoleThermostat, []int{4}},
+	{"HM-TC-IT-WM", RoleThermostat, []int{2}},
+	{"HM-WDS10-TH", RoleClimate, []int{1}},
+	{"HM-WDS40-TH", RoleClimate, []int{1}},
+	{"HmIP-SWDO", RoleContact, []int{1}},
+	{"HmIP-SRH", RoleContact, []int{1}},
+	{"HM-Sec-SC", RoleContact, []int{1}},
+	{"HM-Sec-RHS", RoleContact, []int{1}},
+	{"HmIP-SMI", RoleMotion, []int{1}},
+	{"HmIP-SPI", RoleMotion, []int{1}},
+	{"HM-Sec-MD", RoleMotion, []int{1}},
+	{"HmIP-SWSD", RoleSmoke, []int{1}},
+	{"HM-Sec-SD", RoleSmoke, []int{1}},
+	{"HmIP-SWD", RoleWater, []int{1}},
+	{"HM-Sec-WDS", RoleWater, []int{1}},
+	{"HmIP-DLD", RoleLock, []int{1}},
+	{"HM-Sec-Key", RoleLock, []int{1}},
+	{"HmIP-BSM", RoleKey, []int{1, 2}},
+	{"HmIP-BSM", RoleSwitch, []int{4}},
+	{"HmIP-FSM", RoleSwitch, []int{2}},
+	{"HmIP-PS", RoleSwitch, []int{3}},
+	{"HM-ES-PMSw", RoleSwitch, []int{1}},
+	{"HM-LC-Sw", RoleSwitch, nil},
+	{"HmIP-BDT", RoleDimmer, []int{4}},
+	{"HmIP-PDT", RoleDimmer, []int{3}},
+	{"HM-LC-Dim", RoleDimmer, nil},
+	{"HmIP-BROLL", RoleBlind, []int{4}},
+	{"HmIP-FROLL", RoleBlind, []int{4}},
+	{"HmIP-BBL", RoleBlind, []int{4}},
+	{"HM-LC-Bl1", RoleBlind, nil},
+	{"HmIP-WRC", RoleKey, nil},
+	{"HmIP-BRC", RoleKey, nil},
+	{"HM-PB", RoleKey, nil},
+	{"HM-RC", RoleKey, nil},
+}
+
+// ClassifyChannel returns the role of a channel of a device. Known device
+// types are classified by their type and the channel index; other channels
+// by their data points, if the device was read with its state.
+func ClassifyChannel(device *Device, channel *Channel) ChannelRole {
+	if channel.Index == 0 {
+		return RoleMaintenance
+	}
+
+	deviceType := strings.ToUpper(device.DeviceType)
+	known := false
+	for _, rule := range channelRoleRules {
+		if !strings.HasPrefix(deviceType, strings.ToUpper(rule.prefix)) {
+			continue
+		}
+		known = true
+		if len(rule.channels) == 0 {
+			return rule.role
+		}
+		for _, index := range rule.channels {
+			if index == channel.Index {
+				return rule.role
+			}
+		}
+	}
+	if known {
+		// further channels of known devices, e.g. virtual actuator channels
+		return RoleUnknown
+	}
+	return classifyDataPoints(channel)
+}
+
+// classifyDataPoints derives the role of a channel from its data point types
+func classifyDataPoints(channel *Channel) ChannelRole {
+	types := make(map[string]DataPoint, len(channel.DataPoints))
+	for _, dp := range channel.DataPoints {
+		types[dataPointType(dp)] = dp
+	}
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if _, ok := types[name]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has("SET_POINT_TEMPERATURE", "SET_TEMPERATURE"):
+		return RoleThermostat
+	case has("LOCK_STATE", "LOCK_TARGET_LEVEL"):
+		return RoleLock
+	case has("SMOKE_DETECTOR_ALARM_STATUS"):
+		return RoleSmoke
+	case has("MOISTURE_DETECTED", "WATERLEVEL_DETECTED"):
+		return RoleWater
+	case has("MOTION"):
+		return RoleMotion
+	case has("PRESS_SHORT"):
+		return RoleKey
+	case has("LEVEL") && has("STOP"):
+		return RoleBlind
+	case has("LEVEL"):
+		return RoleDimmer
+	case has("STATE"):
+		if types["STATE"].ValueType == regaValueTypeBinary && channel.Direction == "RECEIVER" {
+			return RoleSwitch
+		}
+		return RoleContact
+	case has("ACTUAL_TEMPERATURE", "TEMPERATURE", "HUMIDITY"):
+		return RoleClimate
+	}
+	return RoleUnknown
+}
+
+// channelRooms maps channel ise_ids to the name of their first room
+func channelRooms(topology *Topology) map[string]string {
+	rooms := make(map[string]string)
+	for _, room := range topology.Rooms {
+		for _, ch := range room.Channels {
+			if _, ok := rooms[ch.IseID]; !ok {
+				rooms[ch.IseID] = room.Name
+			}
+		}
+	}
+	return rooms
+}
+
+// forEachChannelRole calls fn for the classified channels of a device,
+// restricted to the given rooms and roles if any; channelRooms maps the
+// channels to their room as returned by channelRooms
+func forEachChannelRole(device *Device, channelRooms map[string]string, rooms []string, roles []ChannelRole, fn func(ch *Channel, role ChannelRole, room string)) {
+	for i := range device.Channels {
+		ch := &device.Channels[i]
+		role := ClassifyChannel(device, ch)
+		if role == RoleUnknown || role == RoleMaintenance || !includesRole(roles, role) {
+			continue
+		}
+		room := channelRooms[ch.IseID]
+		if len(rooms) > 0 && !containsAnyFold(rooms, []string{room}) {
+			continue
+		}
+		fn(ch, role, room)
+	}
+}
+
+func includesRole(roles []ChannelRole, role ChannelRole) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
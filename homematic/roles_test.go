@@ -0,0 +1,34 @@
+package homematic
+
+import "testing"
+
+func TestClassifyChannel(t *testing.T) {
+	tests := []struct {
+		name       string
+		deviceType string
+		channel    Channel
+		want       ChannelRole
+	}{
+		{"maintenance", "HmIP-eTRV-2", Channel{Index: 0}, RoleMaintenance},
+		{"thermostat", "HmIP-eTRV-2", Channel{Index: 1}, RoleThermostat},
+		{"window contact", "HmIP-SWDO", Channel{Index: 1}, RoleContact},
+		{"water sensor", "HmIP-SWD", Channel{Index: 1}, RoleWater},
+		{"key", "HmIP-BSM", Channel{Index: 2}, RoleKey},
+		{"switch actuator", "HmIP-BSM", Channel{Index: 4}, RoleSwitch},
+		{"virtual actuator", "HmIP-BSM", Channel{Index: 5}, RoleUnknown},
+		{"dimmer", "HM-LC-Dim1T-FM", Channel{Index: 1}, RoleDimmer},
+		{"blind by data points", "ACME-Blind", Channel{Index: 1, DataPoints: []DataPoint{{Type: "LEVEL"}, {Type: "STOP"}}}, RoleBlind},
+		{"switch by data points", "ACME-Switch", Channel{Index: 1, Direction: "RECEIVER", DataPoints: []DataPoint{{Type: "STATE", ValueType: regaValueTypeBinary}}}, RoleSwitch},
+		{"contact by data points", "ACME-Contact", Channel{Index: 1, Direction: "SENDER", DataPoints: []DataPoint{{Name: "ACME.X:1.STATE", ValueType: regaValueTypeBinary}}}, RoleContact},
+		{"unknown", "ACME-Gadget", Channel{Index: 1}, RoleUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := &Device{DeviceType: tt.deviceType}
+			if got := ClassifyChannel(device, &tt.channel); got != tt.want {
+				t.Errorf("ClassifyChannel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// Actuator executes the state and program actions of rules; *homematic.Client implements it
+type Actuator interface {
+	ChangeState(iseIDs, values []string) error
+	RunProgram(programID string, condCheck bool) error
+}
+
+// Notifier delivers the notify actions of rules
+type Notifier func(rule Rule, message string) error
+
+// Engine evaluates rules against data point changes. The condition state of
+// every rule can be persisted to a file, so that a rule whose condition
+// started to hold before a restart still fires on time and a rule that has
+// already fired does not fire again.
+type Engine struct {
+	rules    []Rule
+	actuator Actuator
+	notifier Notifier
+	path     string
+	key      *homematic.EncryptionKey
+
+	mu     sync.Mutex
+	states map[string]*ruleState
+	values *values
+	// conditions holds the compiled condition expressions by rule name,
+	// programs the compiled action expressions by source
+	conditions map[string]*vm.Program
+	programs   map[string]*vm.Program
+}
+
+// ruleState is the persisted evaluation state of a rule
+type ruleState struct {
+	// Matching is set while the condition holds, since Since
+	Matching bool      `json:"matching"`
+	Since    time.Time `json:"since,omitempty"`
+	// Fired is set once the actions ran for the current matching period
+	Fired bool `json:"fired"`
+}
+
+// stateFile is the layout of the persisted engine state
+type stateFile struct {
+	Rules map[string]*ruleState `json:"rules"`
+}
+
+// EngineOption configures an engine created by NewEngine
+type EngineOption func(e *Engine)
+
+// WithEncryptionKey sets the key the state file is encrypted with, e.g. the
+// key file of a configuration profile, instead of HOMEMATIC_ENCRYPTION_KEY
+func WithEncryptionKey(key *homematic.EncryptionKey) EngineOption {
+	return func(e *Engine) {
+		e.key = key
+	}
+}
+
+// NewEngine creates an engine for the given rules. If statePath is not empty
+// the rule state is loaded from and saved to that file, encrypted with the
+// key given by WithEncryptionKey, or else the key from
+// HOMEMATIC_ENCRYPTION_KEY if it is set.
+func NewEngine(rules []Rule, actuator Actuator, notifier Notifier, statePath string, opts ...EngineOption) (*Engine, error) {
+	if err := Validate(rules); err != nil {
+		return nil, err
+	}
+
+	e := &Engine{
+		rules:    rules,
+		actuator: actuator,
+		notifier: notifier,
+		path:     statePath,
+		states:   make(map[string]*ruleState, len(rules)),
+		values:   newValues(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.key == nil {
+		key, err := homematic.LoadEncryptionKey()
+		if err != nil {
+			return nil, err
+		}
+		e.key = key
+	}
+	if err := e.compile(); err != nil {
+		return nil, err
+	}
+	if err := e.load(); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if e.states[rule.Name] == nil {
+			e.states[rule.Name] = &ruleState{}
+		}
+	}
+	return e, nil
+}
+
+// SetTopology assigns channels to rooms for the rooms map of rule expressions
+func (e *Engine) SetTopology(topology *homematic.Topology) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.values.setTopology(topology)
+}
+
+// Observe evaluates the rules depending on the changed data point and runs
+// the actions of rules whose condition has held long enough
+func (e *Engine) Observe(change homematic.DataPointChange) error {
+	return e.evaluate(change.ObservedAt, func() map[string]string {
+		e.values.observe(change)
+		return map[string]string{change.IseID: change.NewValue}
+	})
+}
+
+// ObserveSystemVariables evaluates the rules against a system variable snapshot
+func (e *Engine) ObserveSystemVariables(sysVars []homematic.SystemVariable, at time.Time) error {
+	return e.evaluate(at, func() map[string]string {
+		changes := make(map[string]string, len(sysVars))
+		for _, v := range sysVars {
+			e.values.observeSystemVariable(v)
+			changes[v.IseID] = v.Value
+		}
+		return changes
+	})
+}
+
+// Tick runs the actions of rules whose condition has held for their duration
+// at now; it should be called periodically, e.g. on every poll
+func (e *Engine) Tick(now time.Time) error {
+	return e.evaluate(now, func() map[string]string { return nil })
+}
+
+// evaluate records new values with observe, updates the condition state of
+// the rules depending on the returned changes, fires the rules that are due
+// at now and saves the state if anything changed
+func (e *Engine) evaluate(now time.Time, observe func() map[string]string) error {
+	e.mu.Lock()
+	changes := observe()
+	changed := false
+	var due []firing
+	var errs []error
+	for i := range e.rules {
+		rule := &e.rules[i]
+		state := e.states[rule.Name]
+		if matching, ok := e.matches(rule, changes); ok && matching != state.Matching {
+			*state = ruleState{Matching: matching}
+			if matching {
+				state.Since = now
+			}
+			changed = true
+		}
+		if state.Matching && !state.Fired && now.Sub(state.Since) >= rule.For {
+			state.Fired = true
+			changed = true
+			firing, err := e.resolve(rule)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			due = append(due, firing)
+		}
+	}
+	if changed {
+		errs = append(errs, e.save())
+	}
+	e.mu.Unlock()
+
+	for _, firing := range due {
+		errs = append(errs, e.fire(firing))
+	}
+	return errors.Join(errs...)
+}
+
+// matches evaluates the condition of a rule; ok is false if the condition
+// does not depend on the changes. Expressions that fail, e.g. because a value
+// is not known yet, do not match.
+func (e *Engine) matches(rule *Rule, changes map[string]string) (matching, ok bool) {
+	if rule.When.Expr == "" {
+		value, ok := changes[rule.When.IseID()]
+		return ok && rule.When.Matches(value), ok
+	}
+	if len(changes) == 0 {
+		return false, false
+	}
+
+	result, err := expr.Run(e.conditions[rule.Name], e.values.env)
+	if err != nil {
+		return false, true
+	}
+	matching, _ = result.(bool)
+	return matching, true
+}
+
+// firing is a rule whose actions are due, with their expressions evaluated
+type firing struct {
+	rule    Rule
+	actions []Action
+}
+
+// resolve evaluates the value and message expressions of the actions of a rule
+func (e *Engine) resolve(rule *Rule) (firing, error) {
+	actions := make([]Action, len(rule.Then))
+	for i, action := range rule.Then {
+		switch {
+		case action.SetState != nil && action.SetState.ValueExpr != "":
+			value, err := expr.Run(e.programs[action.SetState.ValueExpr], e.values.env)
+			if err != nil {
+				return firing{}, fmt.Errorf("rule %q: %w", rule.Name, err)
+			}
+			action.SetState = &SetState{IseID: action.SetState.IseID, Value: formatValue(value)}
+		case action.NotifyExpr != "":
+			message, err := expr.Run(e.programs[action.NotifyExpr], e.values.env)
+			if err != nil {
+				return firing{}, fmt.Errorf("rule %q: %w", rule.Name, err)
+			}
+			action = Action{Notify: formatValue(message)}
+		}
+		actions[i] = action
+	}
+	return firing{rule: *rule, actions: actions}, nil
+}
+
+// fire runs the resolved actions of a rule
+func (e *Engine) fire(f firing) error {
+	var errs []error
+	for _, action := range f.actions {
+		var err error
+		switch {
+		case action.SetState != nil:
+			err = e.actuator.ChangeState([]string{action.SetState.IseID}, []string{action.SetState.Value})
+		case action.RunProgram != "":
+			err = e.actuator.RunProgram(action.RunProgram, false)
+		case action.Notify != "":
+			if e.notifier != nil {
+				err = e.notifier(f.rule, action.Notify)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", f.rule.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compile compiles all expressions of the rules
+func (e *Engine) compile() error {
+	e.conditions = make(map[string]*vm.Program)
+	e.programs = make(map[string]*vm.Program)
+	add := func(input string) error {
+		if input == "" || e.programs[input] != nil {
+			return nil
+		}
+		program, err := compileValue(input)
+		if err != nil {
+			return err
+		}
+		e.programs[input] = program
+		return nil
+	}
+
+	for _, rule := range e.rules {
+		if rule.When.Expr != "" {
+			program, err := compileCondition(rule.When.Expr)
+			if err != nil {
+				return err
+			}
+			e.conditions[rule.Name] = program
+		}
+		for _, action := range rule.Then {
+			if action.SetState != nil {
+				if err := add(action.SetState.ValueExpr); err != nil {
+					return err
+				}
+			}
+			if err := add(action.NotifyExpr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// load reads the persisted state, ignoring rules that no longer exist
+func (e *Engine) load() error {
+	if e.path == "" {
+		return nil
+	}
+	data, err := homematic.ReadEncryptedFile(e.path, e.key)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read rule state: %w", err)
+	}
+
+	var file stateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse rule state: %w", err)
+	}
+	for _, rule := range e.rules {
+		if state := file.Rules[rule.Name]; state != nil {
+			e.states[rule.Name] = state
+		}
+	}
+	return nil
+}
+
+// save atomically writes the state of all rules
+func (e *Engine) save() error {
+	if e.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(stateFile{Rules: e.states}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode rule state: %w", err)
+	}
+
+	if err := homematic.WriteEncryptedFile(e.path, data, e.key); err != nil {
+		return fmt.Errorf("failed to write rule state: %w", err)
+	}
+	return nil
+}
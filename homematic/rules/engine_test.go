@@ -0,0 +1,204 @@
+package rules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// recorder records the actions run by an engine
+type recorder struct {
+	actions []string
+}
+
+func (r *recorder) ChangeState(iseIDs, values []string) error {
+	r.actions = append(r.actions, "set "+strings.Join(iseIDs, ",")+"="+strings.Join(values, ","))
+	return nil
+}
+
+func (r *recorder) RunProgram(programID string, condCheck bool) error {
+	r.actions = append(r.actions, "run "+programID)
+	return nil
+}
+
+func (r *recorder) notify(rule Rule, message string) error {
+	r.actions = append(r.actions, "notify "+message)
+	return nil
+}
+
+func humidity(value string, at time.Time) homematic.DataPointChange {
+	return homematic.DataPointChange{IseID: "2412", NewValue: value, ObservedAt: at}
+}
+
+func TestEngine(t *testing.T) {
+	rules, err := Load(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	engine, err := NewEngine(rules, rec, rec.notify, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1700000000, 0)
+
+	// the condition does not hold long enough
+	engine.Observe(humidity("70", start))
+	engine.Tick(start.Add(4 * time.Minute))
+	engine.Observe(humidity("60", start.Add(4*time.Minute)))
+	engine.Tick(start.Add(10 * time.Minute))
+	if len(rec.actions) != 0 {
+		t.Fatalf("expected no actions, got %v", rec.actions)
+	}
+
+	// the condition holds for five minutes and fires once
+	engine.Observe(humidity("68", start.Add(20*time.Minute)))
+	engine.Observe(humidity("72", start.Add(22*time.Minute)))
+	if err := engine.Tick(start.Add(25 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	engine.Tick(start.Add(30 * time.Minute))
+	if strings.Join(rec.actions, ";") != "set 3001=true;notify Bathroom humidity high" {
+		t.Errorf("unexpected actions: %v", rec.actions)
+	}
+
+	// rules without a duration fire with the change
+	rec.actions = nil
+	engine.ObserveSystemVariables([]homematic.SystemVariable{{IseID: "950", Value: "false"}}, start)
+	if strings.Join(rec.actions, ";") != "run 1001" {
+		t.Errorf("unexpected actions: %v", rec.actions)
+	}
+}
+
+func TestEnginePersistence(t *testing.T) {
+	rules, _ := Load(strings.NewReader(testRules))
+	path := filepath.Join(t.TempDir(), "rules.json")
+	start := time.Unix(1700000000, 0)
+
+	rec := &recorder{}
+	engine, err := NewEngine(rules, rec, rec.notify, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.Observe(humidity("70", start))
+
+	// the condition keeps holding across a restart
+	rec = &recorder{}
+	engine, err = NewEngine(rules, rec, rec.notify, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.Tick(start.Add(5 * time.Minute))
+	if len(rec.actions) != 2 {
+		t.Fatalf("expected rule to fire after restart, got %v", rec.actions)
+	}
+
+	// a fired rule does not fire again after another restart
+	rec = &recorder{}
+	engine, err = NewEngine(rules, rec, rec.notify, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.Tick(start.Add(10 * time.Minute))
+	if len(rec.actions) != 0 {
+		t.Errorf("expected fired rule not to fire again, got %v", rec.actions)
+	}
+}
+
+func TestEngineEncryptedState(t *testing.T) {
+	key, err := homematic.NewEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(homematic.EncryptionKeyEnv, key.String())
+
+	rules, _ := Load(strings.NewReader(testRules))
+	path := filepath.Join(t.TempDir(), "rules.json")
+	rec := &recorder{}
+	engine, err := NewEngine(rules, rec, rec.notify, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.Observe(humidity("70", time.Unix(1700000000, 0)))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !homematic.IsEncrypted(data) {
+		t.Errorf("expected the rule state to be encrypted: %s", data)
+	}
+	if _, err := NewEngine(rules, rec, rec.notify, path); err != nil {
+		t.Errorf("expected the encrypted state to load: %v", err)
+	}
+
+	t.Setenv(homematic.EncryptionKeyEnv, "")
+	if _, err := NewEngine(rules, rec, rec.notify, path); !errors.Is(err, homematic.ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted without a key, got %v", err)
+	}
+	if _, err := NewEngine(rules, rec, rec.notify, path, WithEncryptionKey(key)); err != nil {
+		t.Errorf("expected the state to load with the given key: %v", err)
+	}
+}
+
+var _ Actuator = (*homematic.Client)(nil)
+
+const exprRules = `
+rules:
+  - name: bathroom-fan
+    when: {expr: 'rooms["Bad"].humidity > 65 && !rooms["Bad"].state'}
+    then:
+      - set_state: {ise_id: "3001", value_expr: 'rooms["Bad"].humidity > 80 ? "1.0" : "0.5"'}
+      - notify_expr: '"Humidity in Bad is " + string(rooms["Bad"].humidity) + " %"'
+  - name: nobody-home
+    when: {expr: 'sysvars["Presence"] == false && dp["4001"] == "Urlaub"'}
+    then:
+      - run_program: "1001"
+`
+
+func TestEngineExpressions(t *testing.T) {
+	ruleSet, err := Load(strings.NewReader(exprRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	engine, err := NewEngine(ruleSet, rec, rec.notify, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetTopology(&homematic.Topology{Rooms: []homematic.Room{
+		{Name: "Bad", Channels: []homematic.Channel{{IseID: "2410"}, {IseID: "2510"}}},
+	}})
+	at := time.Unix(1700000000, 0)
+
+	window := homematic.DataPointChange{IseID: "2511", ChannelIseID: "2510", Type: "STATE", NewValue: "true", ObservedAt: at}
+	humidity := homematic.DataPointChange{IseID: "2412", ChannelIseID: "2410", Type: "HUMIDITY", NewValue: "85", ObservedAt: at}
+
+	// unknown values do not match
+	if err := engine.Observe(humidity); err != nil {
+		t.Fatal(err)
+	}
+	engine.Observe(window)
+	if len(rec.actions) != 0 {
+		t.Fatalf("expected no actions while the window is open, got %v", rec.actions)
+	}
+
+	window.NewValue = "false"
+	engine.Observe(window)
+	want := "set 3001=1.0;notify Humidity in Bad is 85 %"
+	if strings.Join(rec.actions, ";") != want {
+		t.Errorf("actions = %v, want %s", rec.actions, want)
+	}
+
+	rec.actions = nil
+	engine.Observe(homematic.DataPointChange{IseID: "4001", NewValue: "Urlaub", ObservedAt: at})
+	engine.ObserveSystemVariables([]homematic.SystemVariable{{IseID: "950", Name: "Presence", Value: "false"}}, at)
+	if strings.Join(rec.actions, ";") != "run 1001" {
+		t.Errorf("unexpected actions: %v", rec.actions)
+	}
+}
@@ -0,0 +1,129 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// Env is the environment of rule expressions, written in the expr language
+// (https://expr-lang.org). Values are booleans, numbers or strings, e.g.
+//
+//	rooms["Bad"].humidity > 65 && !rooms["Bad"].state
+//	dp["2412"] > 65 || sysvars["Presence"] == false
+type Env struct {
+	// DataPoints maps the ise_id of data points and system variables to their current value
+	DataPoints map[string]any `expr:"dp"`
+
+	// SysVars maps the name of system variables to their current value
+	SysVars map[string]any `expr:"sysvars"`
+
+	// Rooms maps a room name to the values of the data points of its channels,
+	// keyed by the lower case data point type (e.g. "humidity", "state"); if
+	// several channels of a room have the same type, the latest change wins
+	Rooms map[string]map[string]any `expr:"rooms"`
+}
+
+// compileCondition compiles a boolean condition expression
+func compileCondition(input string) (*vm.Program, error) {
+	program, err := expr.Compile(input, expr.Env(Env{}), expr.AsBool())
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", input, err)
+	}
+	return program, nil
+}
+
+// compileValue compiles an expression computing a value
+func compileValue(input string) (*vm.Program, error) {
+	program, err := expr.Compile(input, expr.Env(Env{}))
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", input, err)
+	}
+	return program, nil
+}
+
+// values holds the current values rule expressions are evaluated against
+type values struct {
+	env          Env
+	channelRooms map[string][]string
+}
+
+func newValues() *values {
+	return &values{env: Env{
+		DataPoints: make(map[string]any),
+		SysVars:    make(map[string]any),
+		Rooms:      make(map[string]map[string]any),
+	}}
+}
+
+// setTopology assigns channels to rooms
+func (v *values) setTopology(topology *homematic.Topology) {
+	v.channelRooms = make(map[string][]string)
+	for _, room := range topology.Rooms {
+		for _, ch := range room.Channels {
+			v.channelRooms[ch.IseID] = append(v.channelRooms[ch.IseID], room.Name)
+		}
+	}
+}
+
+// observe records the new value of a data point change
+func (v *values) observe(change homematic.DataPointChange) {
+	value := typedValue(change.NewValue)
+	v.env.DataPoints[change.IseID] = value
+
+	if change.Type == "" {
+		return
+	}
+	key := strings.ToLower(change.Type)
+	for _, room := range v.channelRooms[change.ChannelIseID] {
+		if v.env.Rooms[room] == nil {
+			v.env.Rooms[room] = make(map[string]any)
+		}
+		v.env.Rooms[room][key] = value
+	}
+}
+
+// observeSystemVariable records the value of a system variable
+func (v *values) observeSystemVariable(sysVar homematic.SystemVariable) {
+	value := typedValue(sysVar.Value)
+	v.env.DataPoints[sysVar.IseID] = value
+	v.env.SysVars[sysVar.Name] = value
+}
+
+// typedValue converts a value to a bool, a number or a string
+func typedValue(value string) any {
+	trimmed := strings.TrimSpace(value)
+	switch strings.ToLower(trimmed) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		return f
+	}
+	return value
+}
+
+// formatValue formats the result of a value expression as a data point value
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
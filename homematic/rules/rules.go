@@ -0,0 +1,242 @@
+// Package rules evaluates declarative automation rules of the form "when
+// <data point or system variable condition> for <duration> then <actions>"
+// against a stream of data point changes
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule fires its actions once its condition has held for the given duration.
+// It fires again only after the condition was false in between.
+type Rule struct {
+	Name string        `yaml:"name" json:"name"`
+	When Condition     `yaml:"when" json:"when"`
+	For  time.Duration `yaml:"for,omitempty" json:"for,omitempty"`
+	Then []Action      `yaml:"then" json:"then"`
+}
+
+// Condition compares the value of a data point or system variable, both
+// identified by their ise_id, with a fixed value, or evaluates an expression
+type Condition struct {
+	DataPoint string `yaml:"datapoint,omitempty" json:"datapoint,omitempty"`
+	SysVar    string `yaml:"sysvar,omitempty" json:"sysvar,omitempty"`
+
+	// Op is one of ==, !=, <, <=, > and >=; values are compared numerically
+	// if both are numbers (or booleans), otherwise as strings
+	Op    string `yaml:"op,omitempty" json:"op,omitempty"`
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+
+	// Expr is a boolean expression over the current values, see Env; it is
+	// evaluated on every change and replaces DataPoint, SysVar, Op and Value
+	Expr string `yaml:"expr,omitempty" json:"expr,omitempty"`
+}
+
+// Action is a single action of a rule; exactly one field must be set
+type Action struct {
+	SetState   *SetState `yaml:"set_state,omitempty" json:"set_state,omitempty"`
+	RunProgram string    `yaml:"run_program,omitempty" json:"run_program,omitempty"`
+	Notify     string    `yaml:"notify,omitempty" json:"notify,omitempty"`
+
+	// NotifyExpr is an expression computing the notification message, see Env
+	NotifyExpr string `yaml:"notify_expr,omitempty" json:"notify_expr,omitempty"`
+}
+
+// SetState changes the value of a data point or system variable
+type SetState struct {
+	IseID string `yaml:"ise_id" json:"ise_id"`
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+
+	// ValueExpr is an expression computing the value, see Env; it replaces Value
+	ValueExpr string `yaml:"value_expr,omitempty" json:"value_expr,omitempty"`
+}
+
+// ruleFile is the layout of a YAML rule file
+type ruleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Load reads rules from YAML and validates them
+func Load(r io.Reader) ([]Rule, error) {
+	var file ruleFile
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	if err := Validate(file.Rules); err != nil {
+		return nil, err
+	}
+	return file.Rules, nil
+}
+
+// LoadFile reads rules from a YAML file
+func LoadFile(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Validate checks that all rules are complete and their names are unique
+func Validate(rules []Rule) error {
+	names := make(map[string]bool, len(rules))
+	var errs []error
+	for i, rule := range rules {
+		if rule.Name == "" {
+			errs = append(errs, fmt.Errorf("rule %d has no name", i))
+			continue
+		}
+		if names[rule.Name] {
+			errs = append(errs, fmt.Errorf("duplicate rule %q", rule.Name))
+		}
+		names[rule.Name] = true
+
+		if err := rule.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", rule.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Rule) validate() error {
+	if r.For < 0 {
+		return fmt.Errorf("negative duration %s", r.For)
+	}
+	if err := r.When.validate(); err != nil {
+		return err
+	}
+	if len(r.Then) == 0 {
+		return errors.New("no actions")
+	}
+	for i, action := range r.Then {
+		if err := action.validate(); err != nil {
+			return fmt.Errorf("action %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (c *Condition) validate() error {
+	if c.Expr != "" {
+		if c.DataPoint != "" || c.SysVar != "" || c.Op != "" || c.Value != "" {
+			return errors.New("condition with expr must not set datapoint, sysvar, op or value")
+		}
+		_, err := compileCondition(c.Expr)
+		return err
+	}
+	if (c.DataPoint == "") == (c.SysVar == "") {
+		return errors.New("condition needs either a datapoint, a sysvar or an expr")
+	}
+	switch c.Op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return nil
+	default:
+		return fmt.Errorf("unsupported operator %q", c.Op)
+	}
+}
+
+func (a *Action) validate() error {
+	set := 0
+	if a.SetState != nil {
+		set++
+		if a.SetState.IseID == "" {
+			return errors.New("set_state needs an ise_id")
+		}
+		if a.SetState.ValueExpr != "" {
+			if a.SetState.Value != "" {
+				return errors.New("set_state must not set both value and value_expr")
+			}
+			if _, err := compileValue(a.SetState.ValueExpr); err != nil {
+				return err
+			}
+		}
+	}
+	if a.RunProgram != "" {
+		set++
+	}
+	if a.Notify != "" {
+		set++
+	}
+	if a.NotifyExpr != "" {
+		set++
+		if _, err := compileValue(a.NotifyExpr); err != nil {
+			return err
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of set_state, run_program, notify and notify_expr must be set")
+	}
+	return nil
+}
+
+// IseID returns the ise_id of the data point or system variable of the condition
+func (c *Condition) IseID() string {
+	if c.DataPoint != "" {
+		return c.DataPoint
+	}
+	return c.SysVar
+}
+
+// Matches reports whether a value fulfills the condition
+func (c *Condition) Matches(value string) bool {
+	a, aNumeric := number(value)
+	b, bNumeric := number(c.Value)
+	if aNumeric && bNumeric {
+		switch c.Op {
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		case "<":
+			return a < b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		case ">=":
+			return a >= b
+		}
+		return false
+	}
+
+	cmp := strings.Compare(value, c.Value)
+	switch c.Op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// number parses a numeric or boolean value
+func number(value string) (float64, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true":
+		return 1, true
+	case "false":
+		return 0, true
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return f, err == nil
+}
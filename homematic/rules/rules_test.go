@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRules = `
+rules:
+  - name: bathroom-fan
+    when: {datapoint: "2412", op: ">", value: "65"}
+    for: 5m
+    then:
+      - set_state: {ise_id: "3001", value: "true"}
+      - notify: Bathroom humidity high
+  - name: away
+    when: {sysvar: "950", op: "==", value: "false"}
+    then:
+      - run_program: "1001"
+`
+
+func TestLoad(t *testing.T) {
+	rules, err := Load(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	fan := rules[0]
+	if fan.For != 5*time.Minute || fan.When.IseID() != "2412" || fan.Then[0].SetState.IseID != "3001" || fan.Then[1].Notify == "" {
+		t.Errorf("unexpected rule: %+v", fan)
+	}
+	if rules[1].When.IseID() != "950" || rules[1].Then[0].RunProgram != "1001" {
+		t.Errorf("unexpected rule: %+v", rules[1])
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		yaml string
+		want string
+	}{
+		{`rules: [{name: a, when: {datapoint: "1", op: "~", value: "1"}, then: [{notify: x}]}]`, "unsupported operator"},
+		{`rules: [{name: a, when: {op: "==", value: "1"}, then: [{notify: x}]}]`, "either a datapoint, a sysvar or an expr"},
+		{`rules: [{name: a, when: {expr: "dp[", }, then: [{notify: x}]}]`, "invalid expression"},
+		{`rules: [{name: a, when: {expr: "1 + 1"}, then: [{notify: x}]}]`, "invalid expression"},
+		{`rules: [{name: a, when: {expr: "true", datapoint: "1"}, then: [{notify: x}]}]`, "must not set"},
+		{`rules: [{name: a, when: {datapoint: "1", op: "==", value: "1"}}]`, "no actions"},
+		{`rules: [{name: a, when: {datapoint: "1", op: "==", value: "1"}, then: [{notify: x, run_program: "1"}]}]`, "exactly one"},
+		{`rules: [{name: a, when: {datapoint: "1", op: "==", value: "1"}, then: [{notify: x}]}, {name: a, when: {datapoint: "1", op: "==", value: "1"}, then: [{notify: x}]}]`, "duplicate rule"},
+		{`rules: [{name: a, when: {datapoint: "1", op: "==", value: "1"}, then: [{notify: x}], unless: foo}]`, "unless"},
+	}
+	for _, tt := range tests {
+		_, err := Load(strings.NewReader(tt.yaml))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Load(%s): expected error containing %q, got %v", tt.yaml, tt.want, err)
+		}
+	}
+}
+
+func TestConditionMatches(t *testing.T) {
+	tests := []struct {
+		op, value, input string
+		want             bool
+	}{
+		{">", "65", "65.5", true},
+		{">", "65", "9", false},
+		{"<=", "21", "21.0", true},
+		{"==", "true", "true", true},
+		{"==", "1", "true", true},
+		{"!=", "open", "closed", true},
+		{"==", "Tür offen", "Tür offen", true},
+		{"<", "b", "a", true},
+	}
+	for _, tt := range tests {
+		c := Condition{DataPoint: "1", Op: tt.op, Value: tt.value}
+		if got := c.Matches(tt.input); got != tt.want {
+			t.Errorf("%q %s %q = %t, want %t", tt.input, tt.op, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package homematic
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ScriptResult is the outcome of a HomeMatic script run with RunScript
+type ScriptResult struct {
+	// Output is the text the script wrote with Write and WriteLine
+	Output string `json:"output"`
+	// Variables holds the values of the variables of the script by name
+	Variables map[string]string `json:"variables"`
+}
+
+// scriptMetaVariables are the elements exec.cgi adds to the variables of a script
+var scriptMetaVariables = map[string]bool{"exec": true, "sessionId": true, "httpUserAgent": true}
+
+// RunScript executes a HomeMatic script (HM-Script) on the CCU with exec.cgi,
+// e.g. to reach functionality not covered by the other endpoints, and
+// returns its output and the final values of its variables:
+//
+//	result, err := client.RunScript(ctx, `string version = dom.BuildLabel();`)
+//	fmt.Println(result.Variables["version"])
+//
+// Scripts can change anything, so they are rejected if a WritePolicy is set
+// and always need a confirmation if CriticalWrites are configured. With a
+// WriteLock, scripts hold the "script" lock key, so that scripts of several
+// clients don't run at the same time. In dry run mode the script is not sent
+// and the result is empty.
+func (c *Client) RunScript(ctx context.Context, script string) (*ScriptResult, error) {
+	if c.WritePolicy != nil {
+		return nil, fmt.Errorf("%w: scripts bypass the write policy", ErrWriteNotAllowed)
+	}
+	if c.DryRun {
+		return &ScriptResult{Variables: map[string]string{}}, nil
+	}
+	if err := c.confirmWrites(ctx, WriteScript, nil, []string{script}); err != nil {
+		return nil, err
+	}
+	unlock, err := c.lockWrites(ctx, []string{"script"})
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	payload, err := encodeLatin1(script)
+	if err != nil {
+		return nil, err
+	}
+	var result *ScriptResult
+	err = c.doPostContext(ctx, "exec.cgi", nil, []byte(payload), func(body []byte) error {
+		if !utf8.Valid(body) {
+			// output without XML declaration is not converted by doPostContext
+			var converted bytes.Buffer
+			writeLatin1AsUTF8(&converted, body)
+			body = converted.Bytes()
+		}
+		result, err = parseScriptResponse(body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseScriptResponse splits a script response into the output and the
+// trailing <xml> element holding the variables
+func parseScriptResponse(body []byte) (*ScriptResult, error) {
+	result := &ScriptResult{Variables: make(map[string]string)}
+	i := bytes.LastIndex(body, []byte("<xml>"))
+	if i < 0 {
+		result.Output = string(body)
+		return result, nil
+	}
+	result.Output = string(body[:i])
+
+	decoder := xml.NewDecoder(bytes.NewReader(body[i:]))
+	var variables struct {
+		Elements []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	}
+	if err := decoder.Decode(&variables); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse script variables: %w", err)
+	}
+	for _, element := range variables.Elements {
+		if !scriptMetaVariables[element.XMLName.Local] {
+			result.Variables[element.XMLName.Local] = strings.TrimSpace(element.Value)
+		}
+	}
+	return result, nil
+}
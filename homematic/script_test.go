@@ -0,0 +1,103 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRunScript(t *testing.T) {
+	var script string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		script = string(body)
+		if r.Method != http.MethodPost || r.URL.Path != "/addons/xmlapi/exec.cgi" || r.URL.Query().Get("sid") != "token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		// the output is ISO-8859-1 encoded
+		w.Write([]byte("Gr\xfc\xdfe\n<xml><exec>/addons/xmlapi/exec.cgi</exec><sessionId></sessionId>" +
+			"<httpUserAgent>Go-http-client/1.1</httpUserAgent><version>3.71.12</version><count>5</count></xml>"))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	result, err := client.RunScript(context.Background(), "string version = dom.BuildLabel(); WriteLine(\"Grüße\");")
+	if err != nil {
+		t.Fatalf("RunScript failed: %v", err)
+	}
+	if script != "string version = dom.BuildLabel(); WriteLine(\"Gr\xfc\xdfe\");" {
+		t.Errorf("expected the script to be sent in ISO-8859-1, got %q", script)
+	}
+	if result.Output != "Grüße\n" {
+		t.Errorf("unexpected output %q", result.Output)
+	}
+	if len(result.Variables) != 2 || result.Variables["version"] != "3.71.12" || result.Variables["count"] != "5" {
+		t.Errorf("unexpected variables %v", result.Variables)
+	}
+
+	script = ""
+	client.DryRun = true
+	if _, err := client.RunScript(context.Background(), "dom.GetObject(1234).State(true);"); err != nil || script != "" {
+		t.Errorf("expected the dry run not to send the script, got %q, %v", script, err)
+	}
+
+	client.DryRun = false
+	client.WritePolicy = &WritePolicy{Allow: []string{"1234"}}
+	if _, err := client.RunScript(context.Background(), ""); !errors.Is(err, ErrWriteNotAllowed) {
+		t.Errorf("expected scripts to be rejected with a write policy, got %v", err)
+	}
+
+	client.WritePolicy = nil
+	var confirmed ConfirmationRequest
+	client.CriticalWrites = &CriticalWrites{Targets: []string{"3445"}, Hook: ConfirmationFunc(
+		func(ctx context.Context, req ConfirmationRequest) error {
+			confirmed = req
+			return errors.New("declined")
+		})}
+	if _, err := client.RunScript(context.Background(), "x"); !errors.Is(err, ErrWriteNotConfirmed) {
+		t.Errorf("expected the script to need a confirmation, got %v", err)
+	}
+	if confirmed.String() != "script x" || len(confirmed.Critical) != 1 {
+		t.Errorf("unexpected confirmation request %+v", confirmed)
+	}
+
+	// scripts are confirmed without critical targets, with the context of the call
+	type ctxKey struct{}
+	var value any
+	client.CriticalWrites = &CriticalWrites{Hook: ConfirmationFunc(
+		func(ctx context.Context, req ConfirmationRequest) error {
+			value = ctx.Value(ctxKey{})
+			return nil
+		})}
+	locker := &recordingLocker{}
+	client.WriteLock = locker
+	ctx := context.WithValue(context.Background(), ctxKey{}, "call")
+	if _, err := client.RunScript(ctx, "x"); err != nil {
+		t.Fatalf("RunScript failed: %v", err)
+	}
+	if value != "call" {
+		t.Errorf("expected the hook to get the context of the call, got %v", value)
+	}
+	if len(locker.keys) != 1 || locker.keys[0][0] != "script" || locker.unlocked != 1 {
+		t.Errorf("expected the script lock to be held, got %v", locker.keys)
+	}
+
+	client.CriticalWrites = &CriticalWrites{}
+	if _, err := client.RunScript(ctx, "x"); !errors.Is(err, ErrWriteNotConfirmed) {
+		t.Errorf("expected scripts to need a hook, got %v", err)
+	}
+}
+
+func TestParseScriptResponse(t *testing.T) {
+	result, err := parseScriptResponse([]byte("no variables"))
+	if err != nil || result.Output != "no variables" || len(result.Variables) != 0 {
+		t.Errorf("unexpected result %+v, %v", result, err)
+	}
+	if _, err := parseScriptResponse([]byte("<xml><a>1</b></xml>")); err == nil {
+		t.Error("expected an error for malformed variables")
+	}
+}
@@ -0,0 +1,383 @@
+package homematic
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretsProvider supplies the token of a client from a secret store
+type SecretsProvider interface {
+	// Token returns the current token
+	Token(ctx context.Context) (string, error)
+}
+
+// SecretsProviderFunc adapts a function to the SecretsProvider interface
+type SecretsProviderFunc func(ctx context.Context) (string, error)
+
+// Token implements SecretsProvider
+func (f SecretsProviderFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// EnvSecret returns a SecretsProvider reading the token from an environment variable
+func EnvSecret(name string) SecretsProvider {
+	return SecretsProviderFunc(func(context.Context) (string, error) {
+		token, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return strings.TrimSpace(token), nil
+	})
+}
+
+// FileSecret returns a SecretsProvider reading the token from a file,
+// decrypting it with key if it is encrypted
+func FileSecret(path string, key *EncryptionKey) SecretsProvider {
+	return SecretsProviderFunc(func(context.Context) (string, error) {
+		data, err := ReadEncryptedFile(path, key)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	})
+}
+
+// secretCache holds the token fetched from the SecretsProvider of a client;
+// it is shared by all copies of a client
+type secretCache struct {
+	mu    sync.Mutex
+	token string
+	valid bool
+}
+
+// sessionToken returns the token for a request: Token, or the token of the
+// SecretsProvider, fetched on first use and again if refresh is set
+func (c *Client) sessionToken(ctx context.Context, refresh bool) (token string, cached bool, err error) {
+	if c.Secrets == nil {
+		return c.Token, false, nil
+	}
+	if c.secrets == nil {
+		token, err := c.Secrets.Token(ctx)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to fetch token: %w", err)
+		}
+		return token, false, nil
+	}
+
+	s := c.secrets
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.valid && !refresh {
+		return s.token, true, nil
+	}
+	token, err = c.Secrets.Token(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to fetch token: %w", err)
+	}
+	s.token, s.valid = token, true
+	return token, false, nil
+}
+
+// VaultSecret reads the token from a HashiCorp Vault KV secrets engine
+type VaultSecret struct {
+	// Addr is the address of the Vault server; VAULT_ADDR if empty
+	Addr string
+	// VaultToken authenticates with Vault; VAULT_TOKEN if empty
+	VaultToken string
+	Namespace  string
+
+	// Mount is the mount path of the secrets engine, "secret" by default
+	Mount string
+	// Path is the path of the secret within the mount, e.g. "homematic/ccu"
+	Path string
+	// Field is the key holding the token, "token" by default
+	Field string
+	// KVVersion is the version of the secrets engine, 2 by default
+	KVVersion int
+
+	HTTPClient *http.Client
+}
+
+// Token implements SecretsProvider
+func (v *VaultSecret) Token(ctx context.Context) (string, error) {
+	addr := cmp.Or(v.Addr, os.Getenv("VAULT_ADDR"))
+	if addr == "" {
+		return "", errors.New("no Vault address configured")
+	}
+	mount := strings.Trim(cmp.Or(v.Mount, "secret"), "/")
+	path := strings.Trim(v.Path, "/")
+	u := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(addr, "/"), mount, path)
+	if v.KVVersion == 1 {
+		u = fmt.Sprintf("%s/v1/%s/%s", strings.TrimRight(addr, "/"), mount, path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", cmp.Or(v.VaultToken, os.Getenv("VAULT_TOKEN")))
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	var result struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := fetchSecretJSON(v.HTTPClient, req, "Vault", &result); err != nil {
+		return "", err
+	}
+	data := result.Data
+	if v.KVVersion != 1 {
+		var versioned struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &versioned); err != nil {
+			return "", fmt.Errorf("failed to parse Vault response: %w", err)
+		}
+		data = versioned.Data
+	}
+	return secretField(data, cmp.Or(v.Field, "token"))
+}
+
+// AWSSecret reads the token from AWS Secrets Manager. The secret is either
+// the token itself or a JSON object holding it in Field.
+type AWSSecret struct {
+	// SecretID is the name or ARN of the secret
+	SecretID string
+	Field    string
+
+	// Region is the AWS region; AWS_REGION or AWS_DEFAULT_REGION if empty
+	Region string
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials;
+	// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN if empty
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Endpoint overrides the regional Secrets Manager endpoint, e.g. for a VPC endpoint
+	Endpoint   string
+	HTTPClient *http.Client
+
+	now func() time.Time
+}
+
+// Token implements SecretsProvider
+func (a *AWSSecret) Token(ctx context.Context) (string, error) {
+	region := cmp.Or(a.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+	creds := awsCredentials{
+		accessKeyID:     cmp.Or(a.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID")),
+		secretAccessKey: cmp.Or(a.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		sessionToken:    cmp.Or(a.SessionToken, os.Getenv("AWS_SESSION_TOKEN")),
+	}
+	if region == "" || creds.accessKeyID == "" || creds.secretAccessKey == "" {
+		return "", errors.New("AWS region and credentials are required")
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": a.SecretID})
+	if err != nil {
+		return "", err
+	}
+	endpoint := cmp.Or(a.Endpoint, "https://secretsmanager."+region+".amazonaws.com/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	now := time.Now
+	if a.now != nil {
+		now = a.now
+	}
+	signAWSv4(req, body, "secretsmanager", region, creds, now())
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := fetchSecretJSON(a.HTTPClient, req, "AWS Secrets Manager", &result); err != nil {
+		return "", err
+	}
+	if a.Field == "" {
+		return strings.TrimSpace(result.SecretString), nil
+	}
+	return secretField([]byte(result.SecretString), a.Field)
+}
+
+// awsCredentials are the credentials requests are signed with
+type awsCredentials struct {
+	accessKeyID, secretAccessKey, sessionToken string
+}
+
+// signAWSv4 signs a request with AWS Signature Version 4, covering the host
+// and all headers set on the request
+func signAWSv4(req *http.Request, body []byte, service, region string, creds awsCredentials, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.Query().Encode(), canonicalHeaders.String(), signedHeaders, hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + creds.secretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// gcpMetadataTokenURL is the access token endpoint of the GCE metadata server
+const gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCPSecret reads the token from Google Cloud Secret Manager
+type GCPSecret struct {
+	// Name is the resource name of the secret version, e.g.
+	// "projects/my-project/secrets/ccu-token/versions/latest"
+	Name  string
+	Field string
+
+	// AccessToken returns an OAuth access token; by default it is requested
+	// from the metadata server of the GCE instance or GKE pod
+	AccessToken func(ctx context.Context) (string, error)
+
+	// Endpoint overrides the Secret Manager API endpoint
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// Token implements SecretsProvider
+func (g *GCPSecret) Token(ctx context.Context) (string, error) {
+	accessToken := g.AccessToken
+	if accessToken == nil {
+		accessToken = g.metadataAccessToken
+	}
+	bearer, err := accessToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get GCP access token: %w", err)
+	}
+
+	u := strings.TrimRight(cmp.Or(g.Endpoint, "https://secretmanager.googleapis.com"), "/") + "/v1/" + g.Name + ":access"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+bearer)
+
+	var result struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := fetchSecretJSON(g.HTTPClient, req, "GCP Secret Manager", &result); err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode GCP secret: %w", err)
+	}
+	if g.Field == "" {
+		return strings.TrimSpace(string(data)), nil
+	}
+	return secretField(data, g.Field)
+}
+
+// metadataAccessToken requests an access token from the GCE metadata server
+func (g *GCPSecret) metadataAccessToken(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := fetchSecretJSON(g.HTTPClient, req, "GCP metadata server", &result); err != nil {
+		return "", err
+	}
+	return result.AccessToken, nil
+}
+
+// fetchSecretJSON sends a request to a secret store and decodes the JSON response
+func fetchSecretJSON(httpClient *http.Client, req *http.Request, store string, v any) error {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", store, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to query %s: unexpected status code %d: %s", store, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", store, err)
+	}
+	return nil
+}
+
+// secretField returns a string field of a JSON object
+func secretField(data []byte, field string) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", fmt.Errorf("failed to parse secret: %w", err)
+	}
+	value, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret has no string field %q", field)
+	}
+	return strings.TrimSpace(value), nil
+}
@@ -0,0 +1,127 @@
+package homematic
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAWSv4(t *testing.T) {
+	// "get-vanilla" of the AWS Signature Version 4 test suite
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{accessKeyID: "AKIDEXAMPLE", secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signAWSv4(req, nil, "service", "us-east-1", creds, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("unexpected authorization:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestVaultSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/data/homematic/ccu" || r.Header.Get("X-Vault-Token") != "root" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"token":"ccu-token"},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	vault := &VaultSecret{Addr: server.URL, VaultToken: "root", Mount: "kv", Path: "homematic/ccu"}
+	token, err := vault.Token(context.Background())
+	if err != nil || token != "ccu-token" {
+		t.Fatalf("unexpected token %q: %v", token, err)
+	}
+
+	vault.VaultToken = "wrong"
+	if _, err := vault.Token(context.Background()); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected a status error, got %v", err)
+	}
+}
+
+func TestAWSSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/20240501/eu-central-1/secretsmanager/aws4_request") ||
+			string(body) != `{"SecretId":"ccu"}` {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"Name":"ccu","SecretString":"{\"token\":\"aws-token\"}"}`))
+	}))
+	defer server.Close()
+
+	secret := &AWSSecret{
+		SecretID: "ccu", Field: "token", Region: "eu-central-1",
+		AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: server.URL,
+		now: func() time.Time { return time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC) },
+	}
+	token, err := secret.Token(context.Background())
+	if err != nil || token != "aws-token" {
+		t.Fatalf("unexpected token %q: %v", token, err)
+	}
+}
+
+func TestGCPSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/p/secrets/ccu/versions/latest:access" || r.Header.Get("Authorization") != "Bearer ya29" {
+			http.Error(w, "denied", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"payload":{"data":"` + base64.StdEncoding.EncodeToString([]byte("gcp-token\n")) + `"}}`))
+	}))
+	defer server.Close()
+
+	secret := &GCPSecret{
+		Name:        "projects/p/secrets/ccu/versions/latest",
+		AccessToken: func(context.Context) (string, error) { return "ya29", nil },
+		Endpoint:    server.URL,
+	}
+	token, err := secret.Token(context.Background())
+	if err != nil || token != "gcp-token" {
+		t.Fatalf("unexpected token %q: %v", token, err)
+	}
+}
+
+func TestClientRefetchesRejectedSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sid") != "new" {
+			w.Write([]byte(`<not_authenticated/>`))
+			return
+		}
+		w.Write([]byte(`<version>2.3</version>`))
+	}))
+	defer server.Close()
+
+	tokens := []string{"old", "new"}
+	fetches := 0
+	client := NewClient(server.URL, "")
+	client.Secrets = SecretsProviderFunc(func(context.Context) (string, error) {
+		token := tokens[min(fetches, len(tokens)-1)]
+		fetches++
+		return token, nil
+	})
+
+	// a freshly fetched token that is rejected is not fetched again
+	if _, err := client.GetVersion(); !errors.Is(err, ErrNotAuthenticated) {
+		t.Fatalf("expected ErrNotAuthenticated, got %v", err)
+	}
+	// the cached token is rejected, so it is fetched again
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if _, err := client.GetVersion(); err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("expected 2 fetches, got %d", fetches)
+	}
+}
@@ -0,0 +1,191 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SMOKE_DETECTOR_COMMAND values of HmIP-SWSD smoke detectors
+const (
+	smokeCommandIntrusionAlarmOff = "1"
+	smokeCommandSmokeTest         = "3"
+)
+
+// SmokeDetectorGroup aggregates HM-Sec-SD and HmIP-SWSD smoke detectors,
+// e.g. all detectors of a home, which alarm together
+type SmokeDetectorGroup struct {
+	Client    *Client
+	DeviceIDs []string
+}
+
+// SmokeDetectorStatus is the state of a single smoke detector
+type SmokeDetectorStatus struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+
+	// Alarm is set while the detector reports smoke or a forwarded alarm;
+	// AlarmStatus holds the SMOKE_DETECTOR_ALARM_STATUS name of HmIP-SWSD
+	Alarm       bool   `json:"alarm"`
+	AlarmStatus string `json:"alarm_status,omitempty"`
+
+	// TestResult is the SMOKE_DETECTOR_TEST_RESULT name of HmIP-SWSD
+	TestResult string `json:"test_result,omitempty"`
+
+	// Errors lists the error states, e.g. UNREACH, LOW_BAT or a degraded smoke chamber
+	Errors []string `json:"errors,omitempty"`
+}
+
+// SmokeDetectorReport is the aggregated state of a smoke detector group
+type SmokeDetectorReport struct {
+	Detectors []SmokeDetectorStatus `json:"detectors"`
+	// Alarm is set if any detector of the group alarms
+	Alarm bool `json:"alarm"`
+}
+
+// OK reports whether no detector alarms or reports an error
+func (r *SmokeDetectorReport) OK() bool {
+	if r.Alarm {
+		return false
+	}
+	for _, d := range r.Detectors {
+		if len(d.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// NewSmokeDetectorGroup creates a group of the smoke detectors with the given device ise_ids
+func NewSmokeDetectorGroup(client *Client, deviceIDs ...string) *SmokeDetectorGroup {
+	return &SmokeDetectorGroup{Client: client, DeviceIDs: deviceIDs}
+}
+
+// Status reads the state of all detectors of the group
+func (g *SmokeDetectorGroup) Status() (*SmokeDetectorReport, error) {
+	devices, err := g.devices()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SmokeDetectorReport{Detectors: make([]SmokeDetectorStatus, 0, len(devices))}
+	for i := range devices {
+		status := newSmokeDetectorStatus(&devices[i])
+		report.Alarm = report.Alarm || status.Alarm
+		report.Detectors = append(report.Detectors, status)
+	}
+	return report, nil
+}
+
+// Test triggers the smoke test of all HmIP-SWSD detectors of the group; the
+// results are reported as TestResult once the detectors finished the test.
+// HM-Sec-SD detectors can only be tested on the device.
+func (g *SmokeDetectorGroup) Test() error {
+	return g.command(smokeCommandSmokeTest)
+}
+
+// Silence stops forwarded and intrusion alarms of all HmIP-SWSD detectors of
+// the group. The detector sensing smoke keeps alarming until it is silenced
+// on the device.
+func (g *SmokeDetectorGroup) Silence() error {
+	return g.command(smokeCommandIntrusionAlarmOff)
+}
+
+// command writes a SMOKE_DETECTOR_COMMAND to all detectors supporting it
+func (g *SmokeDetectorGroup) command(command string) error {
+	devices, err := g.devices()
+	if err != nil {
+		return err
+	}
+
+	var ids, values []string
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				if dataPointType(dp) == "SMOKE_DETECTOR_COMMAND" {
+					ids = append(ids, dp.IseID)
+					values = append(values, command)
+				}
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return errors.New("no smoke detector of the group supports remote commands")
+	}
+	return g.Client.ChangeState(ids, values)
+}
+
+// devices reads the state of the detectors, failing for unknown device ise_ids
+func (g *SmokeDetectorGroup) devices() ([]Device, error) {
+	if len(g.DeviceIDs) == 0 {
+		return nil, errors.New("smoke detector group has no devices")
+	}
+	devices, err := g.Client.GetState(g.DeviceIDs, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		found[device.IseID] = true
+	}
+	var missing []string
+	for _, id := range g.DeviceIDs {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("smoke detectors not found: %s", strings.Join(missing, ", "))
+	}
+	return devices, nil
+}
+
+// newSmokeDetectorStatus extracts the alarm and error states of a detector
+func newSmokeDetectorStatus(device *Device) SmokeDetectorStatus {
+	status := SmokeDetectorStatus{DeviceID: device.IseID, Name: device.Name}
+	if device.Unreach || (device.Maintenance != nil && device.Maintenance.Unreach) {
+		status.Errors = append(status.Errors, "UNREACH")
+	}
+	if device.Maintenance != nil && device.Maintenance.LowBat {
+		status.Errors = append(status.Errors, "LOW_BAT")
+	}
+
+	for _, ch := range device.Channels {
+		for _, dp := range ch.DataPoints {
+			switch dpType := dataPointType(dp); dpType {
+			case "SMOKE_DETECTOR_ALARM_STATUS":
+				status.AlarmStatus = enumName(dp.Value, "IDLE_OFF", "PRIMARY_ALARM", "INTRUSION_ALARM", "SECONDARY_ALARM")
+				status.Alarm = status.AlarmStatus != "IDLE_OFF" && status.AlarmStatus != ""
+			case "SMOKE_DETECTOR_TEST_RESULT":
+				status.TestResult = enumName(dp.Value, "NONE", "SMOKE_TEST_OK", "SMOKE_TEST_FAILED",
+					"COMMUNICATION_TEST_SENT", "COMMUNICATION_TEST_OK")
+				if status.TestResult == "SMOKE_TEST_FAILED" {
+					status.Errors = append(status.Errors, status.TestResult)
+				}
+			case "STATE":
+				// HM-Sec-SD report smoke in the STATE of their sensor channel
+				if ch.Index > 0 && parseFlag(dp.Value) {
+					status.Alarm = true
+				}
+			case "ERROR_SMOKE_CHAMBER", "ERROR_DEGRADED_CHAMBER", "ERROR_ALARM_TEST":
+				if parseFlag(dp.Value) {
+					status.Errors = append(status.Errors, dpType)
+				}
+			}
+		}
+	}
+	return status
+}
+
+// enumName returns the name of an enum value, or the value itself if it is out of range
+func enumName(value string, names ...string) string {
+	value = strings.TrimSpace(value)
+	for i, name := range names {
+		if value == strconv.Itoa(i) {
+			return name
+		}
+	}
+	return value
+}
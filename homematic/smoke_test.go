@@ -0,0 +1,99 @@
+package homematic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestSmokeDetectorGroupStatus(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	group := NewSmokeDetectorGroup(NewClient(server.URL, "token"), "2459")
+	report, err := group.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !report.OK() || len(report.Detectors) != 1 {
+		t.Fatalf("expected an idle detector, got %+v", report)
+	}
+	if d := report.Detectors[0]; d.Name != "Rauchmelder Flur" || d.AlarmStatus != "IDLE_OFF" || d.TestResult != "NONE" {
+		t.Errorf("unexpected detector status: %+v", d)
+	}
+
+	mock.SetValue("2470", "1")
+	mock.SetValue("2463", "true")
+	report, err = group.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	d := report.Detectors[0]
+	if !report.Alarm || report.OK() || d.AlarmStatus != "PRIMARY_ALARM" || strings.Join(d.Errors, ",") != "LOW_BAT" {
+		t.Errorf("expected a primary alarm with low battery, got %+v", report)
+	}
+
+	if _, err := NewSmokeDetectorGroup(group.Client, "2459", "2999").Status(); err == nil || !strings.Contains(err.Error(), "2999") {
+		t.Errorf("expected an error naming the unknown detector, got %v", err)
+	}
+}
+
+func TestSmokeDetectorGroupCommands(t *testing.T) {
+	var changes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/addons/xmlapi/statechange.cgi" {
+			changes = append(changes, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+			writeChanged(w, r)
+			return
+		}
+		ids := r.URL.Query().Get("device_id")
+		w.Write([]byte("<stateList>"))
+		if strings.Contains(ids, "100") {
+			w.Write([]byte(`<device name="SD Keller" ise_id="100"><channel ise_id="101" index="1">
+				<datapoint type="STATE" ise_id="102" value="true"/>
+				<datapoint type="ERROR_SMOKE_CHAMBER" ise_id="103" value="1"/>
+			</channel></device>`))
+		}
+		if strings.Contains(ids, "200") {
+			w.Write([]byte(`<device name="SWSD Flur" ise_id="200"><channel ise_id="201" index="1">
+				<datapoint type="SMOKE_DETECTOR_ALARM_STATUS" ise_id="202" value="3"/>
+				<datapoint type="SMOKE_DETECTOR_COMMAND" ise_id="203" value=""/>
+			</channel></device>`))
+		}
+		w.Write([]byte("</stateList>"))
+	}))
+	defer server.Close()
+
+	group := NewSmokeDetectorGroup(NewClient(server.URL, "token"), "100", "200")
+	report, err := group.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !report.Detectors[0].Alarm || strings.Join(report.Detectors[0].Errors, ",") != "ERROR_SMOKE_CHAMBER" {
+		t.Errorf("unexpected HM-Sec-SD status: %+v", report.Detectors[0])
+	}
+	if !report.Detectors[1].Alarm || report.Detectors[1].AlarmStatus != "SECONDARY_ALARM" {
+		t.Errorf("unexpected HmIP-SWSD status: %+v", report.Detectors[1])
+	}
+
+	if err := group.Test(); err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+	if err := group.Silence(); err != nil {
+		t.Fatalf("Silence failed: %v", err)
+	}
+	if strings.Join(changes, " ") != "203=3 203=1" {
+		t.Errorf("unexpected commands: %v", changes)
+	}
+
+	if err := NewSmokeDetectorGroup(group.Client, "100").Test(); err == nil {
+		t.Error("expected an error for a group without HmIP-SWSD detectors")
+	}
+}
@@ -0,0 +1,166 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHTunnel dials the connections to the CCU through an SSH server in its
+// network, e.g. a router or a Raspberry Pi, so that a CCU at home can be
+// reached without exposing it to the internet. The SSH connection is
+// established on first use and re-established after it broke.
+type SSHTunnel struct {
+	// Addr is the host:port of the SSH server; port 22 is used if it is omitted
+	Addr string
+	User string
+
+	// Auth lists the authentication methods, e.g. from SSHKeyAuth or ssh.Password
+	Auth []ssh.AuthMethod
+
+	// HostKeyCallback verifies the key of the SSH server, e.g. from
+	// SSHKnownHosts; the connection is refused if it is nil
+	HostKeyCallback ssh.HostKeyCallback
+
+	// Timeout limits establishing the SSH connection, 10s by default
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// SSHKeyAuth reads an (optionally passphrase protected) private key for public key authentication
+func SSHKeyAuth(path string, passphrase []byte) (ssh.AuthMethod, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	var signer ssh.Signer
+	if len(passphrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(pem, passphrase)
+	} else {
+		signer, err = ssh.ParsePrivateKey(pem)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", path, err)
+	}
+	return ssh.PublicKeys(signer), nil
+}
+
+// SSHKnownHosts returns a host key callback verifying servers against OpenSSH known_hosts files
+func SSHKnownHosts(paths ...string) (ssh.HostKeyCallback, error) {
+	callback, err := knownhosts.New(paths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// SetSSHTunnel routes all requests of the client through the tunnel. TLS
+// settings of the transport are kept; the tunnel is closed with the client.
+func (c *Client) SetSSHTunnel(tunnel *SSHTunnel) error {
+	if err := c.SetDialer(tunnel.DialContext); err != nil {
+		return err
+	}
+	return c.OnClose(func(context.Context) error { return tunnel.Close() })
+}
+
+// DialContext connects to addr from the SSH server
+func (t *SSHTunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, err := t.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.DialContext(ctx, network, addr)
+	if err == nil {
+		return conn, nil
+	}
+	var openErr *ssh.OpenChannelError
+	if errors.As(err, &openErr) || ctx.Err() != nil {
+		// the server refused to forward, the SSH connection itself is fine
+		return nil, fmt.Errorf("failed to dial %s through SSH tunnel: %w", addr, err)
+	}
+
+	// the SSH connection broke, reconnect once
+	t.drop(client)
+	if client, err = t.connect(ctx); err != nil {
+		return nil, err
+	}
+	conn, err = client.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s through SSH tunnel: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// Close closes the SSH connection; the next request re-establishes it
+func (t *SSHTunnel) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client == nil {
+		return nil
+	}
+	err := t.client.Close()
+	t.client = nil
+	return err
+}
+
+// connect returns the SSH connection, establishing it if necessary
+func (t *SSHTunnel) connect(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client != nil {
+		return t.client, nil
+	}
+	if t.HostKeyCallback == nil {
+		return nil, errors.New("SSH tunnel requires a HostKeyCallback")
+	}
+
+	addr := t.Addr
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+	timeout := durationOrDefault(t.Timeout, 10*time.Second)
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SSH server: %w", err)
+	}
+
+	config := &ssh.ClientConfig{
+		User:            t.User,
+		Auth:            t.Auth,
+		HostKeyCallback: t.HostKeyCallback,
+		Timeout:         timeout,
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to establish SSH connection: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	t.client = ssh.NewClient(sshConn, chans, reqs)
+	return t.client, nil
+}
+
+// drop closes a broken SSH connection unless it was already replaced
+func (t *SSHTunnel) drop(client *ssh.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client == client {
+		t.client.Close()
+		t.client = nil
+	}
+}
@@ -0,0 +1,156 @@
+package homematic
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startSSHServer runs an SSH server accepting the given password and
+// forwarding direct-tcpip channels; it returns its address and host key
+func startSSHServer(t *testing.T, password string, connections *int32) (string, ssh.PublicKey) {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() == "tunnel" && string(pass) == password {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					conn.Close()
+					return
+				}
+				atomic.AddInt32(connections, 1)
+				go ssh.DiscardRequests(reqs)
+				for newChannel := range chans {
+					if newChannel.ChannelType() != "direct-tcpip" {
+						newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+						continue
+					}
+					// host string, port uint32, origin host string, origin port uint32
+					data := newChannel.ExtraData()
+					hostLen := binary.BigEndian.Uint32(data)
+					host := string(data[4 : 4+hostLen])
+					port := binary.BigEndian.Uint32(data[4+hostLen:])
+					target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+					if err != nil {
+						newChannel.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					channel, requests, err := newChannel.Accept()
+					if err != nil {
+						target.Close()
+						continue
+					}
+					go ssh.DiscardRequests(requests)
+					go func() {
+						io.Copy(channel, target)
+						channel.Close()
+					}()
+					go func() {
+						io.Copy(target, channel)
+						target.Close()
+					}()
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), signer.PublicKey()
+}
+
+func TestSSHTunnel(t *testing.T) {
+	var connections int32
+	sshAddr, hostKey := startSSHServer(t, "secret", &connections)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	tunnel := &SSHTunnel{
+		Addr:            sshAddr,
+		User:            "tunnel",
+		Auth:            []ssh.AuthMethod{ssh.Password("secret")},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+	}
+	if err := client.SetSSHTunnel(tunnel); err != nil {
+		t.Fatalf("SetSSHTunnel failed: %v", err)
+	}
+
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping through the tunnel failed: %v", err)
+	}
+	if atomic.LoadInt32(&connections) != 1 {
+		t.Errorf("expected one SSH connection, got %d", connections)
+	}
+
+	// a closed tunnel is re-established by the next request
+	client.HTTPClient.CloseIdleConnections()
+	tunnel.Close()
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping after closing the tunnel failed: %v", err)
+	}
+	if atomic.LoadInt32(&connections) != 2 {
+		t.Errorf("expected a second SSH connection, got %d", connections)
+	}
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+func TestSSHTunnelRejectsUnknownHostKey(t *testing.T) {
+	var connections int32
+	sshAddr, _ := startSSHServer(t, "secret", &connections)
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherKey, _ := ssh.NewPublicKey(other)
+
+	tunnel := &SSHTunnel{
+		Addr:            sshAddr,
+		User:            "tunnel",
+		Auth:            []ssh.AuthMethod{ssh.Password("secret")},
+		HostKeyCallback: ssh.FixedHostKey(otherKey),
+	}
+	if _, err := tunnel.DialContext(context.Background(), "tcp", "127.0.0.1:80"); err == nil {
+		t.Error("expected the connection to fail for an unknown host key")
+	}
+	if _, err := (&SSHTunnel{Addr: sshAddr}).DialContext(context.Background(), "tcp", "127.0.0.1:80"); err == nil {
+		t.Error("expected an error without a HostKeyCallback")
+	}
+}
@@ -0,0 +1,105 @@
+package homematic
+
+import (
+	"io"
+	"sort"
+	"time"
+)
+
+// StalenessPolicy flags data points whose timestamp is older than a threshold.
+// Battery powered sensors may only report every few hours while actuators
+// are expected to be fresh, so thresholds can be set per device type.
+type StalenessPolicy struct {
+	// Default is the threshold of device types without an entry in DeviceTypes;
+	// zero disables the check for them
+	Default time.Duration
+
+	// DeviceTypes maps a device type (e.g. HmIP-SWDO) to its threshold; a zero
+	// threshold disables the check for the device type
+	DeviceTypes map[string]time.Duration
+}
+
+// StaleDataPoint is a data point not updated within the threshold of its device type
+type StaleDataPoint struct {
+	DeviceIseID string        `json:"device_ise_id"`
+	DeviceName  string        `json:"device_name"`
+	DeviceType  string        `json:"device_type,omitempty"`
+	ChannelName string        `json:"channel_name"`
+	IseID       string        `json:"ise_id"`
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	Timestamp   time.Time     `json:"timestamp"`
+	Age         time.Duration `json:"age"`
+	Threshold   time.Duration `json:"threshold"`
+}
+
+// Threshold returns the staleness threshold of a device, zero if the device is not checked
+func (p *StalenessPolicy) Threshold(device *Device) time.Duration {
+	if threshold, ok := p.DeviceTypes[device.DeviceType]; ok {
+		return threshold
+	}
+	return p.Default
+}
+
+// Check returns the stale data points of a state list snapshot, oldest first.
+// Data points without a timestamp have never been updated and are not reported.
+func (p *StalenessPolicy) Check(devices []Device, now time.Time) []StaleDataPoint {
+	var stale []StaleDataPoint
+	for i := range devices {
+		device := &devices[i]
+		threshold := p.Threshold(device)
+		if threshold <= 0 {
+			continue
+		}
+
+		for _, channel := range device.Channels {
+			for _, dp := range channel.DataPoints {
+				if dp.Timestamp <= 0 {
+					continue
+				}
+				timestamp := time.Unix(dp.Timestamp, 0)
+				age := now.Sub(timestamp)
+				if age <= threshold {
+					continue
+				}
+				stale = append(stale, StaleDataPoint{
+					DeviceIseID: device.IseID,
+					DeviceName:  device.Name,
+					DeviceType:  device.DeviceType,
+					ChannelName: channel.Name,
+					IseID:       dp.IseID,
+					Name:        dp.Name,
+					Type:        dataPointType(dp),
+					Timestamp:   timestamp,
+					Age:         age,
+					Threshold:   threshold,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool { return stale[i].Timestamp.Before(stale[j].Timestamp) })
+	return stale
+}
+
+// WritePrometheus writes the age of all stale data points in the Prometheus text exposition format
+func (p *StalenessPolicy) WritePrometheus(w io.Writer, devices []Device, now time.Time) error {
+	const name = "homematic_datapoint_stale_age_seconds"
+	if err := writeMetricHeader(w, name, "Age of data points not updated within the threshold of their device type.", "gauge"); err != nil {
+		return err
+	}
+
+	for _, dp := range p.Check(devices, now) {
+		labels := []metricLabel{
+			{"device", dp.DeviceName},
+			{"device_ise_id", dp.DeviceIseID},
+			{"channel", dp.ChannelName},
+			{"datapoint", dp.Type},
+			{"ise_id", dp.IseID},
+		}
+		if err := writeMetricSample(w, name, labels, dp.Age.Seconds()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package homematic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func staleTestDevices() []Device {
+	return []Device{
+		{IseID: "1000", Name: "Window", DeviceType: "HmIP-SWDO", Channels: []Channel{
+			{Name: "Window:1", DataPoints: []DataPoint{{Name: "HmIP-RF.0001:1.STATE", IseID: "1001", Timestamp: 1700000000 - 5*3600}}},
+		}},
+		{IseID: "2000", Name: "Switch", DeviceType: "HmIP-PS", Channels: []Channel{
+			{Name: "Switch:3", DataPoints: []DataPoint{
+				{Type: "STATE", IseID: "2001", Timestamp: 1700000000 - 2*3600},
+				{Type: "ON_TIME", IseID: "2002"},
+			}},
+		}},
+		{IseID: "3000", Name: "Dimmer", DeviceType: "HmIP-BDT", Channels: []Channel{
+			{Name: "Dimmer:4", DataPoints: []DataPoint{{Type: "LEVEL", IseID: "3001", Timestamp: 1700000000 - 3*3600}}},
+		}},
+	}
+}
+
+func TestStalenessPolicyCheck(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	policy := &StalenessPolicy{
+		Default: time.Hour,
+		DeviceTypes: map[string]time.Duration{
+			"HmIP-SWDO": 24 * time.Hour,
+			"HmIP-BDT":  0,
+		},
+	}
+
+	stale := policy.Check(staleTestDevices(), now)
+	if len(stale) != 1 {
+		t.Fatalf("expected 1 stale data point, got %+v", stale)
+	}
+	dp := stale[0]
+	if dp.IseID != "2001" || dp.Type != "STATE" || dp.DeviceName != "Switch" || dp.ChannelName != "Switch:3" {
+		t.Errorf("unexpected stale data point: %+v", dp)
+	}
+	if dp.Age != 2*time.Hour || dp.Threshold != time.Hour {
+		t.Errorf("unexpected age %s or threshold %s", dp.Age, dp.Threshold)
+	}
+
+	policy.DeviceTypes["HmIP-SWDO"] = 4 * time.Hour
+	stale = policy.Check(staleTestDevices(), now)
+	if len(stale) != 2 || stale[0].IseID != "1001" || stale[0].Type != "STATE" {
+		t.Errorf("expected oldest window contact first, got %+v", stale)
+	}
+}
+
+func TestStalenessPolicyDisabled(t *testing.T) {
+	policy := &StalenessPolicy{}
+	if stale := policy.Check(staleTestDevices(), time.Unix(1800000000, 0)); len(stale) != 0 {
+		t.Errorf("expected no stale data points without thresholds, got %+v", stale)
+	}
+}
+
+func TestStalenessPolicyWritePrometheus(t *testing.T) {
+	policy := &StalenessPolicy{Default: time.Hour}
+
+	var buf bytes.Buffer
+	if err := policy.WritePrometheus(&buf, staleTestDevices(), time.Unix(1700000000, 0)); err != nil {
+		t.Fatal(err)
+	}
+	want := `homematic_datapoint_stale_age_seconds{device="Switch",device_ise_id="2000",channel="Switch:3",datapoint="STATE",ise_id="2001"} 7200`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected metrics to contain %q, got:\n%s", want, buf.String())
+	}
+	if strings.Count(buf.String(), "homematic_datapoint_stale_age_seconds{") != 3 {
+		t.Errorf("expected 3 samples, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,177 @@
+package homematic
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StateMetrics renders state list snapshots as Prometheus metrics
+type StateMetrics struct {
+	// Labels optionally returns additional labels (e.g. room or floor) for a device
+	Labels func(device *Device) map[string]string
+}
+
+// stateMetric describes a metric family written by StateMetrics
+type stateMetric struct {
+	name    string
+	help    string
+	samples []stateSample
+}
+
+// stateSample is a single sample of a state metric
+type stateSample struct {
+	labels []metricLabel
+	value  float64
+}
+
+// Write writes device, data point, battery, reachability and duty cycle metrics for the given devices
+func (m *StateMetrics) Write(w io.Writer, devices []Device) error {
+	datapoints := &stateMetric{name: "homematic_datapoint_value", help: "Current numeric value of a data point."}
+	unreach := &stateMetric{name: "homematic_device_unreach", help: "Whether the device is unreachable (1) or not (0)."}
+	lowBat := &stateMetric{name: "homematic_device_low_battery", help: "Whether the device reports a low battery (1) or not (0)."}
+	dutyCycle := &stateMetric{name: "homematic_device_duty_cycle", help: "Whether the device reached its duty cycle limit (1) or not (0)."}
+	configPending := &stateMetric{name: "homematic_device_config_pending", help: "Whether the device has pending configuration data (1) or not (0)."}
+	rssi := &stateMetric{name: "homematic_device_rssi_dbm", help: "Received signal strength of the device in dBm."}
+
+	for i := range devices {
+		device := &devices[i]
+		deviceLabels := m.deviceLabels(device)
+
+		info := device.Maintenance
+		if info == nil {
+			info = newMaintenanceInfo(device)
+		}
+		if info != nil {
+			unreach.add(deviceLabels, boolValue(info.Unreach || device.Unreach))
+			lowBat.add(deviceLabels, boolValue(info.LowBat))
+			dutyCycle.add(deviceLabels, boolValue(info.DutyCycle))
+			configPending.add(deviceLabels, boolValue(info.ConfigPending))
+			if info.RSSIDevice != nil {
+				rssi.add(withLabels(deviceLabels, metricLabel{"direction", "device"}), float64(*info.RSSIDevice))
+			}
+			if info.RSSIPeer != nil {
+				rssi.add(withLabels(deviceLabels, metricLabel{"direction", "peer"}), float64(*info.RSSIPeer))
+			}
+		} else {
+			unreach.add(deviceLabels, boolValue(device.Unreach))
+		}
+
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				value, ok := numericValue(dp.Value)
+				if !ok {
+					continue
+				}
+				datapoints.add(withLabels(deviceLabels,
+					metricLabel{"channel", ch.Name},
+					metricLabel{"datapoint", dataPointType(dp)},
+					metricLabel{"ise_id", dp.IseID},
+				), value)
+			}
+		}
+	}
+
+	for _, metric := range []*stateMetric{datapoints, unreach, lowBat, dutyCycle, configPending, rssi} {
+		if err := metric.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deviceLabels returns the labels identifying a device, including the configured additional labels
+func (m *StateMetrics) deviceLabels(device *Device) []metricLabel {
+	labels := []metricLabel{
+		{"device", device.Name},
+		{"device_ise_id", device.IseID},
+	}
+	if device.Address != "" {
+		labels = append(labels, metricLabel{"address", device.Address})
+	}
+	if device.DeviceType != "" {
+		labels = append(labels, metricLabel{"device_type", device.DeviceType})
+	}
+
+	if m.Labels == nil {
+		return labels
+	}
+	extra := m.Labels(device)
+	names := make([]string, 0, len(extra))
+	for name := range extra {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		labels = append(labels, metricLabel{sanitizeLabelName(name), extra[name]})
+	}
+	return labels
+}
+
+// withLabels returns a copy of labels extended by extra
+func withLabels(labels []metricLabel, extra ...metricLabel) []metricLabel {
+	result := make([]metricLabel, 0, len(labels)+len(extra))
+	return append(append(result, labels...), extra...)
+}
+
+// add appends a sample to the metric
+func (s *stateMetric) add(labels []metricLabel, value float64) {
+	s.samples = append(s.samples, stateSample{labels: labels, value: value})
+}
+
+// write writes the metric family if it has samples
+func (s *stateMetric) write(w io.Writer) error {
+	if len(s.samples) == 0 {
+		return nil
+	}
+	if err := writeMetricHeader(w, s.name, s.help, "gauge"); err != nil {
+		return err
+	}
+	for _, sample := range s.samples {
+		if err := writeMetricSample(w, s.name, sample.labels, sample.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// numericValue converts a data point value to a metric value; bools map to 0/1,
+// non-numeric values are not exported
+func numericValue(value string) (float64, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true":
+		return 1, true
+	case "false":
+		return 0, true
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
+// boolValue converts a flag to a metric value
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sanitizeLabelName replaces characters that are not allowed in Prometheus label names
+func sanitizeLabelName(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,66 @@
+package homematic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStateMetricsWrite(t *testing.T) {
+	devices := []Device{
+		{
+			Name:       "Thermostat",
+			IseID:      "1234",
+			DeviceType: "HmIP-eTRV-2",
+			Channels: []Channel{
+				{
+					Name:  "Thermostat:0",
+					Index: 0,
+					DataPoints: []DataPoint{
+						{Type: "LOW_BAT", IseID: "1238", Value: "true"},
+						{Type: "RSSI_DEVICE", IseID: "1239", Value: "-65"},
+					},
+				},
+				{
+					Name:  "Thermostat:1",
+					Index: 1,
+					DataPoints: []DataPoint{
+						{Type: "ACTUAL_TEMPERATURE", IseID: "1251", Value: "21.5"},
+						{Type: "WINDOW_STATE", IseID: "1252", Value: "CLOSED"},
+					},
+				},
+			},
+		},
+	}
+
+	metrics := &StateMetrics{
+		Labels: func(device *Device) map[string]string {
+			return map[string]string{"room": "Kitchen", "floor-level": "0"}
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.Write(&buf, devices); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+
+	labels := `device="Thermostat",device_ise_id="1234",device_type="HmIP-eTRV-2",floor_level="0",room="Kitchen"`
+	for _, want := range []string{
+		`homematic_datapoint_value{` + labels + `,channel="Thermostat:1",datapoint="ACTUAL_TEMPERATURE",ise_id="1251"} 21.5`,
+		`homematic_datapoint_value{` + labels + `,channel="Thermostat:0",datapoint="LOW_BAT",ise_id="1238"} 1`,
+		`homematic_device_low_battery{` + labels + `} 1`,
+		`homematic_device_unreach{` + labels + `} 0`,
+		`homematic_device_rssi_dbm{` + labels + `,direction="device"} -65`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "WINDOW_STATE") {
+		t.Errorf("expected non-numeric data points to be skipped:\n%s", out)
+	}
+	if strings.Count(out, "# TYPE homematic_datapoint_value gauge") != 1 {
+		t.Errorf("expected a single metric family header:\n%s", out)
+	}
+}
@@ -0,0 +1,158 @@
+package homematic
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFullRefreshInterval is the interval a StateCache downloads the
+	// full state list at if FullRefreshInterval is not set
+	DefaultFullRefreshInterval = time.Hour
+
+	// DefaultStateBatchSize is the number of devices a StateCache queries
+	// per state.cgi request if BatchSize is not set
+	DefaultStateBatchSize = 50
+)
+
+// StateCache keeps the state list of a CCU and refreshes it incrementally:
+// the full state list, with names and structure, is only downloaded
+// periodically, while other refreshes query the values of the cached devices
+// in small state.cgi batches and merge the data points whose value or
+// timestamp changed. Removed devices and data points added to cached channels
+// trigger a full refresh with the next call; devices and channels added on
+// the CCU are picked up by the next periodic full refresh, or after Invalidate.
+type StateCache struct {
+	Client *Client
+
+	// FullRefreshInterval is the interval the full state list is downloaded
+	// at, DefaultFullRefreshInterval if zero
+	FullRefreshInterval time.Duration
+
+	// BatchSize is the number of devices per state.cgi request, DefaultStateBatchSize if zero
+	BatchSize int
+
+	// ShowInternal includes internal channels in full refreshes
+	ShowInternal bool
+
+	mu      sync.Mutex
+	devices []Device
+	fullAt  time.Time
+}
+
+// NewStateCache creates an empty cache for the state list of client
+func NewStateCache(client *Client) *StateCache {
+	return &StateCache{Client: client}
+}
+
+// Devices returns a copy of the cached state list
+func (s *StateCache) Devices() []Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return cloneDevices(s.devices)
+}
+
+// Invalidate makes the next refresh download the full state list
+func (s *StateCache) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fullAt = time.Time{}
+}
+
+// Refresh updates the cache and returns the changed data points; data points
+// of a full refresh that were not cached before are reported with FirstObserved
+func (s *StateCache) Refresh(now time.Time) ([]DataPointChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.refresh(now)
+	if err != nil {
+		return nil, err
+	}
+	changes := DiffStates(s.devices, current, now)
+	s.devices = current
+	return changes, nil
+}
+
+// refresh returns the refreshed state list
+func (s *StateCache) refresh(now time.Time) ([]Device, error) {
+	if s.fullAt.IsZero() || now.Sub(s.fullAt) >= durationOrDefault(s.FullRefreshInterval, DefaultFullRefreshInterval) {
+		devices, err := s.Client.GetStateList("", s.ShowInternal, false)
+		if err != nil {
+			return nil, err
+		}
+		s.fullAt = now
+		return devices, nil
+	}
+
+	dataPoints := make(map[string]DataPoint)
+	ids := make([]string, len(s.devices))
+	// state.cgi also returns internal channels, which are not cached without ShowInternal
+	channels := make(map[string]bool)
+	for i, device := range s.devices {
+		ids[i] = device.IseID
+		for _, ch := range device.Channels {
+			channels[ch.IseID] = true
+		}
+	}
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultStateBatchSize
+	}
+	for batch := range slices.Chunk(ids, batchSize) {
+		devices, err := s.Client.GetState(batch, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		found := 0
+		for _, device := range devices {
+			found++
+			for _, ch := range device.Channels {
+				if !channels[ch.IseID] {
+					continue
+				}
+				for _, dp := range ch.DataPoints {
+					dataPoints[dp.IseID] = dp
+				}
+			}
+		}
+		if found != len(batch) {
+			// a device was removed, reload the structure next time
+			s.fullAt = time.Time{}
+		}
+	}
+
+	current := cloneDevices(s.devices)
+	merged := 0
+	for i := range current {
+		for j := range current[i].Channels {
+			for k := range current[i].Channels[j].DataPoints {
+				dp := &current[i].Channels[j].DataPoints[k]
+				if fresh, ok := dataPoints[dp.IseID]; ok {
+					merged++
+					dp.Value, dp.Timestamp = fresh.Value, fresh.Timestamp
+				}
+			}
+		}
+	}
+	if merged != len(dataPoints) {
+		// data points were added to known devices, e.g. by a firmware update
+		s.fullAt = time.Time{}
+	}
+	return current, nil
+}
+
+// cloneDevices returns a deep copy of devices
+func cloneDevices(devices []Device) []Device {
+	clone := slices.Clone(devices)
+	for i := range clone {
+		clone[i].Channels = slices.Clone(clone[i].Channels)
+		for j := range clone[i].Channels {
+			clone[i].Channels[j].DataPoints = slices.Clone(clone[i].Channels[j].DataPoints)
+		}
+	}
+	return clone
+}
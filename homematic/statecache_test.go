@@ -0,0 +1,128 @@
+package homematic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestStateCacheDeltaRefresh(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[path.Base(r.URL.Path)]++
+		mu.Unlock()
+		mock.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	cache := NewStateCache(client)
+	cache.BatchSize = 3
+
+	start := time.Now()
+	changes, err := cache.Refresh(start)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	devices := cache.Devices()
+	if len(changes) == 0 || !changes[0].FirstObserved || len(devices) == 0 {
+		t.Fatalf("expected the full state list to be reported, got %d changes", len(changes))
+	}
+
+	var target DataPoint
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				if dp.Type == "STATE" && target.IseID == "" {
+					target = dp
+				}
+			}
+		}
+	}
+	value := "true"
+	if parseFlag(target.Value) {
+		value = "false"
+	}
+	if err := client.ChangeState([]string{target.IseID}, []string{value}); err != nil {
+		t.Fatalf("ChangeState failed: %v", err)
+	}
+
+	changes, err = cache.Refresh(start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].IseID != target.IseID || changes[0].NewValue != value || changes[0].DeviceName == "" {
+		t.Errorf("expected a change of %s to %s, got %+v", target.IseID, value, changes)
+	}
+	if requests["statelist.cgi"] != 1 {
+		t.Errorf("expected a single full download, got %d", requests["statelist.cgi"])
+	}
+	if want := (len(devices) + 2) / 3; requests["state.cgi"] != want {
+		t.Errorf("expected %d state.cgi batches, got %d", want, requests["state.cgi"])
+	}
+
+	if _, err := cache.Refresh(start.Add(2 * time.Hour)); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if requests["statelist.cgi"] != 2 {
+		t.Errorf("expected a full download after FullRefreshInterval, got %d", requests["statelist.cgi"])
+	}
+}
+
+func TestStateCacheInternalChannels(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	state := `<stateList><device ise_id="100" name="Switch"><channel ise_id="101" name="Switch:0">` +
+		`<datapoint ise_id="102" type="UNREACH" value="false" timestamp="1"/></channel>` +
+		`<channel ise_id="103" name="Switch:1"><datapoint ise_id="104" type="STATE" value="false" timestamp="1"/>%s</channel></device></stateList>`
+	extra := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests[path.Base(r.URL.Path)]++
+		if path.Base(r.URL.Path) == "statelist.cgi" {
+			// without show_internal, the maintenance channel is not listed
+			w.Write([]byte(`<stateList><device ise_id="100" name="Switch"><channel ise_id="103" name="Switch:1">` +
+				`<datapoint ise_id="104" type="STATE" value="false" timestamp="1"/></channel></device></stateList>`))
+			return
+		}
+		w.Write([]byte(fmt.Sprintf(state, extra)))
+	}))
+	defer server.Close()
+
+	cache := NewStateCache(NewClient(server.URL, "token"))
+	start := time.Now()
+	for i := range 3 {
+		if _, err := cache.Refresh(start.Add(time.Duration(i) * time.Minute)); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+	}
+	if requests["statelist.cgi"] != 1 || requests["state.cgi"] != 2 {
+		t.Errorf("expected internal channels not to force full downloads, got %v", requests)
+	}
+
+	// a data point added to a cached channel reloads the structure
+	mu.Lock()
+	extra = `<datapoint ise_id="105" type="ON_TIME" value="0" timestamp="1"/>`
+	mu.Unlock()
+	for i := 3; i < 5; i++ {
+		if _, err := cache.Refresh(start.Add(time.Duration(i) * time.Minute)); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+	}
+	if requests["statelist.cgi"] != 2 {
+		t.Errorf("expected a full download after a data point was added, got %v", requests)
+	}
+}
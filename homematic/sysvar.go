@@ -0,0 +1,189 @@
+package homematic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SystemVariableKind classifies a system variable by its ReGa value type and subtype
+type SystemVariableKind string
+
+const (
+	SysVarBool   SystemVariableKind = "bool"
+	SysVarAlarm  SystemVariableKind = "alarm"
+	SysVarNumber SystemVariableKind = "number"
+	SysVarEnum   SystemVariableKind = "enum"
+	SysVarString SystemVariableKind = "string"
+)
+
+// ReGa value types and subtypes as reported by sysvarlist.cgi
+const (
+	regaValueTypeBinary        = 2
+	regaValueTypeFloat         = 4
+	regaValueTypeSignedInteger = 8
+	regaValueTypeInteger       = 16
+	regaValueTypeString        = 20
+
+	regaSubtypeAlarm = "6"
+	regaSubtypeEnum  = "29"
+)
+
+// valueType returns the ReGa value type; sysvarlist.cgi reports it in the
+// type attribute rather than in valuetype
+func (v *SystemVariable) valueType() int {
+	if v.ValueType != 0 {
+		return v.ValueType
+	}
+	valueType, _ := strconv.Atoi(v.Type)
+	return valueType
+}
+
+// Kind returns the kind of the system variable
+func (v *SystemVariable) Kind() SystemVariableKind {
+	switch v.valueType() {
+	case regaValueTypeBinary:
+		if v.Subtype == regaSubtypeAlarm {
+			return SysVarAlarm
+		}
+		return SysVarBool
+	case regaValueTypeInteger:
+		if v.Subtype == regaSubtypeEnum || v.ValueList != "" {
+			return SysVarEnum
+		}
+		return SysVarNumber
+	case regaValueTypeFloat:
+		return SysVarNumber
+	default:
+		return SysVarString
+	}
+}
+
+// EnumValues returns the labels of an enum system variable, indexed by value
+func (v *SystemVariable) EnumValues() []string {
+	if v.ValueList == "" {
+		return nil
+	}
+	return strings.Split(v.ValueList, ";")
+}
+
+// DisplayValue returns the value in human readable form: the label for bool
+// and enum variables, the value with unit for numbers
+func (v *SystemVariable) DisplayValue() string {
+	switch v.Kind() {
+	case SysVarBool, SysVarAlarm:
+		if parseFlag(v.Value) && v.ValueName1 != "" {
+			return v.ValueName1
+		}
+		if !parseFlag(v.Value) && v.ValueName0 != "" {
+			return v.ValueName0
+		}
+	case SysVarEnum:
+		if i, err := strconv.Atoi(v.Value); err == nil {
+			if values := v.EnumValues(); i >= 0 && i < len(values) {
+				return values[i]
+			}
+		}
+	case SysVarNumber:
+		if v.Unit != "" {
+			return v.Value + " " + v.Unit
+		}
+	}
+	return v.Value
+}
+
+// ParseValue converts user input into the value representation expected by
+// statechange.cgi, according to the kind of the variable. Bool variables
+// accept true/false, 1/0, on/off, yes/no and their value names, enum
+// variables accept labels or indexes, numbers are checked against min/max.
+func (v *SystemVariable) ParseValue(input string) (string, error) {
+	trimmed := strings.TrimSpace(input)
+
+	switch v.Kind() {
+	case SysVarBool, SysVarAlarm:
+		switch strings.ToLower(trimmed) {
+		case "true", "1", "on", "yes":
+			return "true", nil
+		case "false", "0", "off", "no":
+			return "false", nil
+		}
+		if v.ValueName1 != "" && strings.EqualFold(trimmed, v.ValueName1) {
+			return "true", nil
+		}
+		if v.ValueName0 != "" && strings.EqualFold(trimmed, v.ValueName0) {
+			return "false", nil
+		}
+		return "", fmt.Errorf("invalid value %q for bool system variable %s", input, v.Name)
+
+	case SysVarEnum:
+		values := v.EnumValues()
+		for i, label := range values {
+			if strings.EqualFold(trimmed, label) {
+				return strconv.Itoa(i), nil
+			}
+		}
+		if i, err := strconv.Atoi(trimmed); err == nil && i >= 0 && i < len(values) {
+			return strconv.Itoa(i), nil
+		}
+		return "", fmt.Errorf("invalid value %q for enum system variable %s, expected one of %s",
+			input, v.Name, strings.Join(values, ", "))
+
+	case SysVarNumber:
+		f, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid value %q for number system variable %s", input, v.Name)
+		}
+		if limit, err := strconv.ParseFloat(v.Min, 64); err == nil && f < limit {
+			return "", fmt.Errorf("value %s of system variable %s is below minimum %s", trimmed, v.Name, v.Min)
+		}
+		if limit, err := strconv.ParseFloat(v.Max, 64); err == nil && f > limit {
+			return "", fmt.Errorf("value %s of system variable %s is above maximum %s", trimmed, v.Name, v.Max)
+		}
+		if v.valueType() == regaValueTypeInteger && f != float64(int64(f)) {
+			return "", fmt.Errorf("invalid value %q for integer system variable %s", input, v.Name)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+
+	default:
+		return input, nil
+	}
+}
+
+// FindSystemVariable resolves a system variable by ise_id or by (case-insensitive)
+// name; a name matching several variables is an error
+func FindSystemVariable(sysVars []SystemVariable, nameOrID string) (*SystemVariable, error) {
+	for i := range sysVars {
+		if sysVars[i].IseID == nameOrID {
+			return &sysVars[i], nil
+		}
+	}
+
+	var found []int
+	for i := range sysVars {
+		if strings.EqualFold(sysVars[i].Name, nameOrID) {
+			found = append(found, i)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("system variable not found: %s", nameOrID)
+	case 1:
+		return &sysVars[found[0]], nil
+	}
+
+	ids := make([]string, len(found))
+	for j, i := range found {
+		ids[j] = sysVars[i].IseID
+	}
+	return nil, fmt.Errorf("system variable name %q is ambiguous (ids %s)", nameOrID, strings.Join(ids, ", "))
+}
+
+// SetSystemVariable validates and writes a new value of a system variable.
+// String values must not contain commas, see ChangeState.
+func (c *Client) SetSystemVariable(sysVar *SystemVariable, input string) error {
+	value, err := sysVar.ParseValue(input)
+	if err != nil {
+		return err
+	}
+	return c.ChangeState([]string{sysVar.IseID}, []string{value})
+}
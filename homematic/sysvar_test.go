@@ -0,0 +1,175 @@
+package homematic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestSystemVariableParseValue(t *testing.T) {
+	presence := SystemVariable{Name: "Presence", ValueType: 2, Subtype: "2", ValueName0: "away", ValueName1: "present"}
+	mode := SystemVariable{Name: "Mode", ValueType: 16, Subtype: "29", ValueList: "Off;Comfort;Eco"}
+	level := SystemVariable{Name: "Level", ValueType: 4, Subtype: "0", Min: "0", Max: "100"}
+	text := SystemVariable{Name: "Text", ValueType: 20, Subtype: "11"}
+
+	tests := []struct {
+		sysVar  SystemVariable
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{presence, "on", "true", false},
+		{presence, "Present", "true", false},
+		{presence, "away", "false", false},
+		{presence, "maybe", "", true},
+		{mode, "eco", "2", false},
+		{mode, "1", "1", false},
+		{mode, "3", "", true},
+		{level, "42.5", "42.5", false},
+		{level, "101", "", true},
+		{level, "abc", "", true},
+		{text, "Hello, World & more", "Hello, World & more", false},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.sysVar.ParseValue(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s.ParseValue(%q): expected error, got %q", tt.sysVar.Name, tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s.ParseValue(%q): unexpected error: %v", tt.sysVar.Name, tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s.ParseValue(%q) = %q, want %q", tt.sysVar.Name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSystemVariableDisplayValue(t *testing.T) {
+	tests := []struct {
+		sysVar SystemVariable
+		want   string
+	}{
+		{SystemVariable{ValueType: 2, Subtype: "2", Value: "true", ValueName0: "away", ValueName1: "present"}, "present"},
+		{SystemVariable{ValueType: 16, Subtype: "29", Value: "1", ValueList: "Off;Comfort;Eco"}, "Comfort"},
+		{SystemVariable{ValueType: 4, Value: "21.5", Unit: "°C"}, "21.5 °C"},
+		{SystemVariable{ValueType: 20, Value: "hello"}, "hello"},
+		{SystemVariable{Type: "16", Subtype: "29", Value: "2", ValueList: "Off;Comfort;Eco"}, "Eco"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sysVar.DisplayValue(); got != tt.want {
+			t.Errorf("DisplayValue() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestFindSystemVariable(t *testing.T) {
+	sysVars := []SystemVariable{
+		{IseID: "950", Name: "Presence"},
+		{IseID: "951", Name: "Mode"},
+		{IseID: "952", Name: "mode"},
+		{IseID: "953", Name: "950"},
+	}
+
+	tests := []struct {
+		nameOrID string
+		wantID   string
+		wantErr  string
+	}{
+		{"951", "951", ""},
+		{"presence", "950", ""},
+		{"950", "950", ""}, // ids take precedence over names
+		{"MODE", "", "ambiguous (ids 951, 952)"},
+		{"Night", "", "not found"},
+	}
+
+	for _, tt := range tests {
+		sysVar, err := FindSystemVariable(sysVars, tt.nameOrID)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FindSystemVariable(%q): expected error %q, got %+v, %v", tt.nameOrID, tt.wantErr, sysVar, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FindSystemVariable(%q): unexpected error: %v", tt.nameOrID, err)
+			continue
+		}
+		if sysVar.IseID != tt.wantID {
+			t.Errorf("FindSystemVariable(%q) = %s, want %s", tt.nameOrID, sysVar.IseID, tt.wantID)
+		}
+	}
+}
+
+func TestSetSystemVariable(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("ise_id") + "=" + r.URL.Query().Get("new_value")
+		w.Write([]byte(`<result><changed id="950" new_value="2"/></result>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	mode := &SystemVariable{Name: "Mode", IseID: "950", ValueType: 16, Subtype: "29", ValueList: "Off;Comfort;Eco"}
+	if err := client.SetSystemVariable(mode, "Eco"); err != nil {
+		t.Fatalf("SetSystemVariable failed: %v", err)
+	}
+	if query != "950=2" {
+		t.Errorf("unexpected statechange query: %s", query)
+	}
+
+	if err := client.SetSystemVariable(mode, "Turbo"); err == nil {
+		t.Error("expected error for invalid enum label")
+	}
+}
+
+func TestSetSystemVariableRoundTrip(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	message, err := client.GetSystemVariable("2501", true)
+	if err != nil {
+		t.Fatalf("GetSystemVariable failed: %v", err)
+	}
+	for _, input := range []string{"Hallo Welt", "Tür & Tor", "1+1=2", "100%"} {
+		if err := client.SetSystemVariable(message, input); err != nil {
+			t.Fatalf("SetSystemVariable(%q) failed: %v", input, err)
+		}
+		sysVar, err := client.GetSystemVariable("2501", true)
+		if err != nil {
+			t.Fatalf("GetSystemVariable failed: %v", err)
+		}
+		if sysVar.Value != input {
+			t.Errorf("SetSystemVariable(%q): read back %q", input, sysVar.Value)
+		}
+	}
+
+	// statechange.cgi would split the value, so it is rejected before sending
+	for _, input := range []string{"a,b", "Grüße €"} {
+		if err := client.SetSystemVariable(message, input); err == nil {
+			t.Errorf("SetSystemVariable(%q): expected error", input)
+		}
+	}
+	if value, _ := mock.Value("2501"); value != "100%" {
+		t.Errorf("expected the rejected values not to be written, got %q", value)
+	}
+	if _, err := client.ChangeStates([]string{"2501"}, []string{"a,b"}); err == nil || !strings.Contains(err.Error(), "comma") {
+		t.Errorf("expected comma error for a single value, got %v", err)
+	}
+	if err := client.ChangeState([]string{"2501", "2499"}, []string{"a,b", "1"}); err == nil {
+		t.Error("expected error for comma in a multi value change")
+	}
+}
@@ -0,0 +1,198 @@
+package homematic
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sysVarImportBatchSize is the number of system variables written per statechange.cgi request
+const sysVarImportBatchSize = 20
+
+// SystemVariableImport is the result of importing the value of a system variable
+type SystemVariableImport struct {
+	// Name is the name or ise_id given in the file until it is resolved, the
+	// name of the system variable afterwards
+	Name  string `json:"name"`
+	IseID string `json:"ise_id,omitempty"`
+	// Input is the value given in the file, Value the converted value that was written
+	Input string `json:"input"`
+	Value string `json:"value,omitempty"`
+	Err   error  `json:"-"`
+}
+
+// sysVarValue is a name/value pair read from an import file
+type sysVarValue struct {
+	name, value string
+}
+
+// ImportSystemVariables writes the system variable values of a file, e.g. to
+// restore automation flags after a CCU reset. The file holds a name (or
+// ise_id) to value object in JSON or YAML, a list of objects with name and
+// value like the output of ExportSystemVariables, or CSV with name and value
+// columns. Values are converted according to the kind of each variable, see
+// SystemVariable.ParseValue, and written in batches. The error is only set if
+// the file or the system variable list could not be read; failures of single
+// variables are reported in their results.
+func (c *Client) ImportSystemVariables(r io.Reader, format ExportFormat) ([]SystemVariableImport, error) {
+	values, err := readSystemVariableValues(r, format)
+	if err != nil {
+		return nil, err
+	}
+	sysVars, err := c.GetSystemVariableList(true)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SystemVariableImport, len(values))
+	var pending []int
+	for i, v := range values {
+		results[i] = SystemVariableImport{Name: v.name, Input: v.value}
+		sysVar, err := FindSystemVariable(sysVars, v.name)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Name, results[i].IseID = sysVar.Name, sysVar.IseID
+		if results[i].Value, err = sysVar.ParseValue(v.value); err != nil {
+			results[i].Err = err
+			continue
+		}
+		// a comma would fail the whole batch
+		if err := checkStateValue(results[i].Value); err != nil {
+			results[i].Err = err
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	var batches [][]int
+	for _, i := range pending {
+		last := len(batches) - 1
+		if last < 0 || len(batches[last]) >= sysVarImportBatchSize {
+			batches = append(batches, []int{i})
+			continue
+		}
+		batches[last] = append(batches[last], i)
+	}
+
+	for _, batch := range batches {
+		ids := make([]string, len(batch))
+		newValues := make([]string, len(batch))
+		for j, i := range batch {
+			ids[j], newValues[j] = results[i].IseID, results[i].Value
+		}
+		changes, err := c.ChangeStates(ids, newValues)
+		for j, i := range batch {
+			switch {
+			case err != nil:
+				results[i].Err = err
+			case j < len(changes):
+				results[i].Err = changes[j].Err
+			}
+		}
+	}
+	return results, nil
+}
+
+// readSystemVariableValues reads the name/value pairs of an import file
+func readSystemVariableValues(r io.Reader, format ExportFormat) ([]sysVarValue, error) {
+	var data any
+	switch format {
+	case ExportJSON:
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber()
+		if err := decoder.Decode(&data); err != nil {
+			return nil, fmt.Errorf("failed to parse system variables: %w", err)
+		}
+	case ExportYAML:
+		if err := yaml.NewDecoder(r).Decode(&data); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse system variables: %w", err)
+		}
+	case ExportCSV:
+		return readSystemVariableCSV(r)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	var values []sysVarValue
+	switch data := data.(type) {
+	case nil:
+	case map[string]any:
+		for name, value := range data {
+			values = append(values, sysVarValue{name, importValue(value)})
+		}
+		// objects have no order, so the values are written in the order of their names
+		slices.SortFunc(values, func(a, b sysVarValue) int { return strings.Compare(a.name, b.name) })
+	case []any:
+		for i, item := range data {
+			object, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("system variable %d is not an object", i+1)
+			}
+			name := importValue(object["name"])
+			if name == "" {
+				name = importValue(object["ise_id"])
+			}
+			values = append(values, sysVarValue{name, importValue(object["value"])})
+		}
+	default:
+		return nil, errors.New("system variables must be an object or a list")
+	}
+
+	for i, v := range values {
+		if strings.TrimSpace(v.name) == "" {
+			return nil, fmt.Errorf("system variable %d has no name", i+1)
+		}
+	}
+	return values, nil
+}
+
+// readSystemVariableCSV reads name and value columns, given by a header or as the first two columns
+func readSystemVariableCSV(r io.Reader) ([]sysVarValue, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse system variables: %w", err)
+	}
+
+	nameColumn, valueColumn := 0, 1
+	if len(records) > 0 {
+		if n, v := slices.Index(records[0], "name"), slices.Index(records[0], "value"); n >= 0 && v >= 0 {
+			nameColumn, valueColumn = n, v
+			records = records[1:]
+		}
+	}
+
+	values := make([]sysVarValue, 0, len(records))
+	for i, record := range records {
+		if len(record) <= max(nameColumn, valueColumn) || strings.TrimSpace(record[nameColumn]) == "" {
+			return nil, fmt.Errorf("invalid system variable record %d", i+1)
+		}
+		values = append(values, sysVarValue{record[nameColumn], record[valueColumn]})
+	}
+	return values, nil
+}
+
+// importValue formats a decoded JSON or YAML value
+func importValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
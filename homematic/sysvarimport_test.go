@@ -0,0 +1,146 @@
+package homematic
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+// sysVarChangeHandler records the state changes sent to the mock CCU
+func sysVarChangeHandler(mock http.Handler, requests *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "statechange.cgi") {
+			*requests = append(*requests, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+		}
+		mock.ServeHTTP(w, r)
+	})
+}
+
+func TestImportSystemVariables(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	var requests []string
+	server := httptest.NewServer(sysVarChangeHandler(mock, &requests))
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	input := `name,value
+Anwesenheit,nicht anwesend
+heizungsmodus,Öko
+2499,42.5
+Letzte Meldung,"Fenster offen, bitte schließen"
+DutyCycle,150
+Unbekannt,1
+`
+	results, err := client.ImportSystemVariables(strings.NewReader(input), ExportCSV)
+	if err != nil {
+		t.Fatalf("ImportSystemVariables failed: %v", err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("expected 6 results, got %+v", results)
+	}
+	for i, want := range []struct{ name, value string }{
+		{"Anwesenheit", "false"},
+		{"Heizungsmodus", "2"},
+		{"DutyCycle", "42.5"},
+	} {
+		if results[i].Err != nil || results[i].Name != want.name || results[i].Value != want.value {
+			t.Errorf("result %d: expected %s=%s, got %+v", i, want.name, want.value, results[i])
+		}
+		if value, _ := mock.Value(results[i].IseID); value != want.value {
+			t.Errorf("result %d: expected the CCU to hold %s, got %s", i, want.value, value)
+		}
+	}
+	if results[3].Err == nil || !strings.Contains(results[3].Err.Error(), "comma") {
+		t.Errorf("expected a comma error, got %+v", results[3])
+	}
+	if results[4].Err == nil || !strings.Contains(results[4].Err.Error(), "above maximum") {
+		t.Errorf("expected a range error, got %+v", results[4])
+	}
+	if results[5].Err == nil || results[5].IseID != "" {
+		t.Errorf("expected an unknown variable error, got %+v", results[5])
+	}
+	// the value with a comma is not sent
+	if len(requests) != 1 || requests[0] != "2497,2500,2499=false,2,42.5" {
+		t.Errorf("unexpected requests %v", requests)
+	}
+}
+
+func TestReadSystemVariableValues(t *testing.T) {
+	for name, tc := range map[string]struct {
+		format ExportFormat
+		input  string
+	}{
+		"json object": {ExportJSON, `{"DutyCycle": 3, "Anwesenheit": true}`},
+		"json list":   {ExportJSON, `[{"name": "DutyCycle", "ise_id": "2499", "value": "3"}, {"ise_id": "2497", "value": "true"}]`},
+		"yaml object": {ExportYAML, "DutyCycle: 3\nAnwesenheit: true\n"},
+		"csv":         {ExportCSV, "# flags\nDutyCycle,3\nAnwesenheit,true\n"},
+	} {
+		values, err := readSystemVariableValues(strings.NewReader(tc.input), tc.format)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		var got []string
+		for _, v := range values {
+			got = append(got, v.name+"="+v.value)
+		}
+		want := "DutyCycle=3 Anwesenheit=true"
+		if strings.HasSuffix(name, "object") {
+			want = "Anwesenheit=true DutyCycle=3"
+		}
+		if name == "json list" {
+			want = "DutyCycle=3 2497=true"
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("%s: expected %s, got %v", name, want, got)
+		}
+	}
+
+	for name, input := range map[string]string{
+		"scalar":       `"true"`,
+		"missing name": `[{"value": "1"}]`,
+	} {
+		if _, err := readSystemVariableValues(strings.NewReader(input), ExportJSON); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestImportExportedSystemVariables(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	var requests []string
+	server := httptest.NewServer(sysVarChangeHandler(mock, &requests))
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	sysVars, err := client.GetSystemVariableList(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := ExportSystemVariables(&buf, ExportJSON, sysVars); err != nil {
+		t.Fatal(err)
+	}
+	results, err := client.ImportSystemVariables(&buf, ExportJSON)
+	if err != nil {
+		t.Fatalf("ImportSystemVariables failed: %v", err)
+	}
+	var errs []error
+	for _, r := range results {
+		errs = append(errs, r.Err)
+	}
+	if len(results) != len(sysVars) || errors.Join(errs...) != nil {
+		t.Errorf("expected the export to be imported, got %+v: %v", results, errors.Join(errs...))
+	}
+}
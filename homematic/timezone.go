@@ -0,0 +1,71 @@
+package homematic
+
+import "time"
+
+// CCUTime converts a timestamp reported by the XML-API into a time. The CCU
+// encodes its local wall clock time as if it were UTC, so the Unix seconds
+// are off by the UTC offset of the CCU, which changes at DST transitions.
+// Wall clock times that occur twice when the clocks are turned back resolve
+// to the earlier instant; times skipped when the clocks are turned forward
+// are interpreted with the offset before the transition. A nil loc returns
+// the timestamp unchanged.
+func CCUTime(timestamp int64, loc *time.Location) time.Time {
+	if loc == nil || timestamp == 0 {
+		return time.Unix(timestamp, 0)
+	}
+
+	// the offset in effect half a day before and after covers both sides of a transition
+	_, before := time.Unix(timestamp-12*3600, 0).In(loc).Zone()
+	_, after := time.Unix(timestamp+12*3600, 0).In(loc).Zone()
+
+	var result int64
+	found := false
+	for _, offset := range []int{before, after} {
+		candidate := timestamp - int64(offset)
+		if _, actual := time.Unix(candidate, 0).In(loc).Zone(); actual != offset {
+			continue
+		}
+		if !found || candidate < result {
+			result, found = candidate, true
+		}
+	}
+	if !found {
+		result = timestamp - int64(before)
+	}
+	return time.Unix(result, 0)
+}
+
+// convertTimestamps converts the data point timestamps of devices from CCU wall clock time to Unix time
+func (c *Client) convertTimestamps(devices []Device) {
+	if c.Location == nil {
+		return
+	}
+	for i := range devices {
+		for j := range devices[i].Channels {
+			dps := devices[i].Channels[j].DataPoints
+			for k := range dps {
+				dps[k].Timestamp = CCUTime(dps[k].Timestamp, c.Location).Unix()
+			}
+		}
+	}
+}
+
+// convertProgramTimestamps converts program timestamps from CCU wall clock time to Unix time
+func (c *Client) convertProgramTimestamps(programs []Program) {
+	if c.Location == nil {
+		return
+	}
+	for i := range programs {
+		programs[i].Timestamp = CCUTime(programs[i].Timestamp, c.Location).Unix()
+	}
+}
+
+// convertSystemVariableTimestamps converts system variable timestamps from CCU wall clock time to Unix time
+func (c *Client) convertSystemVariableTimestamps(sysvars []SystemVariable) {
+	if c.Location == nil {
+		return
+	}
+	for i := range sysvars {
+		sysvars[i].Timestamp = CCUTime(sysvars[i].Timestamp, c.Location).Unix()
+	}
+}
@@ -0,0 +1,93 @@
+package homematic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// wallClock encodes a local wall clock time the way the CCU reports it
+func wallClock(year int, month time.Month, day, hour, minute int) int64 {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC).Unix()
+}
+
+func TestCCUTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		wall int64
+		want time.Time
+	}{
+		{"winter", wallClock(2024, 1, 15, 12, 0), time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"summer", wallClock(2024, 7, 15, 12, 0), time.Date(2024, 7, 15, 10, 0, 0, 0, time.UTC)},
+		{"before spring forward", wallClock(2024, 3, 31, 1, 59), time.Date(2024, 3, 31, 0, 59, 0, 0, time.UTC)},
+		{"skipped by spring forward", wallClock(2024, 3, 31, 2, 30), time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC)},
+		{"after spring forward", wallClock(2024, 3, 31, 3, 0), time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC)},
+		{"before fall back", wallClock(2024, 10, 27, 1, 59), time.Date(2024, 10, 26, 23, 59, 0, 0, time.UTC)},
+		{"repeated by fall back", wallClock(2024, 10, 27, 2, 30), time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC)},
+		{"after fall back", wallClock(2024, 10, 27, 3, 0), time.Date(2024, 10, 27, 2, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := CCUTime(tt.wall, berlin); !got.Equal(tt.want) {
+			t.Errorf("%s: CCUTime = %s, want %s", tt.name, got.UTC(), tt.want)
+		}
+	}
+
+	if got := CCUTime(1700000000, nil); got.Unix() != 1700000000 {
+		t.Errorf("expected timestamp to be unchanged without location, got %d", got.Unix())
+	}
+	if got := CCUTime(0, berlin); got.Unix() != 0 {
+		t.Errorf("expected zero timestamp to stay zero, got %d", got.Unix())
+	}
+}
+
+func TestClientLocation(t *testing.T) {
+	wall := wallClock(2024, 7, 15, 12, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/addons/xmlapi/statelist.cgi":
+			w.Write([]byte(`<stateList><device name="Switch" ise_id="1000"><channel name="Switch:1" ise_id="1001">` +
+				`<datapoint name="STATE" ise_id="1002" value="true" valuetype="2" timestamp="1721044800"/></channel></device></stateList>`))
+		case "/addons/xmlapi/sysvarlist.cgi":
+			w.Write([]byte(`<systemVariables><systemVariable name="Presence" ise_id="950" value="true" timestamp="1721044800"/></systemVariables>`))
+		case "/addons/xmlapi/programlist.cgi":
+			w.Write([]byte(`<programList><program id="1001" name="Morning" timestamp="1721044800"/></programList>`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	client.Location, _ = time.LoadLocation("Europe/Berlin")
+	want := wall - 2*3600
+
+	devices, err := client.GetStateList("", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := devices[0].Channels[0].DataPoints[0].Timestamp; got != want {
+		t.Errorf("data point timestamp = %d, want %d", got, want)
+	}
+
+	sysvars, err := client.GetSystemVariableList(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sysvars[0].Timestamp != want {
+		t.Errorf("system variable timestamp = %d, want %d", sysvars[0].Timestamp, want)
+	}
+
+	programs, err := client.GetProgramList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if programs[0].Timestamp != want {
+		t.Errorf("program timestamp = %d, want %d", programs[0].Timestamp, want)
+	}
+}
@@ -0,0 +1,205 @@
+package homematic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultTokenRotationInterval is the age at which a TokenRotator replaces
+// the active token if Interval is not set
+const DefaultTokenRotationInterval = 30 * 24 * time.Hour
+
+// TokenRotationState is the persisted state of a TokenRotator
+type TokenRotationState struct {
+	Active      string    `json:"active"`
+	ActivatedAt time.Time `json:"activated_at"`
+	// Retired lists replaced tokens that are not revoked yet
+	Retired []RetiredToken `json:"retired,omitempty"`
+}
+
+// RetiredToken is a replaced token awaiting revocation
+type RetiredToken struct {
+	Token     string    `json:"token"`
+	RetiredAt time.Time `json:"retired_at"`
+}
+
+// TokenRotator replaces the token of a client on a schedule: it registers a
+// new token, switches the client to it and revokes the old one once the
+// grace period passed, limiting how long a leaked token stays usable. The
+// state is persisted after every step, so that no registered token is lost
+// when the process stops in between.
+//
+// Check replaces Client.Token; it must not run concurrently with requests of
+// the client. Clients using a SecretsProvider ignore Token, so rotation is
+// refused for them; rotate the secret in the provider instead.
+type TokenRotator struct {
+	Client *Client
+
+	// Path is the file the state is persisted to, encrypted if Key is set
+	Path string
+	Key  *EncryptionKey
+
+	// Interval is the age at which the active token is replaced,
+	// DefaultTokenRotationInterval if zero
+	Interval time.Duration
+
+	// Grace is the time replaced tokens stay valid, e.g. for other processes
+	// to pick up the new token from OnRotate; they are revoked with the first
+	// check after it passed
+	Grace time.Duration
+
+	// Description is registered with new tokens, followed by the date
+	Description string
+
+	// OnRotate is called with the new token after the client switched to it
+	OnRotate func(token string)
+
+	mu    sync.Mutex
+	state *TokenRotationState
+}
+
+// NewTokenRotator creates a rotator for the token of client, persisting its state at path
+func NewTokenRotator(client *Client, path string, key *EncryptionKey) *TokenRotator {
+	return &TokenRotator{Client: client, Path: path, Key: key, Description: "homematic-xml-client-go"}
+}
+
+// State returns a copy of the rotation state, loading it if necessary
+func (r *TokenRotator) State() (TokenRotationState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.load(time.Now()); err != nil {
+		return TokenRotationState{}, err
+	}
+	state := *r.state
+	state.Retired = append([]RetiredToken(nil), state.Retired...)
+	return state, nil
+}
+
+// Check rotates the token if it is due and revokes retired tokens whose
+// grace period passed. The client is switched to the persisted active token
+// with the first check.
+func (r *TokenRotator) Check(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.load(now); err != nil {
+		return err
+	}
+	if now.Sub(r.state.ActivatedAt) >= durationOrDefault(r.Interval, DefaultTokenRotationInterval) {
+		if err := r.rotate(now); err != nil {
+			return err
+		}
+	}
+	return r.revokeRetired(now)
+}
+
+// Rotate replaces the active token regardless of its age
+func (r *TokenRotator) Rotate(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.load(now); err != nil {
+		return err
+	}
+	if err := r.rotate(now); err != nil {
+		return err
+	}
+	return r.revokeRetired(now)
+}
+
+func (r *TokenRotator) rotate(now time.Time) error {
+	token, err := r.Client.RegisterNewToken(fmt.Sprintf("%s %s", r.Description, now.Format(time.DateOnly)))
+	if err != nil {
+		return fmt.Errorf("failed to register token: %w", err)
+	}
+
+	previous := *r.state
+	if r.state.Active != "" {
+		r.state.Retired = append(r.state.Retired, RetiredToken{Token: r.state.Active, RetiredAt: now})
+	}
+	r.state.Active, r.state.ActivatedAt = token, now
+	if err := r.save(); err != nil {
+		// keep using the old token; the new one is unused and can be revoked by hand
+		*r.state = previous
+		return err
+	}
+
+	r.Client.Token = token
+	if r.OnRotate != nil {
+		r.OnRotate(token)
+	}
+	return nil
+}
+
+// revokeRetired revokes the retired tokens whose grace period passed
+func (r *TokenRotator) revokeRetired(now time.Time) error {
+	var remaining []RetiredToken
+	var errs []error
+	for _, retired := range r.state.Retired {
+		if now.Sub(retired.RetiredAt) < r.Grace {
+			remaining = append(remaining, retired)
+			continue
+		}
+		// a token revokes itself, see RevokeToken
+		if err := r.Client.RevokeToken(retired.Token); err != nil && !errors.Is(err, ErrNotAuthenticated) {
+			remaining = append(remaining, retired)
+			errs = append(errs, fmt.Errorf("failed to revoke token: %w", err))
+		}
+	}
+	if len(remaining) == len(r.state.Retired) {
+		return errors.Join(errs...)
+	}
+	r.state.Retired = remaining
+	return errors.Join(append(errs, r.save())...)
+}
+
+// load reads the persisted state once; without a state file the current
+// token of the client becomes the active one
+func (r *TokenRotator) load(now time.Time) error {
+	if r.Client.Secrets != nil {
+		return errors.New("token rotation is not supported for clients using a secrets provider")
+	}
+	if r.state != nil {
+		return nil
+	}
+
+	state := &TokenRotationState{Active: r.Client.Token, ActivatedAt: now}
+	data, err := ReadEncryptedFile(r.Path, r.Key)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := r.write(state); err != nil {
+			return err
+		}
+	case err != nil:
+		return fmt.Errorf("failed to read token rotation state: %w", err)
+	default:
+		if err := json.Unmarshal(data, state); err != nil {
+			return fmt.Errorf("failed to parse token rotation state: %w", err)
+		}
+	}
+
+	r.state = state
+	r.Client.Token = state.Active
+	return nil
+}
+
+func (r *TokenRotator) save() error {
+	return r.write(r.state)
+}
+
+// write atomically writes state to Path
+func (r *TokenRotator) write(state *TokenRotationState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode token rotation state: %w", err)
+	}
+	if err := WriteEncryptedFile(r.Path, data, r.Key); err != nil {
+		return fmt.Errorf("failed to write token rotation state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package homematic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// tokenCCU registers numbered tokens and records revocations
+type tokenCCU struct {
+	valid   []string
+	revoked []string
+}
+
+func (s *tokenCCU) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sid := r.URL.Query().Get("sid")
+	if !slices.Contains(s.valid, sid) {
+		w.Write([]byte(`<not_authenticated/>`))
+		return
+	}
+	switch r.URL.Path {
+	case "/addons/xmlapi/tokenregister.cgi":
+		token := fmt.Sprintf("t%d", len(s.valid))
+		s.valid = append(s.valid, token)
+		fmt.Fprintf(w, `<tokenRegister><token desc="%s">%s</token></tokenRegister>`, r.URL.Query().Get("desc"), token)
+	case "/addons/xmlapi/tokenrevoke.cgi":
+		s.valid = slices.DeleteFunc(s.valid, func(t string) bool { return t == sid })
+		s.revoked = append(s.revoked, sid)
+		w.Write([]byte(`<result/>`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestTokenRotator(t *testing.T) {
+	ccu := &tokenCCU{valid: []string{"t0"}}
+	server := httptest.NewServer(ccu)
+	defer server.Close()
+
+	key, err := NewEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "token.json")
+	client := NewClient(server.URL, "t0")
+	rotator := NewTokenRotator(client, path, key)
+	rotator.Interval = 24 * time.Hour
+	rotator.Grace = time.Hour
+	var rotated []string
+	rotator.OnRotate = func(token string) { rotated = append(rotated, token) }
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := rotator.Check(start); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(rotated) != 0 {
+		t.Errorf("expected no rotation of a fresh token, got %v", rotated)
+	}
+
+	if err := rotator.Check(start.Add(25 * time.Hour)); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if client.Token != "t1" || !slices.Equal(rotated, []string{"t1"}) {
+		t.Fatalf("expected rotation to t1, got token %q and %v", client.Token, rotated)
+	}
+	if len(ccu.revoked) != 0 {
+		t.Errorf("expected the old token to stay valid during the grace period, revoked %v", ccu.revoked)
+	}
+
+	if err := rotator.Check(start.Add(27 * time.Hour)); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if !slices.Equal(ccu.revoked, []string{"t0"}) {
+		t.Errorf("expected t0 to be revoked, got %v", ccu.revoked)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(data) {
+		t.Error("expected the state file to be encrypted")
+	}
+
+	// a new process continues with the persisted token
+	restarted := NewClient(server.URL, "t0")
+	state, err := NewTokenRotator(restarted, path, key).State()
+	if err != nil {
+		t.Fatalf("State failed: %v", err)
+	}
+	if state.Active != "t1" || len(state.Retired) != 0 || restarted.Token != "t1" {
+		t.Errorf("unexpected state after restart: %+v, token %q", state, restarted.Token)
+	}
+}
+
+func TestTokenRotatorKeepsStateWhenRegistrationFails(t *testing.T) {
+	ccu := &tokenCCU{valid: []string{"t0"}}
+	server := httptest.NewServer(ccu)
+	defer server.Close()
+
+	client := NewClient(server.URL, "t0")
+	rotator := NewTokenRotator(client, filepath.Join(t.TempDir(), "token.json"), nil)
+	if err := rotator.Check(time.Now()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	ccu.valid = nil
+	if err := rotator.Rotate(time.Now()); err == nil {
+		t.Fatal("expected Rotate to fail with a rejected token")
+	}
+	state, _ := rotator.State()
+	if state.Active != "t0" || client.Token != "t0" {
+		t.Errorf("expected to keep t0, got %+v and %q", state, client.Token)
+	}
+}
+
+func TestTokenRotatorRefusesSecretsProvider(t *testing.T) {
+	ccu := &tokenCCU{valid: []string{"t0"}}
+	server := httptest.NewServer(ccu)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	client := NewClient(server.URL, "")
+	client.Secrets = SecretsProviderFunc(func(ctx context.Context) (string, error) { return "t0", nil })
+	rotator := NewTokenRotator(client, path, nil)
+
+	if err := rotator.Rotate(time.Now()); err == nil {
+		t.Fatal("expected rotation to be refused")
+	}
+	if len(ccu.valid) != 1 || len(ccu.revoked) != 0 {
+		t.Errorf("expected no token to be registered or revoked, got %v, %v", ccu.valid, ccu.revoked)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no state to be written, got %v", err)
+	}
+}
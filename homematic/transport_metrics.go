@@ -0,0 +1,105 @@
+package homematic
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// TransportStats counts the connection activity of a client, e.g. to
+// diagnose connection churn when polling the CCU frequently
+type TransportStats struct {
+	// Requests is the number of requests that got a connection
+	Requests uint64 `json:"requests"`
+	// ConnectionsOpened and ConnectionsReused split the requests by whether
+	// they opened a new connection or reused an idle one
+	ConnectionsOpened uint64 `json:"connections_opened"`
+	ConnectionsReused uint64 `json:"connections_reused"`
+	// TLSHandshakes and TLSHandshakeErrors count completed and failed handshakes
+	TLSHandshakes      uint64 `json:"tls_handshakes"`
+	TLSHandshakeErrors uint64 `json:"tls_handshake_errors"`
+	// HTTP2Responses is the number of responses received over HTTP/2
+	HTTP2Responses uint64 `json:"http2_responses"`
+}
+
+// transportStats holds the counters of TransportStats; it is shared by all
+// copies of a client
+type transportStats struct {
+	requests, opened, reused           atomic.Uint64
+	handshakes, handshakeErrors, http2 atomic.Uint64
+}
+
+// TransportStats returns the connection counters of the client
+func (c *Client) TransportStats() TransportStats {
+	s := c.transportStats
+	if s == nil {
+		return TransportStats{}
+	}
+	return TransportStats{
+		Requests:           s.requests.Load(),
+		ConnectionsOpened:  s.opened.Load(),
+		ConnectionsReused:  s.reused.Load(),
+		TLSHandshakes:      s.handshakes.Load(),
+		TLSHandshakeErrors: s.handshakeErrors.Load(),
+		HTTP2Responses:     s.http2.Load(),
+	}
+}
+
+// traceTransport adds a trace counting connections and handshakes to ctx
+func (c *Client) traceTransport(ctx context.Context) context.Context {
+	s := c.transportStats
+	if s == nil {
+		return ctx
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			s.requests.Add(1)
+			if info.Reused {
+				s.reused.Add(1)
+			} else {
+				s.opened.Add(1)
+			}
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err != nil {
+				s.handshakeErrors.Add(1)
+			} else {
+				s.handshakes.Add(1)
+			}
+		},
+	})
+}
+
+// observeProtocol counts responses received over HTTP/2
+func (c *Client) observeProtocol(resp *http.Response) {
+	if c.transportStats != nil && resp.ProtoMajor == 2 {
+		c.transportStats.http2.Add(1)
+	}
+}
+
+// WriteTransportMetrics writes the transport counters of the client in the Prometheus text format
+func (c *Client) WriteTransportMetrics(w io.Writer) error {
+	stats := c.TransportStats()
+	metrics := []struct {
+		name, help string
+		value      uint64
+	}{
+		{"homematic_client_connections_opened_total", "Number of requests that opened a new connection to the CCU.", stats.ConnectionsOpened},
+		{"homematic_client_connections_reused_total", "Number of requests that reused an idle connection to the CCU.", stats.ConnectionsReused},
+		{"homematic_client_tls_handshakes_total", "Number of completed TLS handshakes with the CCU.", stats.TLSHandshakes},
+		{"homematic_client_tls_handshake_errors_total", "Number of failed TLS handshakes with the CCU.", stats.TLSHandshakeErrors},
+		{"homematic_client_http2_responses_total", "Number of responses received over HTTP/2.", stats.HTTP2Responses},
+	}
+	for _, m := range metrics {
+		if err := writeMetricHeader(w, m.name, m.help, "counter"); err != nil {
+			return err
+		}
+		if err := writeMetricSample(w, m.name, nil, float64(m.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
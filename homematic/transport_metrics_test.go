@@ -0,0 +1,65 @@
+package homematic
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransportStatsHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetVersion(); err != nil {
+			t.Fatalf("GetVersion failed: %v", err)
+		}
+	}
+
+	stats := client.TransportStats()
+	want := TransportStats{Requests: 3, ConnectionsOpened: 1, ConnectionsReused: 2, TLSHandshakes: 1, HTTP2Responses: 3}
+	if stats != want {
+		t.Errorf("unexpected stats:\n got %+v\nwant %+v", stats, want)
+	}
+
+	var buf bytes.Buffer
+	if err := client.WriteTransportMetrics(&buf); err != nil {
+		t.Fatalf("WriteTransportMetrics failed: %v", err)
+	}
+	for _, line := range []string{
+		"# TYPE homematic_client_connections_opened_total counter",
+		"homematic_client_connections_opened_total 1",
+		"homematic_client_connections_reused_total 2",
+		"homematic_client_tls_handshakes_total 1",
+		"homematic_client_http2_responses_total 3",
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected %q in metrics:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestTransportStatsHTTP1(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<version>1.22</version>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetVersion(); err != nil {
+			t.Fatalf("GetVersion failed: %v", err)
+		}
+	}
+	stats := client.TransportStats()
+	if stats.ConnectionsOpened != 1 || stats.ConnectionsReused != 1 || stats.TLSHandshakes != 0 || stats.HTTP2Responses != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,313 @@
+package homematic
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckStatus is the outcome of a single validation check
+type CheckStatus string
+
+const (
+	CheckOK      CheckStatus = "ok"
+	CheckWarning CheckStatus = "warning"
+	CheckFailed  CheckStatus = "failed"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// Names of the checks performed by Validate
+const (
+	CheckURL          = "url"
+	CheckReachability = "reachability"
+	CheckTLS          = "tls"
+	CheckAddon        = "addon"
+	CheckToken        = "token"
+	CheckClock        = "clock"
+)
+
+// MinAddonVersion is the oldest XML-API addon version supporting security tokens
+const MinAddonVersion = "2.0"
+
+// Check is the result of a single validation check
+type Check struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// ValidationReport is the result of Validate
+type ValidationReport struct {
+	Checks []Check `json:"checks"`
+
+	// Version is the XML-API addon version, if it could be determined
+	Version string `json:"version,omitempty"`
+
+	// ClockSkew is the CCU clock minus the local clock, derived from the HTTP Date header
+	ClockSkew time.Duration `json:"clock_skew,omitempty"`
+}
+
+// OK reports whether no check failed; warnings and skipped checks are accepted
+func (r *ValidationReport) OK() bool {
+	for _, check := range r.Checks {
+		if check.Status == CheckFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns the result of the named check
+func (r *ValidationReport) Check(name string) (Check, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return Check{}, false
+}
+
+func (r *ValidationReport) add(name string, status CheckStatus, format string, args ...any) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// skip marks all remaining checks as skipped
+func (r *ValidationReport) skip(reason string, names ...string) {
+	for _, name := range names {
+		r.add(name, CheckSkipped, "%s", reason)
+	}
+}
+
+// Validate diagnoses the client setup: the base URL and its reachability,
+// whether the TLS certificate is trusted, the presence and version of the
+// XML-API addon, whether the token is accepted and whether the CCU clock
+// matches the local one. Problems are reported as failed or warning checks
+// rather than as an error.
+func (c *Client) Validate(ctx context.Context) *ValidationReport {
+	report := &ValidationReport{}
+
+	var u *url.URL
+	baseURL, err := NormalizeBaseURL(c.BaseURL)
+	if err == nil {
+		u, err = url.Parse(baseURL)
+	}
+	if err != nil {
+		report.add(CheckURL, CheckFailed, "%v", err)
+	} else {
+		report.add(CheckURL, CheckOK, "%s", u.Redacted())
+	}
+	if report.Checks[0].Status == CheckFailed {
+		report.skip("invalid base URL", CheckReachability, CheckTLS, CheckAddon, CheckToken, CheckClock)
+		return report
+	}
+
+	date, err := c.validateReachability(ctx, u, report)
+	if err != nil {
+		report.skip("CCU not reachable", CheckTLS, CheckAddon, CheckToken, CheckClock)
+		return report
+	}
+
+	c.validateTLS(ctx, u, report)
+	if c.validateAddon(ctx, report) {
+		c.validateToken(ctx, report)
+	} else {
+		report.skip("XML-API addon not available", CheckToken)
+	}
+	validateClock(date, time.Now(), c.clockDriftThreshold(), report)
+
+	return report
+}
+
+// validateReachability requests the base URL and returns the Date header of the response
+func (c *Client) validateReachability(ctx context.Context, u *url.URL, report *ValidationReport) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		report.add(CheckReachability, CheckFailed, "invalid request: %v", err)
+		return "", err
+	}
+
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		report.add(CheckReachability, CheckFailed, "%v", err)
+		return "", err
+	}
+	resp.Body.Close()
+
+	report.add(CheckReachability, CheckOK, "HTTP %d in %s", resp.StatusCode, time.Since(start).Round(time.Millisecond))
+	return resp.Header.Get("Date"), nil
+}
+
+// validateTLS verifies the server certificate, even if the client itself skips verification
+func (c *Client) validateTLS(ctx context.Context, u *url.URL, report *ValidationReport) {
+	if u.Scheme != "https" {
+		report.add(CheckTLS, CheckWarning, "plain HTTP, the token is sent unencrypted")
+		return
+	}
+	if browserTransport {
+		report.skip("certificates are verified by the browser", CheckTLS)
+		return
+	}
+
+	config := &tls.Config{}
+	insecure := false
+	// the probe is dialed like requests, e.g. through a dial target, Unix socket or SSH tunnel
+	dial := (&net.Dialer{}).DialContext
+	if transport, ok := httpTransport(c.HTTPClient.Transport); ok {
+		if transport.TLSClientConfig != nil {
+			config = transport.TLSClientConfig.Clone()
+			insecure = config.InsecureSkipVerify
+		}
+		if transport.DialContext != nil {
+			dial = transport.DialContext
+		}
+	}
+	config.InsecureSkipVerify = false
+	config.ServerName = u.Hostname()
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	conn, err := dialTLS(ctx, dial, addr, config)
+	if err != nil {
+		if insecure {
+			report.add(CheckTLS, CheckWarning, "certificate not trusted, verification is disabled: %v", err)
+		} else {
+			report.add(CheckTLS, CheckFailed, "%v", err)
+		}
+		return
+	}
+	defer conn.Close()
+
+	leaf := conn.ConnectionState().PeerCertificates[0]
+	status := CheckOK
+	if insecure {
+		status = CheckWarning
+	}
+	message := fmt.Sprintf("certificate for %s trusted, expires %s", config.ServerName, leaf.NotAfter.Format(time.DateOnly))
+	if insecure {
+		message += ", but verification is disabled"
+	}
+	report.add(CheckTLS, status, "%s", message)
+}
+
+// dialTLS dials addr with dial and performs a TLS handshake with config
+func dialTLS(ctx context.Context, dial DialFunc, addr string, config *tls.Config) (*tls.Conn, error) {
+	raw, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := tls.Client(raw, config)
+	if err := conn.HandshakeContext(ctx); err != nil {
+		raw.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// validateAddon checks that the XML-API addon is installed and recent enough
+func (c *Client) validateAddon(ctx context.Context, report *ValidationReport) bool {
+	var versionResp VersionResponse
+	err := c.doRequestContext(ctx, "version.cgi", nil, func(body []byte) error {
+		return decodeXML(body, &versionResp)
+	})
+
+	var httpErr *HTTPError
+	switch {
+	case errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound:
+		report.add(CheckAddon, CheckFailed, "XML-API addon not installed")
+		return false
+	case errors.Is(err, ErrNotAuthenticated):
+		report.add(CheckAddon, CheckWarning, "XML-API addon installed, version requires a valid token")
+		return true
+	case err != nil:
+		report.add(CheckAddon, CheckFailed, "%v", err)
+		return false
+	}
+
+	report.Version = strings.TrimSpace(versionResp.Value)
+	if compareVersions(report.Version, MinAddonVersion) < 0 {
+		report.add(CheckAddon, CheckWarning, "XML-API %s is older than %s and does not support tokens", report.Version, MinAddonVersion)
+	} else {
+		report.add(CheckAddon, CheckOK, "XML-API %s", report.Version)
+	}
+	return true
+}
+
+// validateToken checks that the CCU accepts the token
+func (c *Client) validateToken(ctx context.Context, report *ValidationReport) {
+	var rooms RoomListResponse
+	err := c.doRequestContext(ctx, "roomlist.cgi", nil, func(body []byte) error {
+		return decodeXML(body, &rooms)
+	})
+
+	switch {
+	case errors.Is(err, ErrNotAuthenticated) && c.Token == "" && c.Secrets == nil:
+		report.add(CheckToken, CheckFailed, "no token configured")
+	case errors.Is(err, ErrNotAuthenticated):
+		report.add(CheckToken, CheckFailed, "token rejected")
+	case err != nil:
+		report.add(CheckToken, CheckFailed, "%v", err)
+	case c.Token == "" && c.Secrets == nil:
+		report.add(CheckToken, CheckWarning, "no token configured, the CCU accepts unauthenticated requests")
+	default:
+		report.add(CheckToken, CheckOK, "token accepted")
+	}
+}
+
+// validateClock compares the Date header of the CCU with the local clock
+func validateClock(date string, now time.Time, threshold time.Duration, report *ValidationReport) {
+	if date == "" {
+		report.add(CheckClock, CheckSkipped, "no Date header in response")
+		return
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		report.add(CheckClock, CheckSkipped, "invalid Date header %q", date)
+		return
+	}
+
+	report.ClockSkew = serverTime.Sub(now).Truncate(time.Second)
+	skew := report.ClockSkew.Abs()
+	if skew > threshold {
+		report.add(CheckClock, CheckWarning, "CCU clock differs from local clock by %s", skew)
+		return
+	}
+	report.add(CheckClock, CheckOK, "CCU clock differs from local clock by %s", skew)
+}
+
+// compareVersions compares dotted numeric versions, ignoring non-numeric suffixes
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := versionPart(as, i), versionPart(bs, i)
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	digits := strings.TrimLeftFunc(parts[i], func(r rune) bool { return r < '0' || r > '9' })
+	if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		digits = digits[:end]
+	}
+	n, _ := strconv.Atoi(digits)
+	return n
+}
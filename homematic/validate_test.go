@@ -0,0 +1,205 @@
+package homematic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newValidateServer serves the endpoints used by Validate
+func newValidateServer(version, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte("<html></html>"))
+		case "/addons/xmlapi/version.cgi":
+			if version == "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte("<version>" + version + "</version>"))
+		case "/addons/xmlapi/roomlist.cgi":
+			if r.URL.Query().Get("sid") != token {
+				w.Write([]byte("<not_authenticated/>"))
+				return
+			}
+			w.Write([]byte("<roomList/>"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func checkStatuses(report *ValidationReport) map[string]CheckStatus {
+	statuses := make(map[string]CheckStatus)
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestValidate(t *testing.T) {
+	server := httptest.NewServer(newValidateServer("2.3", "secret"))
+	defer server.Close()
+
+	report := NewClient(server.URL, "secret").Validate(context.Background())
+	if !report.OK() {
+		t.Fatalf("expected report to pass: %+v", report.Checks)
+	}
+	if report.Version != "2.3" {
+		t.Errorf("expected version 2.3, got %q", report.Version)
+	}
+
+	expected := map[string]CheckStatus{
+		CheckURL:          CheckOK,
+		CheckReachability: CheckOK,
+		CheckTLS:          CheckWarning,
+		CheckAddon:        CheckOK,
+		CheckToken:        CheckOK,
+		CheckClock:        CheckOK,
+	}
+	statuses := checkStatuses(report)
+	for name, status := range expected {
+		if statuses[name] != status {
+			t.Errorf("check %s: expected %s, got %s", name, status, statuses[name])
+		}
+	}
+}
+
+func TestValidateFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		token   string
+		check   string
+		status  CheckStatus
+		message string
+	}{
+		{"missing addon", "", "secret", CheckAddon, CheckFailed, "not installed"},
+		{"old addon", "1.22", "secret", CheckAddon, CheckWarning, "older than 2.0"},
+		{"rejected token", "2.3", "wrong", CheckToken, CheckFailed, "token rejected"},
+		{"no token", "2.3", "", CheckToken, CheckFailed, "no token configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(newValidateServer(tt.version, "secret"))
+			defer server.Close()
+
+			report := NewClient(server.URL, tt.token).Validate(context.Background())
+			check, ok := report.Check(tt.check)
+			if !ok {
+				t.Fatalf("check %s missing", tt.check)
+			}
+			if check.Status != tt.status || !strings.Contains(check.Message, tt.message) {
+				t.Errorf("unexpected check result: %+v", check)
+			}
+		})
+	}
+}
+
+func TestValidateUnreachable(t *testing.T) {
+	server := httptest.NewServer(newValidateServer("2.3", "secret"))
+	server.Close()
+
+	report := NewClient(server.URL, "secret").Validate(context.Background())
+	if report.OK() {
+		t.Fatal("expected report to fail")
+	}
+	statuses := checkStatuses(report)
+	if statuses[CheckReachability] != CheckFailed {
+		t.Errorf("expected reachability to fail, got %s", statuses[CheckReachability])
+	}
+	for _, name := range []string{CheckTLS, CheckAddon, CheckToken, CheckClock} {
+		if statuses[name] != CheckSkipped {
+			t.Errorf("expected check %s to be skipped, got %s", name, statuses[name])
+		}
+	}
+}
+
+func TestValidateInvalidURL(t *testing.T) {
+	report := NewClient("ftp://ccu", "").Validate(context.Background())
+	if check, _ := report.Check(CheckURL); check.Status != CheckFailed {
+		t.Errorf("expected url check to fail, got %+v", check)
+	}
+	if len(report.Checks) != 6 {
+		t.Errorf("expected all checks to be reported, got %d", len(report.Checks))
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	server := httptest.NewTLSServer(newValidateServer("2.3", "secret"))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	check, _ := client.Validate(context.Background()).Check(CheckTLS)
+	if check.Status != CheckWarning || !strings.Contains(check.Message, "not trusted") {
+		t.Errorf("expected untrusted certificate warning, got %+v", check)
+	}
+
+	client.HTTPClient = server.Client()
+	check, _ = client.Validate(context.Background()).Check(CheckTLS)
+	if check.Status != CheckOK {
+		t.Errorf("expected trusted certificate, got %+v", check)
+	}
+}
+
+func TestValidateTLSDialTarget(t *testing.T) {
+	server := httptest.NewTLSServer(newValidateServer("2.3", "secret"))
+	defer server.Close()
+
+	// the test certificate is issued for example.com, which only the dial target reaches
+	client := NewClient("https://example.com", "secret")
+	client.HTTPClient = server.Client()
+	if err := client.SetDialTarget(server.Listener.Addr().String()); err != nil {
+		t.Fatalf("SetDialTarget failed: %v", err)
+	}
+	check, _ := client.Validate(context.Background()).Check(CheckTLS)
+	if check.Status != CheckOK || !strings.Contains(check.Message, "example.com") {
+		t.Errorf("expected the certificate to be checked through the dial target, got %+v", check)
+	}
+}
+
+func TestValidateClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		date   string
+		status CheckStatus
+		skew   time.Duration
+	}{
+		{now.Add(10 * time.Second).Format(http.TimeFormat), CheckOK, 10 * time.Second},
+		{now.Add(-5 * time.Minute).Format(http.TimeFormat), CheckWarning, -5 * time.Minute},
+		{"", CheckSkipped, 0},
+		{"yesterday", CheckSkipped, 0},
+	}
+
+	for _, tt := range tests {
+		report := &ValidationReport{}
+		validateClock(tt.date, now, time.Minute, report)
+		if report.Checks[0].Status != tt.status || report.ClockSkew != tt.skew {
+			t.Errorf("validateClock(%q) = %s/%s, want %s/%s", tt.date, report.Checks[0].Status, report.ClockSkew, tt.status, tt.skew)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.3", "2.0", 1},
+		{"1.22", "2.0", -1},
+		{"2.0", "2", 0},
+		{"2.1-beta", "2.1", 0},
+		{"10.0", "9.9", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package homematic
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Magnus formula coefficients over water, valid from -45 °C to 60 °C
+const (
+	magnusA = 17.62
+	magnusB = 243.12
+)
+
+// DewPoint returns the dew point in °C of air with the given temperature in
+// °C and relative humidity in percent
+func DewPoint(temperature, humidity float64) float64 {
+	gamma := math.Log(humidity/100) + magnusA*temperature/(magnusB+temperature)
+	return magnusB * gamma / (magnusA - gamma)
+}
+
+// AbsoluteHumidity returns the water content in g/m³ of air with the given
+// temperature in °C and relative humidity in percent
+func AbsoluteHumidity(temperature, humidity float64) float64 {
+	saturation := 6.112 * math.Exp(magnusA*temperature/(magnusB+temperature))
+	return 216.7 * humidity / 100 * saturation / (273.15 + temperature)
+}
+
+// VentilationAdvice is the recommendation of a VentilationAdvisor
+type VentilationAdvice string
+
+const (
+	VentilateNow VentilationAdvice = "ventilate"
+	KeepClosed   VentilationAdvice = "keep_closed"
+)
+
+// ClimateSensor references the temperature and humidity data points of a sensor
+type ClimateSensor struct {
+	Temperature string
+	Humidity    string
+}
+
+// VentilationRecommendation is the advice of a VentilationAdvisor with the readings it is based on
+type VentilationRecommendation struct {
+	Advice VentilationAdvice `json:"advice"`
+	// Changed is set if the advice differs from the previous one
+	Changed bool `json:"changed"`
+
+	IndoorTemperature  float64   `json:"indoor_temperature"`
+	IndoorHumidity     float64   `json:"indoor_humidity"`
+	IndoorDewPoint     float64   `json:"indoor_dew_point"`
+	OutdoorTemperature float64   `json:"outdoor_temperature"`
+	OutdoorHumidity    float64   `json:"outdoor_humidity"`
+	OutdoorDewPoint    float64   `json:"outdoor_dew_point"`
+	At                 time.Time `json:"at"`
+}
+
+// VentilationAdvisor recommends ventilating a room, e.g. a cellar, when the
+// outdoor dew point is sufficiently below the indoor dew point, so that
+// ventilation dries the room instead of adding moisture
+type VentilationAdvisor struct {
+	Client  *Client
+	Indoor  ClimateSensor
+	Outdoor ClimateSensor
+
+	// MinDifference is the minimum difference between the indoor and outdoor
+	// dew point to ventilate, 5 °C by default; Hysteresis is the amount the
+	// difference must fall below it to stop, 1 °C by default
+	MinDifference float64
+	Hysteresis    float64
+
+	// MinIndoorTemperature keeps the room from cooling down below the given
+	// temperature, e.g. 10 °C to avoid condensation on cold walls; zero disables the limit
+	MinIndoorTemperature float64
+
+	// Variable is the name or ise_id of an optional system variable the advice
+	// is written to when it changes: bool variables are set while ventilation is
+	// recommended, string variables hold the advice
+	Variable string
+
+	mu         sync.Mutex
+	hysteresis *Hysteresis
+	advice     VentilationAdvice
+}
+
+// NewVentilationAdvisor creates an advisor comparing an indoor and an outdoor sensor
+func NewVentilationAdvisor(client *Client, indoor, outdoor ClimateSensor) *VentilationAdvisor {
+	return &VentilationAdvisor{Client: client, Indoor: indoor, Outdoor: outdoor}
+}
+
+// Check reads the sensors and returns the current recommendation, writing it
+// to the system variable if it changed
+func (a *VentilationAdvisor) Check() (*VentilationRecommendation, error) {
+	ids := []string{a.Indoor.Temperature, a.Indoor.Humidity, a.Outdoor.Temperature, a.Outdoor.Humidity}
+	devices, err := a.Client.GetState(nil, nil, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]float64, len(ids))
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				if v, ok := numericValue(dp.Value); ok {
+					values[dp.IseID] = v
+				}
+			}
+		}
+	}
+	for _, id := range ids {
+		if _, ok := values[id]; !ok {
+			return nil, fmt.Errorf("no numeric value for data point %s", id)
+		}
+	}
+
+	rec := a.Recommend(values[a.Indoor.Temperature], values[a.Indoor.Humidity],
+		values[a.Outdoor.Temperature], values[a.Outdoor.Humidity], time.Now())
+	if rec.Changed && a.Variable != "" {
+		if err := a.writeVariable(rec.Advice); err != nil {
+			// retry with the next check
+			a.mu.Lock()
+			a.advice = ""
+			a.mu.Unlock()
+			return rec, err
+		}
+	}
+	return rec, nil
+}
+
+// Recommend returns the recommendation for the given readings, with
+// temperatures in °C and relative humidities in percent
+func (a *VentilationAdvisor) Recommend(indoorTemperature, indoorHumidity, outdoorTemperature, outdoorHumidity float64, at time.Time) *VentilationRecommendation {
+	rec := &VentilationRecommendation{
+		IndoorTemperature:  indoorTemperature,
+		IndoorHumidity:     indoorHumidity,
+		IndoorDewPoint:     DewPoint(indoorTemperature, indoorHumidity),
+		OutdoorTemperature: outdoorTemperature,
+		OutdoorHumidity:    outdoorHumidity,
+		OutdoorDewPoint:    DewPoint(outdoorTemperature, outdoorHumidity),
+		At:                 at,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.hysteresis == nil {
+		on := a.MinDifference
+		if on == 0 {
+			on = 5
+		}
+		hysteresis := a.Hysteresis
+		if hysteresis == 0 {
+			hysteresis = 1
+		}
+		a.hysteresis = &Hysteresis{On: on, Off: on - hysteresis}
+	}
+	a.hysteresis.Observe(rec.IndoorDewPoint-rec.OutdoorDewPoint, at)
+	on, _ := a.hysteresis.State()
+
+	rec.Advice = KeepClosed
+	if on && (a.MinIndoorTemperature == 0 || indoorTemperature > a.MinIndoorTemperature) {
+		rec.Advice = VentilateNow
+	}
+	rec.Changed = rec.Advice != a.advice
+	a.advice = rec.Advice
+	return rec
+}
+
+// writeVariable writes the advice to the system variable
+func (a *VentilationAdvisor) writeVariable(advice VentilationAdvice) error {
+	sysVars, err := a.Client.GetSystemVariableList(false)
+	if err != nil {
+		return err
+	}
+	sysVar, err := FindSystemVariable(sysVars, a.Variable)
+	if err != nil {
+		return err
+	}
+
+	input := string(advice)
+	switch sysVar.Kind() {
+	case SysVarBool, SysVarAlarm:
+		input = strconv.FormatBool(advice == VentilateNow)
+	case SysVarString:
+	default:
+		return fmt.Errorf("system variable %s must be a bool or string variable", sysVar.Name)
+	}
+	return a.Client.SetSystemVariable(sysVar, input)
+}
@@ -0,0 +1,100 @@
+package homematic
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDewPoint(t *testing.T) {
+	if dp := DewPoint(20, 50); math.Abs(dp-9.26) > 0.05 {
+		t.Errorf("expected a dew point of 9.26 °C, got %.2f", dp)
+	}
+	if dp := DewPoint(-5, 80); math.Abs(dp-(-7.9)) > 0.1 {
+		t.Errorf("expected a dew point of -7.9 °C, got %.2f", dp)
+	}
+	if ah := AbsoluteHumidity(20, 50); math.Abs(ah-8.65) > 0.05 {
+		t.Errorf("expected 8.65 g/m³, got %.2f", ah)
+	}
+}
+
+func TestVentilationAdvisorRecommend(t *testing.T) {
+	advisor := NewVentilationAdvisor(nil, ClimateSensor{}, ClimateSensor{})
+	advisor.MinIndoorTemperature = 10
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// humid cellar, dry and cold outside
+	rec := advisor.Recommend(15, 80, 5, 60, at)
+	if rec.Advice != VentilateNow || !rec.Changed {
+		t.Errorf("expected to ventilate, got %+v", rec)
+	}
+	// the difference shrinks, but stays within the hysteresis
+	rec = advisor.Recommend(15, 70, 8, 82, at.Add(time.Hour))
+	if diff := rec.IndoorDewPoint - rec.OutdoorDewPoint; diff < 4 || diff > 5 {
+		t.Fatalf("test readings should yield a difference between 4 and 5 °C, got %.2f", diff)
+	}
+	if rec.Advice != VentilateNow || rec.Changed {
+		t.Errorf("expected to keep ventilating within the hysteresis, got %+v", rec)
+	}
+	// humid summer air would add moisture
+	rec = advisor.Recommend(15, 70, 25, 60, at.Add(2*time.Hour))
+	if rec.Advice != KeepClosed || !rec.Changed {
+		t.Errorf("expected to keep closed, got %+v", rec)
+	}
+	// a cold room is not cooled down further
+	rec = advisor.Recommend(9, 90, -5, 50, at.Add(3*time.Hour))
+	if rec.Advice != KeepClosed {
+		t.Errorf("expected to keep a cold room closed, got %+v", rec)
+	}
+}
+
+func TestVentilationAdvisorCheck(t *testing.T) {
+	values := map[string]string{"101": "16.0", "102": "80", "201": "4.5", "202": "70", "900": "false"}
+	var changes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/addons/xmlapi/state.cgi":
+			fmt.Fprint(w, `<stateList>`)
+			for _, id := range strings.Split(r.URL.Query().Get("datapoint_id"), ",") {
+				fmt.Fprintf(w, `<device ise_id="1"><channel ise_id="2"><datapoint ise_id="%s" value="%s"/></channel></device>`, id, values[id])
+			}
+			fmt.Fprint(w, `</stateList>`)
+		case "/addons/xmlapi/sysvarlist.cgi":
+			fmt.Fprintf(w, `<systemVariables><systemVariable name="Keller lüften" ise_id="900" value="%s" type="2" subtype="2"/></systemVariables>`, values["900"])
+		case "/addons/xmlapi/statechange.cgi":
+			changes = append(changes, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+			values[r.URL.Query().Get("ise_id")] = r.URL.Query().Get("new_value")
+			fmt.Fprintf(w, `<result><changed id="%s"/></result>`, r.URL.Query().Get("ise_id"))
+		}
+	}))
+	defer server.Close()
+
+	advisor := NewVentilationAdvisor(NewClient(server.URL, "token"),
+		ClimateSensor{Temperature: "101", Humidity: "102"}, ClimateSensor{Temperature: "201", Humidity: "202"})
+	advisor.Variable = "Keller lüften"
+
+	rec, err := advisor.Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if rec.Advice != VentilateNow || len(changes) != 1 || changes[0] != "900=true" {
+		t.Errorf("expected to ventilate and set the variable, got %+v and %v", rec, changes)
+	}
+
+	// an unchanged advice is not written again
+	if _, err := advisor.Check(); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Errorf("expected no further writes, got %v", changes)
+	}
+
+	values["202"] = "invalid"
+	if _, err := advisor.Check(); err == nil {
+		t.Error("expected an error for a non-numeric reading")
+	}
+}
@@ -0,0 +1,109 @@
+package homematic
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// virtualRemoteInterfaces maps the device types of the virtual remotes of
+// the CCU to their interface
+var virtualRemoteInterfaces = map[string]string{
+	"HM-RCV-50":   "BidCos-RF",
+	"HMW-RCV-50":  "BidCos-Wired",
+	"HmIP-RCV-50": "HmIP-RF",
+}
+
+// VirtualKey is a key channel of a virtual remote of the CCU. Programs
+// triggered by a virtual key can be fired from Go code by pressing the key.
+type VirtualKey struct {
+	IseID     string `json:"ise_id"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	Index     int    `json:"index"`
+	Interface string `json:"interface"`
+	// Renamed is set if the key has a name other than the default, which
+	// usually means it is used by a program
+	Renamed bool `json:"renamed"`
+}
+
+// IsVirtualRemote reports whether a device is a virtual remote of the CCU
+func IsVirtualRemote(device *Device) bool {
+	_, ok := virtualRemoteInterfaces[device.DeviceType]
+	return ok
+}
+
+// GetVirtualKeys returns the keys of all virtual remotes of the CCU
+func (c *Client) GetVirtualKeys() ([]VirtualKey, error) {
+	devices, err := c.GetDeviceList(nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	return VirtualKeys(devices), nil
+}
+
+// VirtualKeys returns the keys of the virtual remotes in a device list
+func VirtualKeys(devices []Device) []VirtualKey {
+	var keys []VirtualKey
+	for i := range devices {
+		device := &devices[i]
+		iface, ok := virtualRemoteInterfaces[device.DeviceType]
+		if !ok {
+			continue
+		}
+		for _, ch := range device.Channels {
+			if ch.Index == 0 {
+				continue
+			}
+			keys = append(keys, VirtualKey{
+				IseID:     ch.IseID,
+				Name:      ch.Name,
+				Address:   ch.Address,
+				Index:     ch.Index,
+				Interface: iface,
+				Renamed:   ch.Name != device.Name+":"+strconv.Itoa(ch.Index),
+			})
+		}
+	}
+	slices.SortStableFunc(keys, func(a, b VirtualKey) int {
+		if c := strings.Compare(a.Interface, b.Interface); c != 0 {
+			return c
+		}
+		return a.Index - b.Index
+	})
+	return keys
+}
+
+// FindVirtualKey returns the key with the given name (case-insensitive),
+// ise_id or address
+func FindVirtualKey(keys []VirtualKey, nameOrID string) (*VirtualKey, error) {
+	for i := range keys {
+		if keys[i].IseID == nameOrID || keys[i].Address == nameOrID {
+			return &keys[i], nil
+		}
+	}
+	for i := range keys {
+		if strings.EqualFold(keys[i].Name, nameOrID) {
+			return &keys[i], nil
+		}
+	}
+	return nil, fmt.Errorf("virtual key not found: %s", nameOrID)
+}
+
+// PressVirtualKey simulates a short or long press of a virtual key given by
+// name, ise_id or address
+func (c *Client) PressVirtualKey(nameOrID string, long bool) error {
+	keys, err := c.GetVirtualKeys()
+	if err != nil {
+		return err
+	}
+	key, err := FindVirtualKey(keys, nameOrID)
+	if err != nil {
+		return err
+	}
+	if long {
+		return c.PressLong(key.IseID)
+	}
+	return c.PressShort(key.IseID)
+}
@@ -0,0 +1,69 @@
+package homematic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestVirtualKeys(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.RaspberryMatic)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	var changes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/statechange.cgi") {
+			changes = append(changes, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+		}
+		mock.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	keys, err := client.GetVirtualKeys()
+	if err != nil {
+		t.Fatalf("GetVirtualKeys failed: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 virtual keys, got %+v", keys)
+	}
+	if keys[0].IseID != "3461" || keys[0].Index != 1 || keys[0].Interface != "HmIP-RF" || keys[0].Renamed {
+		t.Errorf("unexpected first key: %+v", keys[0])
+	}
+
+	if err := client.PressVirtualKey("HmIP-RCV-1:2", false); err != nil {
+		t.Fatalf("PressVirtualKey failed: %v", err)
+	}
+	if err := client.PressVirtualKey("3461", true); err != nil {
+		t.Fatalf("PressVirtualKey failed: %v", err)
+	}
+	if got := strings.Join(changes, " "); got != "3466=true 3462=true" {
+		t.Errorf("unexpected state changes: %s", got)
+	}
+	if err := client.PressVirtualKey("Garage", false); err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
+
+func TestVirtualKeysRenamed(t *testing.T) {
+	devices := []Device{
+		{Name: "HM-RCV-50 BidCoS-RF", DeviceType: "HM-RCV-50", Channels: []Channel{
+			{Name: "HM-RCV-50 BidCoS-RF:0", Index: 0},
+			{Name: "Alles aus", IseID: "1461", Index: 1},
+			{Name: "HM-RCV-50 BidCoS-RF:2", IseID: "1465", Index: 2},
+		}},
+		{Name: "Thermostat", DeviceType: "HmIP-eTRV-2", Channels: []Channel{{Name: "Thermostat:1", Index: 1}}},
+	}
+	keys := VirtualKeys(devices)
+	if len(keys) != 2 || !keys[0].Renamed || keys[1].Renamed || keys[0].Interface != "BidCos-RF" {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+	key, err := FindVirtualKey(keys, "alles AUS")
+	if err != nil || key.IseID != "1461" {
+		t.Errorf("expected to find the key by name, got %+v, %v", key, err)
+	}
+}
@@ -0,0 +1,194 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is the polling interval of a Watcher if Interval is not set
+const DefaultWatchInterval = 10 * time.Second
+
+// Watcher polls the state of a CCU and notifies handlers and subscribers of
+// the data points whose value or timestamp changed since the previous poll.
+// The XML-API has no push mechanism, so changes are detected with a delay of
+// up to one interval.
+type Watcher struct {
+	Client *Client
+
+	// Interval is the time between polls, DefaultWatchInterval if zero
+	Interval time.Duration
+
+	// DataPoints restricts polling to the given data point ise_ids, which are
+	// queried with state.cgi; otherwise the full state list is polled
+	DataPoints []string
+
+	// Cache optionally refreshes the full state list incrementally, see StateCache
+	Cache *StateCache
+
+	// ShowInternal includes internal channels when polling the full state list
+	ShowInternal bool
+
+	// Initial reports the data points of the first poll with FirstObserved
+	// set; otherwise the first poll only records the current values
+	Initial bool
+
+	// Filter optionally suppresses chatter of binary data points; its held
+	// transitions are flushed on every poll
+	Filter *ChatterFilter
+
+	// OnError is called for failed polls; the watcher keeps polling
+	OnError func(err error)
+
+	mu          sync.Mutex
+	handlers    []func(change DataPointChange)
+	subscribers []chan DataPointChange
+	previous    []Device
+	polled      bool
+}
+
+// NewWatcher creates a watcher polling the full state list of client at the given interval
+func NewWatcher(client *Client, interval time.Duration) *Watcher {
+	return &Watcher{Client: client, Interval: interval}
+}
+
+// OnChange registers a handler called by Run for every change
+func (w *Watcher) OnChange(fn func(change DataPointChange)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.handlers = append(w.handlers, fn)
+}
+
+// Subscribe returns a channel receiving the changes delivered by Run, which
+// closes it when it returns. Run waits for subscribers whose buffer is full,
+// so slow consumers delay the next poll rather than losing changes.
+func (w *Watcher) Subscribe(buffer int) <-chan DataPointChange {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ch := make(chan DataPointChange, buffer)
+	w.subscribers = append(w.subscribers, ch)
+	return ch
+}
+
+// Poll polls the state once and returns the changes since the previous poll,
+// passed through Filter, without notifying handlers and subscribers, e.g. for
+// custom loops
+func (w *Watcher) Poll(now time.Time) ([]DataPointChange, error) {
+	// the state is fetched without holding the lock, which is only taken to
+	// swap the previous state
+	var (
+		current []Device
+		changes []DataPointChange
+		err     error
+	)
+	cached := len(w.DataPoints) == 0 && w.Cache != nil
+	switch {
+	case len(w.DataPoints) > 0:
+		current, err = w.Client.GetState(nil, nil, w.DataPoints)
+	case cached:
+		changes, err = w.Cache.Refresh(now)
+	default:
+		current, err = w.Client.GetStateList("", w.ShowInternal, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	if !cached {
+		changes = DiffStates(w.previous, current, now)
+		w.previous = current
+	}
+	record := !w.polled && !w.Initial
+	w.polled = true
+	w.mu.Unlock()
+
+	// the first poll still teaches the filter the stable values
+	changes = w.filter(changes, now)
+	if record {
+		return nil, nil
+	}
+	return changes, nil
+}
+
+// filter passes changes through the Filter, if set, followed by the
+// transitions it held for long enough at now
+func (w *Watcher) filter(changes []DataPointChange, now time.Time) []DataPointChange {
+	if w.Filter == nil {
+		return changes
+	}
+	var out []DataPointChange
+	for _, change := range changes {
+		out = append(out, w.Filter.Observe(change)...)
+	}
+	return append(out, w.Filter.Flush(now)...)
+}
+
+// Run polls immediately and then every Interval, delivering the changes to
+// the handlers and subscribers, until ctx is canceled or the client is
+// closed; it returns ctx.Err() or ErrClientClosed
+func (w *Watcher) Run(ctx context.Context) error {
+	defer w.closeSubscribers()
+
+	ticker := time.NewTicker(durationOrDefault(w.Interval, DefaultWatchInterval))
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		changes, err := w.Poll(now)
+		if errors.Is(err, ErrClientClosed) {
+			return err
+		}
+		if err != nil {
+			if w.OnError != nil {
+				w.OnError(err)
+			}
+			// held transitions are delivered even if the poll failed
+			changes = w.filter(nil, now)
+		}
+		if err := w.deliver(ctx, changes); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver passes changes to the handlers and subscribers
+func (w *Watcher) deliver(ctx context.Context, changes []DataPointChange) error {
+	w.mu.Lock()
+	handlers, subscribers := w.handlers, w.subscribers
+	w.mu.Unlock()
+
+	for _, change := range changes {
+		for _, handler := range handlers {
+			handler(change)
+		}
+		for _, ch := range subscribers {
+			select {
+			case ch <- change:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// closeSubscribers closes and removes the subscriber channels
+func (w *Watcher) closeSubscribers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, ch := range w.subscribers {
+		close(ch)
+	}
+	w.subscribers = nil
+}
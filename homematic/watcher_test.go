@@ -0,0 +1,206 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestWatcherPoll(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	client := NewClient(server.URL, "token")
+
+	for name, watcher := range map[string]*Watcher{
+		"state list":  NewWatcher(client, time.Second),
+		"data points": {Client: client, DataPoints: []string{"2416", "2429"}},
+		"cache":       {Client: client, Cache: NewStateCache(client)},
+	} {
+		now := time.Now()
+		// the first poll only records the current values
+		if changes, err := watcher.Poll(now); err != nil || len(changes) != 0 {
+			t.Fatalf("%s: expected no changes, got %+v, %v", name, changes, err)
+		}
+		mock.SetValue("2416", "19.500000")
+		changes, err := watcher.Poll(now.Add(time.Second))
+		if err != nil {
+			t.Fatalf("%s: Poll failed: %v", name, err)
+		}
+		if len(changes) != 1 || changes[0].IseID != "2416" || changes[0].OldValue == "19.500000" || changes[0].NewValue != "19.500000" {
+			t.Errorf("%s: expected the set point change, got %+v", name, changes)
+		}
+		mock.SetValue("2416", "21.000000")
+		watcher.Poll(now.Add(2 * time.Second))
+	}
+
+	initial := &Watcher{Client: client, DataPoints: []string{"2416"}, Initial: true}
+	if changes, err := initial.Poll(time.Now()); err != nil || len(changes) != 1 || !changes[0].FirstObserved {
+		t.Errorf("expected the first poll to be reported, got %+v, %v", changes, err)
+	}
+}
+
+func TestWatcherFilter(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	watcher := &Watcher{Client: NewClient(server.URL, "token"), DataPoints: []string{"2403"}, Filter: NewChatterFilter(time.Minute)}
+	now := time.Now()
+	if changes, err := watcher.Poll(now); err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v, %v", changes, err)
+	}
+
+	// the bounce back to false drops the held transition
+	for i, value := range []string{"true", "false", "true"} {
+		mock.SetValue("2403", value)
+		if changes, err := watcher.Poll(now.Add(time.Duration(i+1) * time.Second)); err != nil || len(changes) != 0 {
+			t.Errorf("poll %d: expected the chatter to be held, got %+v, %v", i, changes, err)
+		}
+	}
+
+	// a poll without changes flushes the transition once it has been held for MinHold
+	changes, err := watcher.Poll(now.Add(2 * time.Minute))
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].OldValue != "false" || changes[0].NewValue != "true" {
+		t.Errorf("expected the held transition, got %+v", changes)
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	watcher := NewWatcher(NewClient(server.URL, "token"), time.Millisecond)
+	var mu sync.Mutex
+	var handled []string
+	watcher.OnChange(func(change DataPointChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, change.IseID)
+	})
+	changes := watcher.Subscribe(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Run(ctx) }()
+
+	for mock.Steps() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	mock.SetValue("2429", "1")
+
+	select {
+	case change := <-changes:
+		if change.IseID != "2429" || change.NewValue != "1" {
+			t.Errorf("unexpected change %+v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the change")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, open := <-changes; open {
+		t.Error("expected the subscription to be closed")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 1 || handled[0] != "2429" {
+		t.Errorf("expected the handler to be called once, got %v", handled)
+	}
+}
+
+func TestWatcherOnError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	watcher := NewWatcher(NewClient(server.URL, "token"), time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	var failures int
+	watcher.OnError = func(err error) {
+		if failures++; failures == 3 {
+			cancel()
+		}
+	}
+	if err := watcher.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the watcher to keep polling until canceled, got %v", err)
+	}
+}
+
+func TestWatcherClientClosed(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	watcher := NewWatcher(client, time.Millisecond)
+	var failures int
+	watcher.OnError = func(err error) { failures++ }
+	changes := watcher.Subscribe(0)
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := watcher.Run(ctx); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected the watcher to stop with the client, got %v", err)
+	}
+	if failures != 0 {
+		t.Errorf("expected no errors to be reported, got %d", failures)
+	}
+	if _, open := <-changes; open {
+		t.Error("expected the subscription to be closed")
+	}
+}
+
+func TestWatcherPollUnlocked(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("<stateList/>"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	watcher := NewWatcher(NewClient(server.URL, "token"), time.Second)
+	go watcher.Poll(time.Now())
+	<-started
+
+	registered := make(chan struct{})
+	go func() {
+		watcher.OnChange(func(DataPointChange) {})
+		watcher.Subscribe(1)
+		close(registered)
+	}()
+	select {
+	case <-registered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected handlers to be registered while a poll is in flight")
+	}
+}
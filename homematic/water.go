@@ -0,0 +1,170 @@
+package homematic
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WaterAlarm aggregates water and moisture sensors (HmIP-SWD, HM-Sec-WDS)
+// into a single alarm state and can shut off valves or switches, e.g. the
+// main water valve or a washing machine, when any sensor detects water
+type WaterAlarm struct {
+	Client *Client
+
+	// DeviceIDs restricts the alarm to the given sensors; by default all
+	// devices with MOISTURE_DETECTED or WATERLEVEL_DETECTED data points
+	// contribute. HM-Sec-WDS sensors, which report water in an enum STATE,
+	// are only recognized when listed here.
+	DeviceIDs []string
+
+	// Shutoff are the ise_ids of the valve or switch data points (e.g. the
+	// STATE of a switch actuator) that are set to false when the alarm triggers
+	Shutoff []string
+
+	// OnAlarm is called when the aggregated alarm state changes, after the
+	// shutoff data points have been switched off
+	OnAlarm func(event WaterAlarmEvent)
+
+	mu     sync.Mutex
+	wet    map[string]DataPointChange
+	active bool
+}
+
+// WaterSensorState is the state of a single water sensor
+type WaterSensorState struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Wet      bool   `json:"wet"`
+}
+
+// WaterAlarmStatus is the aggregated state of all water sensors
+type WaterAlarmStatus struct {
+	Alarm   bool               `json:"alarm"`
+	Sensors []WaterSensorState `json:"sensors"`
+}
+
+// WaterAlarmEvent reports a change of the aggregated alarm state
+type WaterAlarmEvent struct {
+	Alarm bool      `json:"alarm"`
+	At    time.Time `json:"at"`
+	// Wet lists the data point changes of all sensors currently detecting water
+	Wet []DataPointChange `json:"wet,omitempty"`
+	// ShutoffErr is the error of switching off the shutoff data points, if any
+	ShutoffErr error `json:"-"`
+}
+
+// NewWaterAlarm creates a water alarm switching off the given data points when it triggers
+func NewWaterAlarm(client *Client, shutoff ...string) *WaterAlarm {
+	return &WaterAlarm{Client: client, Shutoff: shutoff}
+}
+
+// Status reads the state of all water sensors
+func (w *WaterAlarm) Status() (*WaterAlarmStatus, error) {
+	devices, err := w.Client.GetStateList("", false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &WaterAlarmStatus{Sensors: []WaterSensorState{}}
+	for _, device := range devices {
+		sensor := WaterSensorState{DeviceID: device.IseID, Name: device.Name}
+		contributes := false
+		for _, ch := range device.Channels {
+			for _, dp := range ch.DataPoints {
+				if w.isWaterDataPoint(device.IseID, dataPointType(dp), dp.ValueType) {
+					contributes = true
+					sensor.Wet = sensor.Wet || waterDetected(dp.Value)
+				}
+			}
+		}
+		if contributes {
+			status.Alarm = status.Alarm || sensor.Wet
+			status.Sensors = append(status.Sensors, sensor)
+		}
+	}
+	return status, nil
+}
+
+// Observe processes a data point change. When the first sensor detects
+// water the shutoff data points are switched off and OnAlarm is called; when
+// all sensors are dry again OnAlarm is called with Alarm false.
+func (w *WaterAlarm) Observe(change DataPointChange) {
+	if !w.isWaterDataPoint(change.DeviceIseID, change.Type, change.ValueType) {
+		return
+	}
+
+	w.mu.Lock()
+	if w.wet == nil {
+		w.wet = make(map[string]DataPointChange)
+	}
+	if waterDetected(change.NewValue) {
+		w.wet[change.IseID] = change
+	} else {
+		delete(w.wet, change.IseID)
+	}
+	active := len(w.wet) > 0
+	changed := active != w.active
+	w.active = active
+	event := WaterAlarmEvent{Alarm: active, At: change.ObservedAt}
+	for _, c := range w.wet {
+		event.Wet = append(event.Wet, c)
+	}
+	w.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	sort.Slice(event.Wet, func(i, j int) bool { return event.Wet[i].IseID < event.Wet[j].IseID })
+	if active {
+		event.ShutoffErr = w.ShutOff()
+	}
+	if w.OnAlarm != nil {
+		w.OnAlarm(event)
+	}
+}
+
+// ShutOff sets all shutoff data points to false
+func (w *WaterAlarm) ShutOff() error {
+	if len(w.Shutoff) == 0 {
+		return nil
+	}
+	values := make([]string, len(w.Shutoff))
+	for i := range values {
+		values[i] = "false"
+	}
+	return w.Client.ChangeState(w.Shutoff, values)
+}
+
+// isWaterDataPoint reports whether a data point reports water of a contributing sensor
+func (w *WaterAlarm) isWaterDataPoint(deviceID, dpType string, valueType int) bool {
+	listed := false
+	for _, id := range w.DeviceIDs {
+		if id == deviceID {
+			listed = true
+			break
+		}
+	}
+	if len(w.DeviceIDs) > 0 && !listed {
+		return false
+	}
+
+	switch dpType {
+	case "MOISTURE_DETECTED", "WATERLEVEL_DETECTED":
+		return true
+	case "STATE":
+		// HM-Sec-WDS: 0 dry, 1 wet, 2 water
+		return listed && valueType == regaValueTypeInteger
+	}
+	return false
+}
+
+// waterDetected interprets the value of a water data point
+func waterDetected(value string) bool {
+	switch strings.TrimSpace(value) {
+	case "1", "2":
+		return true
+	}
+	return parseFlag(value)
+}
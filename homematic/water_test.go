@@ -0,0 +1,91 @@
+package homematic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waterCCU serves a HmIP-SWD, a HM-Sec-WDS and a switch actuator
+func waterCCU(changes *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/addons/xmlapi/statechange.cgi" {
+			*changes = append(*changes, r.URL.Query().Get("ise_id")+"="+r.URL.Query().Get("new_value"))
+			writeChanged(w, r)
+			return
+		}
+		w.Write([]byte(`<stateList>
+			<device name="Wassermelder Bad" ise_id="100"><channel ise_id="101" index="1">
+				<datapoint type="ALARMSTATE" ise_id="102" value="false" valuetype="2"/>
+				<datapoint type="MOISTURE_DETECTED" ise_id="103" value="false" valuetype="2"/>
+				<datapoint type="WATERLEVEL_DETECTED" ise_id="104" value="false" valuetype="2"/>
+			</channel></device>
+			<device name="Wassermelder Keller" ise_id="200"><channel ise_id="201" index="1">
+				<datapoint type="STATE" ise_id="202" value="2" valuetype="16"/>
+			</channel></device>
+			<device name="Hauptventil" ise_id="300"><channel ise_id="301" index="1">
+				<datapoint type="STATE" ise_id="302" value="true" valuetype="2"/>
+			</channel></device>
+		</stateList>`))
+	})
+}
+
+func TestWaterAlarmStatus(t *testing.T) {
+	var changes []string
+	server := httptest.NewServer(waterCCU(&changes))
+	defer server.Close()
+
+	alarm := NewWaterAlarm(NewClient(server.URL, "token"), "302")
+	status, err := alarm.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	// HM-Sec-WDS sensors are only recognized when listed
+	if status.Alarm || len(status.Sensors) != 1 || status.Sensors[0].Name != "Wassermelder Bad" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	alarm.DeviceIDs = []string{"100", "200"}
+	status, err = alarm.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !status.Alarm || len(status.Sensors) != 2 || status.Sensors[0].Wet || !status.Sensors[1].Wet {
+		t.Errorf("expected the HM-Sec-WDS to report water, got %+v", status)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected Status not to switch anything, got %v", changes)
+	}
+}
+
+func TestWaterAlarmShutoff(t *testing.T) {
+	var changes []string
+	server := httptest.NewServer(waterCCU(&changes))
+	defer server.Close()
+
+	var events []WaterAlarmEvent
+	alarm := NewWaterAlarm(NewClient(server.URL, "token"), "302")
+	alarm.OnAlarm = func(event WaterAlarmEvent) { events = append(events, event) }
+
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	alarm.Observe(DataPointChange{DeviceIseID: "100", IseID: "102", Type: "ALARMSTATE", NewValue: "true", ObservedAt: at})
+	alarm.Observe(DataPointChange{DeviceIseID: "100", IseID: "103", Type: "MOISTURE_DETECTED", NewValue: "true", ObservedAt: at})
+	alarm.Observe(DataPointChange{DeviceIseID: "100", IseID: "104", Type: "WATERLEVEL_DETECTED", NewValue: "true", ObservedAt: at})
+	alarm.Observe(DataPointChange{DeviceIseID: "100", IseID: "103", Type: "MOISTURE_DETECTED", NewValue: "false", ObservedAt: at})
+	alarm.Observe(DataPointChange{DeviceIseID: "100", IseID: "104", Type: "WATERLEVEL_DETECTED", NewValue: "false", ObservedAt: at.Add(time.Hour)})
+
+	if strings.Join(changes, " ") != "302=false" {
+		t.Errorf("expected the valve to be shut off once, got %v", changes)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected an alarm and an all clear event, got %+v", events)
+	}
+	if !events[0].Alarm || len(events[0].Wet) != 1 || events[0].Wet[0].IseID != "103" || events[0].ShutoffErr != nil {
+		t.Errorf("unexpected alarm event: %+v", events[0])
+	}
+	if events[1].Alarm || len(events[1].Wet) != 0 || !events[1].At.Equal(at.Add(time.Hour)) {
+		t.Errorf("unexpected all clear event: %+v", events[1])
+	}
+}
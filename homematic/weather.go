@@ -0,0 +1,160 @@
+package homematic
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WeatherReading combines the sensor values of a weather station; values the
+// station does not measure are nil
+type WeatherReading struct {
+	Device  string `json:"device"`
+	Address string `json:"address,omitempty"`
+	Type    string `json:"type,omitempty"`
+
+	Temperature *float64 `json:"temperature,omitempty"` // °C
+	Humidity    *float64 `json:"humidity,omitempty"`    // %
+	WindSpeed   *float64 `json:"wind_speed,omitempty"`  // km/h
+	// WindDirection is in degrees, WindDirectionRange the fluctuation of the direction
+	WindDirection      *float64 `json:"wind_direction,omitempty"`
+	WindDirectionRange *float64 `json:"wind_direction_range,omitempty"`
+	Raining            *bool    `json:"raining,omitempty"`
+	// Brightness is the illumination in lux for HmIP stations and a relative
+	// value from 0 to 255 for BidCos stations
+	Brightness *float64 `json:"brightness,omitempty"`
+
+	// RainCounter and SunshineCounter are the raw counters of the station in
+	// mm and minutes, which wrap around or reset; RainTotal and SunshineTotal
+	// accumulate them since the first reading of the WeatherStation
+	RainCounter     *float64 `json:"rain_counter,omitempty"`
+	RainTotal       float64  `json:"rain_total"`
+	SunshineCounter *float64 `json:"sunshine_counter,omitempty"`
+	SunshineTotal   float64  `json:"sunshine_total"`
+
+	At time.Time `json:"at"`
+}
+
+// weatherStationTypes are the device type prefixes of weather stations
+var weatherStationTypes = []string{"HmIP-SWO", "HM-WDS100"}
+
+// IsWeatherStation reports whether a device type is a weather station
+// (HmIP-SWO-B, -PL and -PR or HM-WDS100-C6-O)
+func IsWeatherStation(deviceType string) bool {
+	for _, prefix := range weatherStationTypes {
+		if strings.HasPrefix(deviceType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WeatherStation reads the composite values of a weather station and
+// accumulates its rain and sunshine counters across overflows
+type WeatherStation struct {
+	Client   *Client
+	DeviceID string
+
+	// RainCounterMax and SunshineCounterMax are the values the counters wrap
+	// around at, as configured on the device. If zero, a decreasing counter is
+	// taken as a reset to zero.
+	RainCounterMax     float64
+	SunshineCounterMax float64
+
+	mu       sync.Mutex
+	rain     counterTotal
+	sunshine counterTotal
+}
+
+// counterTotal accumulates the increments of a counter that wraps around or resets
+type counterTotal struct {
+	last  float64
+	known bool
+	total float64
+}
+
+// add adds the increment of the counter since the previous value and returns it
+func (c *counterTotal) add(value, wrap float64) float64 {
+	var increment float64
+	switch {
+	case !c.known:
+	case value >= c.last:
+		increment = value - c.last
+	case wrap > 0 && c.last <= wrap:
+		increment = wrap - c.last + value
+	default:
+		increment = value
+	}
+	c.total += increment
+	c.last = value
+	c.known = true
+	return increment
+}
+
+// NewWeatherStation creates a weather station for the device with the given ise_id
+func NewWeatherStation(client *Client, deviceID string) *WeatherStation {
+	return &WeatherStation{Client: client, DeviceID: deviceID}
+}
+
+// Read reads the current values of the station
+func (s *WeatherStation) Read() (*WeatherReading, error) {
+	devices, err := s.Client.GetState([]string{s.DeviceID}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range devices {
+		if devices[i].IseID == s.DeviceID {
+			return s.Observe(&devices[i], time.Now()), nil
+		}
+	}
+	return nil, fmt.Errorf("weather station %s not found", s.DeviceID)
+}
+
+// Observe combines the data points of a device read with its state into a
+// reading and accumulates its counters
+func (s *WeatherStation) Observe(device *Device, at time.Time) *WeatherReading {
+	reading := &WeatherReading{Device: device.Name, Address: device.Address, Type: device.DeviceType, At: at}
+	for _, ch := range device.Channels {
+		for _, dp := range ch.DataPoints {
+			value, ok := numericValue(dp.Value)
+			if !ok {
+				continue
+			}
+			switch dataPointType(dp) {
+			case "ACTUAL_TEMPERATURE", "TEMPERATURE":
+				reading.Temperature = &value
+			case "HUMIDITY":
+				reading.Humidity = &value
+			case "WIND_SPEED":
+				reading.WindSpeed = &value
+			case "WIND_DIR", "WIND_DIRECTION":
+				reading.WindDirection = &value
+			case "WIND_DIR_RANGE", "WIND_DIRECTION_RANGE":
+				reading.WindDirectionRange = &value
+			case "RAINING":
+				raining := value != 0
+				reading.Raining = &raining
+			case "ILLUMINATION", "BRIGHTNESS":
+				reading.Brightness = &value
+			case "RAIN_COUNTER":
+				reading.RainCounter = &value
+			case "SUNSHINEDURATION":
+				reading.SunshineCounter = &value
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reading.RainCounter != nil {
+		s.rain.add(*reading.RainCounter, s.RainCounterMax)
+	}
+	if reading.SunshineCounter != nil {
+		s.sunshine.add(*reading.SunshineCounter, s.SunshineCounterMax)
+	}
+	reading.RainTotal = s.rain.total
+	reading.SunshineTotal = s.sunshine.total
+	return reading
+}
@@ -0,0 +1,81 @@
+package homematic
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func weatherDevice(rain, sunshine string) *Device {
+	return &Device{Name: "Wetterstation", Address: "00201D89A00001", IseID: "5000", DeviceType: "HmIP-SWO-PR", Channels: []Channel{
+		{Name: "Wetterstation:0", Index: 0, DataPoints: []DataPoint{{Type: "LOW_BAT", Value: "false"}}},
+		{Name: "Wetterstation:1", Index: 1, DataPoints: []DataPoint{
+			{Type: "ACTUAL_TEMPERATURE", Value: "12.3"},
+			{Type: "HUMIDITY", Value: "78"},
+			{Type: "ILLUMINATION", Value: "4512.5"},
+			{Type: "RAINING", Value: "true"},
+			{Type: "RAIN_COUNTER", Value: rain},
+			{Type: "SUNSHINEDURATION", Value: sunshine},
+			{Type: "WIND_DIR", Value: "225"},
+			{Type: "WIND_DIR_RANGE", Value: "22.5"},
+			{Type: "WIND_SPEED", Value: "14.8"},
+		}},
+	}}
+}
+
+func TestWeatherStationObserve(t *testing.T) {
+	station := NewWeatherStation(nil, "5000")
+	station.RainCounterMax = 1000
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	reading := station.Observe(weatherDevice("995.5", "100"), at)
+	if *reading.Temperature != 12.3 || *reading.Humidity != 78 || *reading.Brightness != 4512.5 || !*reading.Raining ||
+		*reading.WindDirection != 225 || *reading.WindDirectionRange != 22.5 || *reading.WindSpeed != 14.8 {
+		t.Errorf("unexpected reading %+v", reading)
+	}
+	if reading.RainTotal != 0 || reading.SunshineTotal != 0 {
+		t.Errorf("expected the totals to start at zero, got %+v", reading)
+	}
+
+	reading = station.Observe(weatherDevice("999", "130"), at.Add(time.Hour))
+	if reading.RainTotal != 3.5 || reading.SunshineTotal != 30 {
+		t.Errorf("expected 3.5 mm and 30 minutes, got %v and %v", reading.RainTotal, reading.SunshineTotal)
+	}
+
+	// the rain counter wraps around at its maximum, the sunshine counter was reset
+	reading = station.Observe(weatherDevice("2", "5"), at.Add(2*time.Hour))
+	if math.Abs(reading.RainTotal-6.5) > 1e-9 || reading.SunshineTotal != 35 {
+		t.Errorf("expected 6.5 mm and 35 minutes after the overflow, got %v and %v", reading.RainTotal, reading.SunshineTotal)
+	}
+	if *reading.RainCounter != 2 {
+		t.Errorf("expected the raw counter, got %v", *reading.RainCounter)
+	}
+}
+
+func TestWeatherStationRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("device_id") != "5001" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `<stateList><device name="Kombisensor" ise_id="5001" device_type="HM-WDS100-C6-O"><channel ise_id="5002">`+
+			`<datapoint type="TEMPERATURE" ise_id="5003" value="-2.5"/><datapoint type="BRIGHTNESS" ise_id="5004" value="120"/>`+
+			`<datapoint type="WIND_DIRECTION" ise_id="5005" value="90"/><datapoint type="RAINING" ise_id="5006" value="false"/>`+
+			`</channel></device></stateList>`)
+	}))
+	defer server.Close()
+
+	reading, err := NewWeatherStation(NewClient(server.URL, "token"), "5001").Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if *reading.Temperature != -2.5 || *reading.Brightness != 120 || *reading.WindDirection != 90 || *reading.Raining ||
+		reading.Humidity != nil || reading.RainCounter != nil {
+		t.Errorf("unexpected reading %+v", reading)
+	}
+	if !IsWeatherStation("HM-WDS100-C6-O") || IsWeatherStation("HM-WDS40-TH-I-2") {
+		t.Error("unexpected weather station classification")
+	}
+}
@@ -0,0 +1,147 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrLockLost is reported when a held write lock could not be extended, so
+// the write it protects may overlap with writes of other clients
+var ErrLockLost = errors.New("write lock lost")
+
+// WriteLocker coordinates mutating calls of several clients, e.g. replicas of
+// an automation service, so they don't change the same actuator at the same time
+type WriteLocker interface {
+	// Lock acquires the locks of all keys and returns a function releasing them
+	Lock(ctx context.Context, keys []string) (unlock func(), err error)
+}
+
+// lockWrites acquires the write locks of the given keys if a WriteLocker is
+// configured, waiting at most until ctx is done
+func (c *Client) lockWrites(ctx context.Context, keys []string) (func(), error) {
+	if c.WriteLock == nil {
+		return func() {}, nil
+	}
+
+	// sorted, unique keys make concurrent lockers acquire them in the same order
+	unique := make(map[string]bool, len(keys))
+	sorted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !unique[key] {
+			unique[key] = true
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Strings(sorted)
+
+	unlock, err := c.WriteLock.Lock(ctx, sorted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire write lock: %w", err)
+	}
+	return unlock, nil
+}
+
+// keepAlive calls extend every interval until the returned function is
+// called; if extend fails, onLost is called and the locks are no longer extended
+func keepAlive(interval time.Duration, extend func() error, onLost func(err error)) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if err := extend(); err != nil {
+				if onLost != nil {
+					onLost(err)
+				}
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// dataPointLockKeys returns the write lock keys of data points
+func dataPointLockKeys(iseIDs []string) []string {
+	keys := make([]string, len(iseIDs))
+	for i, id := range iseIDs {
+		keys[i] = "datapoint:" + id
+	}
+	return keys
+}
+
+// programLockKeys returns the write lock key of a program
+func programLockKeys(programID string) []string {
+	return []string{"program:" + programID}
+}
+
+// masterValueLockKeys returns the write lock keys of master values
+func masterValueLockKeys(deviceIDs, names []string) []string {
+	keys := make([]string, len(deviceIDs))
+	for i := range deviceIDs {
+		keys[i] = "master:" + deviceIDs[i] + ":" + names[i]
+	}
+	return keys
+}
+
+// LocalWriteLocker is a WriteLocker for clients within a single process
+type LocalWriteLocker struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+// NewLocalWriteLocker creates an in-process write locker
+func NewLocalWriteLocker() *LocalWriteLocker {
+	return &LocalWriteLocker{locks: make(map[string]chan struct{})}
+}
+
+// Lock acquires the locks of all keys, waiting until they are released or ctx is done
+func (l *LocalWriteLocker) Lock(ctx context.Context, keys []string) (func(), error) {
+	acquired := make([]string, 0, len(keys))
+	release := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for _, key := range acquired {
+			close(l.locks[key])
+			delete(l.locks, key)
+		}
+	}
+
+	for _, key := range keys {
+		for {
+			l.mu.Lock()
+			held, ok := l.locks[key]
+			if !ok {
+				l.locks[key] = make(chan struct{})
+				l.mu.Unlock()
+				acquired = append(acquired, key)
+				break
+			}
+			l.mu.Unlock()
+
+			select {
+			case <-held:
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	var once sync.Once
+	return func() { once.Do(release) }, nil
+}
@@ -0,0 +1,169 @@
+package homematic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingLocker records the keys of Lock calls
+type recordingLocker struct {
+	keys     [][]string
+	unlocked int
+}
+
+func (l *recordingLocker) Lock(ctx context.Context, keys []string) (func(), error) {
+	l.keys = append(l.keys, keys)
+	return func() { l.unlocked++ }, nil
+}
+
+func TestChangeStateAcquiresWriteLocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "statechange.cgi") {
+			writeChanged(w, r)
+			return
+		}
+		w.Write([]byte(`<result><changed id="1" new_value="1"/></result>`))
+	}))
+	defer server.Close()
+
+	locker := &recordingLocker{}
+	client := NewClient(server.URL, "token")
+	client.WriteLock = locker
+
+	if err := client.ChangeState([]string{"1252", "1251", "1252"}, []string{"1", "2", "3"}); err != nil {
+		t.Fatalf("ChangeState failed: %v", err)
+	}
+	if err := client.ChangeMasterValue([]string{"1234"}, []string{"TEMPERATURE_OFFSET"}, []string{"1.0"}); err != nil {
+		t.Fatalf("ChangeMasterValue failed: %v", err)
+	}
+
+	if err := client.RunProgram("2494", false); err != nil {
+		t.Fatalf("RunProgram failed: %v", err)
+	}
+	active := false
+	if err := client.ChangeProgramActions("2494", &active, nil); err != nil {
+		t.Fatalf("ChangeProgramActions failed: %v", err)
+	}
+
+	if len(locker.keys) != 4 || strings.Join(locker.keys[0], ",") != "datapoint:1251,datapoint:1252" ||
+		strings.Join(locker.keys[1], ",") != "master:1234:TEMPERATURE_OFFSET" ||
+		strings.Join(locker.keys[2], ",") != "program:2494" || strings.Join(locker.keys[3], ",") != "program:2494" {
+		t.Errorf("unexpected lock keys: %v", locker.keys)
+	}
+	if locker.unlocked != 4 {
+		t.Errorf("expected locks to be released, got %d unlocks", locker.unlocked)
+	}
+
+	client.DryRun = true
+	client.ChangeState([]string{"1251"}, []string{"1"})
+	client.RunProgram("2494", false)
+	if len(locker.keys) != 4 {
+		t.Error("dry runs should not acquire locks")
+	}
+}
+
+func TestLocalWriteLocker(t *testing.T) {
+	locker := NewLocalWriteLocker()
+
+	unlock, err := locker.Lock(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := locker.Lock(ctx, []string{"b"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected held lock to time out, got %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock, err := locker.Lock(context.Background(), []string{"a"})
+		if err == nil {
+			unlock()
+		}
+		close(acquired)
+	}()
+
+	unlock()
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("waiting locker did not acquire the released lock")
+	}
+}
+
+func TestWriteLockSerializesConcurrentWrites(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		writeChanged(w, r)
+	}))
+	defer server.Close()
+
+	locker := NewLocalWriteLocker()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		client := NewClient(server.URL, "token")
+		client.WriteLock = locker
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.ChangeState([]string{"1251"}, []string{"1"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxActive != 1 {
+		t.Errorf("expected writes to the same data point to be serialized, got %d concurrent", maxActive)
+	}
+}
+
+func TestChangeStatesContextCancelsLockWait(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`<result><changed id="1251" new_value="1"/></result>`))
+	}))
+	defer server.Close()
+
+	locker := NewLocalWriteLocker()
+	unlock, err := locker.Lock(context.Background(), []string{"datapoint:1251"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	client := NewClient(server.URL, "token")
+	client.WriteLock = locker
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.ChangeStatesContext(ctx, []string{"1251"}, []string{"1"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the lock wait to end with the context, got %v", err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.ChangeMasterValueContext(ctx, []string{"1251"}, []string{"NAME"}, []string{"1"}); err != nil {
+		t.Errorf("expected master values to use other locks, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected only the master value change to be sent, got %d requests", requests)
+	}
+}
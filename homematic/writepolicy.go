@@ -0,0 +1,159 @@
+package homematic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrWriteNotAllowed is returned for state and master value changes and
+// program calls rejected by the WritePolicy
+var ErrWriteNotAllowed = errors.New("write not allowed")
+
+// WritePolicy restricts the data points, system variables, devices and
+// programs a client may change, as a safety net for automations running with
+// a token that can write everything. An ise_id matches a data point, its
+// channel or its device; rooms match the data points of their channels.
+// Programs are matched by their own ise_id only.
+type WritePolicy struct {
+	// Allow and AllowRooms list the ise_ids and room names that may be
+	// written; if both are empty everything that is not denied is allowed
+	Allow      []string
+	AllowRooms []string
+
+	// Deny and DenyRooms list the ise_ids and room names that must not be
+	// written; they take precedence over Allow and AllowRooms
+	Deny      []string
+	DenyRooms []string
+
+	mu sync.Mutex
+	// parents maps data point and channel ise_ids to their channel and device,
+	// rooms maps channel ise_ids to room names; both are loaded on first use
+	parents map[string][]string
+	rooms   map[string][]string
+}
+
+// SetTopology sets the data points (from a state list) and rooms the policy
+// resolves ise_ids and room names with; otherwise the client loads them with
+// the first checked write
+func (p *WritePolicy) SetTopology(devices []Device, rooms []Room) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.setTopology(devices, rooms)
+}
+
+func (p *WritePolicy) setTopology(devices []Device, rooms []Room) {
+	p.parents = dataPointParents(devices)
+	p.rooms = make(map[string][]string)
+	for _, room := range rooms {
+		for _, ch := range room.Channels {
+			p.rooms[ch.IseID] = append(p.rooms[ch.IseID], room.Name)
+		}
+	}
+}
+
+// invalidate drops the topology so that it is loaded again with the next checked write
+func (p *WritePolicy) invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.parents, p.rooms = nil, nil
+}
+
+// Allowed reports whether the data point, system variable or device may be written
+func (p *WritePolicy) Allowed(iseID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.allowed(iseID)
+}
+
+func (p *WritePolicy) allowed(iseID string) bool {
+	ids := append([]string{iseID}, p.parents[iseID]...)
+	var rooms []string
+	for _, id := range ids {
+		rooms = append(rooms, p.rooms[id]...)
+	}
+
+	if containsAny(p.Deny, ids) || containsAnyFold(p.DenyRooms, rooms) {
+		return false
+	}
+	if len(p.Allow) == 0 && len(p.AllowRooms) == 0 {
+		return true
+	}
+	return containsAny(p.Allow, ids) || containsAnyFold(p.AllowRooms, rooms)
+}
+
+// check returns an ErrWriteNotAllowed error naming the first rejected ise_id
+func (p *WritePolicy) check(c *Client, iseIDs []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.parents == nil && (len(p.AllowRooms) > 0 || len(p.DenyRooms) > 0 || len(p.Allow) > 0 || len(p.Deny) > 0) {
+		devices, err := c.GetStateList("", true, false)
+		if err != nil {
+			return fmt.Errorf("failed to load write policy topology: %w", err)
+		}
+		var rooms []Room
+		if len(p.AllowRooms) > 0 || len(p.DenyRooms) > 0 {
+			if rooms, err = c.GetRoomList(); err != nil {
+				return fmt.Errorf("failed to load write policy topology: %w", err)
+			}
+		}
+		p.setTopology(devices, rooms)
+	}
+
+	for _, id := range iseIDs {
+		if !p.allowed(id) {
+			return fmt.Errorf("%w: %s", ErrWriteNotAllowed, id)
+		}
+	}
+	return nil
+}
+
+// dataPointParents maps the data point and channel ise_ids of a state list to
+// their channel and device
+func dataPointParents(devices []Device) map[string][]string {
+	parents := make(map[string][]string)
+	for _, device := range devices {
+		for _, ch := range device.Channels {
+			parents[ch.IseID] = []string{device.IseID}
+			for _, dp := range ch.DataPoints {
+				parents[dp.IseID] = []string{ch.IseID, device.IseID}
+			}
+		}
+	}
+	return parents
+}
+
+// checkWrites applies the WritePolicy of the client, if any
+func (c *Client) checkWrites(iseIDs []string) error {
+	if c.WritePolicy == nil {
+		return nil
+	}
+	return c.WritePolicy.check(c, iseIDs)
+}
+
+func containsAny(list, values []string) bool {
+	for _, v := range values {
+		for _, item := range list {
+			if item == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsAnyFold(list, values []string) bool {
+	for _, v := range values {
+		for _, item := range list {
+			if strings.EqualFold(item, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
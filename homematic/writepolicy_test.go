@@ -0,0 +1,111 @@
+package homematic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+func TestWritePolicy(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	var writes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "change.cgi") {
+			writes = append(writes, r.URL.Query().Get("ise_id")+r.URL.Query().Get("device_id"))
+		}
+		mock.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	client.WritePolicy = &WritePolicy{
+		Allow:      []string{"2430"},
+		AllowRooms: []string{"wohnzimmer"},
+		Deny:       []string{"2443"},
+	}
+
+	// the dimmer level is in an allowed room, the switch state belongs to an allowed device
+	if err := client.ChangeState([]string{"2456", "2445"}, []string{"0.5", "true"}); err != nil {
+		t.Fatalf("expected allowed writes to succeed: %v", err)
+	}
+
+	rejected := map[string]func() error{
+		"denied data point of an allowed device": func() error {
+			return client.ChangeState([]string{"2443"}, []string{"true"})
+		},
+		"data point outside the allowed rooms and devices": func() error {
+			return client.ChangeState([]string{"2456", "2413"}, []string{"0.5", "true"})
+		},
+		"master value of a device that is not allowed": func() error {
+			return client.ChangeMasterValue([]string{"2401"}, []string{"TEMPERATURE_OFFSET"}, []string{"1.0"})
+		},
+	}
+	for name, call := range rejected {
+		if err := call(); !errors.Is(err, ErrWriteNotAllowed) {
+			t.Errorf("%s: expected ErrWriteNotAllowed, got %v", name, err)
+		}
+	}
+	if strings.Join(writes, " ") != "2456,2445" {
+		t.Errorf("expected only the allowed write to be sent, got %v", writes)
+	}
+
+	client.DryRun = true
+	if err := client.ChangeState([]string{"2443"}, []string{"true"}); !errors.Is(err, ErrWriteNotAllowed) {
+		t.Errorf("expected the policy to apply in dry run, got %v", err)
+	}
+}
+
+func TestWritePolicyDenyRooms(t *testing.T) {
+	policy := &WritePolicy{DenyRooms: []string{"Flur"}}
+	policy.SetTopology(
+		[]Device{{IseID: "10", Channels: []Channel{{IseID: "11", DataPoints: []DataPoint{{IseID: "12"}}}}}},
+		[]Room{{Name: "Flur", Channels: []Channel{{IseID: "11"}}}},
+	)
+	if policy.Allowed("12") {
+		t.Error("expected data points of a denied room to be rejected")
+	}
+	if !policy.Allowed("950") {
+		t.Error("expected system variables to be allowed without an allow list")
+	}
+}
+
+func TestWritePolicyPrograms(t *testing.T) {
+	mock, err := fixtures.NewServer(fixtures.CCU3)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "program") && !strings.HasSuffix(r.URL.Path, "programlist.cgi") {
+			calls = append(calls, r.URL.Query().Get("program_id"))
+			w.Write([]byte(`<result><started program_id="` + r.URL.Query().Get("program_id") + `"/></result>`))
+			return
+		}
+		mock.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	client.WritePolicy = &WritePolicy{Deny: []string{"2496"}}
+
+	if err := client.RunProgram("2494", false); err != nil {
+		t.Fatalf("expected the allowed program to run: %v", err)
+	}
+	visible := false
+	if err := client.RunProgram("2496", false); !errors.Is(err, ErrWriteNotAllowed) {
+		t.Errorf("expected ErrWriteNotAllowed for RunProgram, got %v", err)
+	}
+	if err := client.ChangeProgramActions("2496", nil, &visible); !errors.Is(err, ErrWriteNotAllowed) {
+		t.Errorf("expected ErrWriteNotAllowed for ChangeProgramActions, got %v", err)
+	}
+	if strings.Join(calls, " ") != "2494" {
+		t.Errorf("expected only the allowed program call to be sent, got %v", calls)
+	}
+}
@@ -0,0 +1,6 @@
+// Package homematicmock contains a mock of homematic.HomematicAPI for unit
+// testing automations without a CCU. Every method calls the function of the
+// same name with a Func suffix and records its arguments.
+package homematicmock
+
+//go:generate go run github.com/matryer/moq@v0.5.3 -out mock.go -pkg homematicmock ../homematic HomematicAPI
@@ -0,0 +1,60 @@
+package homematicmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// switchOffAll is an automation under test, switching off the given data points
+func switchOffAll(api homematic.HomematicAPI, ids []string) error {
+	values := make([]string, len(ids))
+	for i := range values {
+		values[i] = "false"
+	}
+	return api.ChangeState(ids, values)
+}
+
+func TestHomematicAPIMock(t *testing.T) {
+	mock := &HomematicAPIMock{
+		ChangeStateFunc: func(deviceIDs, newValues []string) error {
+			if len(deviceIDs) > 2 {
+				return errors.New("too many")
+			}
+			return nil
+		},
+	}
+
+	if err := switchOffAll(mock, []string{"2445", "2484"}); err != nil {
+		t.Fatalf("switchOffAll failed: %v", err)
+	}
+	if err := switchOffAll(mock, []string{"1", "2", "3"}); err == nil {
+		t.Error("expected the error of the mock")
+	}
+
+	calls := mock.ChangeStateCalls()
+	if len(calls) != 2 || calls[0].DeviceIDs[1] != "2484" || calls[0].NewValues[0] != "false" {
+		t.Errorf("unexpected calls %+v", calls)
+	}
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "automation")
+	mock.ChangeStatesContextFunc = func(ctx context.Context, deviceIDs, newValues []string) ([]homematic.ChangeResult, error) {
+		return nil, ctx.Err()
+	}
+	if _, err := mock.ChangeStatesContext(ctx, []string{"2445"}, []string{"true"}); err != nil {
+		t.Fatalf("ChangeStatesContext failed: %v", err)
+	}
+	if calls := mock.ChangeStatesContextCalls(); len(calls) != 1 || calls[0].Ctx.Value(key{}) != "automation" {
+		t.Errorf("expected the context to be recorded, got %+v", calls)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a method without function")
+		}
+	}()
+	mock.RunProgram("1001", false)
+}
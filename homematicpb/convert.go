@@ -0,0 +1,359 @@
+package homematicpb
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+)
+
+// FromTopology converts a topology
+func FromTopology(t *homematic.Topology) *Topology {
+	result := &Topology{}
+	for _, d := range t.Devices {
+		result.Devices = append(result.Devices, FromDevice(d))
+	}
+	for _, r := range t.Rooms {
+		result.Rooms = append(result.Rooms, &Room{IseId: r.IseID, Name: r.Name, ChannelIseIds: channelIDs(r.Channels)})
+	}
+	for _, f := range t.Functions {
+		result.Functions = append(result.Functions, &Function{IseId: f.IseID, Name: f.Name, ChannelIseIds: channelIDs(f.Channels)})
+	}
+	return result
+}
+
+// ToTopology converts a topology back; rooms and functions reference their channels by ise_id only
+func ToTopology(t *Topology) *homematic.Topology {
+	result := &homematic.Topology{}
+	for _, d := range t.GetDevices() {
+		result.Devices = append(result.Devices, ToDevice(d))
+	}
+	for _, r := range t.GetRooms() {
+		result.Rooms = append(result.Rooms, homematic.Room{IseID: r.GetIseId(), Name: r.GetName(), Channels: channelRefs(r.GetChannelIseIds())})
+	}
+	for _, f := range t.GetFunctions() {
+		result.Functions = append(result.Functions, homematic.Function{IseID: f.GetIseId(), Name: f.GetName(), Channels: channelRefs(f.GetChannelIseIds())})
+	}
+	return result
+}
+
+// FromDevice converts a device with its channels and data points
+func FromDevice(d homematic.Device) *Device {
+	result := &Device{
+		IseId:       d.IseID,
+		Name:        d.Name,
+		Address:     d.Address,
+		DeviceType:  d.DeviceType,
+		InterfaceId: d.InterfaceID,
+		Unreach:     d.Unreach,
+		Config:      d.Config,
+		Maintenance: FromMaintenanceInfo(d.Maintenance),
+	}
+	for _, ch := range d.Channels {
+		result.Channels = append(result.Channels, FromChannel(ch))
+	}
+	return result
+}
+
+// ToDevice converts a device back
+func ToDevice(d *Device) homematic.Device {
+	result := homematic.Device{
+		IseID:       d.GetIseId(),
+		Name:        d.GetName(),
+		Address:     d.GetAddress(),
+		DeviceType:  d.GetDeviceType(),
+		InterfaceID: d.GetInterfaceId(),
+		Unreach:     d.GetUnreach(),
+		Config:      d.GetConfig(),
+		Maintenance: ToMaintenanceInfo(d.GetMaintenance()),
+	}
+	for _, ch := range d.GetChannels() {
+		result.Channels = append(result.Channels, ToChannel(ch))
+	}
+	return result
+}
+
+// FromChannel converts a channel with its data points
+func FromChannel(ch homematic.Channel) *Channel {
+	result := &Channel{
+		IseId:            ch.IseID,
+		Name:             ch.Name,
+		Type:             ch.Type,
+		Address:          ch.Address,
+		Index:            int32(ch.Index),
+		Direction:        ch.Direction,
+		ParentType:       ch.ParentType,
+		GroupPartner:     ch.GroupPartner,
+		AesAvailable:     ch.AESAvailable,
+		TransmissionMode: ch.Transmission,
+		Visible:          ch.Visible,
+		ReadyConfig:      ch.Ready,
+		Operate:          ch.Operate,
+	}
+	for _, dp := range ch.DataPoints {
+		result.DataPoints = append(result.DataPoints, FromDataPoint(dp))
+	}
+	return result
+}
+
+// ToChannel converts a channel back
+func ToChannel(ch *Channel) homematic.Channel {
+	result := homematic.Channel{
+		IseID:        ch.GetIseId(),
+		Name:         ch.GetName(),
+		Type:         ch.GetType(),
+		Address:      ch.GetAddress(),
+		Index:        int(ch.GetIndex()),
+		Direction:    ch.GetDirection(),
+		ParentType:   ch.GetParentType(),
+		GroupPartner: ch.GetGroupPartner(),
+		AESAvailable: ch.GetAesAvailable(),
+		Transmission: ch.GetTransmissionMode(),
+		Visible:      ch.GetVisible(),
+		Ready:        ch.GetReadyConfig(),
+		Operate:      ch.GetOperate(),
+	}
+	for _, dp := range ch.GetDataPoints() {
+		result.DataPoints = append(result.DataPoints, ToDataPoint(dp))
+	}
+	return result
+}
+
+// FromDataPoint converts a data point
+func FromDataPoint(dp homematic.DataPoint) *DataPoint {
+	return &DataPoint{
+		IseId:     dp.IseID,
+		Name:      dp.Name,
+		Type:      dp.Type,
+		Value:     dp.Value,
+		ValueType: int32(dp.ValueType),
+		ValueUnit: dp.ValueUnit,
+		Timestamp: fromUnix(dp.Timestamp),
+	}
+}
+
+// ToDataPoint converts a data point back
+func ToDataPoint(dp *DataPoint) homematic.DataPoint {
+	return homematic.DataPoint{
+		IseID:     dp.GetIseId(),
+		Name:      dp.GetName(),
+		Type:      dp.GetType(),
+		Value:     dp.GetValue(),
+		ValueType: int(dp.GetValueType()),
+		ValueUnit: dp.GetValueUnit(),
+		Timestamp: toUnix(dp.GetTimestamp()),
+	}
+}
+
+// FromMaintenanceInfo converts maintenance info; nil stays nil
+func FromMaintenanceInfo(info *homematic.MaintenanceInfo) *MaintenanceInfo {
+	if info == nil {
+		return nil
+	}
+	result := &MaintenanceInfo{
+		Unreach:       info.Unreach,
+		LowBat:        info.LowBat,
+		ConfigPending: info.ConfigPending,
+		UpdatePending: info.UpdatePending,
+		DutyCycle:     info.DutyCycle,
+	}
+	if info.RSSIDevice != nil {
+		v := int32(*info.RSSIDevice)
+		result.RssiDevice = &v
+	}
+	if info.RSSIPeer != nil {
+		v := int32(*info.RSSIPeer)
+		result.RssiPeer = &v
+	}
+	return result
+}
+
+// ToMaintenanceInfo converts maintenance info back; nil stays nil
+func ToMaintenanceInfo(info *MaintenanceInfo) *homematic.MaintenanceInfo {
+	if info == nil {
+		return nil
+	}
+	result := &homematic.MaintenanceInfo{
+		Unreach:       info.GetUnreach(),
+		LowBat:        info.GetLowBat(),
+		ConfigPending: info.GetConfigPending(),
+		UpdatePending: info.GetUpdatePending(),
+		DutyCycle:     info.GetDutyCycle(),
+	}
+	if info.RssiDevice != nil {
+		v := int(info.GetRssiDevice())
+		result.RSSIDevice = &v
+	}
+	if info.RssiPeer != nil {
+		v := int(info.GetRssiPeer())
+		result.RSSIPeer = &v
+	}
+	return result
+}
+
+// FromProgram converts a program
+func FromProgram(p homematic.Program) *Program {
+	return &Program{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Info:        p.Info,
+		Visible:     p.Visible,
+		Active:      p.Active,
+		Timestamp:   fromUnix(p.Timestamp),
+	}
+}
+
+// ToProgram converts a program back
+func ToProgram(p *Program) homematic.Program {
+	return homematic.Program{
+		ID:          p.GetId(),
+		Name:        p.GetName(),
+		Description: p.GetDescription(),
+		Info:        p.GetInfo(),
+		Visible:     p.GetVisible(),
+		Active:      p.GetActive(),
+		Timestamp:   toUnix(p.GetTimestamp()),
+	}
+}
+
+// FromSystemVariable converts a system variable
+func FromSystemVariable(s homematic.SystemVariable) *SystemVariable {
+	return &SystemVariable{
+		IseId:       s.IseID,
+		Name:        s.Name,
+		Value:       s.Value,
+		ValueType:   int32(s.ValueType),
+		Type:        s.Type,
+		Subtype:     s.Subtype,
+		Min:         s.Min,
+		Max:         s.Max,
+		Unit:        s.Unit,
+		ValueName_0: s.ValueName0,
+		ValueName_1: s.ValueName1,
+		ValueList:   s.ValueList,
+		ValueText:   s.ValueText,
+		Logged:      s.Logged,
+		Visible:     s.Visible,
+		Timestamp:   fromUnix(s.Timestamp),
+	}
+}
+
+// ToSystemVariable converts a system variable back
+func ToSystemVariable(s *SystemVariable) homematic.SystemVariable {
+	return homematic.SystemVariable{
+		IseID:      s.GetIseId(),
+		Name:       s.GetName(),
+		Value:      s.GetValue(),
+		Variable:   s.GetValue(),
+		ValueType:  int(s.GetValueType()),
+		Type:       s.GetType(),
+		Subtype:    s.GetSubtype(),
+		Min:        s.GetMin(),
+		Max:        s.GetMax(),
+		Unit:       s.GetUnit(),
+		ValueName0: s.GetValueName_0(),
+		ValueName1: s.GetValueName_1(),
+		ValueList:  s.GetValueList(),
+		ValueText:  s.GetValueText(),
+		Logged:     s.GetLogged(),
+		Visible:    s.GetVisible(),
+		Timestamp:  toUnix(s.GetTimestamp()),
+	}
+}
+
+// FromDataPointChange converts a data point change event
+func FromDataPointChange(c homematic.DataPointChange) *DataPointChange {
+	return &DataPointChange{
+		DeviceIseId:   c.DeviceIseID,
+		DeviceName:    c.DeviceName,
+		ChannelIseId:  c.ChannelIseID,
+		ChannelName:   c.ChannelName,
+		IseId:         c.IseID,
+		Name:          c.Name,
+		Type:          c.Type,
+		ValueType:     int32(c.ValueType),
+		OldValue:      c.OldValue,
+		NewValue:      c.NewValue,
+		Timestamp:     fromUnix(c.Timestamp),
+		ObservedAt:    fromTime(c.ObservedAt),
+		FirstObserved: c.FirstObserved,
+	}
+}
+
+// ToDataPointChange converts a data point change event back
+func ToDataPointChange(c *DataPointChange) homematic.DataPointChange {
+	result := homematic.DataPointChange{
+		DeviceIseID:   c.GetDeviceIseId(),
+		DeviceName:    c.GetDeviceName(),
+		ChannelIseID:  c.GetChannelIseId(),
+		ChannelName:   c.GetChannelName(),
+		IseID:         c.GetIseId(),
+		Name:          c.GetName(),
+		Type:          c.GetType(),
+		ValueType:     int(c.GetValueType()),
+		OldValue:      c.GetOldValue(),
+		NewValue:      c.GetNewValue(),
+		Timestamp:     toUnix(c.GetTimestamp()),
+		FirstObserved: c.GetFirstObserved(),
+	}
+	if c.GetObservedAt() != nil {
+		result.ObservedAt = c.GetObservedAt().AsTime()
+	}
+	return result
+}
+
+// FromConnectivityEvent converts a connectivity event
+func FromConnectivityEvent(e homematic.ConnectivityEvent) *ConnectivityEvent {
+	result := &ConnectivityEvent{
+		Type: ConnectivityEvent_Type(e.Type),
+		Time: fromTime(e.Time),
+	}
+	if e.Err != nil {
+		result.Error = e.Err.Error()
+	}
+	return result
+}
+
+// channelIDs returns the ise_ids of channels
+func channelIDs(channels []homematic.Channel) []string {
+	ids := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		ids = append(ids, ch.IseID)
+	}
+	return ids
+}
+
+// channelRefs returns channels referenced by ise_id only
+func channelRefs(ids []string) []homematic.Channel {
+	channels := make([]homematic.Channel, 0, len(ids))
+	for _, id := range ids {
+		channels = append(channels, homematic.Channel{IseID: id})
+	}
+	return channels
+}
+
+// fromUnix converts Unix seconds; 0 (never set) becomes nil
+func fromUnix(seconds int64) *timestamppb.Timestamp {
+	if seconds == 0 {
+		return nil
+	}
+	return timestamppb.New(time.Unix(seconds, 0))
+}
+
+// toUnix converts a timestamp to Unix seconds; nil becomes 0
+func toUnix(ts *timestamppb.Timestamp) int64 {
+	if ts == nil {
+		return 0
+	}
+	return ts.GetSeconds()
+}
+
+// fromTime converts a time; the zero time becomes nil
+func fromTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,84 @@
+package homematicpb
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mheers/homematic-xml-client-go/homematic"
+	"github.com/mheers/homematic-xml-client-go/homematic/fixtures"
+)
+
+// assertSameJSON compares two values by their JSON encoding, which ignores the XMLName fields
+func assertSameJSON(t *testing.T, expected, actual any) {
+	t.Helper()
+	a, _ := json.Marshal(expected)
+	b, _ := json.Marshal(actual)
+	if string(a) != string(b) {
+		t.Errorf("round trip mismatch:\n%s\n%s", a, b)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	server := httptest.NewServer(fixtures.Handler(fixtures.CCU3))
+	defer server.Close()
+	client := homematic.NewClient(server.URL, "token")
+
+	devices, err := client.GetStateList("", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, device := range devices {
+		data, err := proto.Marshal(FromDevice(device))
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		var decoded Device
+		if err := proto.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		assertSameJSON(t, device, ToDevice(&decoded))
+	}
+
+	topology, err := client.GetTopology()
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted := ToTopology(FromTopology(topology))
+	if len(converted.Devices) != len(topology.Devices) || len(converted.Rooms) != len(topology.Rooms) {
+		t.Errorf("unexpected topology: %+v", converted)
+	}
+	if converted.Rooms[0].Channels[0].IseID != topology.Rooms[0].Channels[0].IseID {
+		t.Errorf("expected room channels to be preserved")
+	}
+
+	programs, err := client.GetProgramList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range programs {
+		assertSameJSON(t, p, ToProgram(FromProgram(p)))
+	}
+
+	sysVars, err := client.GetSystemVariableList(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range sysVars {
+		assertSameJSON(t, s, ToSystemVariable(FromSystemVariable(s)))
+	}
+}
+
+func TestEventConversion(t *testing.T) {
+	change := homematic.DataPointChange{IseID: "2456", NewValue: "0.5", Timestamp: 1700000000, ObservedAt: time.Unix(1700000005, 0).UTC()}
+	assertSameJSON(t, change, ToDataPointChange(FromDataPointChange(change)))
+
+	event := FromConnectivityEvent(homematic.ConnectivityEvent{Type: homematic.TokenRejected, Err: errors.New("rejected")})
+	if event.GetType() != ConnectivityEvent_TYPE_TOKEN_REJECTED || event.GetError() != "rejected" || event.GetTime() != nil {
+		t.Errorf("unexpected event: %v", event)
+	}
+}
@@ -0,0 +1,7 @@
+// Package homematicpb contains the protobuf bindings of the canonical
+// HomeMatic schema (proto/homematic/v1/homematic.proto) and converters from
+// and to the types of the homematic package, for gRPC services and
+// cross-language pipelines.
+package homematicpb
+
+//go:generate buf generate ../proto --template ../proto/buf.gen.yaml
@@ -0,0 +1,1373 @@
+// Canonical schema of the HomeMatic data exposed by the XML-API client:
+// the topology of an installation and the events observed on it.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: homematic/v1/homematic.proto
+
+package homematicpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ConnectivityEvent_Type int32
+
+const (
+	ConnectivityEvent_TYPE_UNSPECIFIED    ConnectivityEvent_Type = 0
+	ConnectivityEvent_TYPE_CONNECTED      ConnectivityEvent_Type = 1
+	ConnectivityEvent_TYPE_DEGRADED       ConnectivityEvent_Type = 2
+	ConnectivityEvent_TYPE_RECONNECTED    ConnectivityEvent_Type = 3
+	ConnectivityEvent_TYPE_TOKEN_REJECTED ConnectivityEvent_Type = 4
+)
+
+// Enum value maps for ConnectivityEvent_Type.
+var (
+	ConnectivityEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CONNECTED",
+		2: "TYPE_DEGRADED",
+		3: "TYPE_RECONNECTED",
+		4: "TYPE_TOKEN_REJECTED",
+	}
+	ConnectivityEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED":    0,
+		"TYPE_CONNECTED":      1,
+		"TYPE_DEGRADED":       2,
+		"TYPE_RECONNECTED":    3,
+		"TYPE_TOKEN_REJECTED": 4,
+	}
+)
+
+func (x ConnectivityEvent_Type) Enum() *ConnectivityEvent_Type {
+	p := new(ConnectivityEvent_Type)
+	*p = x
+	return p
+}
+
+func (x ConnectivityEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConnectivityEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_homematic_v1_homematic_proto_enumTypes[0].Descriptor()
+}
+
+func (ConnectivityEvent_Type) Type() protoreflect.EnumType {
+	return &file_homematic_v1_homematic_proto_enumTypes[0]
+}
+
+func (x ConnectivityEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConnectivityEvent_Type.Descriptor instead.
+func (ConnectivityEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{10, 0}
+}
+
+// Topology is the structure of an installation.
+type Topology struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*Device              `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	Rooms         []*Room                `protobuf:"bytes,2,rep,name=rooms,proto3" json:"rooms,omitempty"`
+	Functions     []*Function            `protobuf:"bytes,3,rep,name=functions,proto3" json:"functions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Topology) Reset() {
+	*x = Topology{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Topology) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Topology) ProtoMessage() {}
+
+func (x *Topology) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Topology.ProtoReflect.Descriptor instead.
+func (*Topology) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Topology) GetDevices() []*Device {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+func (x *Topology) GetRooms() []*Room {
+	if x != nil {
+		return x.Rooms
+	}
+	return nil
+}
+
+func (x *Topology) GetFunctions() []*Function {
+	if x != nil {
+		return x.Functions
+	}
+	return nil
+}
+
+// Device is a HomeMatic device.
+type Device struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IseId         string                 `protobuf:"bytes,1,opt,name=ise_id,json=iseId,proto3" json:"ise_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	DeviceType    string                 `protobuf:"bytes,4,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	InterfaceId   string                 `protobuf:"bytes,5,opt,name=interface_id,json=interfaceId,proto3" json:"interface_id,omitempty"`
+	Unreach       bool                   `protobuf:"varint,6,opt,name=unreach,proto3" json:"unreach,omitempty"`
+	Config        bool                   `protobuf:"varint,7,opt,name=config,proto3" json:"config,omitempty"`
+	Channels      []*Channel             `protobuf:"bytes,8,rep,name=channels,proto3" json:"channels,omitempty"`
+	Maintenance   *MaintenanceInfo       `protobuf:"bytes,9,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Device) GetIseId() string {
+	if x != nil {
+		return x.IseId
+	}
+	return ""
+}
+
+func (x *Device) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Device) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Device) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *Device) GetInterfaceId() string {
+	if x != nil {
+		return x.InterfaceId
+	}
+	return ""
+}
+
+func (x *Device) GetUnreach() bool {
+	if x != nil {
+		return x.Unreach
+	}
+	return false
+}
+
+func (x *Device) GetConfig() bool {
+	if x != nil {
+		return x.Config
+	}
+	return false
+}
+
+func (x *Device) GetChannels() []*Channel {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+func (x *Device) GetMaintenance() *MaintenanceInfo {
+	if x != nil {
+		return x.Maintenance
+	}
+	return nil
+}
+
+// Channel is a channel of a device.
+type Channel struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	IseId            string                 `protobuf:"bytes,1,opt,name=ise_id,json=iseId,proto3" json:"ise_id,omitempty"`
+	Name             string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type             string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Address          string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	Index            int32                  `protobuf:"varint,5,opt,name=index,proto3" json:"index,omitempty"`
+	Direction        string                 `protobuf:"bytes,6,opt,name=direction,proto3" json:"direction,omitempty"`
+	ParentType       string                 `protobuf:"bytes,7,opt,name=parent_type,json=parentType,proto3" json:"parent_type,omitempty"`
+	GroupPartner     string                 `protobuf:"bytes,8,opt,name=group_partner,json=groupPartner,proto3" json:"group_partner,omitempty"`
+	AesAvailable     bool                   `protobuf:"varint,9,opt,name=aes_available,json=aesAvailable,proto3" json:"aes_available,omitempty"`
+	TransmissionMode string                 `protobuf:"bytes,10,opt,name=transmission_mode,json=transmissionMode,proto3" json:"transmission_mode,omitempty"`
+	Visible          bool                   `protobuf:"varint,11,opt,name=visible,proto3" json:"visible,omitempty"`
+	ReadyConfig      bool                   `protobuf:"varint,12,opt,name=ready_config,json=readyConfig,proto3" json:"ready_config,omitempty"`
+	Operate          bool                   `protobuf:"varint,13,opt,name=operate,proto3" json:"operate,omitempty"`
+	DataPoints       []*DataPoint           `protobuf:"bytes,14,rep,name=data_points,json=dataPoints,proto3" json:"data_points,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Channel) Reset() {
+	*x = Channel{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Channel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Channel) ProtoMessage() {}
+
+func (x *Channel) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Channel.ProtoReflect.Descriptor instead.
+func (*Channel) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Channel) GetIseId() string {
+	if x != nil {
+		return x.IseId
+	}
+	return ""
+}
+
+func (x *Channel) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Channel) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Channel) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Channel) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Channel) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *Channel) GetParentType() string {
+	if x != nil {
+		return x.ParentType
+	}
+	return ""
+}
+
+func (x *Channel) GetGroupPartner() string {
+	if x != nil {
+		return x.GroupPartner
+	}
+	return ""
+}
+
+func (x *Channel) GetAesAvailable() bool {
+	if x != nil {
+		return x.AesAvailable
+	}
+	return false
+}
+
+func (x *Channel) GetTransmissionMode() string {
+	if x != nil {
+		return x.TransmissionMode
+	}
+	return ""
+}
+
+func (x *Channel) GetVisible() bool {
+	if x != nil {
+		return x.Visible
+	}
+	return false
+}
+
+func (x *Channel) GetReadyConfig() bool {
+	if x != nil {
+		return x.ReadyConfig
+	}
+	return false
+}
+
+func (x *Channel) GetOperate() bool {
+	if x != nil {
+		return x.Operate
+	}
+	return false
+}
+
+func (x *Channel) GetDataPoints() []*DataPoint {
+	if x != nil {
+		return x.DataPoints
+	}
+	return nil
+}
+
+// DataPoint is a data point of a channel.
+type DataPoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IseId         string                 `protobuf:"bytes,1,opt,name=ise_id,json=iseId,proto3" json:"ise_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Value         string                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	ValueType     int32                  `protobuf:"varint,5,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`
+	ValueUnit     string                 `protobuf:"bytes,6,opt,name=value_unit,json=valueUnit,proto3" json:"value_unit,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataPoint) Reset() {
+	*x = DataPoint{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataPoint) ProtoMessage() {}
+
+func (x *DataPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataPoint.ProtoReflect.Descriptor instead.
+func (*DataPoint) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DataPoint) GetIseId() string {
+	if x != nil {
+		return x.IseId
+	}
+	return ""
+}
+
+func (x *DataPoint) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DataPoint) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DataPoint) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *DataPoint) GetValueType() int32 {
+	if x != nil {
+		return x.ValueType
+	}
+	return 0
+}
+
+func (x *DataPoint) GetValueUnit() string {
+	if x != nil {
+		return x.ValueUnit
+	}
+	return ""
+}
+
+func (x *DataPoint) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// MaintenanceInfo is the health data of a device's maintenance channel.
+type MaintenanceInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RssiDevice    *int32                 `protobuf:"varint,1,opt,name=rssi_device,json=rssiDevice,proto3,oneof" json:"rssi_device,omitempty"`
+	RssiPeer      *int32                 `protobuf:"varint,2,opt,name=rssi_peer,json=rssiPeer,proto3,oneof" json:"rssi_peer,omitempty"`
+	Unreach       bool                   `protobuf:"varint,3,opt,name=unreach,proto3" json:"unreach,omitempty"`
+	LowBat        bool                   `protobuf:"varint,4,opt,name=low_bat,json=lowBat,proto3" json:"low_bat,omitempty"`
+	ConfigPending bool                   `protobuf:"varint,5,opt,name=config_pending,json=configPending,proto3" json:"config_pending,omitempty"`
+	UpdatePending bool                   `protobuf:"varint,6,opt,name=update_pending,json=updatePending,proto3" json:"update_pending,omitempty"`
+	DutyCycle     bool                   `protobuf:"varint,7,opt,name=duty_cycle,json=dutyCycle,proto3" json:"duty_cycle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaintenanceInfo) Reset() {
+	*x = MaintenanceInfo{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaintenanceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaintenanceInfo) ProtoMessage() {}
+
+func (x *MaintenanceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaintenanceInfo.ProtoReflect.Descriptor instead.
+func (*MaintenanceInfo) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MaintenanceInfo) GetRssiDevice() int32 {
+	if x != nil && x.RssiDevice != nil {
+		return *x.RssiDevice
+	}
+	return 0
+}
+
+func (x *MaintenanceInfo) GetRssiPeer() int32 {
+	if x != nil && x.RssiPeer != nil {
+		return *x.RssiPeer
+	}
+	return 0
+}
+
+func (x *MaintenanceInfo) GetUnreach() bool {
+	if x != nil {
+		return x.Unreach
+	}
+	return false
+}
+
+func (x *MaintenanceInfo) GetLowBat() bool {
+	if x != nil {
+		return x.LowBat
+	}
+	return false
+}
+
+func (x *MaintenanceInfo) GetConfigPending() bool {
+	if x != nil {
+		return x.ConfigPending
+	}
+	return false
+}
+
+func (x *MaintenanceInfo) GetUpdatePending() bool {
+	if x != nil {
+		return x.UpdatePending
+	}
+	return false
+}
+
+func (x *MaintenanceInfo) GetDutyCycle() bool {
+	if x != nil {
+		return x.DutyCycle
+	}
+	return false
+}
+
+// Room is a room with its channels.
+type Room struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IseId         string                 `protobuf:"bytes,1,opt,name=ise_id,json=iseId,proto3" json:"ise_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ChannelIseIds []string               `protobuf:"bytes,3,rep,name=channel_ise_ids,json=channelIseIds,proto3" json:"channel_ise_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Room) Reset() {
+	*x = Room{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Room) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Room) ProtoMessage() {}
+
+func (x *Room) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Room.ProtoReflect.Descriptor instead.
+func (*Room) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Room) GetIseId() string {
+	if x != nil {
+		return x.IseId
+	}
+	return ""
+}
+
+func (x *Room) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Room) GetChannelIseIds() []string {
+	if x != nil {
+		return x.ChannelIseIds
+	}
+	return nil
+}
+
+// Function is a function (Gewerk) with its channels.
+type Function struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IseId         string                 `protobuf:"bytes,1,opt,name=ise_id,json=iseId,proto3" json:"ise_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ChannelIseIds []string               `protobuf:"bytes,3,rep,name=channel_ise_ids,json=channelIseIds,proto3" json:"channel_ise_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Function) Reset() {
+	*x = Function{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Function) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Function) ProtoMessage() {}
+
+func (x *Function) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Function.ProtoReflect.Descriptor instead.
+func (*Function) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Function) GetIseId() string {
+	if x != nil {
+		return x.IseId
+	}
+	return ""
+}
+
+func (x *Function) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Function) GetChannelIseIds() []string {
+	if x != nil {
+		return x.ChannelIseIds
+	}
+	return nil
+}
+
+// Program is a program of the CCU.
+type Program struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Info          string                 `protobuf:"bytes,4,opt,name=info,proto3" json:"info,omitempty"`
+	Visible       bool                   `protobuf:"varint,5,opt,name=visible,proto3" json:"visible,omitempty"`
+	Active        bool                   `protobuf:"varint,6,opt,name=active,proto3" json:"active,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Program) Reset() {
+	*x = Program{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Program) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Program) ProtoMessage() {}
+
+func (x *Program) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Program.ProtoReflect.Descriptor instead.
+func (*Program) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Program) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Program) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Program) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Program) GetInfo() string {
+	if x != nil {
+		return x.Info
+	}
+	return ""
+}
+
+func (x *Program) GetVisible() bool {
+	if x != nil {
+		return x.Visible
+	}
+	return false
+}
+
+func (x *Program) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *Program) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// SystemVariable is a system variable of the CCU.
+type SystemVariable struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IseId         string                 `protobuf:"bytes,1,opt,name=ise_id,json=iseId,proto3" json:"ise_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	ValueType     int32                  `protobuf:"varint,4,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`
+	Type          string                 `protobuf:"bytes,5,opt,name=type,proto3" json:"type,omitempty"`
+	Subtype       string                 `protobuf:"bytes,6,opt,name=subtype,proto3" json:"subtype,omitempty"`
+	Min           string                 `protobuf:"bytes,7,opt,name=min,proto3" json:"min,omitempty"`
+	Max           string                 `protobuf:"bytes,8,opt,name=max,proto3" json:"max,omitempty"`
+	Unit          string                 `protobuf:"bytes,9,opt,name=unit,proto3" json:"unit,omitempty"`
+	ValueName_0   string                 `protobuf:"bytes,10,opt,name=value_name_0,json=valueName0,proto3" json:"value_name_0,omitempty"`
+	ValueName_1   string                 `protobuf:"bytes,11,opt,name=value_name_1,json=valueName1,proto3" json:"value_name_1,omitempty"`
+	ValueList     string                 `protobuf:"bytes,12,opt,name=value_list,json=valueList,proto3" json:"value_list,omitempty"`
+	ValueText     string                 `protobuf:"bytes,13,opt,name=value_text,json=valueText,proto3" json:"value_text,omitempty"`
+	Logged        bool                   `protobuf:"varint,14,opt,name=logged,proto3" json:"logged,omitempty"`
+	Visible       bool                   `protobuf:"varint,15,opt,name=visible,proto3" json:"visible,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SystemVariable) Reset() {
+	*x = SystemVariable{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SystemVariable) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemVariable) ProtoMessage() {}
+
+func (x *SystemVariable) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemVariable.ProtoReflect.Descriptor instead.
+func (*SystemVariable) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SystemVariable) GetIseId() string {
+	if x != nil {
+		return x.IseId
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetValueType() int32 {
+	if x != nil {
+		return x.ValueType
+	}
+	return 0
+}
+
+func (x *SystemVariable) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetSubtype() string {
+	if x != nil {
+		return x.Subtype
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetMin() string {
+	if x != nil {
+		return x.Min
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetMax() string {
+	if x != nil {
+		return x.Max
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetValueName_0() string {
+	if x != nil {
+		return x.ValueName_0
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetValueName_1() string {
+	if x != nil {
+		return x.ValueName_1
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetValueList() string {
+	if x != nil {
+		return x.ValueList
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetValueText() string {
+	if x != nil {
+		return x.ValueText
+	}
+	return ""
+}
+
+func (x *SystemVariable) GetLogged() bool {
+	if x != nil {
+		return x.Logged
+	}
+	return false
+}
+
+func (x *SystemVariable) GetVisible() bool {
+	if x != nil {
+		return x.Visible
+	}
+	return false
+}
+
+func (x *SystemVariable) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// DataPointChange is an observed change of a data point value.
+type DataPointChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceIseId   string                 `protobuf:"bytes,1,opt,name=device_ise_id,json=deviceIseId,proto3" json:"device_ise_id,omitempty"`
+	DeviceName    string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	ChannelIseId  string                 `protobuf:"bytes,3,opt,name=channel_ise_id,json=channelIseId,proto3" json:"channel_ise_id,omitempty"`
+	ChannelName   string                 `protobuf:"bytes,4,opt,name=channel_name,json=channelName,proto3" json:"channel_name,omitempty"`
+	IseId         string                 `protobuf:"bytes,5,opt,name=ise_id,json=iseId,proto3" json:"ise_id,omitempty"`
+	Name          string                 `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,7,opt,name=type,proto3" json:"type,omitempty"`
+	ValueType     int32                  `protobuf:"varint,8,opt,name=value_type,json=valueType,proto3" json:"value_type,omitempty"`
+	OldValue      string                 `protobuf:"bytes,9,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue      string                 `protobuf:"bytes,10,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ObservedAt    *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=observed_at,json=observedAt,proto3" json:"observed_at,omitempty"`
+	FirstObserved bool                   `protobuf:"varint,13,opt,name=first_observed,json=firstObserved,proto3" json:"first_observed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DataPointChange) Reset() {
+	*x = DataPointChange{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DataPointChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataPointChange) ProtoMessage() {}
+
+func (x *DataPointChange) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataPointChange.ProtoReflect.Descriptor instead.
+func (*DataPointChange) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DataPointChange) GetDeviceIseId() string {
+	if x != nil {
+		return x.DeviceIseId
+	}
+	return ""
+}
+
+func (x *DataPointChange) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *DataPointChange) GetChannelIseId() string {
+	if x != nil {
+		return x.ChannelIseId
+	}
+	return ""
+}
+
+func (x *DataPointChange) GetChannelName() string {
+	if x != nil {
+		return x.ChannelName
+	}
+	return ""
+}
+
+func (x *DataPointChange) GetIseId() string {
+	if x != nil {
+		return x.IseId
+	}
+	return ""
+}
+
+func (x *DataPointChange) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DataPointChange) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DataPointChange) GetValueType() int32 {
+	if x != nil {
+		return x.ValueType
+	}
+	return 0
+}
+
+func (x *DataPointChange) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *DataPointChange) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+func (x *DataPointChange) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *DataPointChange) GetObservedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ObservedAt
+	}
+	return nil
+}
+
+func (x *DataPointChange) GetFirstObserved() bool {
+	if x != nil {
+		return x.FirstObserved
+	}
+	return false
+}
+
+// ConnectivityEvent reports a change of the connection to the CCU.
+type ConnectivityEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          ConnectivityEvent_Type `protobuf:"varint,1,opt,name=type,proto3,enum=homematic.v1.ConnectivityEvent_Type" json:"type,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectivityEvent) Reset() {
+	*x = ConnectivityEvent{}
+	mi := &file_homematic_v1_homematic_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectivityEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectivityEvent) ProtoMessage() {}
+
+func (x *ConnectivityEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_homematic_v1_homematic_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectivityEvent.ProtoReflect.Descriptor instead.
+func (*ConnectivityEvent) Descriptor() ([]byte, []int) {
+	return file_homematic_v1_homematic_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ConnectivityEvent) GetType() ConnectivityEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return ConnectivityEvent_TYPE_UNSPECIFIED
+}
+
+func (x *ConnectivityEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *ConnectivityEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_homematic_v1_homematic_proto protoreflect.FileDescriptor
+
+const file_homematic_v1_homematic_proto_rawDesc = "" +
+	"\n" +
+	"\x1chomematic/v1/homematic.proto\x12\fhomematic.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9a\x01\n" +
+	"\bTopology\x12.\n" +
+	"\adevices\x18\x01 \x03(\v2\x14.homematic.v1.DeviceR\adevices\x12(\n" +
+	"\x05rooms\x18\x02 \x03(\v2\x12.homematic.v1.RoomR\x05rooms\x124\n" +
+	"\tfunctions\x18\x03 \x03(\v2\x16.homematic.v1.FunctionR\tfunctions\"\xb7\x02\n" +
+	"\x06Device\x12\x15\n" +
+	"\x06ise_id\x18\x01 \x01(\tR\x05iseId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12\x1f\n" +
+	"\vdevice_type\x18\x04 \x01(\tR\n" +
+	"deviceType\x12!\n" +
+	"\finterface_id\x18\x05 \x01(\tR\vinterfaceId\x12\x18\n" +
+	"\aunreach\x18\x06 \x01(\bR\aunreach\x12\x16\n" +
+	"\x06config\x18\a \x01(\bR\x06config\x121\n" +
+	"\bchannels\x18\b \x03(\v2\x15.homematic.v1.ChannelR\bchannels\x12?\n" +
+	"\vmaintenance\x18\t \x01(\v2\x1d.homematic.v1.MaintenanceInfoR\vmaintenance\"\xbf\x03\n" +
+	"\aChannel\x12\x15\n" +
+	"\x06ise_id\x18\x01 \x01(\tR\x05iseId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05index\x18\x05 \x01(\x05R\x05index\x12\x1c\n" +
+	"\tdirection\x18\x06 \x01(\tR\tdirection\x12\x1f\n" +
+	"\vparent_type\x18\a \x01(\tR\n" +
+	"parentType\x12#\n" +
+	"\rgroup_partner\x18\b \x01(\tR\fgroupPartner\x12#\n" +
+	"\raes_available\x18\t \x01(\bR\faesAvailable\x12+\n" +
+	"\x11transmission_mode\x18\n" +
+	" \x01(\tR\x10transmissionMode\x12\x18\n" +
+	"\avisible\x18\v \x01(\bR\avisible\x12!\n" +
+	"\fready_config\x18\f \x01(\bR\vreadyConfig\x12\x18\n" +
+	"\aoperate\x18\r \x01(\bR\aoperate\x128\n" +
+	"\vdata_points\x18\x0e \x03(\v2\x17.homematic.v1.DataPointR\n" +
+	"dataPoints\"\xd8\x01\n" +
+	"\tDataPoint\x12\x15\n" +
+	"\x06ise_id\x18\x01 \x01(\tR\x05iseId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\tR\x05value\x12\x1d\n" +
+	"\n" +
+	"value_type\x18\x05 \x01(\x05R\tvalueType\x12\x1d\n" +
+	"\n" +
+	"value_unit\x18\x06 \x01(\tR\tvalueUnit\x128\n" +
+	"\ttimestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\x97\x02\n" +
+	"\x0fMaintenanceInfo\x12$\n" +
+	"\vrssi_device\x18\x01 \x01(\x05H\x00R\n" +
+	"rssiDevice\x88\x01\x01\x12 \n" +
+	"\trssi_peer\x18\x02 \x01(\x05H\x01R\brssiPeer\x88\x01\x01\x12\x18\n" +
+	"\aunreach\x18\x03 \x01(\bR\aunreach\x12\x17\n" +
+	"\alow_bat\x18\x04 \x01(\bR\x06lowBat\x12%\n" +
+	"\x0econfig_pending\x18\x05 \x01(\bR\rconfigPending\x12%\n" +
+	"\x0eupdate_pending\x18\x06 \x01(\bR\rupdatePending\x12\x1d\n" +
+	"\n" +
+	"duty_cycle\x18\a \x01(\bR\tdutyCycleB\x0e\n" +
+	"\f_rssi_deviceB\f\n" +
+	"\n" +
+	"_rssi_peer\"Y\n" +
+	"\x04Room\x12\x15\n" +
+	"\x06ise_id\x18\x01 \x01(\tR\x05iseId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12&\n" +
+	"\x0fchannel_ise_ids\x18\x03 \x03(\tR\rchannelIseIds\"]\n" +
+	"\bFunction\x12\x15\n" +
+	"\x06ise_id\x18\x01 \x01(\tR\x05iseId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12&\n" +
+	"\x0fchannel_ise_ids\x18\x03 \x03(\tR\rchannelIseIds\"\xcf\x01\n" +
+	"\aProgram\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04info\x18\x04 \x01(\tR\x04info\x12\x18\n" +
+	"\avisible\x18\x05 \x01(\bR\avisible\x12\x16\n" +
+	"\x06active\x18\x06 \x01(\bR\x06active\x128\n" +
+	"\ttimestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xc4\x03\n" +
+	"\x0eSystemVariable\x12\x15\n" +
+	"\x06ise_id\x18\x01 \x01(\tR\x05iseId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\x12\x1d\n" +
+	"\n" +
+	"value_type\x18\x04 \x01(\x05R\tvalueType\x12\x12\n" +
+	"\x04type\x18\x05 \x01(\tR\x04type\x12\x18\n" +
+	"\asubtype\x18\x06 \x01(\tR\asubtype\x12\x10\n" +
+	"\x03min\x18\a \x01(\tR\x03min\x12\x10\n" +
+	"\x03max\x18\b \x01(\tR\x03max\x12\x12\n" +
+	"\x04unit\x18\t \x01(\tR\x04unit\x12 \n" +
+	"\fvalue_name_0\x18\n" +
+	" \x01(\tR\n" +
+	"valueName0\x12 \n" +
+	"\fvalue_name_1\x18\v \x01(\tR\n" +
+	"valueName1\x12\x1d\n" +
+	"\n" +
+	"value_list\x18\f \x01(\tR\tvalueList\x12\x1d\n" +
+	"\n" +
+	"value_text\x18\r \x01(\tR\tvalueText\x12\x16\n" +
+	"\x06logged\x18\x0e \x01(\bR\x06logged\x12\x18\n" +
+	"\avisible\x18\x0f \x01(\bR\avisible\x128\n" +
+	"\ttimestamp\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xd5\x03\n" +
+	"\x0fDataPointChange\x12\"\n" +
+	"\rdevice_ise_id\x18\x01 \x01(\tR\vdeviceIseId\x12\x1f\n" +
+	"\vdevice_name\x18\x02 \x01(\tR\n" +
+	"deviceName\x12$\n" +
+	"\x0echannel_ise_id\x18\x03 \x01(\tR\fchannelIseId\x12!\n" +
+	"\fchannel_name\x18\x04 \x01(\tR\vchannelName\x12\x15\n" +
+	"\x06ise_id\x18\x05 \x01(\tR\x05iseId\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\a \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"value_type\x18\b \x01(\x05R\tvalueType\x12\x1b\n" +
+	"\told_value\x18\t \x01(\tR\boldValue\x12\x1b\n" +
+	"\tnew_value\x18\n" +
+	" \x01(\tR\bnewValue\x128\n" +
+	"\ttimestamp\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12;\n" +
+	"\vobserved_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"observedAt\x12%\n" +
+	"\x0efirst_observed\x18\r \x01(\bR\rfirstObserved\"\x87\x02\n" +
+	"\x11ConnectivityEvent\x128\n" +
+	"\x04type\x18\x01 \x01(\x0e2$.homematic.v1.ConnectivityEvent.TypeR\x04type\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"r\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eTYPE_CONNECTED\x10\x01\x12\x11\n" +
+	"\rTYPE_DEGRADED\x10\x02\x12\x14\n" +
+	"\x10TYPE_RECONNECTED\x10\x03\x12\x17\n" +
+	"\x13TYPE_TOKEN_REJECTED\x10\x04BCZAgithub.com/mheers/homematic-xml-client-go/homematicpb;homematicpbb\x06proto3"
+
+var (
+	file_homematic_v1_homematic_proto_rawDescOnce sync.Once
+	file_homematic_v1_homematic_proto_rawDescData []byte
+)
+
+func file_homematic_v1_homematic_proto_rawDescGZIP() []byte {
+	file_homematic_v1_homematic_proto_rawDescOnce.Do(func() {
+		file_homematic_v1_homematic_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_homematic_v1_homematic_proto_rawDesc), len(file_homematic_v1_homematic_proto_rawDesc)))
+	})
+	return file_homematic_v1_homematic_proto_rawDescData
+}
+
+var file_homematic_v1_homematic_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_homematic_v1_homematic_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_homematic_v1_homematic_proto_goTypes = []any{
+	(ConnectivityEvent_Type)(0),   // 0: homematic.v1.ConnectivityEvent.Type
+	(*Topology)(nil),              // 1: homematic.v1.Topology
+	(*Device)(nil),                // 2: homematic.v1.Device
+	(*Channel)(nil),               // 3: homematic.v1.Channel
+	(*DataPoint)(nil),             // 4: homematic.v1.DataPoint
+	(*MaintenanceInfo)(nil),       // 5: homematic.v1.MaintenanceInfo
+	(*Room)(nil),                  // 6: homematic.v1.Room
+	(*Function)(nil),              // 7: homematic.v1.Function
+	(*Program)(nil),               // 8: homematic.v1.Program
+	(*SystemVariable)(nil),        // 9: homematic.v1.SystemVariable
+	(*DataPointChange)(nil),       // 10: homematic.v1.DataPointChange
+	(*ConnectivityEvent)(nil),     // 11: homematic.v1.ConnectivityEvent
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_homematic_v1_homematic_proto_depIdxs = []int32{
+	2,  // 0: homematic.v1.Topology.devices:type_name -> homematic.v1.Device
+	6,  // 1: homematic.v1.Topology.rooms:type_name -> homematic.v1.Room
+	7,  // 2: homematic.v1.Topology.functions:type_name -> homematic.v1.Function
+	3,  // 3: homematic.v1.Device.channels:type_name -> homematic.v1.Channel
+	5,  // 4: homematic.v1.Device.maintenance:type_name -> homematic.v1.MaintenanceInfo
+	4,  // 5: homematic.v1.Channel.data_points:type_name -> homematic.v1.DataPoint
+	12, // 6: homematic.v1.DataPoint.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 7: homematic.v1.Program.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 8: homematic.v1.SystemVariable.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 9: homematic.v1.DataPointChange.timestamp:type_name -> google.protobuf.Timestamp
+	12, // 10: homematic.v1.DataPointChange.observed_at:type_name -> google.protobuf.Timestamp
+	0,  // 11: homematic.v1.ConnectivityEvent.type:type_name -> homematic.v1.ConnectivityEvent.Type
+	12, // 12: homematic.v1.ConnectivityEvent.time:type_name -> google.protobuf.Timestamp
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_homematic_v1_homematic_proto_init() }
+func file_homematic_v1_homematic_proto_init() {
+	if File_homematic_v1_homematic_proto != nil {
+		return
+	}
+	file_homematic_v1_homematic_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_homematic_v1_homematic_proto_rawDesc), len(file_homematic_v1_homematic_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_homematic_v1_homematic_proto_goTypes,
+		DependencyIndexes: file_homematic_v1_homematic_proto_depIdxs,
+		EnumInfos:         file_homematic_v1_homematic_proto_enumTypes,
+		MessageInfos:      file_homematic_v1_homematic_proto_msgTypes,
+	}.Build()
+	File_homematic_v1_homematic_proto = out.File
+	file_homematic_v1_homematic_proto_goTypes = nil
+	file_homematic_v1_homematic_proto_depIdxs = nil
+}